@@ -0,0 +1,201 @@
+// Package audit records who did what to which org, space or user, in an
+// append-only log that is written to one or more sinks
+package audit
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The actions that are recorded
+const (
+	ActionLogin         = "login"
+	ActionUserCreate    = "uaa_user.create"
+	ActionOrgCreate     = "org.create"
+	ActionSpaceCreate   = "space.create"
+	ActionRoleGrant     = "role.grant"
+	ActionTemplateApply = "template.apply"
+	ActionServiceCreate = "service_instance.create"
+	ActionAppPush       = "app.push"
+	ActionMemberRemove  = "member.remove"
+	ActionAuditQuery    = "audit.query"
+	ActionTermsAccept   = "terms.accept"
+	ActionSessionRevoke = "session.revoke"
+)
+
+// The outcomes of an action
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// DefaultQueryLimit is the number of entries a query returns when
+// Filter.Limit is not set
+const DefaultQueryLimit = 100
+
+// recentEntries is the number of entries a Log keeps in memory, for queries
+// when none of its sinks can be queried
+const recentEntries = 1000
+
+// Entry is a single audited action
+type Entry struct {
+	ID   string    `json:"id"`
+	Time time.Time `json:"time"`
+	// Actor is the account name of the user that acted, when it is known
+	Actor string `json:"actor,omitempty"`
+	// ActorID is the UAA user ID of the user that acted, when it is known
+	ActorID string            `json:"actor_id,omitempty"`
+	Action  string            `json:"action"`
+	Target  string            `json:"target"`
+	Outcome string            `json:"outcome"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// Outcome is OutcomeFailure when err is not nil, and OutcomeSuccess otherwise
+func Outcome(err error) string {
+	if err != nil {
+		return OutcomeFailure
+	}
+	return OutcomeSuccess
+}
+
+// Recorder records audit entries
+type Recorder interface {
+	Record(e Entry)
+}
+
+// Record records the entry with r, when there is one
+func Record(r Recorder, e Entry) {
+	if r == nil {
+		return
+	}
+	r.Record(e)
+}
+
+// Sink stores or forwards audit entries
+type Sink interface {
+	Write(e Entry) error
+}
+
+// Querier is a Sink that can search the entries it has stored
+type Querier interface {
+	Query(f Filter) ([]Entry, error)
+}
+
+// Filter selects audit entries; empty fields match every entry
+type Filter struct {
+	Actor   string
+	Action  string
+	Target  string
+	Outcome string
+	Since   time.Time
+	// Limit is the maximum number of entries to return (default: DefaultQueryLimit)
+	Limit int
+}
+
+// Matches is true when the entry is selected by the filter
+func (f Filter) Matches(e Entry) bool {
+	if f.Actor != "" && !strings.EqualFold(f.Actor, e.Actor) && f.Actor != e.ActorID {
+		return false
+	}
+	if f.Action != "" && f.Action != e.Action {
+		return false
+	}
+	if f.Target != "" && !strings.EqualFold(f.Target, e.Target) {
+		return false
+	}
+	if f.Outcome != "" && f.Outcome != e.Outcome {
+		return false
+	}
+	return f.Since.IsZero() || !e.Time.Before(f.Since)
+}
+
+func (f Filter) limit() int {
+	if f.Limit <= 0 {
+		return DefaultQueryLimit
+	}
+	return f.Limit
+}
+
+// Log writes each entry to all of its sinks; a sink that fails does not stop
+// the entry from being written to the others, or the action from completing
+type Log struct {
+	Sinks []Sink
+
+	mu     sync.Mutex
+	recent []Entry
+	now    func() time.Time
+}
+
+// NewLog returns a Log that writes to the sinks
+func NewLog(sinks ...Sink) *Log {
+	return &Log{Sinks: sinks}
+}
+
+// Record assigns the entry an ID and time, and writes it to the sinks
+func (l *Log) Record(e Entry) {
+	if l == nil {
+		return
+	}
+	if e.ID == "" {
+		e.ID = newID()
+	}
+	if e.Time.IsZero() {
+		now := time.Now
+		if l.now != nil {
+			now = l.now
+		}
+		e.Time = now().UTC()
+	}
+	l.mu.Lock()
+	l.recent = append(l.recent, e)
+	if len(l.recent) > recentEntries {
+		l.recent = l.recent[len(l.recent)-recentEntries:]
+	}
+	l.mu.Unlock()
+
+	for _, s := range l.Sinks {
+		if err := s.Write(e); err != nil {
+			log.Printf("could not write audit entry [%s %s %s]: %v\n", e.Action, e.Target, e.Outcome, err)
+		}
+	}
+}
+
+// Query returns the entries selected by the filter, newest first, from the
+// first sink that can be queried; when there is none, only the most recent
+// entries recorded since ignition started are searched
+func (l *Log) Query(f Filter) ([]Entry, error) {
+	for _, s := range l.Sinks {
+		if q, ok := s.(Querier); ok {
+			entries, err := q.Query(f)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not query the audit log")
+			}
+			return entries, nil
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	result := []Entry{}
+	for i := len(l.recent) - 1; i >= 0 && len(result) < f.limit(); i-- {
+		if f.Matches(l.recent[i]) {
+			result = append(result, l.recent[i])
+		}
+	}
+	return result, nil
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,193 @@
+package audit_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/audit"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+type failingSink struct{}
+
+func (failingSink) Write(audit.Entry) error { return errors.New("test error") }
+
+type memorySink struct{ entries []audit.Entry }
+
+func (m *memorySink) Write(e audit.Entry) error {
+	m.entries = append(m.entries, e)
+	return nil
+}
+
+func TestLog(t *testing.T) {
+	spec.Run(t, "Log", testLog, spec.Report(report.Terminal{}))
+}
+
+func testLog(t *testing.T, when spec.G, it spec.S) {
+	it.Before(func() {
+		RegisterTestingT(t)
+	})
+
+	it("writes each entry to every sink, even when one fails", func() {
+		m := &memorySink{}
+		l := audit.NewLog(failingSink{}, m)
+		l.Record(audit.Entry{Actor: "jdoe", Action: audit.ActionOrgCreate, Target: "ignition-jdoe", Outcome: audit.OutcomeSuccess})
+		Expect(m.entries).To(HaveLen(1))
+		Expect(m.entries[0].ID).NotTo(BeEmpty())
+		Expect(m.entries[0].Time).NotTo(BeZero())
+	})
+
+	it("ignores entries when there is no recorder", func() {
+		var l *audit.Log
+		l.Record(audit.Entry{Action: audit.ActionLogin})
+		audit.Record(nil, audit.Entry{Action: audit.ActionLogin})
+	})
+
+	it("queries recent entries when no sink can be queried", func() {
+		l := audit.NewLog(&memorySink{})
+		l.Record(audit.Entry{Actor: "jdoe", Action: audit.ActionLogin, Outcome: audit.OutcomeSuccess})
+		l.Record(audit.Entry{Actor: "asmith", Action: audit.ActionLogin, Outcome: audit.OutcomeSuccess})
+		l.Record(audit.Entry{Actor: "jdoe", Action: audit.ActionOrgCreate, Outcome: audit.OutcomeFailure})
+		entries, err := l.Query(audit.Filter{Actor: "jdoe"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0].Action).To(Equal(audit.ActionOrgCreate))
+
+		entries, err = l.Query(audit.Filter{Outcome: audit.OutcomeSuccess, Limit: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Actor).To(Equal("asmith"))
+	})
+
+	it("describes the outcome of an error", func() {
+		Expect(audit.Outcome(nil)).To(Equal(audit.OutcomeSuccess))
+		Expect(audit.Outcome(errors.New("test error"))).To(Equal(audit.OutcomeFailure))
+	})
+}
+
+func TestFileSink(t *testing.T) {
+	spec.Run(t, "FileSink", testFileSink, spec.Report(report.Terminal{}))
+}
+
+func testFileSink(t *testing.T, when spec.G, it spec.S) {
+	var (
+		dir string
+		s   *audit.FileSink
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		var err error
+		dir, err = ioutil.TempDir("", "audit")
+		Expect(err).NotTo(HaveOccurred())
+		s = &audit.FileSink{Path: filepath.Join(dir, "audit.log")}
+	})
+
+	it.After(func() {
+		os.RemoveAll(dir)
+	})
+
+	it("appends entries as json lines", func() {
+		Expect(s.Write(audit.Entry{ID: "1", Action: audit.ActionLogin})).To(Succeed())
+		Expect(s.Write(audit.Entry{ID: "2", Action: audit.ActionOrgCreate})).To(Succeed())
+		b, err := ioutil.ReadFile(s.Path)
+		Expect(err).NotTo(HaveOccurred())
+		lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+		Expect(lines).To(HaveLen(2))
+		var e audit.Entry
+		Expect(json.Unmarshal([]byte(lines[1]), &e)).To(Succeed())
+		Expect(e.ID).To(Equal("2"))
+		info, err := os.Stat(s.Path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	it("returns the newest matching entries first", func() {
+		start := time.Date(2018, 4, 1, 0, 0, 0, 0, time.UTC)
+		for i, action := range []string{audit.ActionLogin, audit.ActionOrgCreate, audit.ActionLogin, audit.ActionLogin} {
+			Expect(s.Write(audit.Entry{ID: []string{"a", "b", "c", "d"}[i], Time: start.Add(time.Duration(i) * time.Hour), Action: action})).To(Succeed())
+		}
+		entries, err := s.Query(audit.Filter{Action: audit.ActionLogin, Limit: 2})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0].ID).To(Equal("d"))
+		Expect(entries[1].ID).To(Equal("c"))
+
+		entries, err = s.Query(audit.Filter{Since: start.Add(90 * time.Minute)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(2))
+	})
+
+	it("is empty before anything has been written", func() {
+		entries, err := s.Query(audit.Filter{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	it("is used for queries by the log", func() {
+		audit.NewLog(s).Record(audit.Entry{Action: audit.ActionLogin})
+		entries, err := audit.NewLog(s).Query(audit.Filter{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+	})
+}
+
+func TestSyslogSink(t *testing.T) {
+	RegisterTestingT(t)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+	defer l.Close()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('}')
+		received <- line
+	}()
+
+	s := &audit.SyslogSink{Network: "tcp", Address: l.Addr().String(), AppName: "ignition", Hostname: "test-host"}
+	err = s.Write(audit.Entry{
+		ID:      "test-id",
+		Time:    time.Date(2018, 4, 1, 12, 0, 0, 0, time.UTC),
+		Actor:   `j"doe`,
+		Action:  audit.ActionOrgCreate,
+		Target:  "ignition-jdoe",
+		Outcome: audit.OutcomeFailure,
+	})
+	Expect(err).NotTo(HaveOccurred())
+	var msg string
+	Eventually(received).Should(Receive(&msg))
+	Expect(msg).To(MatchRegexp(`^\d+ <84>1 2018-04-01T12:00:00Z test-host ignition \d+ org.create \[audit@32473 actor="j\\"doe" target="ignition-jdoe" outcome="failure"\] \{"id":"test-id"`))
+}
+
+func TestWebhookSink(t *testing.T) {
+	RegisterTestingT(t)
+	received := make(chan audit.Entry, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e audit.Entry
+		json.NewDecoder(r.Body).Decode(&e)
+		received <- e
+	}))
+	defer server.Close()
+
+	s := &audit.WebhookSink{URL: server.URL}
+	Expect(s.Write(audit.Entry{ID: "test-id", Action: audit.ActionLogin})).To(Succeed())
+	var e audit.Entry
+	Eventually(received).Should(Receive(&e))
+	Expect(e.ID).To(Equal("test-id"))
+}
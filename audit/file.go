@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// FileSink appends each entry to a file as a line of JSON
+type FileSink struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// Write appends the entry to the file, creating it when it does not exist
+func (s *FileSink) Write(e Entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrap(err, "could not encode audit entry")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "could not open audit log [%s]", s.Path)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		return errors.Wrapf(err, "could not write to audit log [%s]", s.Path)
+	}
+	return nil
+}
+
+// Query scans the file for the entries selected by the filter, and returns
+// them newest first; lines that cannot be read are skipped
+func (s *FileSink) Query(f Filter) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.Open(s.Path)
+	if os.IsNotExist(err) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open audit log [%s]", s.Path)
+	}
+	defer file.Close()
+
+	// only the newest matches are kept, as the file is read oldest first
+	limit := f.limit()
+	var matches []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if !f.Matches(e) {
+			continue
+		}
+		matches = append(matches, e)
+		if len(matches) > limit {
+			matches = matches[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "could not read audit log [%s]", s.Path)
+	}
+
+	result := make([]Entry, len(matches))
+	for i := range matches {
+		result[i] = matches[len(matches)-1-i]
+	}
+	return result, nil
+}
@@ -0,0 +1,121 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// syslogFacility is the facility (authpriv) of the messages, which is
+// combined with their severity
+const syslogFacility = 10
+
+// The severities of successful and failed actions
+const (
+	severityNotice  = 5
+	severityWarning = 4
+)
+
+// syslogEnterpriseID identifies ignition's structured data element; it is
+// the example enterprise number reserved for documentation by RFC 5612
+const syslogEnterpriseID = 32473
+
+// SyslogSink sends each entry to a syslog server as an RFC 5424 message, with
+// the entry's actor, target and outcome as structured data and the entry as
+// JSON in the message
+type SyslogSink struct {
+	// Network is "udp" or "tcp"; TCP messages are framed with octet counting
+	// (RFC 6587)
+	Network  string
+	Address  string
+	AppName  string
+	Hostname string
+	Timeout  time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// Write sends the entry, reconnecting to the server when the last message
+// could not be sent
+func (s *SyslogSink) Write(e Entry) error {
+	msg, err := s.format(e)
+	if err != nil {
+		return err
+	}
+	if s.Network == "tcp" {
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		timeout := s.Timeout
+		if timeout <= 0 {
+			timeout = 5 * time.Second
+		}
+		s.conn, err = net.DialTimeout(s.Network, s.Address, timeout)
+		if err != nil {
+			s.conn = nil
+			return errors.Wrapf(err, "could not connect to syslog server [%s]", s.Address)
+		}
+	}
+	if _, err := s.conn.Write([]byte(msg)); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return errors.Wrapf(err, "could not send to syslog server [%s]", s.Address)
+	}
+	return nil
+}
+
+// format returns the entry as an RFC 5424 message
+func (s *SyslogSink) format(e Entry) (string, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return "", errors.Wrap(err, "could not encode audit entry")
+	}
+	severity := severityNotice
+	if e.Outcome == OutcomeFailure {
+		severity = severityWarning
+	}
+	hostname := s.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	sd := fmt.Sprintf(`[audit@%d actor="%s" target="%s" outcome="%s"]`, syslogEnterpriseID, sdEscape(e.Actor), sdEscape(e.Target), sdEscape(e.Outcome))
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s %s %s",
+		syslogFacility*8+severity,
+		e.Time.UTC().Format(time.RFC3339Nano),
+		headerField(hostname, 255),
+		headerField(s.AppName, 48),
+		os.Getpid(),
+		headerField(e.Action, 32),
+		sd,
+		b,
+	), nil
+}
+
+// headerField returns the value of a header field, which is "-" when it is
+// empty and may not contain spaces
+func headerField(s string, max int) string {
+	s = strings.Replace(s, " ", "_", -1)
+	if s == "" {
+		return "-"
+	}
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
+
+// sdEscape escapes the characters that RFC 5424 does not allow in a
+// structured data parameter value
+func sdEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// webhookQueueSize is the number of entries that may wait to be posted before
+// new entries are dropped
+const webhookQueueSize = 256
+
+// WebhookSink posts each entry as JSON to a URL; entries are posted in the
+// background, so that a slow receiver does not slow down the action
+type WebhookSink struct {
+	URL     string
+	Client  *http.Client
+	Timeout time.Duration
+
+	once  sync.Once
+	queue chan Entry
+}
+
+// Write queues the entry to be posted, and fails when the queue is full
+func (s *WebhookSink) Write(e Entry) error {
+	s.once.Do(func() {
+		s.queue = make(chan Entry, webhookQueueSize)
+		go s.work()
+	})
+	select {
+	case s.queue <- e:
+		return nil
+	default:
+		return errors.Errorf("the audit webhook queue is full; dropped entry [%s]", e.ID)
+	}
+}
+
+func (s *WebhookSink) work() {
+	for e := range s.queue {
+		if err := s.post(e); err != nil {
+			log.Printf("could not post audit entry [%s]: %v\n", e.ID, err)
+		}
+	}
+}
+
+func (s *WebhookSink) post(e Entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrap(err, "could not encode audit entry")
+	}
+	client := s.Client
+	if client == nil {
+		timeout := s.Timeout
+		if timeout <= 0 {
+			timeout = 10 * time.Second
+		}
+		client = &http.Client{Timeout: timeout}
+	}
+	resp, err := client.Post(s.URL, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return errors.Wrapf(err, "could not post to [%s]", s.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("[%s] responded with %s", s.URL, resp.Status)
+	}
+	return nil
+}
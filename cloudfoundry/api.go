@@ -6,4 +6,9 @@ type API interface {
 	OrganizationQuerier
 	SpaceCreator
 	RoleGrantor
+	InfoQuerier
+	AppDeployer
+	ServiceProvisioner
+	TemplateApplier
+	MemberManager
 }
@@ -0,0 +1,218 @@
+package cloudfoundry
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	"github.com/pkg/errors"
+)
+
+// DefaultStagingTimeout is how long PushApp waits for an app to stage when
+// SampleApp.StagingTimeout is not set
+const DefaultStagingTimeout = 5 * time.Minute
+
+// stagingPollInterval is how often PushApp checks whether the app has staged
+var stagingPollInterval = 2 * time.Second
+
+// The package states of an app that has finished staging
+const (
+	packageStaged = "STAGED"
+	packageFailed = "FAILED"
+)
+
+// SampleApp is an app that is pushed into each new space from a zip file
+type SampleApp struct {
+	Name           string
+	Path           string
+	Memory         int
+	Buildpack      string
+	StagingTimeout time.Duration
+}
+
+// App is an app that has been pushed
+type App struct {
+	GUID  string `json:"guid"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+	URL   string `json:"url"`
+}
+
+// AppPusher creates, uploads and starts apps
+type AppPusher interface {
+	CreateApp(req cfclient.AppCreateRequest) (cfclient.App, error)
+	UploadAppBits(file io.Reader, appGUID string) error
+	StartApp(guid string) error
+}
+
+// AppQuerier is used to query a Cloud Controller API for apps
+type AppQuerier interface {
+	ListAppsByQuery(query url.Values) ([]cfclient.App, error)
+	GetAppByGuidNoInlineCall(guid string) (cfclient.App, error)
+	GetAppRoutes(guid string) ([]cfclient.Route, error)
+	GetSharedDomainByGuid(guid string) (cfclient.SharedDomain, error)
+}
+
+// RouteCreator creates routes and maps them to apps
+type RouteCreator interface {
+	ListSharedDomains() ([]cfclient.SharedDomain, error)
+	CreateRoute(req cfclient.RouteRequest) (cfclient.Route, error)
+	BindRoute(routeGUID, appGUID string) error
+}
+
+// AppDeployer pushes apps and routes them
+type AppDeployer interface {
+	AppPusher
+	AppQuerier
+	RouteCreator
+}
+
+// PushApp pushes the app into the space, with a route on the first shared
+// domain: it creates the app, uploads the zip file as its package, starts it
+// so that it is staged, and waits for staging to finish
+func PushApp(app SampleApp, spaceGUID string, host string, a AppDeployer) (*App, error) {
+	f, err := os.Open(app.Path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open the sample app [%s]", app.Path)
+	}
+	defer f.Close()
+
+	created, err := a.CreateApp(cfclient.AppCreateRequest{
+		Name:      app.Name,
+		SpaceGuid: spaceGUID,
+		Memory:    app.Memory,
+		Instances: 1,
+		Buildpack: app.Buildpack,
+	})
+	if err != nil {
+		return nil, newError(errors.Wrapf(err, "could not create app [%s] in space [%s]", app.Name, spaceGUID))
+	}
+
+	domain, err := sharedDomain(a)
+	if err != nil {
+		return nil, err
+	}
+	route, err := a.CreateRoute(cfclient.RouteRequest{
+		DomainGuid: domain.Guid,
+		SpaceGuid:  spaceGUID,
+		Host:       host,
+	})
+	if err != nil {
+		return nil, newError(errors.Wrapf(err, "could not create route [%s.%s]", host, domain.Name))
+	}
+	if err := a.BindRoute(route.Guid, created.Guid); err != nil {
+		return nil, newError(errors.Wrapf(err, "could not map route [%s.%s] to app [%s]", host, domain.Name, app.Name))
+	}
+
+	if err := a.UploadAppBits(f, created.Guid); err != nil {
+		return nil, newError(errors.Wrapf(err, "could not upload app [%s]", app.Name))
+	}
+	if err := a.StartApp(created.Guid); err != nil {
+		return nil, newError(errors.Wrapf(err, "could not start app [%s]", app.Name))
+	}
+	staged, err := waitForStaging(created.Guid, app.StagingTimeout, a)
+	if err != nil {
+		return nil, err
+	}
+
+	return &App{
+		GUID:  staged.Guid,
+		Name:  staged.Name,
+		State: staged.State,
+		URL:   routeURL(host, domain.Name),
+	}, nil
+}
+
+// FindApp returns the app with the name in the org, or nil when there is no
+// such app
+func FindApp(name string, orgGUID string, a AppQuerier) (*App, error) {
+	q := url.Values{}
+	q.Add("q", fmt.Sprintf("name:%s", name))
+	q.Add("q", fmt.Sprintf("organization_guid:%s", orgGUID))
+	apps, err := a.ListAppsByQuery(q)
+	if err != nil {
+		return nil, newError(errors.Wrapf(err, "could not find app [%s] in org [%s]", name, orgGUID))
+	}
+	if len(apps) == 0 {
+		return nil, nil
+	}
+	result := &App{
+		GUID:  apps[0].Guid,
+		Name:  apps[0].Name,
+		State: apps[0].State,
+	}
+	routes, err := a.GetAppRoutes(apps[0].Guid)
+	if err != nil {
+		return nil, newError(errors.Wrapf(err, "could not find the routes for app [%s]", name))
+	}
+	if len(routes) == 0 {
+		return result, nil
+	}
+	domain, err := a.GetSharedDomainByGuid(routes[0].DomainGuid)
+	if err != nil {
+		return nil, newError(errors.Wrapf(err, "could not find domain [%s]", routes[0].DomainGuid))
+	}
+	result.URL = routeURL(routes[0].Host, domain.Name)
+	return result, nil
+}
+
+// AppHost returns the route host for an app in an org, which must be unique
+// across the foundation and a valid DNS label
+func AppHost(appName string, orgName string) string {
+	host := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(fmt.Sprintf("%s-%s", appName, orgName)))
+	if len(host) > 63 {
+		host = host[:63]
+	}
+	return strings.Trim(host, "-")
+}
+
+func sharedDomain(a RouteCreator) (cfclient.SharedDomain, error) {
+	domains, err := a.ListSharedDomains()
+	if err != nil {
+		return cfclient.SharedDomain{}, newError(errors.Wrap(err, "could not list shared domains"))
+	}
+	for _, d := range domains {
+		// tcp domains need a port rather than a host, and internal domains
+		// cannot be reached by the user
+		if d.RouterGroupType == "" && !d.Internal {
+			return d, nil
+		}
+	}
+	return cfclient.SharedDomain{}, errors.New("there is no shared http domain for the sample app")
+}
+
+func waitForStaging(guid string, timeout time.Duration, a AppQuerier) (cfclient.App, error) {
+	if timeout <= 0 {
+		timeout = DefaultStagingTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		app, err := a.GetAppByGuidNoInlineCall(guid)
+		if err != nil {
+			return cfclient.App{}, newError(errors.Wrapf(err, "could not get the staging status of app [%s]", guid))
+		}
+		switch app.PackageState {
+		case packageStaged:
+			return app, nil
+		case packageFailed:
+			return cfclient.App{}, errors.Errorf("app [%s] failed to stage: %s %s", app.Name, app.StagingFailedReason, app.StagingFailedDescription)
+		}
+		if time.Now().After(deadline) {
+			return cfclient.App{}, errors.Errorf("app [%s] did not stage within %s", app.Name, timeout)
+		}
+		time.Sleep(stagingPollInterval)
+	}
+}
+
+func routeURL(host string, domain string) string {
+	return fmt.Sprintf("https://%s.%s", host, domain)
+}
@@ -0,0 +1,148 @@
+package cloudfoundry_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/cloudfoundry/cloudfoundryfakes"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestPushApp(t *testing.T) {
+	spec.Run(t, "PushApp", testPushApp, spec.Report(report.Terminal{}))
+}
+
+func testPushApp(t *testing.T, when spec.G, it spec.S) {
+	var (
+		a   *cloudfoundryfakes.FakeAPI
+		app cloudfoundry.SampleApp
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		f, err := ioutil.TempFile("", "sample-app")
+		Expect(err).NotTo(HaveOccurred())
+		f.WriteString("zip")
+		f.Close()
+		app = cloudfoundry.SampleApp{Name: "spring-music", Path: f.Name(), Memory: 1024, Buildpack: "java_buildpack"}
+
+		a = &cloudfoundryfakes.FakeAPI{}
+		a.CreateAppReturns(cfclient.App{Guid: "test-app-guid", Name: "spring-music"}, nil)
+		a.ListSharedDomainsReturns([]cfclient.SharedDomain{
+			{Guid: "tcp-domain-guid", Name: "tcp.example.net", RouterGroupType: "tcp"},
+			{Guid: "test-domain-guid", Name: "apps.example.net"},
+		}, nil)
+		a.CreateRouteReturns(cfclient.Route{Guid: "test-route-guid"}, nil)
+		a.GetAppByGuidNoInlineCallReturns(cfclient.App{Guid: "test-app-guid", Name: "spring-music", State: "STARTED", PackageState: "STAGED"}, nil)
+	})
+
+	it.After(func() {
+		os.Remove(app.Path)
+	})
+
+	it("creates, routes, uploads and starts the app", func() {
+		pushed, err := cloudfoundry.PushApp(app, "test-space-guid", "spring-music-ignition-user", a)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pushed).To(Equal(&cloudfoundry.App{
+			GUID:  "test-app-guid",
+			Name:  "spring-music",
+			State: "STARTED",
+			URL:   "https://spring-music-ignition-user.apps.example.net",
+		}))
+
+		req := a.CreateAppArgsForCall(0)
+		Expect(req.Name).To(Equal("spring-music"))
+		Expect(req.SpaceGuid).To(Equal("test-space-guid"))
+		Expect(req.Memory).To(Equal(1024))
+		Expect(req.Buildpack).To(Equal("java_buildpack"))
+		route := a.CreateRouteArgsForCall(0)
+		Expect(route.DomainGuid).To(Equal("test-domain-guid"))
+		Expect(route.Host).To(Equal("spring-music-ignition-user"))
+		routeGUID, appGUID := a.BindRouteArgsForCall(0)
+		Expect(routeGUID).To(Equal("test-route-guid"))
+		Expect(appGUID).To(Equal("test-app-guid"))
+		bits, uploadedGUID := a.UploadAppBitsArgsForCall(0)
+		Expect(uploadedGUID).To(Equal("test-app-guid"))
+		Expect(bits).NotTo(BeNil())
+		Expect(a.StartAppArgsForCall(0)).To(Equal("test-app-guid"))
+	})
+
+	it("fails when the zip file does not exist", func() {
+		app.Path = "/does/not/exist.zip"
+		_, err := cloudfoundry.PushApp(app, "test-space-guid", "host", a)
+		Expect(err).To(MatchError(ContainSubstring("could not open the sample app")))
+		Expect(a.CreateAppCallCount()).To(Equal(0))
+	})
+
+	it("fails when there is no shared http domain", func() {
+		a.ListSharedDomainsReturns([]cfclient.SharedDomain{{Name: "tcp.example.net", RouterGroupType: "tcp"}}, nil)
+		_, err := cloudfoundry.PushApp(app, "test-space-guid", "host", a)
+		Expect(err).To(MatchError(ContainSubstring("no shared http domain")))
+		Expect(a.UploadAppBitsCallCount()).To(Equal(0))
+	})
+
+	it("fails when the app does not stage", func() {
+		a.GetAppByGuidNoInlineCallReturns(cfclient.App{Name: "spring-music", PackageState: "FAILED", StagingFailedReason: "NoAppDetectedError"}, nil)
+		_, err := cloudfoundry.PushApp(app, "test-space-guid", "host", a)
+		Expect(err).To(MatchError(ContainSubstring("app [spring-music] failed to stage: NoAppDetectedError")))
+	})
+
+	it("classifies cloud controller errors", func() {
+		a.StartAppReturns(cfclient.CloudFoundryHTTPError{StatusCode: 503})
+		_, err := cloudfoundry.PushApp(app, "test-space-guid", "host", a)
+		Expect(cloudfoundry.KindOf(err)).To(Equal(cloudfoundry.ErrUnavailable))
+	})
+}
+
+func TestFindApp(t *testing.T) {
+	spec.Run(t, "FindApp", testFindApp, spec.Report(report.Terminal{}))
+}
+
+func testFindApp(t *testing.T, when spec.G, it spec.S) {
+	var a *cloudfoundryfakes.FakeAPI
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		a = &cloudfoundryfakes.FakeAPI{}
+	})
+
+	it("returns nil when the app does not exist", func() {
+		app, err := cloudfoundry.FindApp("spring-music", "test-org-guid", a)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(app).To(BeNil())
+		Expect(a.ListAppsByQueryArgsForCall(0)["q"]).To(Equal([]string{"name:spring-music", "organization_guid:test-org-guid"}))
+	})
+
+	it("returns the app with its route", func() {
+		a.ListAppsByQueryReturns([]cfclient.App{{Guid: "test-app-guid", Name: "spring-music", State: "STARTED"}}, nil)
+		a.GetAppRoutesReturns([]cfclient.Route{{Host: "spring-music-ignition-user", DomainGuid: "test-domain-guid"}}, nil)
+		a.GetSharedDomainByGuidReturns(cfclient.SharedDomain{Name: "apps.example.net"}, nil)
+		app, err := cloudfoundry.FindApp("spring-music", "test-org-guid", a)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(app).To(Equal(&cloudfoundry.App{
+			GUID:  "test-app-guid",
+			Name:  "spring-music",
+			State: "STARTED",
+			URL:   "https://spring-music-ignition-user.apps.example.net",
+		}))
+		Expect(a.GetSharedDomainByGuidArgsForCall(0)).To(Equal("test-domain-guid"))
+	})
+
+	it("returns an error when the apps cannot be listed", func() {
+		a.ListAppsByQueryReturns(nil, errors.New("test error"))
+		_, err := cloudfoundry.FindApp("spring-music", "test-org-guid", a)
+		Expect(err).To(HaveOccurred())
+	})
+}
+
+func TestAppHost(t *testing.T) {
+	RegisterTestingT(t)
+	Expect(cloudfoundry.AppHost("spring-music", "ignition-jdoe")).To(Equal("spring-music-ignition-jdoe"))
+	Expect(cloudfoundry.AppHost("Spring Music", "ignition-j.doe")).To(Equal("spring-music-ignition-j-doe"))
+}
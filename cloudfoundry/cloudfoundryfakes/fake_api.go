@@ -2,6 +2,7 @@
 package cloudfoundryfakes
 
 import (
+	"io"
 	"net/url"
 	"sync"
 
@@ -91,6 +92,539 @@ type FakeAPI struct {
 		result1 cfclient.Org
 		result2 error
 	}
+	GetInfoStub        func() (*cfclient.Info, error)
+	getInfoMutex       sync.RWMutex
+	getInfoArgsForCall []struct{}
+	getInfoReturns     struct {
+		result1 *cfclient.Info
+		result2 error
+	}
+	getInfoReturnsOnCall map[int]struct {
+		result1 *cfclient.Info
+		result2 error
+	}
+	CreateAppStub        func(req cfclient.AppCreateRequest) (cfclient.App, error)
+	createAppMutex       sync.RWMutex
+	createAppArgsForCall []struct {
+		req cfclient.AppCreateRequest
+	}
+	createAppReturns struct {
+		result1 cfclient.App
+		result2 error
+	}
+	createAppReturnsOnCall map[int]struct {
+		result1 cfclient.App
+		result2 error
+	}
+	UploadAppBitsStub        func(file io.Reader, appGUID string) error
+	uploadAppBitsMutex       sync.RWMutex
+	uploadAppBitsArgsForCall []struct {
+		file    io.Reader
+		appGUID string
+	}
+	uploadAppBitsReturns struct {
+		result1 error
+	}
+	uploadAppBitsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StartAppStub        func(guid string) error
+	startAppMutex       sync.RWMutex
+	startAppArgsForCall []struct {
+		guid string
+	}
+	startAppReturns struct {
+		result1 error
+	}
+	startAppReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ListAppsByQueryStub        func(query url.Values) ([]cfclient.App, error)
+	listAppsByQueryMutex       sync.RWMutex
+	listAppsByQueryArgsForCall []struct {
+		query url.Values
+	}
+	listAppsByQueryReturns struct {
+		result1 []cfclient.App
+		result2 error
+	}
+	listAppsByQueryReturnsOnCall map[int]struct {
+		result1 []cfclient.App
+		result2 error
+	}
+	GetAppByGuidNoInlineCallStub        func(guid string) (cfclient.App, error)
+	getAppByGuidNoInlineCallMutex       sync.RWMutex
+	getAppByGuidNoInlineCallArgsForCall []struct {
+		guid string
+	}
+	getAppByGuidNoInlineCallReturns struct {
+		result1 cfclient.App
+		result2 error
+	}
+	getAppByGuidNoInlineCallReturnsOnCall map[int]struct {
+		result1 cfclient.App
+		result2 error
+	}
+	GetAppRoutesStub        func(guid string) ([]cfclient.Route, error)
+	getAppRoutesMutex       sync.RWMutex
+	getAppRoutesArgsForCall []struct {
+		guid string
+	}
+	getAppRoutesReturns struct {
+		result1 []cfclient.Route
+		result2 error
+	}
+	getAppRoutesReturnsOnCall map[int]struct {
+		result1 []cfclient.Route
+		result2 error
+	}
+	GetSharedDomainByGuidStub        func(guid string) (cfclient.SharedDomain, error)
+	getSharedDomainByGuidMutex       sync.RWMutex
+	getSharedDomainByGuidArgsForCall []struct {
+		guid string
+	}
+	getSharedDomainByGuidReturns struct {
+		result1 cfclient.SharedDomain
+		result2 error
+	}
+	getSharedDomainByGuidReturnsOnCall map[int]struct {
+		result1 cfclient.SharedDomain
+		result2 error
+	}
+	ListSharedDomainsStub        func() ([]cfclient.SharedDomain, error)
+	listSharedDomainsMutex       sync.RWMutex
+	listSharedDomainsArgsForCall []struct{}
+	listSharedDomainsReturns     struct {
+		result1 []cfclient.SharedDomain
+		result2 error
+	}
+	listSharedDomainsReturnsOnCall map[int]struct {
+		result1 []cfclient.SharedDomain
+		result2 error
+	}
+	CreateRouteStub        func(req cfclient.RouteRequest) (cfclient.Route, error)
+	createRouteMutex       sync.RWMutex
+	createRouteArgsForCall []struct {
+		req cfclient.RouteRequest
+	}
+	createRouteReturns struct {
+		result1 cfclient.Route
+		result2 error
+	}
+	createRouteReturnsOnCall map[int]struct {
+		result1 cfclient.Route
+		result2 error
+	}
+	BindRouteStub        func(routeGUID, appGUID string) error
+	bindRouteMutex       sync.RWMutex
+	bindRouteArgsForCall []struct {
+		routeGUID string
+		appGUID   string
+	}
+	bindRouteReturns struct {
+		result1 error
+	}
+	bindRouteReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ListServicesByQueryStub        func(query url.Values) ([]cfclient.Service, error)
+	listServicesByQueryMutex       sync.RWMutex
+	listServicesByQueryArgsForCall []struct {
+		query url.Values
+	}
+	listServicesByQueryReturns struct {
+		result1 []cfclient.Service
+		result2 error
+	}
+	listServicesByQueryReturnsOnCall map[int]struct {
+		result1 []cfclient.Service
+		result2 error
+	}
+	ListServicePlansByQueryStub        func(query url.Values) ([]cfclient.ServicePlan, error)
+	listServicePlansByQueryMutex       sync.RWMutex
+	listServicePlansByQueryArgsForCall []struct {
+		query url.Values
+	}
+	listServicePlansByQueryReturns struct {
+		result1 []cfclient.ServicePlan
+		result2 error
+	}
+	listServicePlansByQueryReturnsOnCall map[int]struct {
+		result1 []cfclient.ServicePlan
+		result2 error
+	}
+	ListServicePlanVisibilitiesByQueryStub        func(query url.Values) ([]cfclient.ServicePlanVisibility, error)
+	listServicePlanVisibilitiesByQueryMutex       sync.RWMutex
+	listServicePlanVisibilitiesByQueryArgsForCall []struct {
+		query url.Values
+	}
+	listServicePlanVisibilitiesByQueryReturns struct {
+		result1 []cfclient.ServicePlanVisibility
+		result2 error
+	}
+	listServicePlanVisibilitiesByQueryReturnsOnCall map[int]struct {
+		result1 []cfclient.ServicePlanVisibility
+		result2 error
+	}
+	CreateServiceInstanceStub        func(req cfclient.ServiceInstanceRequest) (cfclient.ServiceInstance, error)
+	createServiceInstanceMutex       sync.RWMutex
+	createServiceInstanceArgsForCall []struct {
+		req cfclient.ServiceInstanceRequest
+	}
+	createServiceInstanceReturns struct {
+		result1 cfclient.ServiceInstance
+		result2 error
+	}
+	createServiceInstanceReturnsOnCall map[int]struct {
+		result1 cfclient.ServiceInstance
+		result2 error
+	}
+	ListIsolationSegmentsByQueryStub        func(query url.Values) ([]cfclient.IsolationSegment, error)
+	listIsolationSegmentsByQueryMutex       sync.RWMutex
+	listIsolationSegmentsByQueryArgsForCall []struct {
+		query url.Values
+	}
+	listIsolationSegmentsByQueryReturns struct {
+		result1 []cfclient.IsolationSegment
+		result2 error
+	}
+	listIsolationSegmentsByQueryReturnsOnCall map[int]struct {
+		result1 []cfclient.IsolationSegment
+		result2 error
+	}
+	AddIsolationSegmentToOrgStub        func(isolationSegmentGUID, orgGUID string) error
+	addIsolationSegmentToOrgMutex       sync.RWMutex
+	addIsolationSegmentToOrgArgsForCall []struct {
+		isolationSegmentGUID string
+		orgGUID              string
+	}
+	addIsolationSegmentToOrgReturns struct {
+		result1 error
+	}
+	addIsolationSegmentToOrgReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DefaultIsolationSegmentForOrgStub        func(orgGUID, isolationSegmentGUID string) error
+	defaultIsolationSegmentForOrgMutex       sync.RWMutex
+	defaultIsolationSegmentForOrgArgsForCall []struct {
+		orgGUID              string
+		isolationSegmentGUID string
+	}
+	defaultIsolationSegmentForOrgReturns struct {
+		result1 error
+	}
+	defaultIsolationSegmentForOrgReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ListDomainsByQueryStub        func(query url.Values) ([]cfclient.Domain, error)
+	listDomainsByQueryMutex       sync.RWMutex
+	listDomainsByQueryArgsForCall []struct {
+		query url.Values
+	}
+	listDomainsByQueryReturns struct {
+		result1 []cfclient.Domain
+		result2 error
+	}
+	listDomainsByQueryReturnsOnCall map[int]struct {
+		result1 []cfclient.Domain
+		result2 error
+	}
+	CreateDomainStub        func(name, orgGUID string) (*cfclient.Domain, error)
+	createDomainMutex       sync.RWMutex
+	createDomainArgsForCall []struct {
+		name    string
+		orgGUID string
+	}
+	createDomainReturns struct {
+		result1 *cfclient.Domain
+		result2 error
+	}
+	createDomainReturnsOnCall map[int]struct {
+		result1 *cfclient.Domain
+		result2 error
+	}
+	ShareOrgPrivateDomainStub        func(orgGUID, privateDomainGUID string) (*cfclient.Domain, error)
+	shareOrgPrivateDomainMutex       sync.RWMutex
+	shareOrgPrivateDomainArgsForCall []struct {
+		orgGUID           string
+		privateDomainGUID string
+	}
+	shareOrgPrivateDomainReturns struct {
+		result1 *cfclient.Domain
+		result2 error
+	}
+	shareOrgPrivateDomainReturnsOnCall map[int]struct {
+		result1 *cfclient.Domain
+		result2 error
+	}
+	ListOrgSpaceQuotasStub        func(orgGUID string) ([]cfclient.SpaceQuota, error)
+	listOrgSpaceQuotasMutex       sync.RWMutex
+	listOrgSpaceQuotasArgsForCall []struct {
+		orgGUID string
+	}
+	listOrgSpaceQuotasReturns struct {
+		result1 []cfclient.SpaceQuota
+		result2 error
+	}
+	listOrgSpaceQuotasReturnsOnCall map[int]struct {
+		result1 []cfclient.SpaceQuota
+		result2 error
+	}
+	CreateSpaceQuotaStub        func(req cfclient.SpaceQuotaRequest) (*cfclient.SpaceQuota, error)
+	createSpaceQuotaMutex       sync.RWMutex
+	createSpaceQuotaArgsForCall []struct {
+		req cfclient.SpaceQuotaRequest
+	}
+	createSpaceQuotaReturns struct {
+		result1 *cfclient.SpaceQuota
+		result2 error
+	}
+	createSpaceQuotaReturnsOnCall map[int]struct {
+		result1 *cfclient.SpaceQuota
+		result2 error
+	}
+	AssignSpaceQuotaStub        func(quotaGUID, spaceGUID string) error
+	assignSpaceQuotaMutex       sync.RWMutex
+	assignSpaceQuotaArgsForCall []struct {
+		quotaGUID string
+		spaceGUID string
+	}
+	assignSpaceQuotaReturns struct {
+		result1 error
+	}
+	assignSpaceQuotaReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetSecGroupByNameStub        func(name string) (cfclient.SecGroup, error)
+	getSecGroupByNameMutex       sync.RWMutex
+	getSecGroupByNameArgsForCall []struct {
+		name string
+	}
+	getSecGroupByNameReturns struct {
+		result1 cfclient.SecGroup
+		result2 error
+	}
+	getSecGroupByNameReturnsOnCall map[int]struct {
+		result1 cfclient.SecGroup
+		result2 error
+	}
+	BindSecGroupStub        func(secGUID, spaceGUID string) error
+	bindSecGroupMutex       sync.RWMutex
+	bindSecGroupArgsForCall []struct {
+		secGUID   string
+		spaceGUID string
+	}
+	bindSecGroupReturns struct {
+		result1 error
+	}
+	bindSecGroupReturnsOnCall map[int]struct {
+		result1 error
+	}
+	AssociateSpaceManagerStub        func(spaceGUID, userGUID string) (cfclient.Space, error)
+	associateSpaceManagerMutex       sync.RWMutex
+	associateSpaceManagerArgsForCall []struct {
+		spaceGUID string
+		userGUID  string
+	}
+	associateSpaceManagerReturns struct {
+		result1 cfclient.Space
+		result2 error
+	}
+	associateSpaceManagerReturnsOnCall map[int]struct {
+		result1 cfclient.Space
+		result2 error
+	}
+	AssociateSpaceDeveloperStub        func(spaceGUID, userGUID string) (cfclient.Space, error)
+	associateSpaceDeveloperMutex       sync.RWMutex
+	associateSpaceDeveloperArgsForCall []struct {
+		spaceGUID string
+		userGUID  string
+	}
+	associateSpaceDeveloperReturns struct {
+		result1 cfclient.Space
+		result2 error
+	}
+	associateSpaceDeveloperReturnsOnCall map[int]struct {
+		result1 cfclient.Space
+		result2 error
+	}
+	AssociateSpaceAuditorStub        func(spaceGUID, userGUID string) (cfclient.Space, error)
+	associateSpaceAuditorMutex       sync.RWMutex
+	associateSpaceAuditorArgsForCall []struct {
+		spaceGUID string
+		userGUID  string
+	}
+	associateSpaceAuditorReturns struct {
+		result1 cfclient.Space
+		result2 error
+	}
+	associateSpaceAuditorReturnsOnCall map[int]struct {
+		result1 cfclient.Space
+		result2 error
+	}
+	ListOrgUsersStub        func(orgGUID string) ([]cfclient.User, error)
+	listOrgUsersMutex       sync.RWMutex
+	listOrgUsersArgsForCall []struct {
+		orgGUID string
+	}
+	listOrgUsersReturns struct {
+		result1 []cfclient.User
+		result2 error
+	}
+	listOrgUsersReturnsOnCall map[int]struct {
+		result1 []cfclient.User
+		result2 error
+	}
+	ListOrgManagersStub        func(orgGUID string) ([]cfclient.User, error)
+	listOrgManagersMutex       sync.RWMutex
+	listOrgManagersArgsForCall []struct {
+		orgGUID string
+	}
+	listOrgManagersReturns struct {
+		result1 []cfclient.User
+		result2 error
+	}
+	listOrgManagersReturnsOnCall map[int]struct {
+		result1 []cfclient.User
+		result2 error
+	}
+	ListOrgAuditorsStub        func(orgGUID string) ([]cfclient.User, error)
+	listOrgAuditorsMutex       sync.RWMutex
+	listOrgAuditorsArgsForCall []struct {
+		orgGUID string
+	}
+	listOrgAuditorsReturns struct {
+		result1 []cfclient.User
+		result2 error
+	}
+	listOrgAuditorsReturnsOnCall map[int]struct {
+		result1 []cfclient.User
+		result2 error
+	}
+	ListSpaceManagersStub        func(spaceGUID string) ([]cfclient.User, error)
+	listSpaceManagersMutex       sync.RWMutex
+	listSpaceManagersArgsForCall []struct {
+		spaceGUID string
+	}
+	listSpaceManagersReturns struct {
+		result1 []cfclient.User
+		result2 error
+	}
+	listSpaceManagersReturnsOnCall map[int]struct {
+		result1 []cfclient.User
+		result2 error
+	}
+	ListSpaceDevelopersStub        func(spaceGUID string) ([]cfclient.User, error)
+	listSpaceDevelopersMutex       sync.RWMutex
+	listSpaceDevelopersArgsForCall []struct {
+		spaceGUID string
+	}
+	listSpaceDevelopersReturns struct {
+		result1 []cfclient.User
+		result2 error
+	}
+	listSpaceDevelopersReturnsOnCall map[int]struct {
+		result1 []cfclient.User
+		result2 error
+	}
+	ListSpaceAuditorsStub        func(spaceGUID string) ([]cfclient.User, error)
+	listSpaceAuditorsMutex       sync.RWMutex
+	listSpaceAuditorsArgsForCall []struct {
+		spaceGUID string
+	}
+	listSpaceAuditorsReturns struct {
+		result1 []cfclient.User
+		result2 error
+	}
+	listSpaceAuditorsReturnsOnCall map[int]struct {
+		result1 []cfclient.User
+		result2 error
+	}
+	RemoveOrgUserStub        func(orgGUID, userGUID string) error
+	removeOrgUserMutex       sync.RWMutex
+	removeOrgUserArgsForCall []struct {
+		orgGUID  string
+		userGUID string
+	}
+	removeOrgUserReturns struct {
+		result1 error
+	}
+	removeOrgUserReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RemoveOrgManagerStub        func(orgGUID, userGUID string) error
+	removeOrgManagerMutex       sync.RWMutex
+	removeOrgManagerArgsForCall []struct {
+		orgGUID  string
+		userGUID string
+	}
+	removeOrgManagerReturns struct {
+		result1 error
+	}
+	removeOrgManagerReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RemoveOrgAuditorStub        func(orgGUID, userGUID string) error
+	removeOrgAuditorMutex       sync.RWMutex
+	removeOrgAuditorArgsForCall []struct {
+		orgGUID  string
+		userGUID string
+	}
+	removeOrgAuditorReturns struct {
+		result1 error
+	}
+	removeOrgAuditorReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RemoveSpaceManagerStub        func(spaceGUID, userGUID string) error
+	removeSpaceManagerMutex       sync.RWMutex
+	removeSpaceManagerArgsForCall []struct {
+		spaceGUID string
+		userGUID  string
+	}
+	removeSpaceManagerReturns struct {
+		result1 error
+	}
+	removeSpaceManagerReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RemoveSpaceDeveloperStub        func(spaceGUID, userGUID string) error
+	removeSpaceDeveloperMutex       sync.RWMutex
+	removeSpaceDeveloperArgsForCall []struct {
+		spaceGUID string
+		userGUID  string
+	}
+	removeSpaceDeveloperReturns struct {
+		result1 error
+	}
+	removeSpaceDeveloperReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RemoveSpaceAuditorStub        func(spaceGUID, userGUID string) error
+	removeSpaceAuditorMutex       sync.RWMutex
+	removeSpaceAuditorArgsForCall []struct {
+		spaceGUID string
+		userGUID  string
+	}
+	removeSpaceAuditorReturns struct {
+		result1 error
+	}
+	removeSpaceAuditorReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ListSpacesByQueryStub        func(query url.Values) ([]cfclient.Space, error)
+	listSpacesByQueryMutex       sync.RWMutex
+	listSpacesByQueryArgsForCall []struct {
+		query url.Values
+	}
+	listSpacesByQueryReturns struct {
+		result1 []cfclient.Space
+		result2 error
+	}
+	listSpacesByQueryReturnsOnCall map[int]struct {
+		result1 []cfclient.Space
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -404,6 +938,2110 @@ func (fake *FakeAPI) AssociateOrgManagerReturnsOnCall(i int, result1 cfclient.Or
 	}{result1, result2}
 }
 
+func (fake *FakeAPI) GetInfo() (*cfclient.Info, error) {
+	fake.getInfoMutex.Lock()
+	ret, specificReturn := fake.getInfoReturnsOnCall[len(fake.getInfoArgsForCall)]
+	fake.getInfoArgsForCall = append(fake.getInfoArgsForCall, struct{}{})
+	fake.recordInvocation("GetInfo", []interface{}{})
+	fake.getInfoMutex.Unlock()
+	if fake.GetInfoStub != nil {
+		return fake.GetInfoStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getInfoReturns.result1, fake.getInfoReturns.result2
+}
+
+func (fake *FakeAPI) GetInfoCallCount() int {
+	fake.getInfoMutex.RLock()
+	defer fake.getInfoMutex.RUnlock()
+	return len(fake.getInfoArgsForCall)
+}
+
+func (fake *FakeAPI) GetInfoReturns(result1 *cfclient.Info, result2 error) {
+	fake.GetInfoStub = nil
+	fake.getInfoReturns = struct {
+		result1 *cfclient.Info
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) GetInfoReturnsOnCall(i int, result1 *cfclient.Info, result2 error) {
+	fake.GetInfoStub = nil
+	if fake.getInfoReturnsOnCall == nil {
+		fake.getInfoReturnsOnCall = make(map[int]struct {
+			result1 *cfclient.Info
+			result2 error
+		})
+	}
+	fake.getInfoReturnsOnCall[i] = struct {
+		result1 *cfclient.Info
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) CreateApp(req cfclient.AppCreateRequest) (cfclient.App, error) {
+	fake.createAppMutex.Lock()
+	ret, specificReturn := fake.createAppReturnsOnCall[len(fake.createAppArgsForCall)]
+	fake.createAppArgsForCall = append(fake.createAppArgsForCall, struct {
+		req cfclient.AppCreateRequest
+	}{req})
+	fake.recordInvocation("CreateApp", []interface{}{req})
+	fake.createAppMutex.Unlock()
+	if fake.CreateAppStub != nil {
+		return fake.CreateAppStub(req)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.createAppReturns.result1, fake.createAppReturns.result2
+}
+
+func (fake *FakeAPI) CreateAppCallCount() int {
+	fake.createAppMutex.RLock()
+	defer fake.createAppMutex.RUnlock()
+	return len(fake.createAppArgsForCall)
+}
+
+func (fake *FakeAPI) CreateAppArgsForCall(i int) cfclient.AppCreateRequest {
+	fake.createAppMutex.RLock()
+	defer fake.createAppMutex.RUnlock()
+	return fake.createAppArgsForCall[i].req
+}
+
+func (fake *FakeAPI) CreateAppReturns(result1 cfclient.App, result2 error) {
+	fake.CreateAppStub = nil
+	fake.createAppReturns = struct {
+		result1 cfclient.App
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) CreateAppReturnsOnCall(i int, result1 cfclient.App, result2 error) {
+	fake.CreateAppStub = nil
+	if fake.createAppReturnsOnCall == nil {
+		fake.createAppReturnsOnCall = make(map[int]struct {
+			result1 cfclient.App
+			result2 error
+		})
+	}
+	fake.createAppReturnsOnCall[i] = struct {
+		result1 cfclient.App
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) UploadAppBits(file io.Reader, appGUID string) error {
+	fake.uploadAppBitsMutex.Lock()
+	ret, specificReturn := fake.uploadAppBitsReturnsOnCall[len(fake.uploadAppBitsArgsForCall)]
+	fake.uploadAppBitsArgsForCall = append(fake.uploadAppBitsArgsForCall, struct {
+		file    io.Reader
+		appGUID string
+	}{file, appGUID})
+	fake.recordInvocation("UploadAppBits", []interface{}{file, appGUID})
+	fake.uploadAppBitsMutex.Unlock()
+	if fake.UploadAppBitsStub != nil {
+		return fake.UploadAppBitsStub(file, appGUID)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.uploadAppBitsReturns.result1
+}
+
+func (fake *FakeAPI) UploadAppBitsCallCount() int {
+	fake.uploadAppBitsMutex.RLock()
+	defer fake.uploadAppBitsMutex.RUnlock()
+	return len(fake.uploadAppBitsArgsForCall)
+}
+
+func (fake *FakeAPI) UploadAppBitsArgsForCall(i int) (io.Reader, string) {
+	fake.uploadAppBitsMutex.RLock()
+	defer fake.uploadAppBitsMutex.RUnlock()
+	return fake.uploadAppBitsArgsForCall[i].file, fake.uploadAppBitsArgsForCall[i].appGUID
+}
+
+func (fake *FakeAPI) UploadAppBitsReturns(result1 error) {
+	fake.UploadAppBitsStub = nil
+	fake.uploadAppBitsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) UploadAppBitsReturnsOnCall(i int, result1 error) {
+	fake.UploadAppBitsStub = nil
+	if fake.uploadAppBitsReturnsOnCall == nil {
+		fake.uploadAppBitsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.uploadAppBitsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) StartApp(guid string) error {
+	fake.startAppMutex.Lock()
+	ret, specificReturn := fake.startAppReturnsOnCall[len(fake.startAppArgsForCall)]
+	fake.startAppArgsForCall = append(fake.startAppArgsForCall, struct {
+		guid string
+	}{guid})
+	fake.recordInvocation("StartApp", []interface{}{guid})
+	fake.startAppMutex.Unlock()
+	if fake.StartAppStub != nil {
+		return fake.StartAppStub(guid)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.startAppReturns.result1
+}
+
+func (fake *FakeAPI) StartAppCallCount() int {
+	fake.startAppMutex.RLock()
+	defer fake.startAppMutex.RUnlock()
+	return len(fake.startAppArgsForCall)
+}
+
+func (fake *FakeAPI) StartAppArgsForCall(i int) string {
+	fake.startAppMutex.RLock()
+	defer fake.startAppMutex.RUnlock()
+	return fake.startAppArgsForCall[i].guid
+}
+
+func (fake *FakeAPI) StartAppReturns(result1 error) {
+	fake.StartAppStub = nil
+	fake.startAppReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) StartAppReturnsOnCall(i int, result1 error) {
+	fake.StartAppStub = nil
+	if fake.startAppReturnsOnCall == nil {
+		fake.startAppReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.startAppReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) ListAppsByQuery(query url.Values) ([]cfclient.App, error) {
+	fake.listAppsByQueryMutex.Lock()
+	ret, specificReturn := fake.listAppsByQueryReturnsOnCall[len(fake.listAppsByQueryArgsForCall)]
+	fake.listAppsByQueryArgsForCall = append(fake.listAppsByQueryArgsForCall, struct {
+		query url.Values
+	}{query})
+	fake.recordInvocation("ListAppsByQuery", []interface{}{query})
+	fake.listAppsByQueryMutex.Unlock()
+	if fake.ListAppsByQueryStub != nil {
+		return fake.ListAppsByQueryStub(query)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listAppsByQueryReturns.result1, fake.listAppsByQueryReturns.result2
+}
+
+func (fake *FakeAPI) ListAppsByQueryCallCount() int {
+	fake.listAppsByQueryMutex.RLock()
+	defer fake.listAppsByQueryMutex.RUnlock()
+	return len(fake.listAppsByQueryArgsForCall)
+}
+
+func (fake *FakeAPI) ListAppsByQueryArgsForCall(i int) url.Values {
+	fake.listAppsByQueryMutex.RLock()
+	defer fake.listAppsByQueryMutex.RUnlock()
+	return fake.listAppsByQueryArgsForCall[i].query
+}
+
+func (fake *FakeAPI) ListAppsByQueryReturns(result1 []cfclient.App, result2 error) {
+	fake.ListAppsByQueryStub = nil
+	fake.listAppsByQueryReturns = struct {
+		result1 []cfclient.App
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListAppsByQueryReturnsOnCall(i int, result1 []cfclient.App, result2 error) {
+	fake.ListAppsByQueryStub = nil
+	if fake.listAppsByQueryReturnsOnCall == nil {
+		fake.listAppsByQueryReturnsOnCall = make(map[int]struct {
+			result1 []cfclient.App
+			result2 error
+		})
+	}
+	fake.listAppsByQueryReturnsOnCall[i] = struct {
+		result1 []cfclient.App
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) GetAppByGuidNoInlineCall(guid string) (cfclient.App, error) {
+	fake.getAppByGuidNoInlineCallMutex.Lock()
+	ret, specificReturn := fake.getAppByGuidNoInlineCallReturnsOnCall[len(fake.getAppByGuidNoInlineCallArgsForCall)]
+	fake.getAppByGuidNoInlineCallArgsForCall = append(fake.getAppByGuidNoInlineCallArgsForCall, struct {
+		guid string
+	}{guid})
+	fake.recordInvocation("GetAppByGuidNoInlineCall", []interface{}{guid})
+	fake.getAppByGuidNoInlineCallMutex.Unlock()
+	if fake.GetAppByGuidNoInlineCallStub != nil {
+		return fake.GetAppByGuidNoInlineCallStub(guid)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getAppByGuidNoInlineCallReturns.result1, fake.getAppByGuidNoInlineCallReturns.result2
+}
+
+func (fake *FakeAPI) GetAppByGuidNoInlineCallCallCount() int {
+	fake.getAppByGuidNoInlineCallMutex.RLock()
+	defer fake.getAppByGuidNoInlineCallMutex.RUnlock()
+	return len(fake.getAppByGuidNoInlineCallArgsForCall)
+}
+
+func (fake *FakeAPI) GetAppByGuidNoInlineCallArgsForCall(i int) string {
+	fake.getAppByGuidNoInlineCallMutex.RLock()
+	defer fake.getAppByGuidNoInlineCallMutex.RUnlock()
+	return fake.getAppByGuidNoInlineCallArgsForCall[i].guid
+}
+
+func (fake *FakeAPI) GetAppByGuidNoInlineCallReturns(result1 cfclient.App, result2 error) {
+	fake.GetAppByGuidNoInlineCallStub = nil
+	fake.getAppByGuidNoInlineCallReturns = struct {
+		result1 cfclient.App
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) GetAppByGuidNoInlineCallReturnsOnCall(i int, result1 cfclient.App, result2 error) {
+	fake.GetAppByGuidNoInlineCallStub = nil
+	if fake.getAppByGuidNoInlineCallReturnsOnCall == nil {
+		fake.getAppByGuidNoInlineCallReturnsOnCall = make(map[int]struct {
+			result1 cfclient.App
+			result2 error
+		})
+	}
+	fake.getAppByGuidNoInlineCallReturnsOnCall[i] = struct {
+		result1 cfclient.App
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) GetAppRoutes(guid string) ([]cfclient.Route, error) {
+	fake.getAppRoutesMutex.Lock()
+	ret, specificReturn := fake.getAppRoutesReturnsOnCall[len(fake.getAppRoutesArgsForCall)]
+	fake.getAppRoutesArgsForCall = append(fake.getAppRoutesArgsForCall, struct {
+		guid string
+	}{guid})
+	fake.recordInvocation("GetAppRoutes", []interface{}{guid})
+	fake.getAppRoutesMutex.Unlock()
+	if fake.GetAppRoutesStub != nil {
+		return fake.GetAppRoutesStub(guid)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getAppRoutesReturns.result1, fake.getAppRoutesReturns.result2
+}
+
+func (fake *FakeAPI) GetAppRoutesCallCount() int {
+	fake.getAppRoutesMutex.RLock()
+	defer fake.getAppRoutesMutex.RUnlock()
+	return len(fake.getAppRoutesArgsForCall)
+}
+
+func (fake *FakeAPI) GetAppRoutesArgsForCall(i int) string {
+	fake.getAppRoutesMutex.RLock()
+	defer fake.getAppRoutesMutex.RUnlock()
+	return fake.getAppRoutesArgsForCall[i].guid
+}
+
+func (fake *FakeAPI) GetAppRoutesReturns(result1 []cfclient.Route, result2 error) {
+	fake.GetAppRoutesStub = nil
+	fake.getAppRoutesReturns = struct {
+		result1 []cfclient.Route
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) GetAppRoutesReturnsOnCall(i int, result1 []cfclient.Route, result2 error) {
+	fake.GetAppRoutesStub = nil
+	if fake.getAppRoutesReturnsOnCall == nil {
+		fake.getAppRoutesReturnsOnCall = make(map[int]struct {
+			result1 []cfclient.Route
+			result2 error
+		})
+	}
+	fake.getAppRoutesReturnsOnCall[i] = struct {
+		result1 []cfclient.Route
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) GetSharedDomainByGuid(guid string) (cfclient.SharedDomain, error) {
+	fake.getSharedDomainByGuidMutex.Lock()
+	ret, specificReturn := fake.getSharedDomainByGuidReturnsOnCall[len(fake.getSharedDomainByGuidArgsForCall)]
+	fake.getSharedDomainByGuidArgsForCall = append(fake.getSharedDomainByGuidArgsForCall, struct {
+		guid string
+	}{guid})
+	fake.recordInvocation("GetSharedDomainByGuid", []interface{}{guid})
+	fake.getSharedDomainByGuidMutex.Unlock()
+	if fake.GetSharedDomainByGuidStub != nil {
+		return fake.GetSharedDomainByGuidStub(guid)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getSharedDomainByGuidReturns.result1, fake.getSharedDomainByGuidReturns.result2
+}
+
+func (fake *FakeAPI) GetSharedDomainByGuidCallCount() int {
+	fake.getSharedDomainByGuidMutex.RLock()
+	defer fake.getSharedDomainByGuidMutex.RUnlock()
+	return len(fake.getSharedDomainByGuidArgsForCall)
+}
+
+func (fake *FakeAPI) GetSharedDomainByGuidArgsForCall(i int) string {
+	fake.getSharedDomainByGuidMutex.RLock()
+	defer fake.getSharedDomainByGuidMutex.RUnlock()
+	return fake.getSharedDomainByGuidArgsForCall[i].guid
+}
+
+func (fake *FakeAPI) GetSharedDomainByGuidReturns(result1 cfclient.SharedDomain, result2 error) {
+	fake.GetSharedDomainByGuidStub = nil
+	fake.getSharedDomainByGuidReturns = struct {
+		result1 cfclient.SharedDomain
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) GetSharedDomainByGuidReturnsOnCall(i int, result1 cfclient.SharedDomain, result2 error) {
+	fake.GetSharedDomainByGuidStub = nil
+	if fake.getSharedDomainByGuidReturnsOnCall == nil {
+		fake.getSharedDomainByGuidReturnsOnCall = make(map[int]struct {
+			result1 cfclient.SharedDomain
+			result2 error
+		})
+	}
+	fake.getSharedDomainByGuidReturnsOnCall[i] = struct {
+		result1 cfclient.SharedDomain
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListSharedDomains() ([]cfclient.SharedDomain, error) {
+	fake.listSharedDomainsMutex.Lock()
+	ret, specificReturn := fake.listSharedDomainsReturnsOnCall[len(fake.listSharedDomainsArgsForCall)]
+	fake.listSharedDomainsArgsForCall = append(fake.listSharedDomainsArgsForCall, struct{}{})
+	fake.recordInvocation("ListSharedDomains", []interface{}{})
+	fake.listSharedDomainsMutex.Unlock()
+	if fake.ListSharedDomainsStub != nil {
+		return fake.ListSharedDomainsStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listSharedDomainsReturns.result1, fake.listSharedDomainsReturns.result2
+}
+
+func (fake *FakeAPI) ListSharedDomainsCallCount() int {
+	fake.listSharedDomainsMutex.RLock()
+	defer fake.listSharedDomainsMutex.RUnlock()
+	return len(fake.listSharedDomainsArgsForCall)
+}
+
+func (fake *FakeAPI) ListSharedDomainsReturns(result1 []cfclient.SharedDomain, result2 error) {
+	fake.ListSharedDomainsStub = nil
+	fake.listSharedDomainsReturns = struct {
+		result1 []cfclient.SharedDomain
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListSharedDomainsReturnsOnCall(i int, result1 []cfclient.SharedDomain, result2 error) {
+	fake.ListSharedDomainsStub = nil
+	if fake.listSharedDomainsReturnsOnCall == nil {
+		fake.listSharedDomainsReturnsOnCall = make(map[int]struct {
+			result1 []cfclient.SharedDomain
+			result2 error
+		})
+	}
+	fake.listSharedDomainsReturnsOnCall[i] = struct {
+		result1 []cfclient.SharedDomain
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) CreateRoute(req cfclient.RouteRequest) (cfclient.Route, error) {
+	fake.createRouteMutex.Lock()
+	ret, specificReturn := fake.createRouteReturnsOnCall[len(fake.createRouteArgsForCall)]
+	fake.createRouteArgsForCall = append(fake.createRouteArgsForCall, struct {
+		req cfclient.RouteRequest
+	}{req})
+	fake.recordInvocation("CreateRoute", []interface{}{req})
+	fake.createRouteMutex.Unlock()
+	if fake.CreateRouteStub != nil {
+		return fake.CreateRouteStub(req)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.createRouteReturns.result1, fake.createRouteReturns.result2
+}
+
+func (fake *FakeAPI) CreateRouteCallCount() int {
+	fake.createRouteMutex.RLock()
+	defer fake.createRouteMutex.RUnlock()
+	return len(fake.createRouteArgsForCall)
+}
+
+func (fake *FakeAPI) CreateRouteArgsForCall(i int) cfclient.RouteRequest {
+	fake.createRouteMutex.RLock()
+	defer fake.createRouteMutex.RUnlock()
+	return fake.createRouteArgsForCall[i].req
+}
+
+func (fake *FakeAPI) CreateRouteReturns(result1 cfclient.Route, result2 error) {
+	fake.CreateRouteStub = nil
+	fake.createRouteReturns = struct {
+		result1 cfclient.Route
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) CreateRouteReturnsOnCall(i int, result1 cfclient.Route, result2 error) {
+	fake.CreateRouteStub = nil
+	if fake.createRouteReturnsOnCall == nil {
+		fake.createRouteReturnsOnCall = make(map[int]struct {
+			result1 cfclient.Route
+			result2 error
+		})
+	}
+	fake.createRouteReturnsOnCall[i] = struct {
+		result1 cfclient.Route
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) BindRoute(routeGUID string, appGUID string) error {
+	fake.bindRouteMutex.Lock()
+	ret, specificReturn := fake.bindRouteReturnsOnCall[len(fake.bindRouteArgsForCall)]
+	fake.bindRouteArgsForCall = append(fake.bindRouteArgsForCall, struct {
+		routeGUID string
+		appGUID   string
+	}{routeGUID, appGUID})
+	fake.recordInvocation("BindRoute", []interface{}{routeGUID, appGUID})
+	fake.bindRouteMutex.Unlock()
+	if fake.BindRouteStub != nil {
+		return fake.BindRouteStub(routeGUID, appGUID)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.bindRouteReturns.result1
+}
+
+func (fake *FakeAPI) BindRouteCallCount() int {
+	fake.bindRouteMutex.RLock()
+	defer fake.bindRouteMutex.RUnlock()
+	return len(fake.bindRouteArgsForCall)
+}
+
+func (fake *FakeAPI) BindRouteArgsForCall(i int) (string, string) {
+	fake.bindRouteMutex.RLock()
+	defer fake.bindRouteMutex.RUnlock()
+	return fake.bindRouteArgsForCall[i].routeGUID, fake.bindRouteArgsForCall[i].appGUID
+}
+
+func (fake *FakeAPI) BindRouteReturns(result1 error) {
+	fake.BindRouteStub = nil
+	fake.bindRouteReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) BindRouteReturnsOnCall(i int, result1 error) {
+	fake.BindRouteStub = nil
+	if fake.bindRouteReturnsOnCall == nil {
+		fake.bindRouteReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.bindRouteReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) ListServicesByQuery(query url.Values) ([]cfclient.Service, error) {
+	fake.listServicesByQueryMutex.Lock()
+	ret, specificReturn := fake.listServicesByQueryReturnsOnCall[len(fake.listServicesByQueryArgsForCall)]
+	fake.listServicesByQueryArgsForCall = append(fake.listServicesByQueryArgsForCall, struct {
+		query url.Values
+	}{query})
+	fake.recordInvocation("ListServicesByQuery", []interface{}{query})
+	fake.listServicesByQueryMutex.Unlock()
+	if fake.ListServicesByQueryStub != nil {
+		return fake.ListServicesByQueryStub(query)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listServicesByQueryReturns.result1, fake.listServicesByQueryReturns.result2
+}
+
+func (fake *FakeAPI) ListServicesByQueryCallCount() int {
+	fake.listServicesByQueryMutex.RLock()
+	defer fake.listServicesByQueryMutex.RUnlock()
+	return len(fake.listServicesByQueryArgsForCall)
+}
+
+func (fake *FakeAPI) ListServicesByQueryArgsForCall(i int) url.Values {
+	fake.listServicesByQueryMutex.RLock()
+	defer fake.listServicesByQueryMutex.RUnlock()
+	return fake.listServicesByQueryArgsForCall[i].query
+}
+
+func (fake *FakeAPI) ListServicesByQueryReturns(result1 []cfclient.Service, result2 error) {
+	fake.ListServicesByQueryStub = nil
+	fake.listServicesByQueryReturns = struct {
+		result1 []cfclient.Service
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListServicesByQueryReturnsOnCall(i int, result1 []cfclient.Service, result2 error) {
+	fake.ListServicesByQueryStub = nil
+	if fake.listServicesByQueryReturnsOnCall == nil {
+		fake.listServicesByQueryReturnsOnCall = make(map[int]struct {
+			result1 []cfclient.Service
+			result2 error
+		})
+	}
+	fake.listServicesByQueryReturnsOnCall[i] = struct {
+		result1 []cfclient.Service
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListServicePlansByQuery(query url.Values) ([]cfclient.ServicePlan, error) {
+	fake.listServicePlansByQueryMutex.Lock()
+	ret, specificReturn := fake.listServicePlansByQueryReturnsOnCall[len(fake.listServicePlansByQueryArgsForCall)]
+	fake.listServicePlansByQueryArgsForCall = append(fake.listServicePlansByQueryArgsForCall, struct {
+		query url.Values
+	}{query})
+	fake.recordInvocation("ListServicePlansByQuery", []interface{}{query})
+	fake.listServicePlansByQueryMutex.Unlock()
+	if fake.ListServicePlansByQueryStub != nil {
+		return fake.ListServicePlansByQueryStub(query)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listServicePlansByQueryReturns.result1, fake.listServicePlansByQueryReturns.result2
+}
+
+func (fake *FakeAPI) ListServicePlansByQueryCallCount() int {
+	fake.listServicePlansByQueryMutex.RLock()
+	defer fake.listServicePlansByQueryMutex.RUnlock()
+	return len(fake.listServicePlansByQueryArgsForCall)
+}
+
+func (fake *FakeAPI) ListServicePlansByQueryArgsForCall(i int) url.Values {
+	fake.listServicePlansByQueryMutex.RLock()
+	defer fake.listServicePlansByQueryMutex.RUnlock()
+	return fake.listServicePlansByQueryArgsForCall[i].query
+}
+
+func (fake *FakeAPI) ListServicePlansByQueryReturns(result1 []cfclient.ServicePlan, result2 error) {
+	fake.ListServicePlansByQueryStub = nil
+	fake.listServicePlansByQueryReturns = struct {
+		result1 []cfclient.ServicePlan
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListServicePlansByQueryReturnsOnCall(i int, result1 []cfclient.ServicePlan, result2 error) {
+	fake.ListServicePlansByQueryStub = nil
+	if fake.listServicePlansByQueryReturnsOnCall == nil {
+		fake.listServicePlansByQueryReturnsOnCall = make(map[int]struct {
+			result1 []cfclient.ServicePlan
+			result2 error
+		})
+	}
+	fake.listServicePlansByQueryReturnsOnCall[i] = struct {
+		result1 []cfclient.ServicePlan
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListServicePlanVisibilitiesByQuery(query url.Values) ([]cfclient.ServicePlanVisibility, error) {
+	fake.listServicePlanVisibilitiesByQueryMutex.Lock()
+	ret, specificReturn := fake.listServicePlanVisibilitiesByQueryReturnsOnCall[len(fake.listServicePlanVisibilitiesByQueryArgsForCall)]
+	fake.listServicePlanVisibilitiesByQueryArgsForCall = append(fake.listServicePlanVisibilitiesByQueryArgsForCall, struct {
+		query url.Values
+	}{query})
+	fake.recordInvocation("ListServicePlanVisibilitiesByQuery", []interface{}{query})
+	fake.listServicePlanVisibilitiesByQueryMutex.Unlock()
+	if fake.ListServicePlanVisibilitiesByQueryStub != nil {
+		return fake.ListServicePlanVisibilitiesByQueryStub(query)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listServicePlanVisibilitiesByQueryReturns.result1, fake.listServicePlanVisibilitiesByQueryReturns.result2
+}
+
+func (fake *FakeAPI) ListServicePlanVisibilitiesByQueryCallCount() int {
+	fake.listServicePlanVisibilitiesByQueryMutex.RLock()
+	defer fake.listServicePlanVisibilitiesByQueryMutex.RUnlock()
+	return len(fake.listServicePlanVisibilitiesByQueryArgsForCall)
+}
+
+func (fake *FakeAPI) ListServicePlanVisibilitiesByQueryArgsForCall(i int) url.Values {
+	fake.listServicePlanVisibilitiesByQueryMutex.RLock()
+	defer fake.listServicePlanVisibilitiesByQueryMutex.RUnlock()
+	return fake.listServicePlanVisibilitiesByQueryArgsForCall[i].query
+}
+
+func (fake *FakeAPI) ListServicePlanVisibilitiesByQueryReturns(result1 []cfclient.ServicePlanVisibility, result2 error) {
+	fake.ListServicePlanVisibilitiesByQueryStub = nil
+	fake.listServicePlanVisibilitiesByQueryReturns = struct {
+		result1 []cfclient.ServicePlanVisibility
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListServicePlanVisibilitiesByQueryReturnsOnCall(i int, result1 []cfclient.ServicePlanVisibility, result2 error) {
+	fake.ListServicePlanVisibilitiesByQueryStub = nil
+	if fake.listServicePlanVisibilitiesByQueryReturnsOnCall == nil {
+		fake.listServicePlanVisibilitiesByQueryReturnsOnCall = make(map[int]struct {
+			result1 []cfclient.ServicePlanVisibility
+			result2 error
+		})
+	}
+	fake.listServicePlanVisibilitiesByQueryReturnsOnCall[i] = struct {
+		result1 []cfclient.ServicePlanVisibility
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) CreateServiceInstance(req cfclient.ServiceInstanceRequest) (cfclient.ServiceInstance, error) {
+	fake.createServiceInstanceMutex.Lock()
+	ret, specificReturn := fake.createServiceInstanceReturnsOnCall[len(fake.createServiceInstanceArgsForCall)]
+	fake.createServiceInstanceArgsForCall = append(fake.createServiceInstanceArgsForCall, struct {
+		req cfclient.ServiceInstanceRequest
+	}{req})
+	fake.recordInvocation("CreateServiceInstance", []interface{}{req})
+	fake.createServiceInstanceMutex.Unlock()
+	if fake.CreateServiceInstanceStub != nil {
+		return fake.CreateServiceInstanceStub(req)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.createServiceInstanceReturns.result1, fake.createServiceInstanceReturns.result2
+}
+
+func (fake *FakeAPI) CreateServiceInstanceCallCount() int {
+	fake.createServiceInstanceMutex.RLock()
+	defer fake.createServiceInstanceMutex.RUnlock()
+	return len(fake.createServiceInstanceArgsForCall)
+}
+
+func (fake *FakeAPI) CreateServiceInstanceArgsForCall(i int) cfclient.ServiceInstanceRequest {
+	fake.createServiceInstanceMutex.RLock()
+	defer fake.createServiceInstanceMutex.RUnlock()
+	return fake.createServiceInstanceArgsForCall[i].req
+}
+
+func (fake *FakeAPI) CreateServiceInstanceReturns(result1 cfclient.ServiceInstance, result2 error) {
+	fake.CreateServiceInstanceStub = nil
+	fake.createServiceInstanceReturns = struct {
+		result1 cfclient.ServiceInstance
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) CreateServiceInstanceReturnsOnCall(i int, result1 cfclient.ServiceInstance, result2 error) {
+	fake.CreateServiceInstanceStub = nil
+	if fake.createServiceInstanceReturnsOnCall == nil {
+		fake.createServiceInstanceReturnsOnCall = make(map[int]struct {
+			result1 cfclient.ServiceInstance
+			result2 error
+		})
+	}
+	fake.createServiceInstanceReturnsOnCall[i] = struct {
+		result1 cfclient.ServiceInstance
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListIsolationSegmentsByQuery(query url.Values) ([]cfclient.IsolationSegment, error) {
+	fake.listIsolationSegmentsByQueryMutex.Lock()
+	ret, specificReturn := fake.listIsolationSegmentsByQueryReturnsOnCall[len(fake.listIsolationSegmentsByQueryArgsForCall)]
+	fake.listIsolationSegmentsByQueryArgsForCall = append(fake.listIsolationSegmentsByQueryArgsForCall, struct {
+		query url.Values
+	}{query})
+	fake.recordInvocation("ListIsolationSegmentsByQuery", []interface{}{query})
+	fake.listIsolationSegmentsByQueryMutex.Unlock()
+	if fake.ListIsolationSegmentsByQueryStub != nil {
+		return fake.ListIsolationSegmentsByQueryStub(query)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listIsolationSegmentsByQueryReturns.result1, fake.listIsolationSegmentsByQueryReturns.result2
+}
+
+func (fake *FakeAPI) ListIsolationSegmentsByQueryCallCount() int {
+	fake.listIsolationSegmentsByQueryMutex.RLock()
+	defer fake.listIsolationSegmentsByQueryMutex.RUnlock()
+	return len(fake.listIsolationSegmentsByQueryArgsForCall)
+}
+
+func (fake *FakeAPI) ListIsolationSegmentsByQueryArgsForCall(i int) url.Values {
+	fake.listIsolationSegmentsByQueryMutex.RLock()
+	defer fake.listIsolationSegmentsByQueryMutex.RUnlock()
+	return fake.listIsolationSegmentsByQueryArgsForCall[i].query
+}
+
+func (fake *FakeAPI) ListIsolationSegmentsByQueryReturns(result1 []cfclient.IsolationSegment, result2 error) {
+	fake.ListIsolationSegmentsByQueryStub = nil
+	fake.listIsolationSegmentsByQueryReturns = struct {
+		result1 []cfclient.IsolationSegment
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListIsolationSegmentsByQueryReturnsOnCall(i int, result1 []cfclient.IsolationSegment, result2 error) {
+	fake.ListIsolationSegmentsByQueryStub = nil
+	if fake.listIsolationSegmentsByQueryReturnsOnCall == nil {
+		fake.listIsolationSegmentsByQueryReturnsOnCall = make(map[int]struct {
+			result1 []cfclient.IsolationSegment
+			result2 error
+		})
+	}
+	fake.listIsolationSegmentsByQueryReturnsOnCall[i] = struct {
+		result1 []cfclient.IsolationSegment
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) AddIsolationSegmentToOrg(isolationSegmentGUID string, orgGUID string) error {
+	fake.addIsolationSegmentToOrgMutex.Lock()
+	ret, specificReturn := fake.addIsolationSegmentToOrgReturnsOnCall[len(fake.addIsolationSegmentToOrgArgsForCall)]
+	fake.addIsolationSegmentToOrgArgsForCall = append(fake.addIsolationSegmentToOrgArgsForCall, struct {
+		isolationSegmentGUID string
+		orgGUID              string
+	}{isolationSegmentGUID, orgGUID})
+	fake.recordInvocation("AddIsolationSegmentToOrg", []interface{}{isolationSegmentGUID, orgGUID})
+	fake.addIsolationSegmentToOrgMutex.Unlock()
+	if fake.AddIsolationSegmentToOrgStub != nil {
+		return fake.AddIsolationSegmentToOrgStub(isolationSegmentGUID, orgGUID)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.addIsolationSegmentToOrgReturns.result1
+}
+
+func (fake *FakeAPI) AddIsolationSegmentToOrgCallCount() int {
+	fake.addIsolationSegmentToOrgMutex.RLock()
+	defer fake.addIsolationSegmentToOrgMutex.RUnlock()
+	return len(fake.addIsolationSegmentToOrgArgsForCall)
+}
+
+func (fake *FakeAPI) AddIsolationSegmentToOrgArgsForCall(i int) (string, string) {
+	fake.addIsolationSegmentToOrgMutex.RLock()
+	defer fake.addIsolationSegmentToOrgMutex.RUnlock()
+	return fake.addIsolationSegmentToOrgArgsForCall[i].isolationSegmentGUID, fake.addIsolationSegmentToOrgArgsForCall[i].orgGUID
+}
+
+func (fake *FakeAPI) AddIsolationSegmentToOrgReturns(result1 error) {
+	fake.AddIsolationSegmentToOrgStub = nil
+	fake.addIsolationSegmentToOrgReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) AddIsolationSegmentToOrgReturnsOnCall(i int, result1 error) {
+	fake.AddIsolationSegmentToOrgStub = nil
+	if fake.addIsolationSegmentToOrgReturnsOnCall == nil {
+		fake.addIsolationSegmentToOrgReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.addIsolationSegmentToOrgReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) DefaultIsolationSegmentForOrg(orgGUID string, isolationSegmentGUID string) error {
+	fake.defaultIsolationSegmentForOrgMutex.Lock()
+	ret, specificReturn := fake.defaultIsolationSegmentForOrgReturnsOnCall[len(fake.defaultIsolationSegmentForOrgArgsForCall)]
+	fake.defaultIsolationSegmentForOrgArgsForCall = append(fake.defaultIsolationSegmentForOrgArgsForCall, struct {
+		orgGUID              string
+		isolationSegmentGUID string
+	}{orgGUID, isolationSegmentGUID})
+	fake.recordInvocation("DefaultIsolationSegmentForOrg", []interface{}{orgGUID, isolationSegmentGUID})
+	fake.defaultIsolationSegmentForOrgMutex.Unlock()
+	if fake.DefaultIsolationSegmentForOrgStub != nil {
+		return fake.DefaultIsolationSegmentForOrgStub(orgGUID, isolationSegmentGUID)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.defaultIsolationSegmentForOrgReturns.result1
+}
+
+func (fake *FakeAPI) DefaultIsolationSegmentForOrgCallCount() int {
+	fake.defaultIsolationSegmentForOrgMutex.RLock()
+	defer fake.defaultIsolationSegmentForOrgMutex.RUnlock()
+	return len(fake.defaultIsolationSegmentForOrgArgsForCall)
+}
+
+func (fake *FakeAPI) DefaultIsolationSegmentForOrgArgsForCall(i int) (string, string) {
+	fake.defaultIsolationSegmentForOrgMutex.RLock()
+	defer fake.defaultIsolationSegmentForOrgMutex.RUnlock()
+	return fake.defaultIsolationSegmentForOrgArgsForCall[i].orgGUID, fake.defaultIsolationSegmentForOrgArgsForCall[i].isolationSegmentGUID
+}
+
+func (fake *FakeAPI) DefaultIsolationSegmentForOrgReturns(result1 error) {
+	fake.DefaultIsolationSegmentForOrgStub = nil
+	fake.defaultIsolationSegmentForOrgReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) DefaultIsolationSegmentForOrgReturnsOnCall(i int, result1 error) {
+	fake.DefaultIsolationSegmentForOrgStub = nil
+	if fake.defaultIsolationSegmentForOrgReturnsOnCall == nil {
+		fake.defaultIsolationSegmentForOrgReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.defaultIsolationSegmentForOrgReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) ListDomainsByQuery(query url.Values) ([]cfclient.Domain, error) {
+	fake.listDomainsByQueryMutex.Lock()
+	ret, specificReturn := fake.listDomainsByQueryReturnsOnCall[len(fake.listDomainsByQueryArgsForCall)]
+	fake.listDomainsByQueryArgsForCall = append(fake.listDomainsByQueryArgsForCall, struct {
+		query url.Values
+	}{query})
+	fake.recordInvocation("ListDomainsByQuery", []interface{}{query})
+	fake.listDomainsByQueryMutex.Unlock()
+	if fake.ListDomainsByQueryStub != nil {
+		return fake.ListDomainsByQueryStub(query)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listDomainsByQueryReturns.result1, fake.listDomainsByQueryReturns.result2
+}
+
+func (fake *FakeAPI) ListDomainsByQueryCallCount() int {
+	fake.listDomainsByQueryMutex.RLock()
+	defer fake.listDomainsByQueryMutex.RUnlock()
+	return len(fake.listDomainsByQueryArgsForCall)
+}
+
+func (fake *FakeAPI) ListDomainsByQueryArgsForCall(i int) url.Values {
+	fake.listDomainsByQueryMutex.RLock()
+	defer fake.listDomainsByQueryMutex.RUnlock()
+	return fake.listDomainsByQueryArgsForCall[i].query
+}
+
+func (fake *FakeAPI) ListDomainsByQueryReturns(result1 []cfclient.Domain, result2 error) {
+	fake.ListDomainsByQueryStub = nil
+	fake.listDomainsByQueryReturns = struct {
+		result1 []cfclient.Domain
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListDomainsByQueryReturnsOnCall(i int, result1 []cfclient.Domain, result2 error) {
+	fake.ListDomainsByQueryStub = nil
+	if fake.listDomainsByQueryReturnsOnCall == nil {
+		fake.listDomainsByQueryReturnsOnCall = make(map[int]struct {
+			result1 []cfclient.Domain
+			result2 error
+		})
+	}
+	fake.listDomainsByQueryReturnsOnCall[i] = struct {
+		result1 []cfclient.Domain
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) CreateDomain(name string, orgGUID string) (*cfclient.Domain, error) {
+	fake.createDomainMutex.Lock()
+	ret, specificReturn := fake.createDomainReturnsOnCall[len(fake.createDomainArgsForCall)]
+	fake.createDomainArgsForCall = append(fake.createDomainArgsForCall, struct {
+		name    string
+		orgGUID string
+	}{name, orgGUID})
+	fake.recordInvocation("CreateDomain", []interface{}{name, orgGUID})
+	fake.createDomainMutex.Unlock()
+	if fake.CreateDomainStub != nil {
+		return fake.CreateDomainStub(name, orgGUID)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.createDomainReturns.result1, fake.createDomainReturns.result2
+}
+
+func (fake *FakeAPI) CreateDomainCallCount() int {
+	fake.createDomainMutex.RLock()
+	defer fake.createDomainMutex.RUnlock()
+	return len(fake.createDomainArgsForCall)
+}
+
+func (fake *FakeAPI) CreateDomainArgsForCall(i int) (string, string) {
+	fake.createDomainMutex.RLock()
+	defer fake.createDomainMutex.RUnlock()
+	return fake.createDomainArgsForCall[i].name, fake.createDomainArgsForCall[i].orgGUID
+}
+
+func (fake *FakeAPI) CreateDomainReturns(result1 *cfclient.Domain, result2 error) {
+	fake.CreateDomainStub = nil
+	fake.createDomainReturns = struct {
+		result1 *cfclient.Domain
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) CreateDomainReturnsOnCall(i int, result1 *cfclient.Domain, result2 error) {
+	fake.CreateDomainStub = nil
+	if fake.createDomainReturnsOnCall == nil {
+		fake.createDomainReturnsOnCall = make(map[int]struct {
+			result1 *cfclient.Domain
+			result2 error
+		})
+	}
+	fake.createDomainReturnsOnCall[i] = struct {
+		result1 *cfclient.Domain
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ShareOrgPrivateDomain(orgGUID string, privateDomainGUID string) (*cfclient.Domain, error) {
+	fake.shareOrgPrivateDomainMutex.Lock()
+	ret, specificReturn := fake.shareOrgPrivateDomainReturnsOnCall[len(fake.shareOrgPrivateDomainArgsForCall)]
+	fake.shareOrgPrivateDomainArgsForCall = append(fake.shareOrgPrivateDomainArgsForCall, struct {
+		orgGUID           string
+		privateDomainGUID string
+	}{orgGUID, privateDomainGUID})
+	fake.recordInvocation("ShareOrgPrivateDomain", []interface{}{orgGUID, privateDomainGUID})
+	fake.shareOrgPrivateDomainMutex.Unlock()
+	if fake.ShareOrgPrivateDomainStub != nil {
+		return fake.ShareOrgPrivateDomainStub(orgGUID, privateDomainGUID)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.shareOrgPrivateDomainReturns.result1, fake.shareOrgPrivateDomainReturns.result2
+}
+
+func (fake *FakeAPI) ShareOrgPrivateDomainCallCount() int {
+	fake.shareOrgPrivateDomainMutex.RLock()
+	defer fake.shareOrgPrivateDomainMutex.RUnlock()
+	return len(fake.shareOrgPrivateDomainArgsForCall)
+}
+
+func (fake *FakeAPI) ShareOrgPrivateDomainArgsForCall(i int) (string, string) {
+	fake.shareOrgPrivateDomainMutex.RLock()
+	defer fake.shareOrgPrivateDomainMutex.RUnlock()
+	return fake.shareOrgPrivateDomainArgsForCall[i].orgGUID, fake.shareOrgPrivateDomainArgsForCall[i].privateDomainGUID
+}
+
+func (fake *FakeAPI) ShareOrgPrivateDomainReturns(result1 *cfclient.Domain, result2 error) {
+	fake.ShareOrgPrivateDomainStub = nil
+	fake.shareOrgPrivateDomainReturns = struct {
+		result1 *cfclient.Domain
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ShareOrgPrivateDomainReturnsOnCall(i int, result1 *cfclient.Domain, result2 error) {
+	fake.ShareOrgPrivateDomainStub = nil
+	if fake.shareOrgPrivateDomainReturnsOnCall == nil {
+		fake.shareOrgPrivateDomainReturnsOnCall = make(map[int]struct {
+			result1 *cfclient.Domain
+			result2 error
+		})
+	}
+	fake.shareOrgPrivateDomainReturnsOnCall[i] = struct {
+		result1 *cfclient.Domain
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListOrgSpaceQuotas(orgGUID string) ([]cfclient.SpaceQuota, error) {
+	fake.listOrgSpaceQuotasMutex.Lock()
+	ret, specificReturn := fake.listOrgSpaceQuotasReturnsOnCall[len(fake.listOrgSpaceQuotasArgsForCall)]
+	fake.listOrgSpaceQuotasArgsForCall = append(fake.listOrgSpaceQuotasArgsForCall, struct {
+		orgGUID string
+	}{orgGUID})
+	fake.recordInvocation("ListOrgSpaceQuotas", []interface{}{orgGUID})
+	fake.listOrgSpaceQuotasMutex.Unlock()
+	if fake.ListOrgSpaceQuotasStub != nil {
+		return fake.ListOrgSpaceQuotasStub(orgGUID)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listOrgSpaceQuotasReturns.result1, fake.listOrgSpaceQuotasReturns.result2
+}
+
+func (fake *FakeAPI) ListOrgSpaceQuotasCallCount() int {
+	fake.listOrgSpaceQuotasMutex.RLock()
+	defer fake.listOrgSpaceQuotasMutex.RUnlock()
+	return len(fake.listOrgSpaceQuotasArgsForCall)
+}
+
+func (fake *FakeAPI) ListOrgSpaceQuotasArgsForCall(i int) string {
+	fake.listOrgSpaceQuotasMutex.RLock()
+	defer fake.listOrgSpaceQuotasMutex.RUnlock()
+	return fake.listOrgSpaceQuotasArgsForCall[i].orgGUID
+}
+
+func (fake *FakeAPI) ListOrgSpaceQuotasReturns(result1 []cfclient.SpaceQuota, result2 error) {
+	fake.ListOrgSpaceQuotasStub = nil
+	fake.listOrgSpaceQuotasReturns = struct {
+		result1 []cfclient.SpaceQuota
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListOrgSpaceQuotasReturnsOnCall(i int, result1 []cfclient.SpaceQuota, result2 error) {
+	fake.ListOrgSpaceQuotasStub = nil
+	if fake.listOrgSpaceQuotasReturnsOnCall == nil {
+		fake.listOrgSpaceQuotasReturnsOnCall = make(map[int]struct {
+			result1 []cfclient.SpaceQuota
+			result2 error
+		})
+	}
+	fake.listOrgSpaceQuotasReturnsOnCall[i] = struct {
+		result1 []cfclient.SpaceQuota
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) CreateSpaceQuota(req cfclient.SpaceQuotaRequest) (*cfclient.SpaceQuota, error) {
+	fake.createSpaceQuotaMutex.Lock()
+	ret, specificReturn := fake.createSpaceQuotaReturnsOnCall[len(fake.createSpaceQuotaArgsForCall)]
+	fake.createSpaceQuotaArgsForCall = append(fake.createSpaceQuotaArgsForCall, struct {
+		req cfclient.SpaceQuotaRequest
+	}{req})
+	fake.recordInvocation("CreateSpaceQuota", []interface{}{req})
+	fake.createSpaceQuotaMutex.Unlock()
+	if fake.CreateSpaceQuotaStub != nil {
+		return fake.CreateSpaceQuotaStub(req)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.createSpaceQuotaReturns.result1, fake.createSpaceQuotaReturns.result2
+}
+
+func (fake *FakeAPI) CreateSpaceQuotaCallCount() int {
+	fake.createSpaceQuotaMutex.RLock()
+	defer fake.createSpaceQuotaMutex.RUnlock()
+	return len(fake.createSpaceQuotaArgsForCall)
+}
+
+func (fake *FakeAPI) CreateSpaceQuotaArgsForCall(i int) cfclient.SpaceQuotaRequest {
+	fake.createSpaceQuotaMutex.RLock()
+	defer fake.createSpaceQuotaMutex.RUnlock()
+	return fake.createSpaceQuotaArgsForCall[i].req
+}
+
+func (fake *FakeAPI) CreateSpaceQuotaReturns(result1 *cfclient.SpaceQuota, result2 error) {
+	fake.CreateSpaceQuotaStub = nil
+	fake.createSpaceQuotaReturns = struct {
+		result1 *cfclient.SpaceQuota
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) CreateSpaceQuotaReturnsOnCall(i int, result1 *cfclient.SpaceQuota, result2 error) {
+	fake.CreateSpaceQuotaStub = nil
+	if fake.createSpaceQuotaReturnsOnCall == nil {
+		fake.createSpaceQuotaReturnsOnCall = make(map[int]struct {
+			result1 *cfclient.SpaceQuota
+			result2 error
+		})
+	}
+	fake.createSpaceQuotaReturnsOnCall[i] = struct {
+		result1 *cfclient.SpaceQuota
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) AssignSpaceQuota(quotaGUID string, spaceGUID string) error {
+	fake.assignSpaceQuotaMutex.Lock()
+	ret, specificReturn := fake.assignSpaceQuotaReturnsOnCall[len(fake.assignSpaceQuotaArgsForCall)]
+	fake.assignSpaceQuotaArgsForCall = append(fake.assignSpaceQuotaArgsForCall, struct {
+		quotaGUID string
+		spaceGUID string
+	}{quotaGUID, spaceGUID})
+	fake.recordInvocation("AssignSpaceQuota", []interface{}{quotaGUID, spaceGUID})
+	fake.assignSpaceQuotaMutex.Unlock()
+	if fake.AssignSpaceQuotaStub != nil {
+		return fake.AssignSpaceQuotaStub(quotaGUID, spaceGUID)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.assignSpaceQuotaReturns.result1
+}
+
+func (fake *FakeAPI) AssignSpaceQuotaCallCount() int {
+	fake.assignSpaceQuotaMutex.RLock()
+	defer fake.assignSpaceQuotaMutex.RUnlock()
+	return len(fake.assignSpaceQuotaArgsForCall)
+}
+
+func (fake *FakeAPI) AssignSpaceQuotaArgsForCall(i int) (string, string) {
+	fake.assignSpaceQuotaMutex.RLock()
+	defer fake.assignSpaceQuotaMutex.RUnlock()
+	return fake.assignSpaceQuotaArgsForCall[i].quotaGUID, fake.assignSpaceQuotaArgsForCall[i].spaceGUID
+}
+
+func (fake *FakeAPI) AssignSpaceQuotaReturns(result1 error) {
+	fake.AssignSpaceQuotaStub = nil
+	fake.assignSpaceQuotaReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) AssignSpaceQuotaReturnsOnCall(i int, result1 error) {
+	fake.AssignSpaceQuotaStub = nil
+	if fake.assignSpaceQuotaReturnsOnCall == nil {
+		fake.assignSpaceQuotaReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.assignSpaceQuotaReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) GetSecGroupByName(name string) (cfclient.SecGroup, error) {
+	fake.getSecGroupByNameMutex.Lock()
+	ret, specificReturn := fake.getSecGroupByNameReturnsOnCall[len(fake.getSecGroupByNameArgsForCall)]
+	fake.getSecGroupByNameArgsForCall = append(fake.getSecGroupByNameArgsForCall, struct {
+		name string
+	}{name})
+	fake.recordInvocation("GetSecGroupByName", []interface{}{name})
+	fake.getSecGroupByNameMutex.Unlock()
+	if fake.GetSecGroupByNameStub != nil {
+		return fake.GetSecGroupByNameStub(name)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getSecGroupByNameReturns.result1, fake.getSecGroupByNameReturns.result2
+}
+
+func (fake *FakeAPI) GetSecGroupByNameCallCount() int {
+	fake.getSecGroupByNameMutex.RLock()
+	defer fake.getSecGroupByNameMutex.RUnlock()
+	return len(fake.getSecGroupByNameArgsForCall)
+}
+
+func (fake *FakeAPI) GetSecGroupByNameArgsForCall(i int) string {
+	fake.getSecGroupByNameMutex.RLock()
+	defer fake.getSecGroupByNameMutex.RUnlock()
+	return fake.getSecGroupByNameArgsForCall[i].name
+}
+
+func (fake *FakeAPI) GetSecGroupByNameReturns(result1 cfclient.SecGroup, result2 error) {
+	fake.GetSecGroupByNameStub = nil
+	fake.getSecGroupByNameReturns = struct {
+		result1 cfclient.SecGroup
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) GetSecGroupByNameReturnsOnCall(i int, result1 cfclient.SecGroup, result2 error) {
+	fake.GetSecGroupByNameStub = nil
+	if fake.getSecGroupByNameReturnsOnCall == nil {
+		fake.getSecGroupByNameReturnsOnCall = make(map[int]struct {
+			result1 cfclient.SecGroup
+			result2 error
+		})
+	}
+	fake.getSecGroupByNameReturnsOnCall[i] = struct {
+		result1 cfclient.SecGroup
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) BindSecGroup(secGUID string, spaceGUID string) error {
+	fake.bindSecGroupMutex.Lock()
+	ret, specificReturn := fake.bindSecGroupReturnsOnCall[len(fake.bindSecGroupArgsForCall)]
+	fake.bindSecGroupArgsForCall = append(fake.bindSecGroupArgsForCall, struct {
+		secGUID   string
+		spaceGUID string
+	}{secGUID, spaceGUID})
+	fake.recordInvocation("BindSecGroup", []interface{}{secGUID, spaceGUID})
+	fake.bindSecGroupMutex.Unlock()
+	if fake.BindSecGroupStub != nil {
+		return fake.BindSecGroupStub(secGUID, spaceGUID)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.bindSecGroupReturns.result1
+}
+
+func (fake *FakeAPI) BindSecGroupCallCount() int {
+	fake.bindSecGroupMutex.RLock()
+	defer fake.bindSecGroupMutex.RUnlock()
+	return len(fake.bindSecGroupArgsForCall)
+}
+
+func (fake *FakeAPI) BindSecGroupArgsForCall(i int) (string, string) {
+	fake.bindSecGroupMutex.RLock()
+	defer fake.bindSecGroupMutex.RUnlock()
+	return fake.bindSecGroupArgsForCall[i].secGUID, fake.bindSecGroupArgsForCall[i].spaceGUID
+}
+
+func (fake *FakeAPI) BindSecGroupReturns(result1 error) {
+	fake.BindSecGroupStub = nil
+	fake.bindSecGroupReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) BindSecGroupReturnsOnCall(i int, result1 error) {
+	fake.BindSecGroupStub = nil
+	if fake.bindSecGroupReturnsOnCall == nil {
+		fake.bindSecGroupReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.bindSecGroupReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) AssociateSpaceManager(spaceGUID string, userGUID string) (cfclient.Space, error) {
+	fake.associateSpaceManagerMutex.Lock()
+	ret, specificReturn := fake.associateSpaceManagerReturnsOnCall[len(fake.associateSpaceManagerArgsForCall)]
+	fake.associateSpaceManagerArgsForCall = append(fake.associateSpaceManagerArgsForCall, struct {
+		spaceGUID string
+		userGUID  string
+	}{spaceGUID, userGUID})
+	fake.recordInvocation("AssociateSpaceManager", []interface{}{spaceGUID, userGUID})
+	fake.associateSpaceManagerMutex.Unlock()
+	if fake.AssociateSpaceManagerStub != nil {
+		return fake.AssociateSpaceManagerStub(spaceGUID, userGUID)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.associateSpaceManagerReturns.result1, fake.associateSpaceManagerReturns.result2
+}
+
+func (fake *FakeAPI) AssociateSpaceManagerCallCount() int {
+	fake.associateSpaceManagerMutex.RLock()
+	defer fake.associateSpaceManagerMutex.RUnlock()
+	return len(fake.associateSpaceManagerArgsForCall)
+}
+
+func (fake *FakeAPI) AssociateSpaceManagerArgsForCall(i int) (string, string) {
+	fake.associateSpaceManagerMutex.RLock()
+	defer fake.associateSpaceManagerMutex.RUnlock()
+	return fake.associateSpaceManagerArgsForCall[i].spaceGUID, fake.associateSpaceManagerArgsForCall[i].userGUID
+}
+
+func (fake *FakeAPI) AssociateSpaceManagerReturns(result1 cfclient.Space, result2 error) {
+	fake.AssociateSpaceManagerStub = nil
+	fake.associateSpaceManagerReturns = struct {
+		result1 cfclient.Space
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) AssociateSpaceManagerReturnsOnCall(i int, result1 cfclient.Space, result2 error) {
+	fake.AssociateSpaceManagerStub = nil
+	if fake.associateSpaceManagerReturnsOnCall == nil {
+		fake.associateSpaceManagerReturnsOnCall = make(map[int]struct {
+			result1 cfclient.Space
+			result2 error
+		})
+	}
+	fake.associateSpaceManagerReturnsOnCall[i] = struct {
+		result1 cfclient.Space
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) AssociateSpaceDeveloper(spaceGUID string, userGUID string) (cfclient.Space, error) {
+	fake.associateSpaceDeveloperMutex.Lock()
+	ret, specificReturn := fake.associateSpaceDeveloperReturnsOnCall[len(fake.associateSpaceDeveloperArgsForCall)]
+	fake.associateSpaceDeveloperArgsForCall = append(fake.associateSpaceDeveloperArgsForCall, struct {
+		spaceGUID string
+		userGUID  string
+	}{spaceGUID, userGUID})
+	fake.recordInvocation("AssociateSpaceDeveloper", []interface{}{spaceGUID, userGUID})
+	fake.associateSpaceDeveloperMutex.Unlock()
+	if fake.AssociateSpaceDeveloperStub != nil {
+		return fake.AssociateSpaceDeveloperStub(spaceGUID, userGUID)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.associateSpaceDeveloperReturns.result1, fake.associateSpaceDeveloperReturns.result2
+}
+
+func (fake *FakeAPI) AssociateSpaceDeveloperCallCount() int {
+	fake.associateSpaceDeveloperMutex.RLock()
+	defer fake.associateSpaceDeveloperMutex.RUnlock()
+	return len(fake.associateSpaceDeveloperArgsForCall)
+}
+
+func (fake *FakeAPI) AssociateSpaceDeveloperArgsForCall(i int) (string, string) {
+	fake.associateSpaceDeveloperMutex.RLock()
+	defer fake.associateSpaceDeveloperMutex.RUnlock()
+	return fake.associateSpaceDeveloperArgsForCall[i].spaceGUID, fake.associateSpaceDeveloperArgsForCall[i].userGUID
+}
+
+func (fake *FakeAPI) AssociateSpaceDeveloperReturns(result1 cfclient.Space, result2 error) {
+	fake.AssociateSpaceDeveloperStub = nil
+	fake.associateSpaceDeveloperReturns = struct {
+		result1 cfclient.Space
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) AssociateSpaceDeveloperReturnsOnCall(i int, result1 cfclient.Space, result2 error) {
+	fake.AssociateSpaceDeveloperStub = nil
+	if fake.associateSpaceDeveloperReturnsOnCall == nil {
+		fake.associateSpaceDeveloperReturnsOnCall = make(map[int]struct {
+			result1 cfclient.Space
+			result2 error
+		})
+	}
+	fake.associateSpaceDeveloperReturnsOnCall[i] = struct {
+		result1 cfclient.Space
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) AssociateSpaceAuditor(spaceGUID string, userGUID string) (cfclient.Space, error) {
+	fake.associateSpaceAuditorMutex.Lock()
+	ret, specificReturn := fake.associateSpaceAuditorReturnsOnCall[len(fake.associateSpaceAuditorArgsForCall)]
+	fake.associateSpaceAuditorArgsForCall = append(fake.associateSpaceAuditorArgsForCall, struct {
+		spaceGUID string
+		userGUID  string
+	}{spaceGUID, userGUID})
+	fake.recordInvocation("AssociateSpaceAuditor", []interface{}{spaceGUID, userGUID})
+	fake.associateSpaceAuditorMutex.Unlock()
+	if fake.AssociateSpaceAuditorStub != nil {
+		return fake.AssociateSpaceAuditorStub(spaceGUID, userGUID)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.associateSpaceAuditorReturns.result1, fake.associateSpaceAuditorReturns.result2
+}
+
+func (fake *FakeAPI) AssociateSpaceAuditorCallCount() int {
+	fake.associateSpaceAuditorMutex.RLock()
+	defer fake.associateSpaceAuditorMutex.RUnlock()
+	return len(fake.associateSpaceAuditorArgsForCall)
+}
+
+func (fake *FakeAPI) AssociateSpaceAuditorArgsForCall(i int) (string, string) {
+	fake.associateSpaceAuditorMutex.RLock()
+	defer fake.associateSpaceAuditorMutex.RUnlock()
+	return fake.associateSpaceAuditorArgsForCall[i].spaceGUID, fake.associateSpaceAuditorArgsForCall[i].userGUID
+}
+
+func (fake *FakeAPI) AssociateSpaceAuditorReturns(result1 cfclient.Space, result2 error) {
+	fake.AssociateSpaceAuditorStub = nil
+	fake.associateSpaceAuditorReturns = struct {
+		result1 cfclient.Space
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) AssociateSpaceAuditorReturnsOnCall(i int, result1 cfclient.Space, result2 error) {
+	fake.AssociateSpaceAuditorStub = nil
+	if fake.associateSpaceAuditorReturnsOnCall == nil {
+		fake.associateSpaceAuditorReturnsOnCall = make(map[int]struct {
+			result1 cfclient.Space
+			result2 error
+		})
+	}
+	fake.associateSpaceAuditorReturnsOnCall[i] = struct {
+		result1 cfclient.Space
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListOrgUsers(orgGUID string) ([]cfclient.User, error) {
+	fake.listOrgUsersMutex.Lock()
+	ret, specificReturn := fake.listOrgUsersReturnsOnCall[len(fake.listOrgUsersArgsForCall)]
+	fake.listOrgUsersArgsForCall = append(fake.listOrgUsersArgsForCall, struct {
+		orgGUID string
+	}{orgGUID})
+	fake.recordInvocation("ListOrgUsers", []interface{}{orgGUID})
+	fake.listOrgUsersMutex.Unlock()
+	if fake.ListOrgUsersStub != nil {
+		return fake.ListOrgUsersStub(orgGUID)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listOrgUsersReturns.result1, fake.listOrgUsersReturns.result2
+}
+
+func (fake *FakeAPI) ListOrgUsersCallCount() int {
+	fake.listOrgUsersMutex.RLock()
+	defer fake.listOrgUsersMutex.RUnlock()
+	return len(fake.listOrgUsersArgsForCall)
+}
+
+func (fake *FakeAPI) ListOrgUsersArgsForCall(i int) string {
+	fake.listOrgUsersMutex.RLock()
+	defer fake.listOrgUsersMutex.RUnlock()
+	return fake.listOrgUsersArgsForCall[i].orgGUID
+}
+
+func (fake *FakeAPI) ListOrgUsersReturns(result1 []cfclient.User, result2 error) {
+	fake.ListOrgUsersStub = nil
+	fake.listOrgUsersReturns = struct {
+		result1 []cfclient.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListOrgUsersReturnsOnCall(i int, result1 []cfclient.User, result2 error) {
+	fake.ListOrgUsersStub = nil
+	if fake.listOrgUsersReturnsOnCall == nil {
+		fake.listOrgUsersReturnsOnCall = make(map[int]struct {
+			result1 []cfclient.User
+			result2 error
+		})
+	}
+	fake.listOrgUsersReturnsOnCall[i] = struct {
+		result1 []cfclient.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListOrgManagers(orgGUID string) ([]cfclient.User, error) {
+	fake.listOrgManagersMutex.Lock()
+	ret, specificReturn := fake.listOrgManagersReturnsOnCall[len(fake.listOrgManagersArgsForCall)]
+	fake.listOrgManagersArgsForCall = append(fake.listOrgManagersArgsForCall, struct {
+		orgGUID string
+	}{orgGUID})
+	fake.recordInvocation("ListOrgManagers", []interface{}{orgGUID})
+	fake.listOrgManagersMutex.Unlock()
+	if fake.ListOrgManagersStub != nil {
+		return fake.ListOrgManagersStub(orgGUID)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listOrgManagersReturns.result1, fake.listOrgManagersReturns.result2
+}
+
+func (fake *FakeAPI) ListOrgManagersCallCount() int {
+	fake.listOrgManagersMutex.RLock()
+	defer fake.listOrgManagersMutex.RUnlock()
+	return len(fake.listOrgManagersArgsForCall)
+}
+
+func (fake *FakeAPI) ListOrgManagersArgsForCall(i int) string {
+	fake.listOrgManagersMutex.RLock()
+	defer fake.listOrgManagersMutex.RUnlock()
+	return fake.listOrgManagersArgsForCall[i].orgGUID
+}
+
+func (fake *FakeAPI) ListOrgManagersReturns(result1 []cfclient.User, result2 error) {
+	fake.ListOrgManagersStub = nil
+	fake.listOrgManagersReturns = struct {
+		result1 []cfclient.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListOrgManagersReturnsOnCall(i int, result1 []cfclient.User, result2 error) {
+	fake.ListOrgManagersStub = nil
+	if fake.listOrgManagersReturnsOnCall == nil {
+		fake.listOrgManagersReturnsOnCall = make(map[int]struct {
+			result1 []cfclient.User
+			result2 error
+		})
+	}
+	fake.listOrgManagersReturnsOnCall[i] = struct {
+		result1 []cfclient.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListOrgAuditors(orgGUID string) ([]cfclient.User, error) {
+	fake.listOrgAuditorsMutex.Lock()
+	ret, specificReturn := fake.listOrgAuditorsReturnsOnCall[len(fake.listOrgAuditorsArgsForCall)]
+	fake.listOrgAuditorsArgsForCall = append(fake.listOrgAuditorsArgsForCall, struct {
+		orgGUID string
+	}{orgGUID})
+	fake.recordInvocation("ListOrgAuditors", []interface{}{orgGUID})
+	fake.listOrgAuditorsMutex.Unlock()
+	if fake.ListOrgAuditorsStub != nil {
+		return fake.ListOrgAuditorsStub(orgGUID)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listOrgAuditorsReturns.result1, fake.listOrgAuditorsReturns.result2
+}
+
+func (fake *FakeAPI) ListOrgAuditorsCallCount() int {
+	fake.listOrgAuditorsMutex.RLock()
+	defer fake.listOrgAuditorsMutex.RUnlock()
+	return len(fake.listOrgAuditorsArgsForCall)
+}
+
+func (fake *FakeAPI) ListOrgAuditorsArgsForCall(i int) string {
+	fake.listOrgAuditorsMutex.RLock()
+	defer fake.listOrgAuditorsMutex.RUnlock()
+	return fake.listOrgAuditorsArgsForCall[i].orgGUID
+}
+
+func (fake *FakeAPI) ListOrgAuditorsReturns(result1 []cfclient.User, result2 error) {
+	fake.ListOrgAuditorsStub = nil
+	fake.listOrgAuditorsReturns = struct {
+		result1 []cfclient.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListOrgAuditorsReturnsOnCall(i int, result1 []cfclient.User, result2 error) {
+	fake.ListOrgAuditorsStub = nil
+	if fake.listOrgAuditorsReturnsOnCall == nil {
+		fake.listOrgAuditorsReturnsOnCall = make(map[int]struct {
+			result1 []cfclient.User
+			result2 error
+		})
+	}
+	fake.listOrgAuditorsReturnsOnCall[i] = struct {
+		result1 []cfclient.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListSpaceManagers(spaceGUID string) ([]cfclient.User, error) {
+	fake.listSpaceManagersMutex.Lock()
+	ret, specificReturn := fake.listSpaceManagersReturnsOnCall[len(fake.listSpaceManagersArgsForCall)]
+	fake.listSpaceManagersArgsForCall = append(fake.listSpaceManagersArgsForCall, struct {
+		spaceGUID string
+	}{spaceGUID})
+	fake.recordInvocation("ListSpaceManagers", []interface{}{spaceGUID})
+	fake.listSpaceManagersMutex.Unlock()
+	if fake.ListSpaceManagersStub != nil {
+		return fake.ListSpaceManagersStub(spaceGUID)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listSpaceManagersReturns.result1, fake.listSpaceManagersReturns.result2
+}
+
+func (fake *FakeAPI) ListSpaceManagersCallCount() int {
+	fake.listSpaceManagersMutex.RLock()
+	defer fake.listSpaceManagersMutex.RUnlock()
+	return len(fake.listSpaceManagersArgsForCall)
+}
+
+func (fake *FakeAPI) ListSpaceManagersArgsForCall(i int) string {
+	fake.listSpaceManagersMutex.RLock()
+	defer fake.listSpaceManagersMutex.RUnlock()
+	return fake.listSpaceManagersArgsForCall[i].spaceGUID
+}
+
+func (fake *FakeAPI) ListSpaceManagersReturns(result1 []cfclient.User, result2 error) {
+	fake.ListSpaceManagersStub = nil
+	fake.listSpaceManagersReturns = struct {
+		result1 []cfclient.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListSpaceManagersReturnsOnCall(i int, result1 []cfclient.User, result2 error) {
+	fake.ListSpaceManagersStub = nil
+	if fake.listSpaceManagersReturnsOnCall == nil {
+		fake.listSpaceManagersReturnsOnCall = make(map[int]struct {
+			result1 []cfclient.User
+			result2 error
+		})
+	}
+	fake.listSpaceManagersReturnsOnCall[i] = struct {
+		result1 []cfclient.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListSpaceDevelopers(spaceGUID string) ([]cfclient.User, error) {
+	fake.listSpaceDevelopersMutex.Lock()
+	ret, specificReturn := fake.listSpaceDevelopersReturnsOnCall[len(fake.listSpaceDevelopersArgsForCall)]
+	fake.listSpaceDevelopersArgsForCall = append(fake.listSpaceDevelopersArgsForCall, struct {
+		spaceGUID string
+	}{spaceGUID})
+	fake.recordInvocation("ListSpaceDevelopers", []interface{}{spaceGUID})
+	fake.listSpaceDevelopersMutex.Unlock()
+	if fake.ListSpaceDevelopersStub != nil {
+		return fake.ListSpaceDevelopersStub(spaceGUID)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listSpaceDevelopersReturns.result1, fake.listSpaceDevelopersReturns.result2
+}
+
+func (fake *FakeAPI) ListSpaceDevelopersCallCount() int {
+	fake.listSpaceDevelopersMutex.RLock()
+	defer fake.listSpaceDevelopersMutex.RUnlock()
+	return len(fake.listSpaceDevelopersArgsForCall)
+}
+
+func (fake *FakeAPI) ListSpaceDevelopersArgsForCall(i int) string {
+	fake.listSpaceDevelopersMutex.RLock()
+	defer fake.listSpaceDevelopersMutex.RUnlock()
+	return fake.listSpaceDevelopersArgsForCall[i].spaceGUID
+}
+
+func (fake *FakeAPI) ListSpaceDevelopersReturns(result1 []cfclient.User, result2 error) {
+	fake.ListSpaceDevelopersStub = nil
+	fake.listSpaceDevelopersReturns = struct {
+		result1 []cfclient.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListSpaceDevelopersReturnsOnCall(i int, result1 []cfclient.User, result2 error) {
+	fake.ListSpaceDevelopersStub = nil
+	if fake.listSpaceDevelopersReturnsOnCall == nil {
+		fake.listSpaceDevelopersReturnsOnCall = make(map[int]struct {
+			result1 []cfclient.User
+			result2 error
+		})
+	}
+	fake.listSpaceDevelopersReturnsOnCall[i] = struct {
+		result1 []cfclient.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListSpaceAuditors(spaceGUID string) ([]cfclient.User, error) {
+	fake.listSpaceAuditorsMutex.Lock()
+	ret, specificReturn := fake.listSpaceAuditorsReturnsOnCall[len(fake.listSpaceAuditorsArgsForCall)]
+	fake.listSpaceAuditorsArgsForCall = append(fake.listSpaceAuditorsArgsForCall, struct {
+		spaceGUID string
+	}{spaceGUID})
+	fake.recordInvocation("ListSpaceAuditors", []interface{}{spaceGUID})
+	fake.listSpaceAuditorsMutex.Unlock()
+	if fake.ListSpaceAuditorsStub != nil {
+		return fake.ListSpaceAuditorsStub(spaceGUID)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listSpaceAuditorsReturns.result1, fake.listSpaceAuditorsReturns.result2
+}
+
+func (fake *FakeAPI) ListSpaceAuditorsCallCount() int {
+	fake.listSpaceAuditorsMutex.RLock()
+	defer fake.listSpaceAuditorsMutex.RUnlock()
+	return len(fake.listSpaceAuditorsArgsForCall)
+}
+
+func (fake *FakeAPI) ListSpaceAuditorsArgsForCall(i int) string {
+	fake.listSpaceAuditorsMutex.RLock()
+	defer fake.listSpaceAuditorsMutex.RUnlock()
+	return fake.listSpaceAuditorsArgsForCall[i].spaceGUID
+}
+
+func (fake *FakeAPI) ListSpaceAuditorsReturns(result1 []cfclient.User, result2 error) {
+	fake.ListSpaceAuditorsStub = nil
+	fake.listSpaceAuditorsReturns = struct {
+		result1 []cfclient.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListSpaceAuditorsReturnsOnCall(i int, result1 []cfclient.User, result2 error) {
+	fake.ListSpaceAuditorsStub = nil
+	if fake.listSpaceAuditorsReturnsOnCall == nil {
+		fake.listSpaceAuditorsReturnsOnCall = make(map[int]struct {
+			result1 []cfclient.User
+			result2 error
+		})
+	}
+	fake.listSpaceAuditorsReturnsOnCall[i] = struct {
+		result1 []cfclient.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) RemoveOrgUser(orgGUID string, userGUID string) error {
+	fake.removeOrgUserMutex.Lock()
+	ret, specificReturn := fake.removeOrgUserReturnsOnCall[len(fake.removeOrgUserArgsForCall)]
+	fake.removeOrgUserArgsForCall = append(fake.removeOrgUserArgsForCall, struct {
+		orgGUID  string
+		userGUID string
+	}{orgGUID, userGUID})
+	fake.recordInvocation("RemoveOrgUser", []interface{}{orgGUID, userGUID})
+	fake.removeOrgUserMutex.Unlock()
+	if fake.RemoveOrgUserStub != nil {
+		return fake.RemoveOrgUserStub(orgGUID, userGUID)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.removeOrgUserReturns.result1
+}
+
+func (fake *FakeAPI) RemoveOrgUserCallCount() int {
+	fake.removeOrgUserMutex.RLock()
+	defer fake.removeOrgUserMutex.RUnlock()
+	return len(fake.removeOrgUserArgsForCall)
+}
+
+func (fake *FakeAPI) RemoveOrgUserArgsForCall(i int) (string, string) {
+	fake.removeOrgUserMutex.RLock()
+	defer fake.removeOrgUserMutex.RUnlock()
+	return fake.removeOrgUserArgsForCall[i].orgGUID, fake.removeOrgUserArgsForCall[i].userGUID
+}
+
+func (fake *FakeAPI) RemoveOrgUserReturns(result1 error) {
+	fake.RemoveOrgUserStub = nil
+	fake.removeOrgUserReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) RemoveOrgUserReturnsOnCall(i int, result1 error) {
+	fake.RemoveOrgUserStub = nil
+	if fake.removeOrgUserReturnsOnCall == nil {
+		fake.removeOrgUserReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.removeOrgUserReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) RemoveOrgManager(orgGUID string, userGUID string) error {
+	fake.removeOrgManagerMutex.Lock()
+	ret, specificReturn := fake.removeOrgManagerReturnsOnCall[len(fake.removeOrgManagerArgsForCall)]
+	fake.removeOrgManagerArgsForCall = append(fake.removeOrgManagerArgsForCall, struct {
+		orgGUID  string
+		userGUID string
+	}{orgGUID, userGUID})
+	fake.recordInvocation("RemoveOrgManager", []interface{}{orgGUID, userGUID})
+	fake.removeOrgManagerMutex.Unlock()
+	if fake.RemoveOrgManagerStub != nil {
+		return fake.RemoveOrgManagerStub(orgGUID, userGUID)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.removeOrgManagerReturns.result1
+}
+
+func (fake *FakeAPI) RemoveOrgManagerCallCount() int {
+	fake.removeOrgManagerMutex.RLock()
+	defer fake.removeOrgManagerMutex.RUnlock()
+	return len(fake.removeOrgManagerArgsForCall)
+}
+
+func (fake *FakeAPI) RemoveOrgManagerArgsForCall(i int) (string, string) {
+	fake.removeOrgManagerMutex.RLock()
+	defer fake.removeOrgManagerMutex.RUnlock()
+	return fake.removeOrgManagerArgsForCall[i].orgGUID, fake.removeOrgManagerArgsForCall[i].userGUID
+}
+
+func (fake *FakeAPI) RemoveOrgManagerReturns(result1 error) {
+	fake.RemoveOrgManagerStub = nil
+	fake.removeOrgManagerReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) RemoveOrgManagerReturnsOnCall(i int, result1 error) {
+	fake.RemoveOrgManagerStub = nil
+	if fake.removeOrgManagerReturnsOnCall == nil {
+		fake.removeOrgManagerReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.removeOrgManagerReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) RemoveOrgAuditor(orgGUID string, userGUID string) error {
+	fake.removeOrgAuditorMutex.Lock()
+	ret, specificReturn := fake.removeOrgAuditorReturnsOnCall[len(fake.removeOrgAuditorArgsForCall)]
+	fake.removeOrgAuditorArgsForCall = append(fake.removeOrgAuditorArgsForCall, struct {
+		orgGUID  string
+		userGUID string
+	}{orgGUID, userGUID})
+	fake.recordInvocation("RemoveOrgAuditor", []interface{}{orgGUID, userGUID})
+	fake.removeOrgAuditorMutex.Unlock()
+	if fake.RemoveOrgAuditorStub != nil {
+		return fake.RemoveOrgAuditorStub(orgGUID, userGUID)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.removeOrgAuditorReturns.result1
+}
+
+func (fake *FakeAPI) RemoveOrgAuditorCallCount() int {
+	fake.removeOrgAuditorMutex.RLock()
+	defer fake.removeOrgAuditorMutex.RUnlock()
+	return len(fake.removeOrgAuditorArgsForCall)
+}
+
+func (fake *FakeAPI) RemoveOrgAuditorArgsForCall(i int) (string, string) {
+	fake.removeOrgAuditorMutex.RLock()
+	defer fake.removeOrgAuditorMutex.RUnlock()
+	return fake.removeOrgAuditorArgsForCall[i].orgGUID, fake.removeOrgAuditorArgsForCall[i].userGUID
+}
+
+func (fake *FakeAPI) RemoveOrgAuditorReturns(result1 error) {
+	fake.RemoveOrgAuditorStub = nil
+	fake.removeOrgAuditorReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) RemoveOrgAuditorReturnsOnCall(i int, result1 error) {
+	fake.RemoveOrgAuditorStub = nil
+	if fake.removeOrgAuditorReturnsOnCall == nil {
+		fake.removeOrgAuditorReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.removeOrgAuditorReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) RemoveSpaceManager(spaceGUID string, userGUID string) error {
+	fake.removeSpaceManagerMutex.Lock()
+	ret, specificReturn := fake.removeSpaceManagerReturnsOnCall[len(fake.removeSpaceManagerArgsForCall)]
+	fake.removeSpaceManagerArgsForCall = append(fake.removeSpaceManagerArgsForCall, struct {
+		spaceGUID string
+		userGUID  string
+	}{spaceGUID, userGUID})
+	fake.recordInvocation("RemoveSpaceManager", []interface{}{spaceGUID, userGUID})
+	fake.removeSpaceManagerMutex.Unlock()
+	if fake.RemoveSpaceManagerStub != nil {
+		return fake.RemoveSpaceManagerStub(spaceGUID, userGUID)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.removeSpaceManagerReturns.result1
+}
+
+func (fake *FakeAPI) RemoveSpaceManagerCallCount() int {
+	fake.removeSpaceManagerMutex.RLock()
+	defer fake.removeSpaceManagerMutex.RUnlock()
+	return len(fake.removeSpaceManagerArgsForCall)
+}
+
+func (fake *FakeAPI) RemoveSpaceManagerArgsForCall(i int) (string, string) {
+	fake.removeSpaceManagerMutex.RLock()
+	defer fake.removeSpaceManagerMutex.RUnlock()
+	return fake.removeSpaceManagerArgsForCall[i].spaceGUID, fake.removeSpaceManagerArgsForCall[i].userGUID
+}
+
+func (fake *FakeAPI) RemoveSpaceManagerReturns(result1 error) {
+	fake.RemoveSpaceManagerStub = nil
+	fake.removeSpaceManagerReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) RemoveSpaceManagerReturnsOnCall(i int, result1 error) {
+	fake.RemoveSpaceManagerStub = nil
+	if fake.removeSpaceManagerReturnsOnCall == nil {
+		fake.removeSpaceManagerReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.removeSpaceManagerReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) RemoveSpaceDeveloper(spaceGUID string, userGUID string) error {
+	fake.removeSpaceDeveloperMutex.Lock()
+	ret, specificReturn := fake.removeSpaceDeveloperReturnsOnCall[len(fake.removeSpaceDeveloperArgsForCall)]
+	fake.removeSpaceDeveloperArgsForCall = append(fake.removeSpaceDeveloperArgsForCall, struct {
+		spaceGUID string
+		userGUID  string
+	}{spaceGUID, userGUID})
+	fake.recordInvocation("RemoveSpaceDeveloper", []interface{}{spaceGUID, userGUID})
+	fake.removeSpaceDeveloperMutex.Unlock()
+	if fake.RemoveSpaceDeveloperStub != nil {
+		return fake.RemoveSpaceDeveloperStub(spaceGUID, userGUID)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.removeSpaceDeveloperReturns.result1
+}
+
+func (fake *FakeAPI) RemoveSpaceDeveloperCallCount() int {
+	fake.removeSpaceDeveloperMutex.RLock()
+	defer fake.removeSpaceDeveloperMutex.RUnlock()
+	return len(fake.removeSpaceDeveloperArgsForCall)
+}
+
+func (fake *FakeAPI) RemoveSpaceDeveloperArgsForCall(i int) (string, string) {
+	fake.removeSpaceDeveloperMutex.RLock()
+	defer fake.removeSpaceDeveloperMutex.RUnlock()
+	return fake.removeSpaceDeveloperArgsForCall[i].spaceGUID, fake.removeSpaceDeveloperArgsForCall[i].userGUID
+}
+
+func (fake *FakeAPI) RemoveSpaceDeveloperReturns(result1 error) {
+	fake.RemoveSpaceDeveloperStub = nil
+	fake.removeSpaceDeveloperReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) RemoveSpaceDeveloperReturnsOnCall(i int, result1 error) {
+	fake.RemoveSpaceDeveloperStub = nil
+	if fake.removeSpaceDeveloperReturnsOnCall == nil {
+		fake.removeSpaceDeveloperReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.removeSpaceDeveloperReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) RemoveSpaceAuditor(spaceGUID string, userGUID string) error {
+	fake.removeSpaceAuditorMutex.Lock()
+	ret, specificReturn := fake.removeSpaceAuditorReturnsOnCall[len(fake.removeSpaceAuditorArgsForCall)]
+	fake.removeSpaceAuditorArgsForCall = append(fake.removeSpaceAuditorArgsForCall, struct {
+		spaceGUID string
+		userGUID  string
+	}{spaceGUID, userGUID})
+	fake.recordInvocation("RemoveSpaceAuditor", []interface{}{spaceGUID, userGUID})
+	fake.removeSpaceAuditorMutex.Unlock()
+	if fake.RemoveSpaceAuditorStub != nil {
+		return fake.RemoveSpaceAuditorStub(spaceGUID, userGUID)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.removeSpaceAuditorReturns.result1
+}
+
+func (fake *FakeAPI) RemoveSpaceAuditorCallCount() int {
+	fake.removeSpaceAuditorMutex.RLock()
+	defer fake.removeSpaceAuditorMutex.RUnlock()
+	return len(fake.removeSpaceAuditorArgsForCall)
+}
+
+func (fake *FakeAPI) RemoveSpaceAuditorArgsForCall(i int) (string, string) {
+	fake.removeSpaceAuditorMutex.RLock()
+	defer fake.removeSpaceAuditorMutex.RUnlock()
+	return fake.removeSpaceAuditorArgsForCall[i].spaceGUID, fake.removeSpaceAuditorArgsForCall[i].userGUID
+}
+
+func (fake *FakeAPI) RemoveSpaceAuditorReturns(result1 error) {
+	fake.RemoveSpaceAuditorStub = nil
+	fake.removeSpaceAuditorReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) RemoveSpaceAuditorReturnsOnCall(i int, result1 error) {
+	fake.RemoveSpaceAuditorStub = nil
+	if fake.removeSpaceAuditorReturnsOnCall == nil {
+		fake.removeSpaceAuditorReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.removeSpaceAuditorReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) ListSpacesByQuery(query url.Values) ([]cfclient.Space, error) {
+	fake.listSpacesByQueryMutex.Lock()
+	ret, specificReturn := fake.listSpacesByQueryReturnsOnCall[len(fake.listSpacesByQueryArgsForCall)]
+	fake.listSpacesByQueryArgsForCall = append(fake.listSpacesByQueryArgsForCall, struct {
+		query url.Values
+	}{query})
+	fake.recordInvocation("ListSpacesByQuery", []interface{}{query})
+	fake.listSpacesByQueryMutex.Unlock()
+	if fake.ListSpacesByQueryStub != nil {
+		return fake.ListSpacesByQueryStub(query)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listSpacesByQueryReturns.result1, fake.listSpacesByQueryReturns.result2
+}
+
+func (fake *FakeAPI) ListSpacesByQueryCallCount() int {
+	fake.listSpacesByQueryMutex.RLock()
+	defer fake.listSpacesByQueryMutex.RUnlock()
+	return len(fake.listSpacesByQueryArgsForCall)
+}
+
+func (fake *FakeAPI) ListSpacesByQueryArgsForCall(i int) url.Values {
+	fake.listSpacesByQueryMutex.RLock()
+	defer fake.listSpacesByQueryMutex.RUnlock()
+	return fake.listSpacesByQueryArgsForCall[i].query
+}
+
+func (fake *FakeAPI) ListSpacesByQueryReturns(result1 []cfclient.Space, result2 error) {
+	fake.ListSpacesByQueryStub = nil
+	fake.listSpacesByQueryReturns = struct {
+		result1 []cfclient.Space
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListSpacesByQueryReturnsOnCall(i int, result1 []cfclient.Space, result2 error) {
+	fake.ListSpacesByQueryStub = nil
+	if fake.listSpacesByQueryReturnsOnCall == nil {
+		fake.listSpacesByQueryReturnsOnCall = make(map[int]struct {
+			result1 []cfclient.Space
+			result2 error
+		})
+	}
+	fake.listSpacesByQueryReturnsOnCall[i] = struct {
+		result1 []cfclient.Space
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeAPI) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -419,6 +3057,90 @@ func (fake *FakeAPI) Invocations() map[string][][]interface{} {
 	defer fake.associateOrgAuditorMutex.RUnlock()
 	fake.associateOrgManagerMutex.RLock()
 	defer fake.associateOrgManagerMutex.RUnlock()
+	fake.getInfoMutex.RLock()
+	defer fake.getInfoMutex.RUnlock()
+	fake.createAppMutex.RLock()
+	defer fake.createAppMutex.RUnlock()
+	fake.uploadAppBitsMutex.RLock()
+	defer fake.uploadAppBitsMutex.RUnlock()
+	fake.startAppMutex.RLock()
+	defer fake.startAppMutex.RUnlock()
+	fake.listAppsByQueryMutex.RLock()
+	defer fake.listAppsByQueryMutex.RUnlock()
+	fake.getAppByGuidNoInlineCallMutex.RLock()
+	defer fake.getAppByGuidNoInlineCallMutex.RUnlock()
+	fake.getAppRoutesMutex.RLock()
+	defer fake.getAppRoutesMutex.RUnlock()
+	fake.getSharedDomainByGuidMutex.RLock()
+	defer fake.getSharedDomainByGuidMutex.RUnlock()
+	fake.listSharedDomainsMutex.RLock()
+	defer fake.listSharedDomainsMutex.RUnlock()
+	fake.createRouteMutex.RLock()
+	defer fake.createRouteMutex.RUnlock()
+	fake.bindRouteMutex.RLock()
+	defer fake.bindRouteMutex.RUnlock()
+	fake.listServicesByQueryMutex.RLock()
+	defer fake.listServicesByQueryMutex.RUnlock()
+	fake.listServicePlansByQueryMutex.RLock()
+	defer fake.listServicePlansByQueryMutex.RUnlock()
+	fake.listServicePlanVisibilitiesByQueryMutex.RLock()
+	defer fake.listServicePlanVisibilitiesByQueryMutex.RUnlock()
+	fake.createServiceInstanceMutex.RLock()
+	defer fake.createServiceInstanceMutex.RUnlock()
+	fake.listIsolationSegmentsByQueryMutex.RLock()
+	defer fake.listIsolationSegmentsByQueryMutex.RUnlock()
+	fake.addIsolationSegmentToOrgMutex.RLock()
+	defer fake.addIsolationSegmentToOrgMutex.RUnlock()
+	fake.defaultIsolationSegmentForOrgMutex.RLock()
+	defer fake.defaultIsolationSegmentForOrgMutex.RUnlock()
+	fake.listDomainsByQueryMutex.RLock()
+	defer fake.listDomainsByQueryMutex.RUnlock()
+	fake.createDomainMutex.RLock()
+	defer fake.createDomainMutex.RUnlock()
+	fake.shareOrgPrivateDomainMutex.RLock()
+	defer fake.shareOrgPrivateDomainMutex.RUnlock()
+	fake.listOrgSpaceQuotasMutex.RLock()
+	defer fake.listOrgSpaceQuotasMutex.RUnlock()
+	fake.createSpaceQuotaMutex.RLock()
+	defer fake.createSpaceQuotaMutex.RUnlock()
+	fake.assignSpaceQuotaMutex.RLock()
+	defer fake.assignSpaceQuotaMutex.RUnlock()
+	fake.getSecGroupByNameMutex.RLock()
+	defer fake.getSecGroupByNameMutex.RUnlock()
+	fake.bindSecGroupMutex.RLock()
+	defer fake.bindSecGroupMutex.RUnlock()
+	fake.associateSpaceManagerMutex.RLock()
+	defer fake.associateSpaceManagerMutex.RUnlock()
+	fake.associateSpaceDeveloperMutex.RLock()
+	defer fake.associateSpaceDeveloperMutex.RUnlock()
+	fake.associateSpaceAuditorMutex.RLock()
+	defer fake.associateSpaceAuditorMutex.RUnlock()
+	fake.listOrgUsersMutex.RLock()
+	defer fake.listOrgUsersMutex.RUnlock()
+	fake.listOrgManagersMutex.RLock()
+	defer fake.listOrgManagersMutex.RUnlock()
+	fake.listOrgAuditorsMutex.RLock()
+	defer fake.listOrgAuditorsMutex.RUnlock()
+	fake.listSpaceManagersMutex.RLock()
+	defer fake.listSpaceManagersMutex.RUnlock()
+	fake.listSpaceDevelopersMutex.RLock()
+	defer fake.listSpaceDevelopersMutex.RUnlock()
+	fake.listSpaceAuditorsMutex.RLock()
+	defer fake.listSpaceAuditorsMutex.RUnlock()
+	fake.removeOrgUserMutex.RLock()
+	defer fake.removeOrgUserMutex.RUnlock()
+	fake.removeOrgManagerMutex.RLock()
+	defer fake.removeOrgManagerMutex.RUnlock()
+	fake.removeOrgAuditorMutex.RLock()
+	defer fake.removeOrgAuditorMutex.RUnlock()
+	fake.removeSpaceManagerMutex.RLock()
+	defer fake.removeSpaceManagerMutex.RUnlock()
+	fake.removeSpaceDeveloperMutex.RLock()
+	defer fake.removeSpaceDeveloperMutex.RUnlock()
+	fake.removeSpaceAuditorMutex.RLock()
+	defer fake.removeSpaceAuditorMutex.RUnlock()
+	fake.listSpacesByQueryMutex.RLock()
+	defer fake.listSpacesByQueryMutex.RUnlock()
 	return fake.invocations
 }
 
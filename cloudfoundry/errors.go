@@ -0,0 +1,112 @@
+package cloudfoundry
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	"github.com/pkg/errors"
+)
+
+// ErrorKind classifies why a Cloud Controller request failed
+type ErrorKind int
+
+const (
+	// ErrUnknown is a failure that could not be classified
+	ErrUnknown ErrorKind = iota
+	// ErrUnauthorized indicates that the Cloud Controller rejected ignition's
+	// credentials
+	ErrUnauthorized
+	// ErrForbidden indicates that ignition is not permitted to make the request
+	ErrForbidden
+	// ErrConflict indicates that the resource already exists, e.g. an org with
+	// the same name
+	ErrConflict
+	// ErrUnavailable indicates that the Cloud Controller could not be reached,
+	// or could not handle the request; the request may succeed if retried
+	ErrUnavailable
+)
+
+// Error is a failed Cloud Controller request
+type Error struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Cause returns the underlying error
+func (e *Error) Cause() error {
+	return e.Err
+}
+
+// KindOf returns the ErrorKind of err, which is ErrUnknown when err was not
+// returned by a Cloud Controller request
+func KindOf(err error) ErrorKind {
+	for err != nil {
+		if e, ok := err.(*Error); ok {
+			return e.Kind
+		}
+		cause, ok := err.(interface {
+			Cause() error
+		})
+		if !ok {
+			return ErrUnknown
+		}
+		err = cause.Cause()
+	}
+	return ErrUnknown
+}
+
+// newError classifies the failure of a Cloud Controller request
+func newError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: classify(errors.Cause(err)), Err: err}
+}
+
+// Cloud Controller v2 error codes
+const (
+	ccInvalidAuthToken      = 1000
+	ccNotAuthenticated      = 10002
+	ccNotAuthorized         = 10003
+	ccServiceUnavailable    = 10015
+	ccOrganizationNameTaken = 30002
+	ccSpaceNameTaken        = 40002
+)
+
+func classify(err error) ErrorKind {
+	switch e := err.(type) {
+	case cfclient.CloudFoundryError:
+		switch e.Code {
+		case ccInvalidAuthToken, ccNotAuthenticated:
+			return ErrUnauthorized
+		case ccNotAuthorized:
+			return ErrForbidden
+		case ccOrganizationNameTaken, ccSpaceNameTaken:
+			return ErrConflict
+		case ccServiceUnavailable:
+			return ErrUnavailable
+		}
+	case cfclient.CloudFoundryHTTPError:
+		switch {
+		case e.StatusCode == http.StatusUnauthorized:
+			return ErrUnauthorized
+		case e.StatusCode == http.StatusForbidden:
+			return ErrForbidden
+		case e.StatusCode == http.StatusConflict:
+			return ErrConflict
+		case e.StatusCode >= http.StatusInternalServerError:
+			return ErrUnavailable
+		}
+	case *url.Error:
+		return ErrUnavailable
+	case net.Error:
+		return ErrUnavailable
+	}
+	return ErrUnknown
+}
@@ -0,0 +1,70 @@
+package cloudfoundry_test
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/cloudfoundry/cloudfoundryfakes"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestKindOf(t *testing.T) {
+	spec.Run(t, "KindOf", testKindOf, spec.Report(report.Terminal{}))
+}
+
+func testKindOf(t *testing.T, when spec.G, it spec.S) {
+	var a *cloudfoundryfakes.FakeAPI
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		a = &cloudfoundryfakes.FakeAPI{}
+	})
+
+	kindOfListError := func(err error) cloudfoundry.ErrorKind {
+		a.ListOrgsByQueryReturns(nil, err)
+		_, err = cloudfoundry.OrgsForUserID("123", "", a)
+		Expect(err).To(HaveOccurred())
+		return cloudfoundry.KindOf(err)
+	}
+
+	it("is unknown for errors that are not from the cloud controller", func() {
+		Expect(cloudfoundry.KindOf(errors.New("test error"))).To(Equal(cloudfoundry.ErrUnknown))
+		Expect(cloudfoundry.KindOf(nil)).To(Equal(cloudfoundry.ErrUnknown))
+		Expect(kindOfListError(errors.New("test error"))).To(Equal(cloudfoundry.ErrUnknown))
+	})
+
+	it("classifies cloud controller error codes", func() {
+		Expect(kindOfListError(cfclient.CloudFoundryError{Code: 1000})).To(Equal(cloudfoundry.ErrUnauthorized))
+		Expect(kindOfListError(cfclient.CloudFoundryError{Code: 10002})).To(Equal(cloudfoundry.ErrUnauthorized))
+		Expect(kindOfListError(cfclient.CloudFoundryError{Code: 10003})).To(Equal(cloudfoundry.ErrForbidden))
+		Expect(kindOfListError(cfclient.CloudFoundryError{Code: 30002})).To(Equal(cloudfoundry.ErrConflict))
+		Expect(kindOfListError(cfclient.CloudFoundryError{Code: 10015})).To(Equal(cloudfoundry.ErrUnavailable))
+		Expect(kindOfListError(cfclient.CloudFoundryError{Code: 10001})).To(Equal(cloudfoundry.ErrUnknown))
+	})
+
+	it("classifies http status codes", func() {
+		Expect(kindOfListError(cfclient.CloudFoundryHTTPError{StatusCode: http.StatusUnauthorized})).To(Equal(cloudfoundry.ErrUnauthorized))
+		Expect(kindOfListError(cfclient.CloudFoundryHTTPError{StatusCode: http.StatusForbidden})).To(Equal(cloudfoundry.ErrForbidden))
+		Expect(kindOfListError(cfclient.CloudFoundryHTTPError{StatusCode: http.StatusConflict})).To(Equal(cloudfoundry.ErrConflict))
+		Expect(kindOfListError(cfclient.CloudFoundryHTTPError{StatusCode: http.StatusBadGateway})).To(Equal(cloudfoundry.ErrUnavailable))
+		Expect(kindOfListError(cfclient.CloudFoundryHTTPError{StatusCode: http.StatusBadRequest})).To(Equal(cloudfoundry.ErrUnknown))
+	})
+
+	it("treats connection failures as unavailable", func() {
+		err := &url.Error{Op: "Get", URL: "https://api.example.net", Err: errors.New("connection refused")}
+		Expect(kindOfListError(err)).To(Equal(cloudfoundry.ErrUnavailable))
+	})
+
+	it("classifies wrapped errors", func() {
+		a.CreateOrgReturns(cfclient.Org{}, cfclient.CloudFoundryError{Code: 30002})
+		_, err := cloudfoundry.CreateOrg("test-org", "", "test-quota", a)
+		Expect(err).To(MatchError(ContainSubstring("could not create org with name [test-org]")))
+		Expect(cloudfoundry.KindOf(err)).To(Equal(cloudfoundry.ErrConflict))
+	})
+}
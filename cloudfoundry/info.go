@@ -0,0 +1,32 @@
+package cloudfoundry
+
+import (
+	"context"
+
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	"github.com/pkg/errors"
+)
+
+// InfoQuerier retrieves information about a Cloud Controller API
+type InfoQuerier interface {
+	GetInfo() (*cfclient.Info, error)
+}
+
+// Ping checks that the Cloud Controller API can be reached, giving up when the
+// context is done
+func Ping(ctx context.Context, q InfoQuerier) error {
+	result := make(chan error, 1)
+	go func() {
+		_, err := q.GetInfo()
+		result <- err
+	}()
+	select {
+	case err := <-result:
+		if err != nil {
+			return errors.Wrap(err, "could not reach the cloud controller")
+		}
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "could not reach the cloud controller")
+	}
+}
@@ -0,0 +1,49 @@
+package cloudfoundry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/cloudfoundry/cloudfoundryfakes"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestPing(t *testing.T) {
+	spec.Run(t, "Ping", func(t *testing.T, when spec.G, it spec.S) {
+		it.Before(func() {
+			RegisterTestingT(t)
+		})
+
+		it("succeeds when the info endpoint responds", func() {
+			a := &cloudfoundryfakes.FakeAPI{}
+			a.GetInfoReturns(&cfclient.Info{}, nil)
+			Expect(cloudfoundry.Ping(context.Background(), a)).To(Succeed())
+			Expect(a.GetInfoCallCount()).To(Equal(1))
+		})
+
+		it("returns an error when the info endpoint fails", func() {
+			a := &cloudfoundryfakes.FakeAPI{}
+			a.GetInfoReturns(nil, errors.New("test error"))
+			Expect(cloudfoundry.Ping(context.Background(), a)).To(MatchError("could not reach the cloud controller: test error"))
+		})
+
+		it("gives up when the context is done", func() {
+			a := &cloudfoundryfakes.FakeAPI{}
+			release := make(chan struct{})
+			defer close(release)
+			a.GetInfoStub = func() (*cfclient.Info, error) {
+				<-release
+				return &cfclient.Info{}, nil
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			Expect(cloudfoundry.Ping(ctx, a)).To(MatchError(ContainSubstring("deadline exceeded")))
+		})
+	}, spec.Report(report.Terminal{}))
+}
@@ -0,0 +1,189 @@
+package cloudfoundry
+
+import (
+	"sort"
+
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	"github.com/pkg/errors"
+)
+
+// The org and space roles that can be granted to the members of an org; every
+// member is also an org user
+const (
+	RoleOrgManager     = "org_manager"
+	RoleOrgAuditor     = "org_auditor"
+	RoleSpaceManager   = "space_manager"
+	RoleSpaceDeveloper = "space_developer"
+	RoleSpaceAuditor   = "space_auditor"
+)
+
+// Roles are all of the roles that can be granted to a member
+var Roles = []string{RoleOrgManager, RoleOrgAuditor, RoleSpaceManager, RoleSpaceDeveloper, RoleSpaceAuditor}
+
+// IsRole is true when role is one of the Roles
+func IsRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSpaceRole is true for the roles that are granted in a space
+func IsSpaceRole(role string) bool {
+	return role == RoleSpaceManager || role == RoleSpaceDeveloper || role == RoleSpaceAuditor
+}
+
+// Member is a user of an org, with their org and space roles
+type Member struct {
+	UserID   string   `json:"user_id"`
+	Username string   `json:"username"`
+	Roles    []string `json:"roles"`
+}
+
+// MemberQuerier lists the users of an org and its spaces by role
+type MemberQuerier interface {
+	ListOrgUsers(orgGUID string) ([]cfclient.User, error)
+	ListOrgManagers(orgGUID string) ([]cfclient.User, error)
+	ListOrgAuditors(orgGUID string) ([]cfclient.User, error)
+	ListSpaceManagers(spaceGUID string) ([]cfclient.User, error)
+	ListSpaceDevelopers(spaceGUID string) ([]cfclient.User, error)
+	ListSpaceAuditors(spaceGUID string) ([]cfclient.User, error)
+}
+
+// RoleRevoker allows for users to be removed from org and space roles
+type RoleRevoker interface {
+	RemoveOrgUser(orgGUID, userGUID string) error
+	RemoveOrgManager(orgGUID, userGUID string) error
+	RemoveOrgAuditor(orgGUID, userGUID string) error
+	RemoveSpaceManager(spaceGUID, userGUID string) error
+	RemoveSpaceDeveloper(spaceGUID, userGUID string) error
+	RemoveSpaceAuditor(spaceGUID, userGUID string) error
+}
+
+// MemberRemover revokes a member's roles in an org and all of its spaces
+type MemberRemover interface {
+	RoleRevoker
+	SpaceQuerier
+}
+
+// MemberManager grants, lists and revokes the roles of the members of an org
+type MemberManager interface {
+	RoleGrantor
+	MemberQuerier
+	RoleRevoker
+	SpaceQuerier
+}
+
+// GrantRoles makes the user a member of the org with the given roles; space
+// roles are granted in the space, and are skipped when spaceGUID is empty
+func GrantRoles(userID string, orgGUID string, spaceGUID string, roles []string, a RoleGrantor) error {
+	if _, err := a.AssociateOrgUser(orgGUID, userID); err != nil {
+		return newError(errors.Wrapf(err, "could not add user [%s] to org [%s]", userID, orgGUID))
+	}
+	for _, role := range roles {
+		if IsSpaceRole(role) && spaceGUID == "" {
+			continue
+		}
+		var err error
+		switch role {
+		case RoleOrgManager:
+			_, err = a.AssociateOrgManager(orgGUID, userID)
+		case RoleOrgAuditor:
+			_, err = a.AssociateOrgAuditor(orgGUID, userID)
+		case RoleSpaceManager:
+			_, err = a.AssociateSpaceManager(spaceGUID, userID)
+		case RoleSpaceDeveloper:
+			_, err = a.AssociateSpaceDeveloper(spaceGUID, userID)
+		case RoleSpaceAuditor:
+			_, err = a.AssociateSpaceAuditor(spaceGUID, userID)
+		default:
+			return errors.Errorf("unknown role [%s]", role)
+		}
+		if err != nil {
+			return newError(errors.Wrapf(err, "could not grant role [%s] to user [%s]", role, userID))
+		}
+	}
+	return nil
+}
+
+// ListMembers returns the users of the org, sorted by username, with their org
+// roles and their roles in the space; space roles are skipped when spaceGUID
+// is empty
+func ListMembers(orgGUID string, spaceGUID string, a MemberQuerier) ([]Member, error) {
+	users, err := a.ListOrgUsers(orgGUID)
+	if err != nil {
+		return nil, newError(errors.Wrapf(err, "could not list the users of org [%s]", orgGUID))
+	}
+	members := make([]Member, len(users))
+	byID := map[string]*Member{}
+	for i := range users {
+		members[i] = Member{UserID: users[i].Guid, Username: users[i].Username, Roles: []string{}}
+		byID[users[i].Guid] = &members[i]
+	}
+
+	lists := []struct {
+		role string
+		list func(string) ([]cfclient.User, error)
+		guid string
+	}{
+		{RoleOrgManager, a.ListOrgManagers, orgGUID},
+		{RoleOrgAuditor, a.ListOrgAuditors, orgGUID},
+		{RoleSpaceManager, a.ListSpaceManagers, spaceGUID},
+		{RoleSpaceDeveloper, a.ListSpaceDevelopers, spaceGUID},
+		{RoleSpaceAuditor, a.ListSpaceAuditors, spaceGUID},
+	}
+	for _, l := range lists {
+		if l.guid == "" {
+			continue
+		}
+		users, err := l.list(l.guid)
+		if err != nil {
+			return nil, newError(errors.Wrapf(err, "could not list the users with role [%s]", l.role))
+		}
+		for _, u := range users {
+			if m, ok := byID[u.Guid]; ok {
+				m.Roles = append(m.Roles, l.role)
+			}
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Username < members[j].Username })
+	return members, nil
+}
+
+// RemoveMember revokes the user's roles in every space of the org and in the
+// org, and then removes them from the org
+func RemoveMember(userID string, orgGUID string, a MemberRemover) error {
+	spaces, err := OrgSpaces(orgGUID, a)
+	if err != nil {
+		return err
+	}
+	type removal struct {
+		role   string
+		remove func(string, string) error
+		guid   string
+	}
+	var removals []removal
+	for _, space := range spaces {
+		removals = append(removals,
+			removal{RoleSpaceManager, a.RemoveSpaceManager, space.GUID},
+			removal{RoleSpaceDeveloper, a.RemoveSpaceDeveloper, space.GUID},
+			removal{RoleSpaceAuditor, a.RemoveSpaceAuditor, space.GUID},
+		)
+	}
+	removals = append(removals,
+		removal{RoleOrgManager, a.RemoveOrgManager, orgGUID},
+		removal{RoleOrgAuditor, a.RemoveOrgAuditor, orgGUID},
+	)
+	for _, r := range removals {
+		// removing a role the user does not have has no effect
+		if err := r.remove(r.guid, userID); err != nil {
+			return newError(errors.Wrapf(err, "could not revoke role [%s] from user [%s]", r.role, userID))
+		}
+	}
+	if err := a.RemoveOrgUser(orgGUID, userID); err != nil {
+		return newError(errors.Wrapf(err, "could not remove user [%s] from org [%s]", userID, orgGUID))
+	}
+	return nil
+}
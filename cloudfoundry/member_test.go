@@ -0,0 +1,124 @@
+package cloudfoundry_test
+
+import (
+	"errors"
+	"testing"
+
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/cloudfoundry/cloudfoundryfakes"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestMembers(t *testing.T) {
+	spec.Run(t, "Members", testMembers, spec.Report(report.Terminal{}))
+}
+
+func testMembers(t *testing.T, when spec.G, it spec.S) {
+	var a *cloudfoundryfakes.FakeAPI
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		a = &cloudfoundryfakes.FakeAPI{}
+	})
+
+	when("granting roles", func() {
+		it("adds the user to the org and grants the roles", func() {
+			err := cloudfoundry.GrantRoles("test-user-id", "test-org-guid", "test-space-guid", []string{cloudfoundry.RoleOrgAuditor, cloudfoundry.RoleSpaceDeveloper}, a)
+			Expect(err).NotTo(HaveOccurred())
+			orgGUID, userID := a.AssociateOrgUserArgsForCall(0)
+			Expect(orgGUID).To(Equal("test-org-guid"))
+			Expect(userID).To(Equal("test-user-id"))
+			Expect(a.AssociateOrgAuditorCallCount()).To(Equal(1))
+			spaceGUID, userID := a.AssociateSpaceDeveloperArgsForCall(0)
+			Expect(spaceGUID).To(Equal("test-space-guid"))
+			Expect(userID).To(Equal("test-user-id"))
+			Expect(a.AssociateOrgManagerCallCount()).To(Equal(0))
+		})
+
+		it("skips space roles when there is no space", func() {
+			Expect(cloudfoundry.GrantRoles("test-user-id", "test-org-guid", "", []string{cloudfoundry.RoleSpaceManager}, a)).To(Succeed())
+			Expect(a.AssociateSpaceManagerCallCount()).To(Equal(0))
+		})
+
+		it("fails for an unknown role", func() {
+			err := cloudfoundry.GrantRoles("test-user-id", "test-org-guid", "test-space-guid", []string{"admin"}, a)
+			Expect(err).To(MatchError("unknown role [admin]"))
+		})
+
+		it("classifies cloud controller errors", func() {
+			a.AssociateOrgUserReturns(cfclient.Org{}, cfclient.CloudFoundryHTTPError{StatusCode: 403})
+			err := cloudfoundry.GrantRoles("test-user-id", "test-org-guid", "test-space-guid", nil, a)
+			Expect(cloudfoundry.KindOf(err)).To(Equal(cloudfoundry.ErrForbidden))
+		})
+	})
+
+	when("listing members", func() {
+		it.Before(func() {
+			a.ListOrgUsersReturns([]cfclient.User{{Guid: "user-2", Username: "zoe@example.com"}, {Guid: "user-1", Username: "adam@example.com"}}, nil)
+			a.ListOrgManagersReturns([]cfclient.User{{Guid: "user-1"}}, nil)
+			a.ListSpaceDevelopersReturns([]cfclient.User{{Guid: "user-1"}, {Guid: "user-2"}}, nil)
+		})
+
+		it("returns the users with their roles", func() {
+			members, err := cloudfoundry.ListMembers("test-org-guid", "test-space-guid", a)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(members).To(Equal([]cloudfoundry.Member{
+				{UserID: "user-1", Username: "adam@example.com", Roles: []string{cloudfoundry.RoleOrgManager, cloudfoundry.RoleSpaceDeveloper}},
+				{UserID: "user-2", Username: "zoe@example.com", Roles: []string{cloudfoundry.RoleSpaceDeveloper}},
+			}))
+			Expect(a.ListSpaceDevelopersArgsForCall(0)).To(Equal("test-space-guid"))
+		})
+
+		it("skips space roles when there is no space", func() {
+			members, err := cloudfoundry.ListMembers("test-org-guid", "", a)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(members[1].Roles).To(BeEmpty())
+			Expect(a.ListSpaceDevelopersCallCount()).To(Equal(0))
+		})
+
+		it("returns an error when a role cannot be listed", func() {
+			a.ListOrgAuditorsReturns(nil, errors.New("test error"))
+			_, err := cloudfoundry.ListMembers("test-org-guid", "test-space-guid", a)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	when("removing a member", func() {
+		it.Before(func() {
+			a.ListSpacesByQueryReturns([]cfclient.Space{{Guid: "test-space-guid", Name: "playground"}, {Guid: "other-space-guid", Name: "other"}}, nil)
+		})
+
+		it("revokes their roles in every space and the org and then removes them from the org", func() {
+			Expect(cloudfoundry.RemoveMember("test-user-id", "test-org-guid", a)).To(Succeed())
+			Expect(a.ListSpacesByQueryArgsForCall(0).Get("q")).To(Equal("organization_guid:test-org-guid"))
+			Expect(a.RemoveSpaceDeveloperCallCount()).To(Equal(2))
+			spaceGUID, userID := a.RemoveSpaceDeveloperArgsForCall(0)
+			Expect(spaceGUID).To(Equal("test-space-guid"))
+			Expect(userID).To(Equal("test-user-id"))
+			spaceGUID, _ = a.RemoveSpaceDeveloperArgsForCall(1)
+			Expect(spaceGUID).To(Equal("other-space-guid"))
+			Expect(a.RemoveSpaceManagerCallCount()).To(Equal(2))
+			Expect(a.RemoveSpaceAuditorCallCount()).To(Equal(2))
+			Expect(a.RemoveOrgManagerCallCount()).To(Equal(1))
+			orgGUID, userID := a.RemoveOrgUserArgsForCall(0)
+			Expect(orgGUID).To(Equal("test-org-guid"))
+			Expect(userID).To(Equal("test-user-id"))
+		})
+
+		it("keeps them in the org when the spaces cannot be listed", func() {
+			a.ListSpacesByQueryReturns(nil, errors.New("test error"))
+			Expect(cloudfoundry.RemoveMember("test-user-id", "test-org-guid", a)).NotTo(Succeed())
+			Expect(a.RemoveSpaceDeveloperCallCount()).To(Equal(0))
+			Expect(a.RemoveOrgUserCallCount()).To(Equal(0))
+		})
+
+		it("keeps them in the org when a role cannot be revoked", func() {
+			a.RemoveOrgAuditorReturns(errors.New("test error"))
+			Expect(cloudfoundry.RemoveMember("test-user-id", "test-org-guid", a)).NotTo(Succeed())
+			Expect(a.RemoveOrgUserCallCount()).To(Equal(0))
+		})
+	})
+}
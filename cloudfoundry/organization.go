@@ -18,6 +18,7 @@ type Organization struct {
 	QuotaDefinitionGUID         string `json:"quota_definition_guid"`
 	DefaultIsolationSegmentGUID string `json:"default_isolation_segment_guid"`
 	URL                         string `json:"url"`
+	SampleApp                   *App   `json:"sample_app,omitempty"`
 }
 
 // OrganizationQuerier is used to query a Cloud Controller API or organizations
@@ -35,6 +36,9 @@ type RoleGrantor interface {
 	AssociateOrgUser(orgGUID, userGUID string) (cfclient.Org, error)
 	AssociateOrgAuditor(orgGUID, userGUID string) (cfclient.Org, error)
 	AssociateOrgManager(orgGUID, userGUID string) (cfclient.Org, error)
+	AssociateSpaceManager(spaceGUID, userGUID string) (cfclient.Space, error)
+	AssociateSpaceDeveloper(spaceGUID, userGUID string) (cfclient.Space, error)
+	AssociateSpaceAuditor(spaceGUID, userGUID string) (cfclient.Space, error)
 }
 
 // OrgsForUserID returns the orgs that the user is a member of
@@ -43,7 +47,7 @@ func OrgsForUserID(id string, appsURL string, q OrganizationQuerier) ([]Organiza
 	query.Add("q", fmt.Sprintf("user_guid:%s", id))
 	o, err := q.ListOrgsByQuery(query)
 	if err != nil {
-		return nil, err
+		return nil, newError(err)
 	}
 
 	result := make([]Organization, len(o))
@@ -53,6 +57,37 @@ func OrgsForUserID(id string, appsURL string, q OrganizationQuerier) ([]Organiza
 	return result, nil
 }
 
+// OrgsManagedByUserID returns the orgs that the user is a manager of
+func OrgsManagedByUserID(id string, appsURL string, q OrganizationQuerier) ([]Organization, error) {
+	query := url.Values{}
+	query.Add("q", fmt.Sprintf("manager_guid:%s", id))
+	o, err := q.ListOrgsByQuery(query)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	result := make([]Organization, len(o))
+	for i := range o {
+		result[i] = convertOrg(o[i], appsURL)
+	}
+	return result, nil
+}
+
+// OrgByName returns the org with the name, or nil when it does not exist
+func OrgByName(name string, appsURL string, q OrganizationQuerier) (*Organization, error) {
+	query := url.Values{}
+	query.Add("q", fmt.Sprintf("name:%s", strings.ToLower(name)))
+	o, err := q.ListOrgsByQuery(query)
+	if err != nil {
+		return nil, newError(errors.Wrapf(err, "could not find org [%s]", name))
+	}
+	if len(o) == 0 {
+		return nil, nil
+	}
+	org := convertOrg(o[0], appsURL)
+	return &org, nil
+}
+
 // CreateOrg creates an organization with the given name and quota for
 // the given user
 func CreateOrg(name string, appsURL string, quotaID string, a OrganizationCreator) (*Organization, error) {
@@ -62,7 +97,7 @@ func CreateOrg(name string, appsURL string, quotaID string, a OrganizationCreato
 	}
 	org, err := a.CreateOrg(req)
 	if err != nil {
-		return nil, errors.Wrapf(err, "could not create org with name [%s] and quota [%s]", name, quotaID)
+		return nil, newError(errors.Wrapf(err, "could not create org with name [%s] and quota [%s]", name, quotaID))
 	}
 	o := convertOrg(org, appsURL)
 	return &o, nil
@@ -55,6 +55,34 @@ func testOrgsForUserID(t *testing.T, when spec.G, it spec.S) {
 	})
 }
 
+func TestOrgsManagedByUserID(t *testing.T) {
+	spec.Run(t, "OrgsManagedByUserID", testOrgsManagedByUserID, spec.Report(report.Terminal{}))
+}
+
+func testOrgsManagedByUserID(t *testing.T, when spec.G, it spec.S) {
+	it.Before(func() {
+		RegisterTestingT(t)
+	})
+
+	it("returns an error if the querier returns an error", func() {
+		a := &cloudfoundryfakes.FakeAPI{}
+		a.ListOrgsByQueryReturns(nil, errors.New("test error"))
+		orgs, err := cloudfoundry.OrgsManagedByUserID("123", "", a)
+		Expect(err).To(HaveOccurred())
+		Expect(orgs).To(BeNil())
+	})
+
+	it("queries the orgs the user manages", func() {
+		a := &cloudfoundryfakes.FakeAPI{}
+		a.ListOrgsByQueryReturns([]cfclient.Org{{Guid: "1234", Name: "test-org"}}, nil)
+		orgs, err := cloudfoundry.OrgsManagedByUserID("123", "https://example.com", a)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(orgs).To(HaveLen(1))
+		Expect(orgs[0].GUID).To(Equal("1234"))
+		Expect(a.ListOrgsByQueryArgsForCall(0).Get("q")).To(Equal("manager_guid:123"))
+	})
+}
+
 func TestCreateOrg(t *testing.T) {
 	spec.Run(t, "CreateOrg", testCreateOrg, spec.Report(report.Terminal{}))
 }
@@ -0,0 +1,130 @@
+package cloudfoundry
+
+import (
+	"fmt"
+	"net/url"
+
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	"github.com/pkg/errors"
+)
+
+// StarterService is a marketplace service instance that is created in each new
+// space
+type StarterService struct {
+	Offering     string
+	Plan         string
+	InstanceName string
+}
+
+// ServiceInstance is a service instance that has been created; State is the
+// state of its last operation ("in progress", "succeeded" or "failed"), as
+// brokers may provision asynchronously
+type ServiceInstance struct {
+	GUID     string `json:"guid"`
+	Name     string `json:"name"`
+	Offering string `json:"offering"`
+	Plan     string `json:"plan"`
+	State    string `json:"state"`
+}
+
+// ServiceQuerier is used to query a Cloud Controller API for the marketplace
+type ServiceQuerier interface {
+	ListServicesByQuery(query url.Values) ([]cfclient.Service, error)
+	ListServicePlansByQuery(query url.Values) ([]cfclient.ServicePlan, error)
+	ListServicePlanVisibilitiesByQuery(query url.Values) ([]cfclient.ServicePlanVisibility, error)
+}
+
+// ServiceInstanceCreator creates service instances
+type ServiceInstanceCreator interface {
+	CreateServiceInstance(req cfclient.ServiceInstanceRequest) (cfclient.ServiceInstance, error)
+}
+
+// ServiceProvisioner finds marketplace plans and creates instances of them
+type ServiceProvisioner interface {
+	ServiceQuerier
+	ServiceInstanceCreator
+}
+
+// PlanNotVisibleError indicates that a service plan cannot be used in an org
+type PlanNotVisibleError struct {
+	Offering string
+	Plan     string
+	OrgGUID  string
+}
+
+func (p PlanNotVisibleError) Error() string {
+	return fmt.Sprintf("service plan [%s] of [%s] is not visible to org [%s]", p.Plan, p.Offering, p.OrgGUID)
+}
+
+// CreateServiceInstance creates an instance of the starter service in the
+// space, after checking that its plan is visible to the org
+func CreateServiceInstance(s StarterService, orgGUID string, spaceGUID string, a ServiceProvisioner) (*ServiceInstance, error) {
+	plan, err := servicePlan(s, a)
+	if err != nil {
+		return nil, err
+	}
+	visible, err := planVisible(plan, orgGUID, a)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, PlanNotVisibleError{Offering: s.Offering, Plan: s.Plan, OrgGUID: orgGUID}
+	}
+
+	instance, err := a.CreateServiceInstance(cfclient.ServiceInstanceRequest{
+		Name:            s.InstanceName,
+		SpaceGuid:       spaceGUID,
+		ServicePlanGuid: plan.Guid,
+	})
+	if err != nil {
+		return nil, newError(errors.Wrapf(err, "could not create service instance [%s] of [%s %s]", s.InstanceName, s.Offering, s.Plan))
+	}
+	return &ServiceInstance{
+		GUID:     instance.Guid,
+		Name:     s.InstanceName,
+		Offering: s.Offering,
+		Plan:     s.Plan,
+		State:    instance.LastOperation.State,
+	}, nil
+}
+
+func servicePlan(s StarterService, a ServiceQuerier) (cfclient.ServicePlan, error) {
+	q := url.Values{}
+	q.Set("q", fmt.Sprintf("label:%s", s.Offering))
+	services, err := a.ListServicesByQuery(q)
+	if err != nil {
+		return cfclient.ServicePlan{}, newError(errors.Wrapf(err, "could not find service offering [%s]", s.Offering))
+	}
+	if len(services) == 0 {
+		return cfclient.ServicePlan{}, errors.Errorf("service offering [%s] is not in the marketplace", s.Offering)
+	}
+
+	q = url.Values{}
+	q.Set("q", fmt.Sprintf("service_guid:%s", services[0].Guid))
+	plans, err := a.ListServicePlansByQuery(q)
+	if err != nil {
+		return cfclient.ServicePlan{}, newError(errors.Wrapf(err, "could not find the plans of service offering [%s]", s.Offering))
+	}
+	for _, p := range plans {
+		if p.Name == s.Plan {
+			return p, nil
+		}
+	}
+	return cfclient.ServicePlan{}, errors.Errorf("service offering [%s] has no plan [%s]", s.Offering, s.Plan)
+}
+
+// planVisible is true when the plan is public, or has been made visible to
+// the org
+func planVisible(plan cfclient.ServicePlan, orgGUID string, a ServiceQuerier) (bool, error) {
+	if plan.Public {
+		return true, nil
+	}
+	q := url.Values{}
+	q.Add("q", fmt.Sprintf("service_plan_guid:%s", plan.Guid))
+	q.Add("q", fmt.Sprintf("organization_guid:%s", orgGUID))
+	visibilities, err := a.ListServicePlanVisibilitiesByQuery(q)
+	if err != nil {
+		return false, newError(errors.Wrapf(err, "could not check the visibility of service plan [%s]", plan.Name))
+	}
+	return len(visibilities) > 0, nil
+}
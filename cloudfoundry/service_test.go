@@ -0,0 +1,104 @@
+package cloudfoundry_test
+
+import (
+	"errors"
+	"testing"
+
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/cloudfoundry/cloudfoundryfakes"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestCreateServiceInstance(t *testing.T) {
+	spec.Run(t, "CreateServiceInstance", testCreateServiceInstance, spec.Report(report.Terminal{}))
+}
+
+func testCreateServiceInstance(t *testing.T, when spec.G, it spec.S) {
+	var (
+		a       *cloudfoundryfakes.FakeAPI
+		starter cloudfoundry.StarterService
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		starter = cloudfoundry.StarterService{Offering: "p.mysql", Plan: "db-small", InstanceName: "my-database"}
+		a = &cloudfoundryfakes.FakeAPI{}
+		a.ListServicesByQueryReturns([]cfclient.Service{{Guid: "test-service-guid", Label: "p.mysql"}}, nil)
+		a.ListServicePlansByQueryReturns([]cfclient.ServicePlan{
+			{Guid: "large-plan-guid", Name: "db-large", Public: true},
+			{Guid: "small-plan-guid", Name: "db-small", Public: true},
+		}, nil)
+		a.CreateServiceInstanceReturns(cfclient.ServiceInstance{
+			Guid:          "test-instance-guid",
+			Name:          "my-database",
+			LastOperation: cfclient.LastOperation{State: "in progress"},
+		}, nil)
+	})
+
+	it("creates an instance of the plan in the space", func() {
+		instance, err := cloudfoundry.CreateServiceInstance(starter, "test-org-guid", "test-space-guid", a)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instance).To(Equal(&cloudfoundry.ServiceInstance{
+			GUID:     "test-instance-guid",
+			Name:     "my-database",
+			Offering: "p.mysql",
+			Plan:     "db-small",
+			State:    "in progress",
+		}))
+		Expect(a.ListServicesByQueryArgsForCall(0).Get("q")).To(Equal("label:p.mysql"))
+		Expect(a.ListServicePlansByQueryArgsForCall(0).Get("q")).To(Equal("service_guid:test-service-guid"))
+		Expect(a.CreateServiceInstanceArgsForCall(0)).To(Equal(cfclient.ServiceInstanceRequest{
+			Name:            "my-database",
+			SpaceGuid:       "test-space-guid",
+			ServicePlanGuid: "small-plan-guid",
+		}))
+		Expect(a.ListServicePlanVisibilitiesByQueryCallCount()).To(Equal(0))
+	})
+
+	it("fails when the offering is not in the marketplace", func() {
+		a.ListServicesByQueryReturns(nil, nil)
+		_, err := cloudfoundry.CreateServiceInstance(starter, "test-org-guid", "test-space-guid", a)
+		Expect(err).To(MatchError("service offering [p.mysql] is not in the marketplace"))
+	})
+
+	it("fails when the offering has no such plan", func() {
+		starter.Plan = "db-huge"
+		_, err := cloudfoundry.CreateServiceInstance(starter, "test-org-guid", "test-space-guid", a)
+		Expect(err).To(MatchError("service offering [p.mysql] has no plan [db-huge]"))
+		Expect(a.CreateServiceInstanceCallCount()).To(Equal(0))
+	})
+
+	when("the plan is not public", func() {
+		it.Before(func() {
+			a.ListServicePlansByQueryReturns([]cfclient.ServicePlan{{Guid: "small-plan-guid", Name: "db-small"}}, nil)
+		})
+
+		it("creates the instance when the plan is visible to the org", func() {
+			a.ListServicePlanVisibilitiesByQueryReturns([]cfclient.ServicePlanVisibility{{ServicePlanGuid: "small-plan-guid", OrganizationGuid: "test-org-guid"}}, nil)
+			_, err := cloudfoundry.CreateServiceInstance(starter, "test-org-guid", "test-space-guid", a)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.ListServicePlanVisibilitiesByQueryArgsForCall(0)["q"]).To(Equal([]string{"service_plan_guid:small-plan-guid", "organization_guid:test-org-guid"}))
+		})
+
+		it("fails when the plan is not visible to the org", func() {
+			_, err := cloudfoundry.CreateServiceInstance(starter, "test-org-guid", "test-space-guid", a)
+			Expect(err).To(Equal(cloudfoundry.PlanNotVisibleError{Offering: "p.mysql", Plan: "db-small", OrgGUID: "test-org-guid"}))
+			Expect(a.CreateServiceInstanceCallCount()).To(Equal(0))
+		})
+	})
+
+	it("classifies cloud controller errors", func() {
+		a.CreateServiceInstanceReturns(cfclient.ServiceInstance{}, cfclient.CloudFoundryHTTPError{StatusCode: 502})
+		_, err := cloudfoundry.CreateServiceInstance(starter, "test-org-guid", "test-space-guid", a)
+		Expect(cloudfoundry.KindOf(err)).To(Equal(cloudfoundry.ErrUnavailable))
+	})
+
+	it("returns an error when the marketplace cannot be queried", func() {
+		a.ListServicesByQueryReturns(nil, errors.New("test error"))
+		_, err := cloudfoundry.CreateServiceInstance(starter, "test-org-guid", "test-space-guid", a)
+		Expect(err).To(HaveOccurred())
+	})
+}
@@ -1,6 +1,8 @@
 package cloudfoundry
 
 import (
+	"fmt"
+	"net/url"
 	"strings"
 
 	cfclient "github.com/cloudfoundry-community/go-cfclient"
@@ -12,21 +14,70 @@ type SpaceCreator interface {
 	CreateSpace(req cfclient.SpaceRequest) (cfclient.Space, error)
 }
 
+// Space is a Cloud Foundry space
+type Space struct {
+	GUID             string `json:"guid"`
+	Name             string `json:"name"`
+	OrganizationGUID string `json:"organization_guid"`
+}
+
 // CreateSpace creates an space with the given name
-// the given user
-func CreateSpace(name string, organizationID string, userID string, a SpaceCreator) error {
+// the given user; the space has no members when userID is empty
+func CreateSpace(name string, organizationID string, userID string, allowSSH bool, a SpaceCreator) (*Space, error) {
 	req := cfclient.SpaceRequest{
 		Name:             strings.ToLower(name),
-		AuditorGuid:      []string{userID},
-		DeveloperGuid:    []string{userID},
-		ManagerGuid:      []string{userID},
 		OrganizationGuid: organizationID,
-		AllowSSH:         true,
+		AllowSSH:         allowSSH,
+	}
+	if userID != "" {
+		req.AuditorGuid = []string{userID}
+		req.DeveloperGuid = []string{userID}
+		req.ManagerGuid = []string{userID}
 	}
 	space, err := a.CreateSpace(req)
 	if err != nil || space.Guid == "" {
-		return errors.Wrapf(err, "could not create space with name [%s] and organizationID [%s]", name, organizationID)
+		return nil, newError(errors.Wrapf(err, "could not create space with name [%s] and organizationID [%s]", name, organizationID))
 	}
 
-	return nil
+	return &Space{
+		GUID:             space.Guid,
+		Name:             space.Name,
+		OrganizationGUID: organizationID,
+	}, nil
+}
+
+// SpaceQuerier is used to query a Cloud Controller API for spaces
+type SpaceQuerier interface {
+	ListSpacesByQuery(query url.Values) ([]cfclient.Space, error)
+}
+
+// FindSpace returns the space with the name in the org, or nil when it does
+// not exist
+func FindSpace(name string, orgGUID string, a SpaceQuerier) (*Space, error) {
+	q := url.Values{}
+	q.Add("q", fmt.Sprintf("name:%s", strings.ToLower(name)))
+	q.Add("q", fmt.Sprintf("organization_guid:%s", orgGUID))
+	spaces, err := a.ListSpacesByQuery(q)
+	if err != nil {
+		return nil, newError(errors.Wrapf(err, "could not find space [%s] in org [%s]", name, orgGUID))
+	}
+	if len(spaces) == 0 {
+		return nil, nil
+	}
+	return &Space{GUID: spaces[0].Guid, Name: spaces[0].Name, OrganizationGUID: orgGUID}, nil
+}
+
+// OrgSpaces returns all of the spaces in the org
+func OrgSpaces(orgGUID string, a SpaceQuerier) ([]Space, error) {
+	q := url.Values{}
+	q.Add("q", fmt.Sprintf("organization_guid:%s", orgGUID))
+	spaces, err := a.ListSpacesByQuery(q)
+	if err != nil {
+		return nil, newError(errors.Wrapf(err, "could not list the spaces in org [%s]", orgGUID))
+	}
+	result := make([]Space, len(spaces))
+	for i := range spaces {
+		result[i] = Space{GUID: spaces[i].Guid, Name: spaces[i].Name, OrganizationGUID: orgGUID}
+	}
+	return result, nil
 }
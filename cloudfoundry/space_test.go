@@ -24,8 +24,9 @@ func testCreateSpace(t *testing.T, when spec.G, it spec.S) {
 	it("returns an error if the creator returns an error", func() {
 		a := &cloudfoundryfakes.FakeAPI{}
 		a.CreateSpaceReturns(cfclient.Space{}, errors.New("test error"))
-		err := cloudfoundry.CreateSpace("test-space", "test-organization-id", "test-user-id", a)
+		space, err := cloudfoundry.CreateSpace("test-space", "test-organization-id", "test-user-id", true, a)
 		Expect(err).To(HaveOccurred())
+		Expect(space).To(BeNil())
 	})
 
 	it("returns the space if it is created successfully", func() {
@@ -36,7 +37,57 @@ func testCreateSpace(t *testing.T, when spec.G, it spec.S) {
 			CreatedAt: "created-at",
 			UpdatedAt: "updated-at",
 		}, nil)
-		err := cloudfoundry.CreateSpace("test-space", "test-organization-id", "test-user-id", a)
+		space, err := cloudfoundry.CreateSpace("test-space", "test-organization-id", "test-user-id", true, a)
 		Expect(err).NotTo(HaveOccurred())
+		Expect(space).To(Equal(&cloudfoundry.Space{
+			GUID:             "test-space-guid",
+			Name:             "test-space",
+			OrganizationGUID: "test-organization-id",
+		}))
+		Expect(a.CreateSpaceArgsForCall(0).AllowSSH).To(BeTrue())
+	})
+
+	it("disables ssh", func() {
+		a := &cloudfoundryfakes.FakeAPI{}
+		a.CreateSpaceReturns(cfclient.Space{Guid: "test-space-guid"}, nil)
+		_, err := cloudfoundry.CreateSpace("test-space", "test-organization-id", "test-user-id", false, a)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.CreateSpaceArgsForCall(0).AllowSSH).To(BeFalse())
+	})
+
+	it("creates a space without members when there is no user", func() {
+		a := &cloudfoundryfakes.FakeAPI{}
+		a.CreateSpaceReturns(cfclient.Space{Guid: "test-space-guid"}, nil)
+		_, err := cloudfoundry.CreateSpace("test-space", "test-organization-id", "", true, a)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.CreateSpaceArgsForCall(0).ManagerGuid).To(BeEmpty())
+		Expect(a.CreateSpaceArgsForCall(0).DeveloperGuid).To(BeEmpty())
+	})
+}
+
+func TestFindSpace(t *testing.T) {
+	spec.Run(t, "FindSpace", testFindSpace, spec.Report(report.Terminal{}))
+}
+
+func testFindSpace(t *testing.T, when spec.G, it spec.S) {
+	var a *cloudfoundryfakes.FakeAPI
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		a = &cloudfoundryfakes.FakeAPI{}
+	})
+
+	it("returns the space in the org", func() {
+		a.ListSpacesByQueryReturns([]cfclient.Space{{Guid: "test-space-guid", Name: "playground"}}, nil)
+		space, err := cloudfoundry.FindSpace("Playground", "test-org-guid", a)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(space).To(Equal(&cloudfoundry.Space{GUID: "test-space-guid", Name: "playground", OrganizationGUID: "test-org-guid"}))
+		Expect(a.ListSpacesByQueryArgsForCall(0)["q"]).To(Equal([]string{"name:playground", "organization_guid:test-org-guid"}))
+	})
+
+	it("returns nil when the space does not exist", func() {
+		space, err := cloudfoundry.FindSpace("playground", "test-org-guid", a)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(space).To(BeNil())
 	})
 }
@@ -0,0 +1,201 @@
+package cloudfoundry
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	"github.com/pkg/errors"
+)
+
+// OrgTemplate is applied to each new org and its space. Each part is applied
+// idempotently, so that a template can be applied again to an org that
+// already has some of it
+type OrgTemplate struct {
+	// IsolationSegment is the name of an isolation segment that the org is
+	// entitled to, and that is the org's default
+	IsolationSegment string
+	// SecurityGroups are the names of application security groups that are
+	// bound to the space
+	SecurityGroups []string
+	// SpaceQuota is created in the org (if it does not already exist) and
+	// assigned to the space
+	SpaceQuota *SpaceQuota
+	// PrivateDomains are created for the org, or shared with it when another
+	// org already owns them
+	PrivateDomains []string
+	// DisableSSH creates the space with SSH access to apps disabled
+	DisableSSH bool
+}
+
+// SpaceQuota is a space quota definition; limits that are less than zero are
+// unlimited
+type SpaceQuota struct {
+	Name                    string
+	MemoryLimit             int
+	InstanceMemoryLimit     int
+	TotalRoutes             int
+	TotalServices           int
+	AppInstanceLimit        int
+	NonBasicServicesAllowed bool
+}
+
+// Empty is true when there is nothing to apply after the space has been
+// created
+func (t OrgTemplate) Empty() bool {
+	return strings.TrimSpace(t.IsolationSegment) == "" && len(t.SecurityGroups) == 0 && t.SpaceQuota == nil && len(t.PrivateDomains) == 0
+}
+
+// IsolationSegmentAssigner entitles orgs to isolation segments
+type IsolationSegmentAssigner interface {
+	ListIsolationSegmentsByQuery(query url.Values) ([]cfclient.IsolationSegment, error)
+	AddIsolationSegmentToOrg(isolationSegmentGUID, orgGUID string) error
+	DefaultIsolationSegmentForOrg(orgGUID, isolationSegmentGUID string) error
+}
+
+// DomainCreator creates and shares private domains
+type DomainCreator interface {
+	ListDomainsByQuery(query url.Values) ([]cfclient.Domain, error)
+	CreateDomain(name, orgGUID string) (*cfclient.Domain, error)
+	ShareOrgPrivateDomain(orgGUID, privateDomainGUID string) (*cfclient.Domain, error)
+}
+
+// SpaceQuotaAssigner creates space quotas and assigns them to spaces
+type SpaceQuotaAssigner interface {
+	ListOrgSpaceQuotas(orgGUID string) ([]cfclient.SpaceQuota, error)
+	CreateSpaceQuota(req cfclient.SpaceQuotaRequest) (*cfclient.SpaceQuota, error)
+	AssignSpaceQuota(quotaGUID, spaceGUID string) error
+}
+
+// SecurityGroupBinder binds application security groups to spaces
+type SecurityGroupBinder interface {
+	GetSecGroupByName(name string) (cfclient.SecGroup, error)
+	BindSecGroup(secGUID, spaceGUID string) error
+}
+
+// TemplateApplier applies org templates
+type TemplateApplier interface {
+	IsolationSegmentAssigner
+	DomainCreator
+	SpaceQuotaAssigner
+	SecurityGroupBinder
+}
+
+// ApplyOrgTemplate applies the template to the org and the space. The parts
+// of the template that are applied to the space are skipped when space is nil.
+// The org's DefaultIsolationSegmentGUID is set when the template has an
+// isolation segment
+func ApplyOrgTemplate(t OrgTemplate, org *Organization, space *Space, a TemplateApplier) error {
+	if strings.TrimSpace(t.IsolationSegment) != "" {
+		guid, err := assignIsolationSegment(t.IsolationSegment, org.GUID, a)
+		if err != nil {
+			return err
+		}
+		org.DefaultIsolationSegmentGUID = guid
+	}
+	for _, d := range t.PrivateDomains {
+		if err := addPrivateDomain(d, org.GUID, a); err != nil {
+			return err
+		}
+	}
+	if space == nil {
+		return nil
+	}
+	if t.SpaceQuota != nil {
+		if err := assignSpaceQuota(*t.SpaceQuota, org.GUID, space.GUID, a); err != nil {
+			return err
+		}
+	}
+	for _, name := range t.SecurityGroups {
+		group, err := a.GetSecGroupByName(name)
+		if err != nil {
+			return newError(errors.Wrapf(err, "could not find security group [%s]", name))
+		}
+		if err := a.BindSecGroup(group.Guid, space.GUID); err != nil {
+			return newError(errors.Wrapf(err, "could not bind security group [%s] to space [%s]", name, space.GUID))
+		}
+	}
+	return nil
+}
+
+func assignIsolationSegment(name string, orgGUID string, a IsolationSegmentAssigner) (string, error) {
+	q := url.Values{}
+	q.Set("names", name)
+	segments, err := a.ListIsolationSegmentsByQuery(q)
+	if err != nil {
+		return "", newError(errors.Wrapf(err, "could not find isolation segment [%s]", name))
+	}
+	if len(segments) == 0 {
+		return "", errors.Errorf("isolation segment [%s] does not exist", name)
+	}
+	guid := segments[0].GUID
+	// entitling an org that is already entitled has no effect
+	if err := a.AddIsolationSegmentToOrg(guid, orgGUID); err != nil {
+		return "", newError(errors.Wrapf(err, "could not entitle org [%s] to isolation segment [%s]", orgGUID, name))
+	}
+	if err := a.DefaultIsolationSegmentForOrg(orgGUID, guid); err != nil {
+		return "", newError(errors.Wrapf(err, "could not make isolation segment [%s] the default for org [%s]", name, orgGUID))
+	}
+	return guid, nil
+}
+
+func addPrivateDomain(name string, orgGUID string, a DomainCreator) error {
+	q := url.Values{}
+	q.Set("q", fmt.Sprintf("name:%s", name))
+	domains, err := a.ListDomainsByQuery(q)
+	if err != nil {
+		return newError(errors.Wrapf(err, "could not find private domain [%s]", name))
+	}
+	if len(domains) == 0 {
+		if _, err := a.CreateDomain(name, orgGUID); err != nil {
+			return newError(errors.Wrapf(err, "could not create private domain [%s] for org [%s]", name, orgGUID))
+		}
+		return nil
+	}
+	if domains[0].OwningOrganizationGuid == orgGUID {
+		return nil
+	}
+	// sharing a domain that is already shared has no effect
+	if _, err := a.ShareOrgPrivateDomain(orgGUID, domains[0].Guid); err != nil {
+		return newError(errors.Wrapf(err, "could not share private domain [%s] with org [%s]", name, orgGUID))
+	}
+	return nil
+}
+
+func assignSpaceQuota(quota SpaceQuota, orgGUID string, spaceGUID string, a SpaceQuotaAssigner) error {
+	quotas, err := a.ListOrgSpaceQuotas(orgGUID)
+	if err != nil {
+		return newError(errors.Wrapf(err, "could not list the space quotas of org [%s]", orgGUID))
+	}
+	var guid string
+	for _, q := range quotas {
+		if q.Name == quota.Name {
+			guid = q.Guid
+			break
+		}
+	}
+	if guid == "" {
+		created, err := a.CreateSpaceQuota(cfclient.SpaceQuotaRequest{
+			Name:                    quota.Name,
+			OrganizationGuid:        orgGUID,
+			NonBasicServicesAllowed: quota.NonBasicServicesAllowed,
+			TotalServices:           quota.TotalServices,
+			TotalRoutes:             quota.TotalRoutes,
+			MemoryLimit:             quota.MemoryLimit,
+			InstanceMemoryLimit:     quota.InstanceMemoryLimit,
+			AppInstanceLimit:        quota.AppInstanceLimit,
+			AppTaskLimit:            -1,
+			TotalServiceKeys:        -1,
+			TotalReservedRoutePorts: 0,
+		})
+		if err != nil {
+			return newError(errors.Wrapf(err, "could not create space quota [%s] in org [%s]", quota.Name, orgGUID))
+		}
+		guid = created.Guid
+	}
+	if err := a.AssignSpaceQuota(guid, spaceGUID); err != nil {
+		return newError(errors.Wrapf(err, "could not assign space quota [%s] to space [%s]", quota.Name, spaceGUID))
+	}
+	return nil
+}
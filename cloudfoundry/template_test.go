@@ -0,0 +1,146 @@
+package cloudfoundry_test
+
+import (
+	"errors"
+	"testing"
+
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/cloudfoundry/cloudfoundryfakes"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestApplyOrgTemplate(t *testing.T) {
+	spec.Run(t, "ApplyOrgTemplate", testApplyOrgTemplate, spec.Report(report.Terminal{}))
+}
+
+func testApplyOrgTemplate(t *testing.T, when spec.G, it spec.S) {
+	var (
+		a        *cloudfoundryfakes.FakeAPI
+		org      *cloudfoundry.Organization
+		space    *cloudfoundry.Space
+		template cloudfoundry.OrgTemplate
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		a = &cloudfoundryfakes.FakeAPI{}
+		org = &cloudfoundry.Organization{GUID: "test-org-guid", Name: "ignition-testuser"}
+		space = &cloudfoundry.Space{GUID: "test-space-guid", Name: "playground", OrganizationGUID: "test-org-guid"}
+		template = cloudfoundry.OrgTemplate{}
+	})
+
+	it("does nothing for an empty template", func() {
+		Expect(template.Empty()).To(BeTrue())
+		Expect(cloudfoundry.ApplyOrgTemplate(template, org, space, a)).To(Succeed())
+		Expect(a.Invocations()).To(BeEmpty())
+	})
+
+	it("is empty when it only disables ssh, which is applied when the space is created", func() {
+		template.DisableSSH = true
+		Expect(template.Empty()).To(BeTrue())
+	})
+
+	when("the template has an isolation segment", func() {
+		it.Before(func() {
+			template.IsolationSegment = "dev-segment"
+		})
+
+		it("entitles the org to the segment and makes it the default", func() {
+			a.ListIsolationSegmentsByQueryReturns([]cfclient.IsolationSegment{{GUID: "test-segment-guid", Name: "dev-segment"}}, nil)
+			Expect(cloudfoundry.ApplyOrgTemplate(template, org, space, a)).To(Succeed())
+			Expect(a.ListIsolationSegmentsByQueryArgsForCall(0).Get("names")).To(Equal("dev-segment"))
+			segmentGUID, orgGUID := a.AddIsolationSegmentToOrgArgsForCall(0)
+			Expect(segmentGUID).To(Equal("test-segment-guid"))
+			Expect(orgGUID).To(Equal("test-org-guid"))
+			orgGUID, segmentGUID = a.DefaultIsolationSegmentForOrgArgsForCall(0)
+			Expect(orgGUID).To(Equal("test-org-guid"))
+			Expect(segmentGUID).To(Equal("test-segment-guid"))
+			Expect(org.DefaultIsolationSegmentGUID).To(Equal("test-segment-guid"))
+		})
+
+		it("fails when the segment does not exist", func() {
+			err := cloudfoundry.ApplyOrgTemplate(template, org, space, a)
+			Expect(err).To(MatchError("isolation segment [dev-segment] does not exist"))
+			Expect(org.DefaultIsolationSegmentGUID).To(BeEmpty())
+		})
+	})
+
+	when("the template has private domains", func() {
+		it.Before(func() {
+			template.PrivateDomains = []string{"new.example.com", "shared.example.com", "owned.example.com"}
+			a.ListDomainsByQueryReturnsOnCall(0, nil, nil)
+			a.ListDomainsByQueryReturnsOnCall(1, []cfclient.Domain{{Guid: "shared-domain-guid", OwningOrganizationGuid: "other-org-guid"}}, nil)
+			a.ListDomainsByQueryReturnsOnCall(2, []cfclient.Domain{{Guid: "owned-domain-guid", OwningOrganizationGuid: "test-org-guid"}}, nil)
+		})
+
+		it("creates new domains, shares domains owned by other orgs, and leaves the org's domains alone", func() {
+			Expect(cloudfoundry.ApplyOrgTemplate(template, org, space, a)).To(Succeed())
+			Expect(a.ListDomainsByQueryArgsForCall(0).Get("q")).To(Equal("name:new.example.com"))
+			Expect(a.CreateDomainCallCount()).To(Equal(1))
+			name, orgGUID := a.CreateDomainArgsForCall(0)
+			Expect(name).To(Equal("new.example.com"))
+			Expect(orgGUID).To(Equal("test-org-guid"))
+			Expect(a.ShareOrgPrivateDomainCallCount()).To(Equal(1))
+			orgGUID, domainGUID := a.ShareOrgPrivateDomainArgsForCall(0)
+			Expect(orgGUID).To(Equal("test-org-guid"))
+			Expect(domainGUID).To(Equal("shared-domain-guid"))
+		})
+	})
+
+	when("the template has a space quota", func() {
+		it.Before(func() {
+			template.SpaceQuota = &cloudfoundry.SpaceQuota{Name: "playground", MemoryLimit: 2048, InstanceMemoryLimit: -1, TotalRoutes: 10, TotalServices: 5, AppInstanceLimit: -1}
+			a.CreateSpaceQuotaReturns(&cfclient.SpaceQuota{Guid: "new-quota-guid"}, nil)
+		})
+
+		it("creates the quota and assigns it to the space", func() {
+			Expect(cloudfoundry.ApplyOrgTemplate(template, org, space, a)).To(Succeed())
+			req := a.CreateSpaceQuotaArgsForCall(0)
+			Expect(req.Name).To(Equal("playground"))
+			Expect(req.OrganizationGuid).To(Equal("test-org-guid"))
+			Expect(req.MemoryLimit).To(Equal(2048))
+			Expect(req.TotalRoutes).To(Equal(10))
+			quotaGUID, spaceGUID := a.AssignSpaceQuotaArgsForCall(0)
+			Expect(quotaGUID).To(Equal("new-quota-guid"))
+			Expect(spaceGUID).To(Equal("test-space-guid"))
+		})
+
+		it("reuses a quota that already exists in the org", func() {
+			a.ListOrgSpaceQuotasReturns([]cfclient.SpaceQuota{{Guid: "existing-quota-guid", Name: "playground"}}, nil)
+			Expect(cloudfoundry.ApplyOrgTemplate(template, org, space, a)).To(Succeed())
+			Expect(a.CreateSpaceQuotaCallCount()).To(Equal(0))
+			quotaGUID, _ := a.AssignSpaceQuotaArgsForCall(0)
+			Expect(quotaGUID).To(Equal("existing-quota-guid"))
+		})
+
+		it("skips the quota when there is no space", func() {
+			Expect(cloudfoundry.ApplyOrgTemplate(template, org, nil, a)).To(Succeed())
+			Expect(a.ListOrgSpaceQuotasCallCount()).To(Equal(0))
+		})
+	})
+
+	when("the template has security groups", func() {
+		it.Before(func() {
+			template.SecurityGroups = []string{"public-networks"}
+		})
+
+		it("binds them to the space", func() {
+			a.GetSecGroupByNameReturns(cfclient.SecGroup{Guid: "test-group-guid", Name: "public-networks"}, nil)
+			Expect(cloudfoundry.ApplyOrgTemplate(template, org, space, a)).To(Succeed())
+			Expect(a.GetSecGroupByNameArgsForCall(0)).To(Equal("public-networks"))
+			groupGUID, spaceGUID := a.BindSecGroupArgsForCall(0)
+			Expect(groupGUID).To(Equal("test-group-guid"))
+			Expect(spaceGUID).To(Equal("test-space-guid"))
+		})
+
+		it("fails when a group does not exist", func() {
+			a.GetSecGroupByNameReturns(cfclient.SecGroup{}, errors.New("No security group with name public-networks found"))
+			err := cloudfoundry.ApplyOrgTemplate(template, org, space, a)
+			Expect(err).To(MatchError(ContainSubstring("could not find security group [public-networks]")))
+			Expect(a.BindSecGroupCallCount()).To(Equal(0))
+		})
+	})
+}
@@ -0,0 +1,779 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/http/session"
+	"github.com/pivotalservices/ignition/notify"
+	"github.com/pivotalservices/ignition/webhook"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// config holds every ignition setting. Settings are read from an optional
+// YAML (or JSON) config file and are then overridden by any IGNITION_*
+// environment variables that are set
+type config struct {
+	AuthVariant            string        `envconfig:"auth_variant" yaml:"auth_variant"`                         // IGNITION_AUTH_VARIANT
+	ClientID               string        `envconfig:"client_id" yaml:"client_id"`                               // IGNITION_CLIENT_ID
+	ClientSecret           string        `envconfig:"client_secret" yaml:"client_secret"`                       // IGNITION_CLIENT_SECRET
+	AuthURL                string        `envconfig:"auth_url" yaml:"auth_url"`                                 // IGNITION_AUTH_URL
+	TokenURL               string        `envconfig:"token_url" yaml:"token_url"`                               // IGNITION_TOKEN_URL
+	JWKSURL                string        `envconfig:"jwks_url" yaml:"jwks_url"`                                 // IGNITION_JWKS_URL
+	IssuerURL              string        `envconfig:"issuer_url" yaml:"issuer_url"`                             // IGNITION_ISSUER_URL
+	AuthScopes             []string      `envconfig:"auth_scopes" yaml:"auth_scopes"`                           // IGNITION_AUTH_SCOPES
+	GroupsClaim            string        `envconfig:"groups_claim" yaml:"groups_claim"`                         // IGNITION_GROUPS_CLAIM
+	AuthorizedDomain       string        `envconfig:"authorized_domain" yaml:"authorized_domain"`               // IGNITION_AUTHORIZED_DOMAIN
+	SessionSecret          string        `envconfig:"session_secret" yaml:"session_secret"`                     // IGNITION_SESSION_SECRET
+	SessionPreviousSecrets []string      `envconfig:"session_previous_secrets" yaml:"session_previous_secrets"` // IGNITION_SESSION_PREVIOUS_SECRETS
+	SessionStorePath       string        `envconfig:"session_store_path" yaml:"session_store_path"`             // IGNITION_SESSION_STORE_PATH
+	SessionLifetime        time.Duration `envconfig:"session_lifetime" yaml:"session_lifetime"`                 // IGNITION_SESSION_LIFETIME
+	SessionIdleTimeout     time.Duration `envconfig:"session_idle_timeout" yaml:"session_idle_timeout"`         // IGNITION_SESSION_IDLE_TIMEOUT
+	LogoutRevokeTokens     bool          `envconfig:"logout_revoke_tokens" yaml:"logout_revoke_tokens"`         // IGNITION_LOGOUT_REVOKE_TOKENS
+	LogoutURL              string        `envconfig:"logout_url" yaml:"logout_url"`                             // IGNITION_LOGOUT_URL
+	LogoutReturnURL        string        `envconfig:"logout_return_url" yaml:"logout_return_url"`               // IGNITION_LOGOUT_RETURN_URL
+	LoginNextPaths         []string      `envconfig:"login_next_paths" yaml:"login_next_paths"`                 // IGNITION_LOGIN_NEXT_PATHS
+	SessionMaxAge          time.Duration `envconfig:"session_max_age" yaml:"session_max_age"`                   // IGNITION_SESSION_MAX_AGE
+	SessionCookieSecure    *bool         `envconfig:"session_cookie_secure" yaml:"session_cookie_secure"`       // IGNITION_SESSION_COOKIE_SECURE
+	SessionCookieHTTPOnly  bool          `envconfig:"session_cookie_http_only" yaml:"session_cookie_http_only"` // IGNITION_SESSION_COOKIE_HTTP_ONLY
+	SessionCookieSameSite  string        `envconfig:"session_cookie_same_site" yaml:"session_cookie_same_site"` // IGNITION_SESSION_COOKIE_SAME_SITE
+	Port                   int           `envconfig:"port" yaml:"port"`                                         // IGNITION_PORT
+	ServePort              int           `envconfig:"serve_port" yaml:"serve_port"`                             // IGNITION_SERVE_PORT
+	Domain                 string        `envconfig:"domain" yaml:"domain"`                                     // IGNITION_DOMAIN
+	Scheme                 string        `envconfig:"scheme" yaml:"scheme"`                                     // IGNITION_SCHEME
+	WebRoot                string        `envconfig:"web_root" yaml:"web_root"`                                 // IGNITION_WEB_ROOT
+	UAAURL                 string        `envconfig:"uaa_url" yaml:"uaa_url"`                                   // IGNITION_UAA_URL
+	UAAOrigin              string        `envconfig:"uaa_origin" yaml:"uaa_origin"`                             // IGNITION_UAA_ORIGIN
+	AppsURL                string        `envconfig:"apps_url" yaml:"apps_url"`                                 // IGNITION_APPS_URL
+	CCAPIURL               string        `envconfig:"ccapi_url" yaml:"ccapi_url"`                               // IGNITION_CCAPI_URL
+	CCAPIClientID          string        `envconfig:"ccapi_client_id" yaml:"ccapi_client_id"`                   // IGNITION_CCAPI_CLIENT_ID
+	CCAPIClientSecret      string        `envconfig:"ccapi_client_secret" yaml:"ccapi_client_secret"`           // IGNITION_CCAPI_CLIENT_SECRET
+	CCAPIUsername          string        `envconfig:"ccapi_username" yaml:"ccapi_username"`                     // IGNITION_CCAPI_USERNAME
+	CCAPIPassword          string        `envconfig:"ccapi_password" yaml:"ccapi_password"`                     // IGNITION_CCAPI_PASSWORD
+	OrgPrefix              string        `envconfig:"org_prefix" yaml:"org_prefix"`                             // IGNITION_ORG_PREFIX
+	QuotaID                string        `envconfig:"quota_id" yaml:"quota_id"`                                 // IGNITION_QUOTA_ID
+	QuotaTier              string        `envconfig:"quota_tier" yaml:"quota_tier"`                             // IGNITION_QUOTA_TIER
+	SpaceName              string        `envconfig:"space_name" yaml:"space_name"`                             // IGNITION_SPACE_NAME
+	CredentialsService     string        `envconfig:"credentials_service" yaml:"credentials_service"`           // IGNITION_CREDENTIALS_SERVICE
+	ReadTimeout            time.Duration `envconfig:"read_timeout" yaml:"read_timeout"`                         // IGNITION_READ_TIMEOUT
+	WriteTimeout           time.Duration `envconfig:"write_timeout" yaml:"write_timeout"`                       // IGNITION_WRITE_TIMEOUT
+	IdleTimeout            time.Duration `envconfig:"idle_timeout" yaml:"idle_timeout"`                         // IGNITION_IDLE_TIMEOUT
+	ShutdownTimeout        time.Duration `envconfig:"shutdown_timeout" yaml:"shutdown_timeout"`                 // IGNITION_SHUTDOWN_TIMEOUT
+	TLSCertFile            string        `envconfig:"tls_cert_file" yaml:"tls_cert_file"`                       // IGNITION_TLS_CERT_FILE
+	TLSKeyFile             string        `envconfig:"tls_key_file" yaml:"tls_key_file"`                         // IGNITION_TLS_KEY_FILE
+	HealthCacheTTL         time.Duration `envconfig:"health_cache_ttl" yaml:"health_cache_ttl"`                 // IGNITION_HEALTH_CACHE_TTL
+	HealthCheckTimeout     time.Duration `envconfig:"health_check_timeout" yaml:"health_check_timeout"`         // IGNITION_HEALTH_CHECK_TIMEOUT
+	CORSAllowedOrigins     []string      `envconfig:"cors_allowed_origins" yaml:"cors_allowed_origins"`         // IGNITION_CORS_ALLOWED_ORIGINS
+	CORSAllowedMethods     []string      `envconfig:"cors_allowed_methods" yaml:"cors_allowed_methods"`         // IGNITION_CORS_ALLOWED_METHODS
+	CORSAllowedHeaders     []string      `envconfig:"cors_allowed_headers" yaml:"cors_allowed_headers"`         // IGNITION_CORS_ALLOWED_HEADERS
+	CORSAllowCredentials   bool          `envconfig:"cors_allow_credentials" yaml:"cors_allow_credentials"`     // IGNITION_CORS_ALLOW_CREDENTIALS
+	ProvisionWorkers       int           `envconfig:"provision_workers" yaml:"provision_workers"`               // IGNITION_PROVISION_WORKERS
+	JobStorePath           string        `envconfig:"job_store_path" yaml:"job_store_path"`                     // IGNITION_JOB_STORE_PATH
+	BrandingDir            string        `envconfig:"branding_dir" yaml:"branding_dir"`                         // IGNITION_BRANDING_DIR
+	SampleAppPath          string        `envconfig:"sample_app_path" yaml:"sample_app_path"`                   // IGNITION_SAMPLE_APP_PATH
+	SampleAppName          string        `envconfig:"sample_app_name" yaml:"sample_app_name"`                   // IGNITION_SAMPLE_APP_NAME
+	SampleAppMemory        int           `envconfig:"sample_app_memory" yaml:"sample_app_memory"`               // IGNITION_SAMPLE_APP_MEMORY
+	SampleAppBuildpack     string        `envconfig:"sample_app_buildpack" yaml:"sample_app_buildpack"`         // IGNITION_SAMPLE_APP_BUILDPACK
+	SampleAppTimeout       time.Duration `envconfig:"sample_app_timeout" yaml:"sample_app_timeout"`             // IGNITION_SAMPLE_APP_TIMEOUT
+	AuditLogPath           string        `envconfig:"audit_log_path" yaml:"audit_log_path"`                     // IGNITION_AUDIT_LOG_PATH
+	AuditSyslogURL         string        `envconfig:"audit_syslog_url" yaml:"audit_syslog_url"`                 // IGNITION_AUDIT_SYSLOG_URL
+	AuditWebhookURL        string        `envconfig:"audit_webhook_url" yaml:"audit_webhook_url"`               // IGNITION_AUDIT_WEBHOOK_URL
+	Admins                 []string      `envconfig:"admins" yaml:"admins"`                                     // IGNITION_ADMINS
+	WebhookStorePath       string        `envconfig:"webhook_store_path" yaml:"webhook_store_path"`             // IGNITION_WEBHOOK_STORE_PATH
+	WebhookMaxAttempts     int           `envconfig:"webhook_max_attempts" yaml:"webhook_max_attempts"`         // IGNITION_WEBHOOK_MAX_ATTEMPTS
+	SMTPHost               string        `envconfig:"smtp_host" yaml:"smtp_host"`                               // IGNITION_SMTP_HOST
+	SMTPPort               int           `envconfig:"smtp_port" yaml:"smtp_port"`                               // IGNITION_SMTP_PORT
+	SMTPUsername           string        `envconfig:"smtp_username" yaml:"smtp_username"`                       // IGNITION_SMTP_USERNAME
+	SMTPPassword           string        `envconfig:"smtp_password" yaml:"smtp_password"`                       // IGNITION_SMTP_PASSWORD
+	EmailFrom              string        `envconfig:"email_from" yaml:"email_from"`                             // IGNITION_EMAIL_FROM
+	EmailTemplatesDir      string        `envconfig:"email_templates_dir" yaml:"email_templates_dir"`           // IGNITION_EMAIL_TEMPLATES_DIR
+	EmailDryRunDir         string        `envconfig:"email_dry_run_dir" yaml:"email_dry_run_dir"`               // IGNITION_EMAIL_DRY_RUN_DIR
+	TermsPath              string        `envconfig:"terms_path" yaml:"terms_path"`                             // IGNITION_TERMS_PATH
+	TermsVersion           string        `envconfig:"terms_version" yaml:"terms_version"`                       // IGNITION_TERMS_VERSION
+	TermsStorePath         string        `envconfig:"terms_store_path" yaml:"terms_store_path"`                 // IGNITION_TERMS_STORE_PATH
+
+	// The following settings can only be provided in the config file
+	Foundations []foundation      `ignored:"true" yaml:"foundations"`
+	QuotaTiers  []quotaTier       `ignored:"true" yaml:"quota_tiers"`
+	Policies    []policy          `ignored:"true" yaml:"policies"`
+	Branding    branding          `ignored:"true" yaml:"branding"`
+	TeamOrgs    []teamOrg         `ignored:"true" yaml:"team_orgs"`
+	Webhooks    []webhookEndpoint `ignored:"true" yaml:"webhooks"`
+
+	// OrgTemplate is applied to each new org and its space
+	OrgTemplate orgTemplate `ignored:"true" yaml:"org_template"`
+
+	// StarterServices are created in each new space
+	StarterServices []starterService `ignored:"true" yaml:"starter_services"`
+
+	// ServiceCredentials read settings from the credentials of bound services
+	ServiceCredentials []serviceCredential `ignored:"true" yaml:"service_credentials"`
+
+	// IdentityService selects the bound Single Sign On service that the
+	// p-identity variant reads its client from (default: the service named
+	// identity)
+	IdentityService *serviceSelector `ignored:"true" yaml:"identity_service"`
+}
+
+// foundation is a Cloud Foundry deployment; the first foundation supplies the
+// ccapi_url, uaa_url and apps_url when they are not set directly
+type foundation struct {
+	Name    string `yaml:"name"`
+	APIURL  string `yaml:"api_url"`
+	UAAURL  string `yaml:"uaa_url"`
+	AppsURL string `yaml:"apps_url"`
+}
+
+// quotaTier gives a name to a Cloud Controller quota definition
+type quotaTier struct {
+	Name    string `yaml:"name"`
+	QuotaID string `yaml:"quota_id"`
+}
+
+// policy authorizes users with an email address in the domain and assigns
+// their org the quota of the given tier
+type policy struct {
+	Domain    string `yaml:"domain"`
+	QuotaTier string `yaml:"quota_tier"`
+}
+
+// teamOrg is an org that is shared by the members of an identity provider
+// group; its members are granted the roles, or are space developers when no
+// roles are given
+type teamOrg struct {
+	Group     string   `yaml:"group"`
+	Name      string   `yaml:"name"`
+	QuotaTier string   `yaml:"quota_tier"`
+	Roles     []string `yaml:"roles"`
+}
+
+// webhookEndpoint is notified of onboarding events; the payloads are signed
+// with the secret, and it is notified of every event when no events are given
+type webhookEndpoint struct {
+	URL    string   `yaml:"url"`
+	Secret string   `yaml:"secret"`
+	Events []string `yaml:"events"`
+}
+
+// orgTemplate configures new orgs and spaces
+type orgTemplate struct {
+	IsolationSegment string      `yaml:"isolation_segment"`
+	SecurityGroups   []string    `yaml:"security_groups"`
+	SpaceQuota       *spaceQuota `yaml:"space_quota"`
+	PrivateDomains   []string    `yaml:"private_domains"`
+	AllowSSH         *bool       `yaml:"allow_ssh"`
+}
+
+// spaceQuota is a space quota definition; limits other than memory_limit are
+// unlimited when they are not set
+type spaceQuota struct {
+	Name                    string `yaml:"name"`
+	MemoryLimit             int    `yaml:"memory_limit"`
+	InstanceMemoryLimit     int    `yaml:"instance_memory_limit"`
+	TotalRoutes             int    `yaml:"total_routes"`
+	TotalServices           int    `yaml:"total_services"`
+	AppInstanceLimit        int    `yaml:"app_instance_limit"`
+	NonBasicServicesAllowed bool   `yaml:"non_basic_services_allowed"`
+}
+
+// starterService is a marketplace service instance created in new spaces
+type starterService struct {
+	Offering     string `yaml:"offering"`
+	Plan         string `yaml:"plan"`
+	InstanceName string `yaml:"instance_name"`
+}
+
+// branding customizes the content of the web app; anything that is not set
+// keeps the default content
+type branding struct {
+	Title        string         `yaml:"title"`
+	LogoURL      string         `yaml:"logo_url"`
+	Support      support        `yaml:"support"`
+	Steps        []brandingStep `yaml:"steps"`
+	CLIDownloads []link         `yaml:"cli_downloads"`
+}
+
+// support is who users should contact for help
+type support struct {
+	Name  string `yaml:"name"`
+	Email string `yaml:"email"`
+	URL   string `yaml:"url"`
+}
+
+// brandingStep is a getting started step, displayed as "text link_text suffix"
+type brandingStep struct {
+	Text     string `yaml:"text"`
+	LinkText string `yaml:"link_text"`
+	LinkURL  string `yaml:"link_url"`
+	Suffix   string `yaml:"suffix"`
+}
+
+// link is a named URL
+type link struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+}
+
+// defaultConfig returns a config with the default value for each setting
+func defaultConfig() config {
+	return config{
+		AuthVariant:           "openid",
+		AuthScopes:            []string{"openid", "profile", "user_attributes"},
+		GroupsClaim:           "groups",
+		Port:                  3000,
+		ServePort:             3000,
+		Domain:                "localhost",
+		Scheme:                "http",
+		CCAPIClientID:         "cf",
+		OrgPrefix:             "ignition",
+		SpaceName:             "playground",
+		ReadTimeout:           30 * time.Second,
+		WriteTimeout:          60 * time.Second,
+		IdleTimeout:           120 * time.Second,
+		ShutdownTimeout:       10 * time.Second,
+		HealthCacheTTL:        10 * time.Second,
+		HealthCheckTimeout:    5 * time.Second,
+		CORSAllowedMethods:    []string{"GET", "HEAD", "POST", "DELETE"},
+		ProvisionWorkers:      4,
+		SampleAppName:         "sample-app",
+		SampleAppMemory:       1024,
+		SampleAppTimeout:      5 * time.Minute,
+		SMTPPort:              587,
+		SessionIdleTimeout:    24 * time.Hour,
+		LoginNextPaths:        []string{"/"},
+		SessionMaxAge:         session.DefaultMaxAge,
+		SessionCookieHTTPOnly: true,
+		SessionCookieSameSite: "lax",
+	}
+}
+
+// loadConfig reads the config file at path (if path is not empty) and then
+// applies any IGNITION_* environment variables on top of it
+func loadConfig(path string) (*config, error) {
+	c := defaultConfig()
+	if strings.TrimSpace(path) != "" {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read config file [%s]", path)
+		}
+		// JSON is a subset of YAML, so the YAML decoder reads both formats
+		err = yaml.UnmarshalStrict(b, &c)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse config file [%s]", path)
+		}
+	}
+	err := envconfig.Process("ignition", &c)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// configFilePath returns the config file path from the flag value, falling
+// back to IGNITION_CONFIG_FILE
+func configFilePath(flagValue string) string {
+	if strings.TrimSpace(flagValue) != "" {
+		return flagValue
+	}
+	return os.Getenv("IGNITION_CONFIG_FILE")
+}
+
+// resolve fills settings that can be derived from the structured settings
+func (c *config) resolve() {
+	if len(c.Foundations) > 0 {
+		f := c.Foundations[0]
+		if strings.TrimSpace(c.CCAPIURL) == "" {
+			c.CCAPIURL = f.APIURL
+		}
+		if strings.TrimSpace(c.UAAURL) == "" {
+			c.UAAURL = f.UAAURL
+		}
+		if strings.TrimSpace(c.AppsURL) == "" {
+			c.AppsURL = f.AppsURL
+		}
+	}
+	if c.SessionLifetime == 0 {
+		c.SessionLifetime = c.SessionMaxAge
+	}
+	if strings.TrimSpace(c.QuotaID) == "" && strings.TrimSpace(c.QuotaTier) != "" {
+		if t, ok := c.quotaTier(c.QuotaTier); ok {
+			c.QuotaID = t.QuotaID
+		}
+	}
+}
+
+func (c *config) quotaTier(name string) (quotaTier, bool) {
+	for i := range c.QuotaTiers {
+		if strings.EqualFold(c.QuotaTiers[i].Name, name) {
+			return c.QuotaTiers[i], true
+		}
+	}
+	return quotaTier{}, false
+}
+
+// validate checks every setting and reports all of the invalid ones at once
+func (c *config) validate() error {
+	var invalid invalidConfigError
+	required := []struct {
+		key   string
+		value string
+	}{
+		{"client_id", c.ClientID},
+		{"client_secret", c.ClientSecret},
+		{"auth_url", c.AuthURL},
+		{"token_url", c.TokenURL},
+		{"jwks_url", c.JWKSURL},
+		{"issuer_url", c.IssuerURL},
+		{"session_secret", c.SessionSecret},
+		{"uaa_url", c.UAAURL},
+		{"uaa_origin", c.UAAOrigin},
+		{"apps_url", c.AppsURL},
+		{"ccapi_url", c.CCAPIURL},
+		{"ccapi_username", c.CCAPIUsername},
+		{"ccapi_password", c.CCAPIPassword},
+	}
+	for _, r := range required {
+		if strings.TrimSpace(r.value) == "" {
+			invalid.add(r.key, "is required")
+		}
+	}
+	if strings.TrimSpace(c.AuthorizedDomain) == "" && len(c.Policies) == 0 {
+		invalid.add("authorized_domain", "is required when no policies are configured")
+	}
+	if strings.TrimSpace(c.QuotaID) == "" {
+		if strings.TrimSpace(c.QuotaTier) == "" {
+			invalid.add("quota_id", "is required")
+		} else if _, ok := c.quotaTier(c.QuotaTier); !ok {
+			invalid.add("quota_tier", fmt.Sprintf("refers to unknown quota tier [%s]", c.QuotaTier))
+		} else {
+			invalid.add("quota_tier", fmt.Sprintf("quota tier [%s] has no quota_id", c.QuotaTier))
+		}
+	}
+	if c.Port < 0 {
+		invalid.add("port", "must not be negative")
+	}
+	if c.ServePort <= 0 {
+		invalid.add("serve_port", "must be greater than zero")
+	}
+	if c.ProvisionWorkers <= 0 {
+		invalid.add("provision_workers", "must be greater than zero")
+	}
+	durations := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"read_timeout", c.ReadTimeout},
+		{"write_timeout", c.WriteTimeout},
+		{"idle_timeout", c.IdleTimeout},
+		{"shutdown_timeout", c.ShutdownTimeout},
+		{"health_cache_ttl", c.HealthCacheTTL},
+		{"health_check_timeout", c.HealthCheckTimeout},
+		{"sample_app_timeout", c.SampleAppTimeout},
+		{"session_idle_timeout", c.SessionIdleTimeout},
+		{"session_lifetime", c.SessionLifetime},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			invalid.add(d.key, "must not be negative")
+		}
+	}
+	for _, secret := range c.SessionPreviousSecrets {
+		if strings.TrimSpace(secret) == "" {
+			invalid.add("session_previous_secrets", "must not contain an empty secret")
+		} else if secret == c.SessionSecret {
+			invalid.add("session_previous_secrets", "must not contain the current session_secret (IGNITION_SESSION_SECRET)")
+		}
+	}
+	if c.LogoutRevokeTokens && !strings.HasSuffix(strings.TrimSuffix(c.TokenURL, "/"), "/oauth/token") {
+		invalid.add("logout_revoke_tokens", "requires a UAA token_url (IGNITION_TOKEN_URL) that ends in /oauth/token")
+	}
+	if strings.TrimSpace(c.LogoutURL) != "" && !isWebURL(c.LogoutURL) {
+		invalid.add("logout_url", fmt.Sprintf("[%s] must be an http(s) URL", c.LogoutURL))
+	}
+	if strings.TrimSpace(c.LogoutReturnURL) != "" && !isWebURL(c.LogoutReturnURL) {
+		invalid.add("logout_return_url", fmt.Sprintf("[%s] must be an http(s) URL", c.LogoutReturnURL))
+	}
+	for _, p := range c.LoginNextPaths {
+		if !strings.HasPrefix(p, "/") || strings.HasPrefix(p, "//") {
+			invalid.add("login_next_paths", fmt.Sprintf("[%s] must be a path that starts with /", p))
+		}
+	}
+	if c.SessionMaxAge <= 0 {
+		invalid.add("session_max_age", "must be greater than zero")
+	} else if c.SessionLifetime > c.SessionMaxAge {
+		invalid.add("session_lifetime", "must not be longer than session_max_age (IGNITION_SESSION_MAX_AGE)")
+	}
+	if _, ok := sameSiteModes[strings.ToLower(strings.TrimSpace(c.SessionCookieSameSite))]; !ok {
+		invalid.add("session_cookie_same_site", fmt.Sprintf("[%s] is not one of lax, strict or none", c.SessionCookieSameSite))
+	} else if strings.EqualFold(strings.TrimSpace(c.SessionCookieSameSite), "none") && !c.sessionCookieSecure() {
+		invalid.add("session_cookie_same_site", "can only be none when session_cookie_secure (IGNITION_SESSION_COOKIE_SECURE) is true")
+	}
+	if strings.TrimSpace(c.SampleAppPath) != "" {
+		if info, err := os.Stat(c.SampleAppPath); err != nil || info.IsDir() {
+			invalid.add("sample_app_path", fmt.Sprintf("refers to [%s], which is not a file", c.SampleAppPath))
+		}
+		if strings.TrimSpace(c.SampleAppName) == "" {
+			invalid.add("sample_app_name", "is required when sample_app_path is set")
+		}
+		if c.SampleAppMemory <= 0 {
+			invalid.add("sample_app_memory", "must be greater than zero")
+		}
+	}
+	if (strings.TrimSpace(c.TLSCertFile) == "") != (strings.TrimSpace(c.TLSKeyFile) == "") {
+		invalid.add("tls_cert_file", "and tls_key_file must be set together")
+	}
+	for _, o := range c.CORSAllowedOrigins {
+		if o == "*" {
+			if c.CORSAllowCredentials {
+				invalid.add("cors_allowed_origins", "must list each origin when cors_allow_credentials is true")
+			}
+			continue
+		}
+		u, err := url.Parse(o)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.TrimSuffix(u.Path, "/") != "" || u.RawQuery != "" {
+			invalid.add("cors_allowed_origins", fmt.Sprintf("contains [%s], which is not an origin like https://portal.example.com", o))
+		}
+	}
+	for _, m := range c.CORSAllowedMethods {
+		if strings.TrimSpace(m) == "" || m != strings.ToUpper(m) {
+			invalid.add("cors_allowed_methods", fmt.Sprintf("contains [%s], which is not an upper case HTTP method", m))
+		}
+	}
+
+	names := map[string]bool{}
+	for i, f := range c.Foundations {
+		field := fmt.Sprintf("foundations[%d]", i)
+		if strings.TrimSpace(f.Name) == "" {
+			invalid.addField(field+".name", "is required")
+		} else if names[strings.ToLower(f.Name)] {
+			invalid.addField(field+".name", fmt.Sprintf("duplicates foundation [%s]", f.Name))
+		}
+		names[strings.ToLower(f.Name)] = true
+		if strings.TrimSpace(f.APIURL) == "" {
+			invalid.addField(field+".api_url", "is required")
+		}
+	}
+	tiers := map[string]bool{}
+	for i, t := range c.QuotaTiers {
+		field := fmt.Sprintf("quota_tiers[%d]", i)
+		if strings.TrimSpace(t.Name) == "" {
+			invalid.addField(field+".name", "is required")
+		} else if tiers[strings.ToLower(t.Name)] {
+			invalid.addField(field+".name", fmt.Sprintf("duplicates quota tier [%s]", t.Name))
+		}
+		tiers[strings.ToLower(t.Name)] = true
+		if strings.TrimSpace(t.QuotaID) == "" {
+			invalid.addField(field+".quota_id", "is required")
+		}
+	}
+	for i, p := range c.Policies {
+		field := fmt.Sprintf("policies[%d]", i)
+		if strings.TrimSpace(p.Domain) == "" {
+			invalid.addField(field+".domain", "is required")
+		}
+		if strings.TrimSpace(p.QuotaTier) != "" {
+			if _, ok := c.quotaTier(p.QuotaTier); !ok {
+				invalid.addField(field+".quota_tier", fmt.Sprintf("refers to unknown quota tier [%s]", p.QuotaTier))
+			}
+		}
+	}
+	if strings.TrimSpace(c.TermsPath) != "" {
+		if info, err := os.Stat(c.TermsPath); err != nil || info.IsDir() {
+			invalid.add("terms_path", fmt.Sprintf("refers to [%s], which is not a file", c.TermsPath))
+		} else if info.Size() == 0 {
+			invalid.add("terms_path", fmt.Sprintf("refers to [%s], which is empty", c.TermsPath))
+		}
+	} else if strings.TrimSpace(c.TermsVersion) != "" {
+		invalid.add("terms_version", "is set, but terms_path (IGNITION_TERMS_PATH) is not")
+	}
+	c.validateTeamOrgs(&invalid)
+	c.validateWebhooks(&invalid)
+	c.validateEmail(&invalid)
+	if q := c.OrgTemplate.SpaceQuota; q != nil {
+		if strings.TrimSpace(q.Name) == "" {
+			invalid.addField("org_template.space_quota.name", "is required")
+		}
+		if q.MemoryLimit <= 0 {
+			invalid.addField("org_template.space_quota.memory_limit", "must be greater than zero")
+		}
+	}
+	for i, d := range c.OrgTemplate.PrivateDomains {
+		if strings.TrimSpace(d) == "" || strings.ContainsAny(d, "/: ") {
+			invalid.addField(fmt.Sprintf("org_template.private_domains[%d]", i), fmt.Sprintf("[%s] is not a domain name", d))
+		}
+	}
+	instanceNames := map[string]bool{}
+	for i, s := range c.StarterServices {
+		field := fmt.Sprintf("starter_services[%d]", i)
+		if strings.TrimSpace(s.Offering) == "" {
+			invalid.addField(field+".offering", "is required")
+		}
+		if strings.TrimSpace(s.Plan) == "" {
+			invalid.addField(field+".plan", "is required")
+		}
+		if strings.TrimSpace(s.InstanceName) == "" {
+			invalid.addField(field+".instance_name", "is required")
+		} else if instanceNames[s.InstanceName] {
+			invalid.addField(field+".instance_name", fmt.Sprintf("[%s] is used by another starter service", s.InstanceName))
+		}
+		instanceNames[s.InstanceName] = true
+	}
+
+	if strings.TrimSpace(c.AuditSyslogURL) != "" {
+		if _, _, err := syslogAddress(c.AuditSyslogURL); err != nil {
+			invalid.add("audit_syslog_url", err.Error())
+		}
+	}
+	if strings.TrimSpace(c.AuditWebhookURL) != "" && !isWebURL(c.AuditWebhookURL) {
+		invalid.add("audit_webhook_url", fmt.Sprintf("[%s] is not an http or https URL", c.AuditWebhookURL))
+	}
+	for _, a := range c.Admins {
+		if !strings.Contains(a, "@") {
+			invalid.add("admins", fmt.Sprintf("[%s] is not an email address", a))
+		}
+	}
+
+	c.validateBranding(&invalid)
+
+	if len(invalid) == 0 {
+		return nil
+	}
+	return invalid
+}
+
+func (c *config) validateBranding(invalid *invalidConfigError) {
+	if strings.TrimSpace(c.BrandingDir) != "" {
+		if info, err := os.Stat(c.BrandingDir); err != nil || !info.IsDir() {
+			invalid.add("branding_dir", fmt.Sprintf("refers to [%s], which is not a directory", c.BrandingDir))
+		}
+	}
+	b := c.Branding
+	if strings.TrimSpace(b.LogoURL) != "" {
+		if strings.HasPrefix(b.LogoURL, "/branding/") && strings.TrimSpace(c.BrandingDir) == "" {
+			invalid.addField("branding.logo_url", "refers to the branding directory, but branding_dir (IGNITION_BRANDING_DIR) is not set")
+		} else if !strings.HasPrefix(b.LogoURL, "/") && !isWebURL(b.LogoURL) {
+			invalid.addField("branding.logo_url", "must be a path or an http(s) URL")
+		}
+	}
+	if strings.TrimSpace(b.Support.Email) != "" && !strings.Contains(b.Support.Email, "@") {
+		invalid.addField("branding.support.email", fmt.Sprintf("[%s] is not an email address", b.Support.Email))
+	}
+	if strings.TrimSpace(b.Support.URL) != "" && !isWebURL(b.Support.URL) {
+		invalid.addField("branding.support.url", "must be an http(s) URL")
+	}
+	for i, step := range b.Steps {
+		field := fmt.Sprintf("branding.steps[%d]", i)
+		if strings.TrimSpace(step.Text) == "" && strings.TrimSpace(step.LinkText) == "" {
+			invalid.addField(field, "requires text or link_text")
+		}
+		if strings.TrimSpace(step.LinkURL) != "" && strings.TrimSpace(step.LinkText) == "" {
+			invalid.addField(field+".link_text", "is required when link_url is set")
+		}
+		if strings.TrimSpace(step.LinkURL) != "" && !isWebURL(step.LinkURL) {
+			invalid.addField(field+".link_url", "must be an http(s) URL")
+		}
+	}
+	for i, l := range b.CLIDownloads {
+		field := fmt.Sprintf("branding.cli_downloads[%d]", i)
+		if strings.TrimSpace(l.Name) == "" {
+			invalid.addField(field+".name", "is required")
+		}
+		if !isWebURL(l.URL) {
+			invalid.addField(field+".url", "must be an http(s) URL")
+		}
+	}
+}
+
+// validateTeamOrgs checks that each team org has its own quota, so that it is
+// never mistaken for a personal org that has the personal quota
+func (c *config) validateTeamOrgs(invalid *invalidConfigError) {
+	personalQuotas := map[string]bool{c.QuotaID: true}
+	for _, p := range c.Policies {
+		if t, ok := c.quotaTier(p.QuotaTier); ok {
+			personalQuotas[t.QuotaID] = true
+		}
+	}
+	names := map[string]bool{}
+	for i, t := range c.TeamOrgs {
+		field := fmt.Sprintf("team_orgs[%d]", i)
+		if strings.TrimSpace(t.Group) == "" {
+			invalid.addField(field+".group", "is required")
+		}
+		if strings.TrimSpace(t.Name) == "" {
+			invalid.addField(field+".name", "is required")
+		} else if names[strings.ToLower(t.Name)] {
+			invalid.addField(field+".name", fmt.Sprintf("duplicates team org [%s]", t.Name))
+		}
+		names[strings.ToLower(t.Name)] = true
+		if strings.TrimSpace(t.QuotaTier) == "" {
+			invalid.addField(field+".quota_tier", "is required")
+		} else if q, ok := c.quotaTier(t.QuotaTier); !ok {
+			invalid.addField(field+".quota_tier", fmt.Sprintf("refers to unknown quota tier [%s]", t.QuotaTier))
+		} else if personalQuotas[q.QuotaID] {
+			invalid.addField(field+".quota_tier", fmt.Sprintf("quota tier [%s] is also the quota of personal orgs", t.QuotaTier))
+		}
+		for _, r := range t.Roles {
+			if !cloudfoundry.IsRole(r) {
+				invalid.addField(field+".roles", fmt.Sprintf("[%s] is not one of the roles %s", r, strings.Join(cloudfoundry.Roles, ", ")))
+			}
+		}
+	}
+}
+
+// validateWebhooks checks that each webhook has a unique URL and a secret to
+// sign its payloads with
+func (c *config) validateWebhooks(invalid *invalidConfigError) {
+	if c.WebhookMaxAttempts < 0 {
+		invalid.add("webhook_max_attempts", "must not be negative")
+	}
+	urls := map[string]bool{}
+	for i, w := range c.Webhooks {
+		field := fmt.Sprintf("webhooks[%d]", i)
+		if !isWebURL(w.URL) {
+			invalid.addField(field+".url", "must be an http(s) URL")
+		} else if urls[w.URL] {
+			invalid.addField(field+".url", fmt.Sprintf("duplicates webhook [%s]", w.URL))
+		}
+		urls[w.URL] = true
+		if strings.TrimSpace(w.Secret) == "" {
+			invalid.addField(field+".secret", "is required")
+		}
+		for _, e := range w.Events {
+			if !webhook.IsEvent(e) {
+				invalid.addField(field+".events", fmt.Sprintf("[%s] is not one of the events %s", e, strings.Join(webhook.Events, ", ")))
+			}
+		}
+	}
+}
+
+// sameSiteModes are the SameSite attributes of the session cookie, by name
+var sameSiteModes = map[string]http.SameSite{
+	"lax":    http.SameSiteLaxMode,
+	"strict": http.SameSiteStrictMode,
+	"none":   http.SameSiteNoneMode,
+}
+
+// sessionKeys returns the keys that session cookies are signed and encrypted
+// with, for the current session secret and then each previous one
+func (c *config) sessionKeys() [][]byte {
+	return session.KeyPairs(append([]string{c.SessionSecret}, c.SessionPreviousSecrets...)...)
+}
+
+// logout returns how users are logged out at the identity provider, or nil
+// when they are only logged out of ignition. The return URL defaults to the
+// app's own URL
+func (c *config) logout() *session.Logout {
+	if !c.LogoutRevokeTokens && strings.TrimSpace(c.LogoutURL) == "" {
+		return nil
+	}
+	l := &session.Logout{
+		EndSessionURL: c.LogoutURL,
+		ReturnURL:     c.LogoutReturnURL,
+		ClientID:      c.ClientID,
+	}
+	if c.LogoutRevokeTokens {
+		l.RevokeURL = strings.TrimSuffix(c.TokenURL, "/") + "/revoke"
+	}
+	if strings.TrimSpace(l.ReturnURL) == "" {
+		l.ReturnURL = fmt.Sprintf("%s://%s/", c.Scheme, c.Domain)
+		if c.Port != 0 && !(c.Scheme == "https" && c.Port == 443) && !(c.Scheme == "http" && c.Port == 80) {
+			l.ReturnURL = fmt.Sprintf("%s://%s:%d/", c.Scheme, c.Domain, c.Port)
+		}
+	}
+	return l
+}
+
+// returnTo returns the pages that users may be sent back to after they log in
+func (c *config) returnTo() *session.ReturnTo {
+	return &session.ReturnTo{
+		Paths:   c.LoginNextPaths,
+		Options: c.sessionCookie(),
+	}
+}
+
+// sessionCookie returns the attributes of the session cookie
+func (c *config) sessionCookie() session.CookieOptions {
+	return session.CookieOptions{
+		Path:     "/",
+		MaxAge:   c.SessionMaxAge,
+		Secure:   c.sessionCookieSecure(),
+		HTTPOnly: c.SessionCookieHTTPOnly,
+		SameSite: sameSiteModes[strings.ToLower(strings.TrimSpace(c.SessionCookieSameSite))],
+	}
+}
+
+// sessionCookieSecure is true when the session cookie is only sent over
+// HTTPS; unless it is set, it is when the app is reached over HTTPS
+func (c *config) sessionCookieSecure() bool {
+	if c.SessionCookieSecure != nil {
+		return *c.SessionCookieSecure
+	}
+	return c.Scheme == "https"
+}
+
+// emailEnabled is true when emails are sent through an SMTP server, or
+// written to the dry run directory
+func (c *config) emailEnabled() bool {
+	return strings.TrimSpace(c.SMTPHost) != "" || strings.TrimSpace(c.EmailDryRunDir) != ""
+}
+
+func (c *config) validateEmail(invalid *invalidConfigError) {
+	if !c.emailEnabled() {
+		return
+	}
+	if strings.TrimSpace(c.EmailFrom) == "" {
+		invalid.add("email_from", "is required to send email")
+	} else if _, err := mail.ParseAddress(c.EmailFrom); err != nil {
+		invalid.add("email_from", fmt.Sprintf("[%s] is not an email address", c.EmailFrom))
+	}
+	if c.SMTPPort <= 0 || c.SMTPPort > 65535 {
+		invalid.add("smtp_port", fmt.Sprintf("[%d] is not a port", c.SMTPPort))
+	}
+	if strings.TrimSpace(c.EmailTemplatesDir) != "" {
+		if info, err := os.Stat(c.EmailTemplatesDir); err != nil || !info.IsDir() {
+			invalid.add("email_templates_dir", fmt.Sprintf("refers to [%s], which is not a directory", c.EmailTemplatesDir))
+		} else if _, err := notify.NewTemplates(c.EmailTemplatesDir); err != nil {
+			invalid.add("email_templates_dir", err.Error())
+		}
+	}
+}
+
+// syslogAddress returns the network and address of a udp:// or tcp:// syslog
+// URL
+func syslogAddress(s string) (network string, address string, err error) {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "udp" && u.Scheme != "tcp") || u.Host == "" || u.Port() == "" {
+		return "", "", errors.Errorf("[%s] is not a udp://host:port or tcp://host:port URL", s)
+	}
+	return u.Scheme, u.Host, nil
+}
+
+// isWebURL is true for absolute http and https URLs
+func isWebURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// invalidConfigError lists every invalid setting found during validation
+type invalidConfigError []string
+
+func (e *invalidConfigError) add(key string, problem string) {
+	*e = append(*e, fmt.Sprintf("%s (IGNITION_%s) %s", key, strings.ToUpper(key), problem))
+}
+
+func (e *invalidConfigError) addField(field string, problem string) {
+	*e = append(*e, fmt.Sprintf("%s %s", field, problem))
+}
+
+func (e invalidConfigError) Error() string {
+	return fmt.Sprintf("invalid configuration: %s", strings.Join(e, "; "))
+}
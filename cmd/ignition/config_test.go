@@ -1,11 +1,22 @@
 package main
 
 import (
+	"io/ioutil"
+	nethttp "net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/audit"
+	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/http"
+	"github.com/pivotalservices/ignition/http/organization"
+	"github.com/pivotalservices/ignition/http/session"
+	"github.com/pivotalservices/ignition/notify"
+	"github.com/pivotalservices/ignition/webhook"
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 )
@@ -14,6 +25,23 @@ func resetEnv() {
 	os.Unsetenv("IGNITION_AUTH_VARIANT")
 	os.Unsetenv("IGNITION_CLIENT_ID")
 	os.Unsetenv("IGNITION_CLIENT_SECRET")
+	os.Unsetenv("IGNITION_GROUPS_CLAIM")
+	os.Unsetenv("IGNITION_AUDIT_LOG_PATH")
+	os.Unsetenv("IGNITION_AUDIT_SYSLOG_URL")
+	os.Unsetenv("IGNITION_AUDIT_WEBHOOK_URL")
+	os.Unsetenv("IGNITION_ADMINS")
+	os.Unsetenv("IGNITION_WEBHOOK_STORE_PATH")
+	os.Unsetenv("IGNITION_WEBHOOK_MAX_ATTEMPTS")
+	os.Unsetenv("IGNITION_SMTP_HOST")
+	os.Unsetenv("IGNITION_SMTP_PORT")
+	os.Unsetenv("IGNITION_SMTP_USERNAME")
+	os.Unsetenv("IGNITION_SMTP_PASSWORD")
+	os.Unsetenv("IGNITION_EMAIL_FROM")
+	os.Unsetenv("IGNITION_EMAIL_TEMPLATES_DIR")
+	os.Unsetenv("IGNITION_EMAIL_DRY_RUN_DIR")
+	os.Unsetenv("IGNITION_TERMS_PATH")
+	os.Unsetenv("IGNITION_TERMS_VERSION")
+	os.Unsetenv("IGNITION_TERMS_STORE_PATH")
 	os.Unsetenv("IGNITION_AUTH_URL")
 	os.Unsetenv("IGNITION_TOKEN_URL")
 	os.Unsetenv("IGNITION_JWKS_URL")
@@ -21,6 +49,18 @@ func resetEnv() {
 	os.Unsetenv("IGNITION_AUTH_SCOPES")
 	os.Unsetenv("IGNITION_AUTHORIZED_DOMAIN")
 	os.Unsetenv("IGNITION_SESSION_SECRET")
+	os.Unsetenv("IGNITION_SESSION_PREVIOUS_SECRETS")
+	os.Unsetenv("IGNITION_SESSION_STORE_PATH")
+	os.Unsetenv("IGNITION_SESSION_LIFETIME")
+	os.Unsetenv("IGNITION_SESSION_IDLE_TIMEOUT")
+	os.Unsetenv("IGNITION_LOGOUT_REVOKE_TOKENS")
+	os.Unsetenv("IGNITION_LOGOUT_URL")
+	os.Unsetenv("IGNITION_LOGOUT_RETURN_URL")
+	os.Unsetenv("IGNITION_LOGIN_NEXT_PATHS")
+	os.Unsetenv("IGNITION_SESSION_MAX_AGE")
+	os.Unsetenv("IGNITION_SESSION_COOKIE_SECURE")
+	os.Unsetenv("IGNITION_SESSION_COOKIE_HTTP_ONLY")
+	os.Unsetenv("IGNITION_SESSION_COOKIE_SAME_SITE")
 	os.Unsetenv("IGNITION_PORT")
 	os.Unsetenv("IGNITION_SERVE_PORT")
 	os.Unsetenv("IGNITION_DOMAIN")
@@ -39,6 +79,29 @@ func resetEnv() {
 	os.Unsetenv("IGNITION_ORG_PREFIX")
 	os.Unsetenv("IGNITION_QUOTA_ID")
 	os.Unsetenv("IGNITION_UAA_ORIGIN")
+	os.Unsetenv("IGNITION_QUOTA_TIER")
+	os.Unsetenv("IGNITION_SPACE_NAME")
+	os.Unsetenv("IGNITION_CONFIG_FILE")
+	os.Unsetenv("IGNITION_BRANDING_DIR")
+	os.Unsetenv("IGNITION_SAMPLE_APP_PATH")
+}
+
+func setRequiredEnv() {
+	os.Setenv("IGNITION_CLIENT_ID", "test-ignition-client-id")
+	os.Setenv("IGNITION_CLIENT_SECRET", "test-ignition-client-secret")
+	os.Setenv("IGNITION_AUTH_URL", "test-ignition-auth-url")
+	os.Setenv("IGNITION_TOKEN_URL", "test-ignition-token-url")
+	os.Setenv("IGNITION_JWKS_URL", "test-ignition-jwks-url")
+	os.Setenv("IGNITION_ISSUER_URL", "test-ignition-issuer-url")
+	os.Setenv("IGNITION_AUTHORIZED_DOMAIN", "test-ignition-authorized-domain")
+	os.Setenv("IGNITION_SESSION_SECRET", "test-ignition-session-secret")
+	os.Setenv("IGNITION_CCAPI_URL", "https://example.com")
+	os.Setenv("IGNITION_UAA_URL", "https://example.com")
+	os.Setenv("IGNITION_APPS_URL", "https://example.com")
+	os.Setenv("IGNITION_CCAPI_USERNAME", "test-ccapi-username")
+	os.Setenv("IGNITION_CCAPI_PASSWORD", "test-ccapi-password")
+	os.Setenv("IGNITION_QUOTA_ID", "test-quotaid")
+	os.Setenv("IGNITION_UAA_ORIGIN", "test-origin")
 }
 
 func TestIgnitionMain(t *testing.T) {
@@ -56,53 +119,839 @@ func TestIgnitionMain(t *testing.T) {
 
 		when("the environment is empty", func() {
 			it("returns an error", func() {
-				api, err := NewAPI()
+				api, err := NewAPI("")
 				Expect(err).To(HaveOccurred())
 				Expect(api).To(BeNil())
 			})
+
+			it("names every missing setting in the error", func() {
+				_, err := NewAPI("")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("auth_url (IGNITION_AUTH_URL) is required"))
+				Expect(err.Error()).To(ContainSubstring("ccapi_password (IGNITION_CCAPI_PASSWORD) is required"))
+				Expect(err.Error()).To(ContainSubstring("quota_id (IGNITION_QUOTA_ID) is required"))
+			})
 		})
 
-		when("all required env vars have been set", func() {
+		when("a config file is used", func() {
+			var dir string
+
+			writeConfig := func(name string, contents string) string {
+				path := filepath.Join(dir, name)
+				Expect(ioutil.WriteFile(path, []byte(contents), 0600)).To(Succeed())
+				return path
+			}
+
 			it.Before(func() {
-				os.Setenv("IGNITION_CLIENT_ID", "test-ignition-client-id")
-				os.Setenv("IGNITION_CLIENT_SECRET", "test-ignition-client-secret")
-				os.Setenv("IGNITION_AUTH_URL", "test-ignition-auth-url")
-				os.Setenv("IGNITION_TOKEN_URL", "test-ignition-token-url")
-				os.Setenv("IGNITION_JWKS_URL", "test-ignition-jwks-url")
-				os.Setenv("IGNITION_ISSUER_URL", "test-ignition-issuer-url")
-				os.Setenv("IGNITION_AUTHORIZED_DOMAIN", "test-ignition-authorized-domain")
-				os.Setenv("IGNITION_SESSION_SECRET", "test-ignition-session-secret")
-				os.Setenv("IGNITION_CCAPI_URL", "https://example.com")
-				os.Setenv("IGNITION_UAA_URL", "https://example.com")
-				os.Setenv("IGNITION_APPS_URL", "https://example.com")
-				os.Setenv("IGNITION_CCAPI_USERNAME", "test-ccapi-username")
-				os.Setenv("IGNITION_CCAPI_PASSWORD", "test-ccapi-password")
-				os.Setenv("IGNITION_QUOTA_ID", "test-quotaid")
-				os.Setenv("IGNITION_UAA_ORIGIN", "test-origin")
+				var err error
+				dir, err = ioutil.TempDir("", "ignition-config")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it.After(func() {
+				os.RemoveAll(dir)
+			})
+
+			it("fails if the file does not exist", func() {
+				api, err := NewAPI(filepath.Join(dir, "missing.yml"))
+				Expect(err).To(HaveOccurred())
+				Expect(api).To(BeNil())
+			})
+
+			it("fails if the file has unknown settings", func() {
+				api, err := NewAPI(writeConfig("ignition.yml", "not_a_setting: true\n"))
+				Expect(err).To(HaveOccurred())
+				Expect(api).To(BeNil())
+			})
+
+			when("the file contains every required setting", func() {
+				var path string
+
+				it.Before(func() {
+					path = writeConfig("ignition.yml", `
+client_id: file-client-id
+client_secret: file-client-secret
+auth_url: https://login.example.com/oauth/authorize
+token_url: https://login.example.com/oauth/token
+jwks_url: https://login.example.com/token_keys
+issuer_url: https://login.example.com/oauth/token
+authorized_domain: "@example.com"
+session_secret: file-session-secret
+uaa_origin: file-origin
+ccapi_username: file-username
+ccapi_password: file-password
+quota_tier: small
+space_name: file-space
+foundations:
+  - name: east
+    api_url: https://api.east.example.com
+    uaa_url: https://uaa.east.example.com
+    apps_url: https://apps.east.example.com
+quota_tiers:
+  - name: small
+    quota_id: small-quota-id
+  - name: large
+    quota_id: large-quota-id
+policies:
+  - domain: "@research.example.com"
+    quota_tier: large
+`)
+				})
+
+				it("uses the values from the file", func() {
+					api, err := NewAPI(path)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(api).NotTo(BeNil())
+					Expect(api.UserConfig.ClientID).To(Equal("file-client-id"))
+					Expect(api.SpaceName).To(Equal("file-space"))
+					Expect(api.UAAOrigin).To(Equal("file-origin"))
+				})
+
+				it("uses the first foundation for the cloud foundry urls", func() {
+					api, err := NewAPI(path)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(api.APIURL).To(Equal("https://api.east.example.com"))
+					Expect(api.UAAURL).To(Equal("https://uaa.east.example.com"))
+					Expect(api.AppsURL).To(Equal("https://apps.east.example.com"))
+				})
+
+				it("resolves the quota id from the quota tier", func() {
+					api, err := NewAPI(path)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(api.QuotaID).To(Equal("small-quota-id"))
+				})
+
+				it("resolves the quota id for each policy", func() {
+					api, err := NewAPI(path)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(api.Policies).To(HaveLen(1))
+					Expect(api.Policies[0].Domain).To(Equal("@research.example.com"))
+					Expect(api.Policies[0].QuotaID).To(Equal("large-quota-id"))
+				})
+
+				it("keeps the defaults for settings that are not in the file", func() {
+					api, err := NewAPI(path)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(api.OrgPrefix).To(Equal("ignition"))
+					Expect(api.ServePort).To(Equal(3000))
+				})
+
+				it("lets environment variables override the file", func() {
+					os.Setenv("IGNITION_CLIENT_ID", "env-client-id")
+					os.Setenv("IGNITION_CCAPI_URL", "https://api.env.example.com")
+					os.Setenv("IGNITION_QUOTA_ID", "env-quota-id")
+					api, err := NewAPI(path)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(api.UserConfig.ClientID).To(Equal("env-client-id"))
+					Expect(api.APIURL).To(Equal("https://api.env.example.com"))
+					Expect(api.QuotaID).To(Equal("env-quota-id"))
+				})
+
+				it("reads the path from IGNITION_CONFIG_FILE when no flag is given", func() {
+					os.Setenv("IGNITION_CONFIG_FILE", path)
+					Expect(configFilePath("")).To(Equal(path))
+					Expect(configFilePath("other.yml")).To(Equal("other.yml"))
+				})
+			})
+
+			it("reads timeouts and tls settings", func() {
+				path := writeConfig("ignition.yml", `
+read_timeout: 5s
+write_timeout: 2m
+tls_cert_file: /etc/ignition/cert.pem
+tls_key_file: /etc/ignition/key.pem
+`)
+				os.Setenv("IGNITION_SHUTDOWN_TIMEOUT", "20s")
+				defer os.Unsetenv("IGNITION_SHUTDOWN_TIMEOUT")
+				setRequiredEnv()
+				api, err := NewAPI(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.ReadTimeout).To(Equal(5 * time.Second))
+				Expect(api.WriteTimeout).To(Equal(2 * time.Minute))
+				Expect(api.IdleTimeout).To(Equal(120 * time.Second))
+				Expect(api.ShutdownTimeout).To(Equal(20 * time.Second))
+				Expect(api.TLSEnabled()).To(BeTrue())
+				Expect(api.Scheme).To(Equal("https"))
+			})
+
+			it("requires the tls certificate and key to be set together", func() {
+				path := writeConfig("ignition.yml", "tls_cert_file: /etc/ignition/cert.pem\n")
+				setRequiredEnv()
+				_, err := NewAPI(path)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("tls_cert_file (IGNITION_TLS_CERT_FILE) and tls_key_file must be set together"))
+			})
+
+			it("reads cors settings", func() {
+				path := writeConfig("ignition.yml", `
+cors_allowed_origins:
+  - https://portal.example.com
+cors_allowed_headers:
+  - Content-Type
+cors_allow_credentials: true
+`)
+				os.Setenv("IGNITION_CORS_ALLOWED_METHODS", "GET,POST,DELETE")
+				defer os.Unsetenv("IGNITION_CORS_ALLOWED_METHODS")
+				setRequiredEnv()
+				api, err := NewAPI(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.CORSAllowedOrigins).To(Equal([]string{"https://portal.example.com"}))
+				Expect(api.CORSAllowedMethods).To(Equal([]string{"GET", "POST", "DELETE"}))
+				Expect(api.CORSAllowedHeaders).To(Equal([]string{"Content-Type"}))
+				Expect(api.CORSAllowCredentials).To(BeTrue())
+			})
+
+			it("allows no cross-origin requests by default", func() {
+				setRequiredEnv()
+				api, err := NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.CORSAllowedOrigins).To(BeEmpty())
+				Expect(api.CORSAllowCredentials).To(BeFalse())
+			})
+
+			it("rejects invalid cors settings", func() {
+				path := writeConfig("ignition.yml", `
+cors_allowed_origins:
+  - "*"
+  - https://portal.example.com/path
+cors_allowed_methods:
+  - get
+cors_allow_credentials: true
+`)
+				setRequiredEnv()
+				_, err := NewAPI(path)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("cors_allowed_origins (IGNITION_CORS_ALLOWED_ORIGINS) must list each origin when cors_allow_credentials is true"))
+				Expect(err.Error()).To(ContainSubstring("cors_allowed_origins (IGNITION_CORS_ALLOWED_ORIGINS) contains [https://portal.example.com/path], which is not an origin like https://portal.example.com"))
+				Expect(err.Error()).To(ContainSubstring("cors_allowed_methods (IGNITION_CORS_ALLOWED_METHODS) contains [get], which is not an upper case HTTP method"))
+			})
+
+			it("configures the provisioner", func() {
+				dir, err := ioutil.TempDir("", "ignition-config")
+				Expect(err).NotTo(HaveOccurred())
+				defer os.RemoveAll(dir)
+				path := writeConfig("ignition.yml", "provision_workers: 8\njob_store_path: "+filepath.Join(dir, "jobs.json")+"\n")
+				setRequiredEnv()
+				api, err := NewAPI(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Provisioner.Workers).To(Equal(8))
+				Expect(api.Provisioner.SpaceName).To(Equal("playground"))
+				Expect(api.Provisioner.Store).NotTo(BeNil())
+			})
+
+			it("requires at least one provisioning worker", func() {
+				path := writeConfig("ignition.yml", "provision_workers: 0\n")
+				setRequiredEnv()
+				_, err := NewAPI(path)
+				Expect(err).To(MatchError(ContainSubstring("provision_workers (IGNITION_PROVISION_WORKERS) must be greater than zero")))
+			})
+
+			it("configures the sample app", func() {
+				f, err := ioutil.TempFile("", "sample-app")
+				Expect(err).NotTo(HaveOccurred())
+				f.Close()
+				defer os.Remove(f.Name())
+				path := writeConfig("ignition.yml", "sample_app_path: "+f.Name()+"\nsample_app_name: spring-music\nsample_app_buildpack: java_buildpack\n")
+				setRequiredEnv()
+				api, err := NewAPI(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Provisioner.SampleApp).NotTo(BeNil())
+				Expect(*api.Provisioner.SampleApp).To(Equal(cloudfoundry.SampleApp{
+					Name:           "spring-music",
+					Path:           f.Name(),
+					Memory:         1024,
+					Buildpack:      "java_buildpack",
+					StagingTimeout: 5 * time.Minute,
+				}))
+			})
+
+			it("does not push a sample app by default", func() {
+				setRequiredEnv()
+				api, err := NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Provisioner.SampleApp).To(BeNil())
+			})
+
+			it("requires the sample app to exist", func() {
+				path := writeConfig("ignition.yml", "sample_app_path: /does/not/exist.zip\n")
+				setRequiredEnv()
+				_, err := NewAPI(path)
+				Expect(err).To(MatchError(ContainSubstring("sample_app_path (IGNITION_SAMPLE_APP_PATH) refers to [/does/not/exist.zip], which is not a file")))
+			})
+
+			it("configures the audit log sinks and admins", func() {
+				setRequiredEnv()
+				os.Setenv("IGNITION_AUDIT_LOG_PATH", "/var/log/ignition/audit.log")
+				os.Setenv("IGNITION_AUDIT_SYSLOG_URL", "tcp://syslog.example.com:6514")
+				os.Setenv("IGNITION_AUDIT_WEBHOOK_URL", "https://siem.example.com/ignition")
+				os.Setenv("IGNITION_ADMINS", "admin@test.com,ops@test.com")
+				api, err := NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Audit.Sinks).To(HaveLen(3))
+				Expect(api.Audit.Sinks[0]).To(Equal(&audit.FileSink{Path: "/var/log/ignition/audit.log"}))
+				syslog := api.Audit.Sinks[1].(*audit.SyslogSink)
+				Expect(syslog.Network).To(Equal("tcp"))
+				Expect(syslog.Address).To(Equal("syslog.example.com:6514"))
+				Expect(api.Provisioner.Audit).To(Equal(api.Audit))
+				Expect(api.Admins).To(Equal([]string{"admin@test.com", "ops@test.com"}))
+			})
+
+			it("rejects invalid audit sinks and admins", func() {
+				setRequiredEnv()
+				os.Setenv("IGNITION_AUDIT_SYSLOG_URL", "syslog.example.com")
+				os.Setenv("IGNITION_AUDIT_WEBHOOK_URL", "ftp://siem.example.com")
+				os.Setenv("IGNITION_ADMINS", "admin")
+				_, err := NewAPI("")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("audit_syslog_url (IGNITION_AUDIT_SYSLOG_URL) [syslog.example.com] is not a udp://host:port or tcp://host:port URL"))
+				Expect(err.Error()).To(ContainSubstring("audit_webhook_url (IGNITION_AUDIT_WEBHOOK_URL) [ftp://siem.example.com] is not an http or https URL"))
+				Expect(err.Error()).To(ContainSubstring("admins (IGNITION_ADMINS) [admin] is not an email address"))
+			})
+
+			it("configures the webhooks", func() {
+				path := writeConfig("ignition.yml", `
+webhook_max_attempts: 5
+webhooks:
+  - url: https://chat.example.com/hooks/sandbox
+    secret: chat-secret
+    events: [org.created]
+  - url: https://cmdb.example.com/ignition
+    secret: cmdb-secret
+  - url: https://billing.example.com/ignition
+    secret: billing-secret
+    events: [org.reclaimed, quota.changed]
+`)
+				setRequiredEnv()
+				api, err := NewAPI(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Webhooks.MaxAttempts).To(Equal(5))
+				Expect(api.Webhooks.Endpoints).To(Equal([]webhook.Endpoint{
+					{URL: "https://chat.example.com/hooks/sandbox", Secret: "chat-secret", Events: []string{"org.created"}},
+					{URL: "https://cmdb.example.com/ignition", Secret: "cmdb-secret"},
+					{URL: "https://billing.example.com/ignition", Secret: "billing-secret", Events: []string{"org.reclaimed", "quota.changed"}},
+				}))
+				Expect(api.Provisioner.Webhooks).To(Equal(api.Webhooks))
+				Expect(api.Teams.Webhooks).To(Equal(api.Webhooks))
+			})
+
+			it("rejects invalid webhooks", func() {
+				path := writeConfig("ignition.yml", `
+webhooks:
+  - url: chat.example.com
+    events: [org.deleted]
+  - url: https://cmdb.example.com/ignition
+    secret: cmdb-secret
+  - url: https://cmdb.example.com/ignition
+    secret: cmdb-secret
+`)
+				setRequiredEnv()
+				_, err := NewAPI(path)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("webhooks[0].url must be an http(s) URL"))
+				Expect(err.Error()).To(ContainSubstring("webhooks[0].secret is required"))
+				Expect(err.Error()).To(ContainSubstring("webhooks[0].events [org.deleted] is not one of the events"))
+				Expect(err.Error()).To(ContainSubstring("webhooks[0].events [org.deleted] is not one of the events user.created, org.created, org.reclaimed, quota.changed"))
+				Expect(err.Error()).To(ContainSubstring("webhooks[2].url duplicates webhook [https://cmdb.example.com/ignition]"))
+			})
+
+			it("sends email through the SMTP server", func() {
+				setRequiredEnv()
+				os.Setenv("IGNITION_SMTP_HOST", "smtp.example.com")
+				os.Setenv("IGNITION_SMTP_USERNAME", "ignition")
+				os.Setenv("IGNITION_SMTP_PASSWORD", "smtp-password")
+				os.Setenv("IGNITION_EMAIL_FROM", "Ignition <ignition@example.com>")
+				api, err := NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Provisioner.Mail).NotTo(BeNil())
+				Expect(api.Provisioner.Mail.From).To(Equal("Ignition <ignition@example.com>"))
+				Expect(api.Provisioner.Mail.Sender).To(Equal(&notify.SMTPSender{Host: "smtp.example.com", Port: 587, Username: "ignition", Password: "smtp-password"}))
+			})
+
+			it("writes email to the dry run directory", func() {
+				dir := filepath.Dir(writeConfig("welcome.tmpl", "Subject: Welcome\n\n{{.OrgURL}}\n"))
+				setRequiredEnv()
+				os.Setenv("IGNITION_EMAIL_DRY_RUN_DIR", "/tmp/ignition-outbox")
+				os.Setenv("IGNITION_EMAIL_TEMPLATES_DIR", dir)
+				os.Setenv("IGNITION_EMAIL_FROM", "ignition@example.com")
+				api, err := NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Provisioner.Mail.Sender).To(Equal(&notify.DirSender{Dir: "/tmp/ignition-outbox"}))
+				subject, _, err := api.Provisioner.Mail.Templates.Render(notify.KindWelcome, notify.Welcome{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(subject).To(Equal("Welcome"))
+			})
+
+			it("does not send email when there is no SMTP server", func() {
+				setRequiredEnv()
+				api, err := NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Provisioner.Mail).To(BeNil())
+			})
+
+			it("rejects invalid email settings", func() {
+				setRequiredEnv()
+				os.Setenv("IGNITION_SMTP_HOST", "smtp.example.com")
+				os.Setenv("IGNITION_SMTP_PORT", "0")
+				os.Setenv("IGNITION_EMAIL_TEMPLATES_DIR", "/does/not/exist")
+				_, err := NewAPI("")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("email_from (IGNITION_EMAIL_FROM) is required to send email"))
+				Expect(err.Error()).To(ContainSubstring("smtp_port (IGNITION_SMTP_PORT) [0] is not a port"))
+				Expect(err.Error()).To(ContainSubstring("email_templates_dir (IGNITION_EMAIL_TEMPLATES_DIR) refers to [/does/not/exist], which is not a directory"))
+			})
+
+			it("configures the session cookie", func() {
+				setRequiredEnv()
+				api, err := NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.SessionCookie).To(Equal(session.CookieOptions{Path: "/", MaxAge: 168 * time.Hour, HTTPOnly: true, SameSite: nethttp.SameSiteLaxMode}))
+				Expect(api.SessionStore.(*session.CookieStore).Options).To(Equal(api.SessionCookie))
+
+				os.Setenv("IGNITION_SCHEME", "https")
+				api, err = NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.SessionCookie).To(Equal(session.CookieOptions{Path: "/", MaxAge: 168 * time.Hour, Secure: true, HTTPOnly: true, SameSite: nethttp.SameSiteLaxMode}))
+
+				os.Setenv("IGNITION_SESSION_MAX_AGE", "8h")
+				os.Setenv("IGNITION_SESSION_COOKIE_SAME_SITE", "Strict")
+				os.Setenv("IGNITION_SESSION_COOKIE_HTTP_ONLY", "false")
+				api, err = NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.SessionCookie).To(Equal(session.CookieOptions{Path: "/", MaxAge: 8 * time.Hour, Secure: true, SameSite: nethttp.SameSiteStrictMode}))
+
+				os.Setenv("IGNITION_SESSION_COOKIE_SECURE", "false")
+				api, err = NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.SessionCookie.Secure).To(BeFalse())
+
+				os.Setenv("IGNITION_SCHEME", "http")
+				os.Setenv("IGNITION_SESSION_COOKIE_SECURE", "true")
+				api, err = NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.SessionCookie.Secure).To(BeTrue())
+			})
+
+			it("reads sessions saved with the previous session secrets", func() {
+				setRequiredEnv()
+				os.Setenv("IGNITION_SESSION_PREVIOUS_SECRETS", "old-secret,older-secret")
+				api, err := NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.SessionStore.(*session.CookieStore).Codecs).To(HaveLen(3))
+
+				w := httptest.NewRecorder()
+				old := session.NewCookieStore(api.SessionCookie, session.KeyPairs("older-secret")...)
+				Expect(old.New("ignition").Save(w)).To(Succeed())
+				req := httptest.NewRequest(nethttp.MethodGet, "/", nil)
+				req.AddCookie(w.Result().Cookies()[0])
+				_, err = api.SessionStore.Get(req, "ignition")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("rejects the current session secret as a previous one", func() {
+				setRequiredEnv()
+				os.Setenv("IGNITION_SESSION_PREVIOUS_SECRETS", "test-ignition-session-secret")
+				_, err := NewAPI("")
+				Expect(err).To(MatchError(ContainSubstring("session_previous_secrets (IGNITION_SESSION_PREVIOUS_SECRETS) must not contain the current session_secret (IGNITION_SESSION_SECRET)")))
 			})
 
+			it("ends sessions after their lifetime or idle timeout", func() {
+				setRequiredEnv()
+				api, err := NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Sessions.Lifetime).To(Equal(168 * time.Hour))
+				Expect(api.Sessions.IdleTimeout).To(Equal(24 * time.Hour))
+				Expect(api.Sessions.Audit).To(Equal(api.Audit))
+
+				os.Setenv("IGNITION_SESSION_LIFETIME", "12h")
+				os.Setenv("IGNITION_SESSION_IDLE_TIMEOUT", "30m")
+				api, err = NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Sessions.Lifetime).To(Equal(12 * time.Hour))
+				Expect(api.Sessions.IdleTimeout).To(Equal(30 * time.Minute))
+
+				os.Setenv("IGNITION_SESSION_LIFETIME", "-1h")
+				os.Setenv("IGNITION_SESSION_IDLE_TIMEOUT", "-1m")
+				_, err = NewAPI("")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("session_lifetime (IGNITION_SESSION_LIFETIME) must not be negative"))
+				Expect(err.Error()).To(ContainSubstring("session_idle_timeout (IGNITION_SESSION_IDLE_TIMEOUT) must not be negative"))
+			})
+
+			it("does not let sessions outlive their cookie", func() {
+				setRequiredEnv()
+				os.Setenv("IGNITION_SESSION_MAX_AGE", "8h")
+				api, err := NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Sessions.Lifetime).To(Equal(8 * time.Hour))
+
+				os.Setenv("IGNITION_SESSION_LIFETIME", "12h")
+				_, err = NewAPI("")
+				Expect(err).To(MatchError(ContainSubstring("session_lifetime (IGNITION_SESSION_LIFETIME) must not be longer than session_max_age (IGNITION_SESSION_MAX_AGE)")))
+			})
+
+			it("logs users out at the identity provider", func() {
+				setRequiredEnv()
+				api, err := NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Logout).To(BeNil())
+
+				os.Setenv("IGNITION_TOKEN_URL", "https://login.example.com/oauth/token")
+				os.Setenv("IGNITION_LOGOUT_REVOKE_TOKENS", "true")
+				os.Setenv("IGNITION_LOGOUT_URL", "https://login.example.com/logout.do")
+				os.Setenv("IGNITION_DOMAIN", "ignition.example.com")
+				os.Setenv("IGNITION_SCHEME", "https")
+				os.Setenv("IGNITION_PORT", "443")
+				api, err = NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Logout).To(Equal(&session.Logout{
+					RevokeURL:     "https://login.example.com/oauth/token/revoke",
+					EndSessionURL: "https://login.example.com/logout.do",
+					ReturnURL:     "https://ignition.example.com/",
+					ClientID:      "test-ignition-client-id",
+				}))
+
+				os.Setenv("IGNITION_LOGOUT_RETURN_URL", "https://portal.example.com/goodbye")
+				api, err = NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Logout.ReturnURL).To(Equal("https://portal.example.com/goodbye"))
+			})
+
+			it("rejects invalid logout settings", func() {
+				setRequiredEnv()
+				os.Setenv("IGNITION_LOGOUT_REVOKE_TOKENS", "true")
+				os.Setenv("IGNITION_LOGOUT_URL", "login.example.com/logout.do")
+				_, err := NewAPI("")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("logout_revoke_tokens (IGNITION_LOGOUT_REVOKE_TOKENS) requires a UAA token_url (IGNITION_TOKEN_URL) that ends in /oauth/token"))
+				Expect(err.Error()).To(ContainSubstring("logout_url (IGNITION_LOGOUT_URL) [login.example.com/logout.do] must be an http(s) URL"))
+			})
+
+			it("returns users to the allowed pages after they log in", func() {
+				setRequiredEnv()
+				api, err := NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.ReturnTo.Paths).To(Equal([]string{"/"}))
+				Expect(api.ReturnTo.Options).To(Equal(api.SessionCookie))
+
+				os.Setenv("IGNITION_LOGIN_NEXT_PATHS", "/organization,https://evil.example.com/")
+				_, err = NewAPI("")
+				Expect(err).To(MatchError(ContainSubstring("login_next_paths (IGNITION_LOGIN_NEXT_PATHS) [https://evil.example.com/] must be a path that starts with /")))
+
+				os.Setenv("IGNITION_LOGIN_NEXT_PATHS", "/organization,/profile")
+				api, err = NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.ReturnTo.Paths).To(Equal([]string{"/organization", "/profile"}))
+			})
+
+			it("rejects invalid session cookie settings", func() {
+				setRequiredEnv()
+				os.Setenv("IGNITION_SESSION_MAX_AGE", "0s")
+				os.Setenv("IGNITION_SESSION_COOKIE_SAME_SITE", "loose")
+				_, err := NewAPI("")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("session_max_age (IGNITION_SESSION_MAX_AGE) must be greater than zero"))
+				Expect(err.Error()).To(ContainSubstring("session_cookie_same_site (IGNITION_SESSION_COOKIE_SAME_SITE) [loose] is not one of lax, strict or none"))
+
+				os.Setenv("IGNITION_SESSION_MAX_AGE", "1h")
+				os.Setenv("IGNITION_SESSION_COOKIE_SAME_SITE", "none")
+				os.Setenv("IGNITION_SESSION_COOKIE_SECURE", "false")
+				_, err = NewAPI("")
+				Expect(err).To(MatchError(ContainSubstring("session_cookie_same_site (IGNITION_SESSION_COOKIE_SAME_SITE) can only be none when session_cookie_secure (IGNITION_SESSION_COOKIE_SECURE) is true")))
+			})
+
+			it("requires users to accept the terms of use", func() {
+				path := writeConfig("terms.md", "# Sandbox Terms\n")
+				setRequiredEnv()
+				os.Setenv("IGNITION_TERMS_PATH", path)
+				os.Setenv("IGNITION_TERMS_VERSION", "2026-10")
+				os.Setenv("IGNITION_TERMS_STORE_PATH", filepath.Join(filepath.Dir(path), "acceptances.json"))
+				api, err := NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Terms.Required()).To(BeTrue())
+				Expect(api.Terms.Version).To(Equal("2026-10"))
+				Expect(string(api.Terms.Document)).To(Equal("# Sandbox Terms\n"))
+				Expect(api.Terms.Audit).To(Equal(api.Audit))
+			})
+
+			it("does not require terms by default", func() {
+				setRequiredEnv()
+				api, err := NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Terms).To(BeNil())
+			})
+
+			it("rejects terms that cannot be read", func() {
+				setRequiredEnv()
+				os.Setenv("IGNITION_TERMS_PATH", "/does/not/exist.md")
+				_, err := NewAPI("")
+				Expect(err).To(MatchError(ContainSubstring("terms_path (IGNITION_TERMS_PATH) refers to [/does/not/exist.md], which is not a file")))
+
+				resetEnv()
+				setRequiredEnv()
+				os.Setenv("IGNITION_TERMS_VERSION", "2026-10")
+				_, err = NewAPI("")
+				Expect(err).To(MatchError(ContainSubstring("terms_version (IGNITION_TERMS_VERSION) is set, but terms_path (IGNITION_TERMS_PATH) is not")))
+			})
+
+			it("configures the team orgs", func() {
+				path := writeConfig("ignition.yml", `
+groups_claim: roles
+quota_tiers:
+  - name: team
+    quota_id: team-quota-id
+team_orgs:
+  - group: platform-team
+    name: platform-sandbox
+    quota_tier: team
+    roles: [org_auditor, space_developer]
+  - group: data-team
+    name: data-sandbox
+    quota_tier: team
+`)
+				setRequiredEnv()
+				api, err := NewAPI(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Teams.Orgs).To(Equal([]organization.TeamOrg{
+					{Group: "platform-team", Name: "platform-sandbox", QuotaID: "team-quota-id", Roles: []string{"org_auditor", "space_developer"}},
+					{Group: "data-team", Name: "data-sandbox", QuotaID: "team-quota-id", Roles: []string{"space_developer"}},
+				}))
+				Expect(api.Teams.SpaceName).To(Equal("playground"))
+			})
+
+			it("rejects invalid team orgs", func() {
+				path := writeConfig("ignition.yml", `
+quota_tiers:
+  - name: personal
+    quota_id: test-quotaid
+team_orgs:
+  - name: platform-sandbox
+    quota_tier: personal
+    roles: [admin]
+  - group: data-team
+    name: platform-sandbox
+`)
+				setRequiredEnv()
+				_, err := NewAPI(path)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("team_orgs[0].group is required"))
+				Expect(err.Error()).To(ContainSubstring("team_orgs[0].quota_tier quota tier [personal] is also the quota of personal orgs"))
+				Expect(err.Error()).To(ContainSubstring("team_orgs[0].roles [admin] is not one of the roles"))
+				Expect(err.Error()).To(ContainSubstring("team_orgs[1].name duplicates team org [platform-sandbox]"))
+				Expect(err.Error()).To(ContainSubstring("team_orgs[1].quota_tier is required"))
+			})
+
+			it("configures the org template", func() {
+				path := writeConfig("ignition.yml", `
+org_template:
+  isolation_segment: dev-segment
+  security_groups: [public-networks]
+  private_domains: [apps.example.com]
+  allow_ssh: false
+  space_quota:
+    name: playground
+    memory_limit: 2048
+    total_routes: 10
+`)
+				setRequiredEnv()
+				api, err := NewAPI(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Provisioner.Template).To(Equal(cloudfoundry.OrgTemplate{
+					IsolationSegment: "dev-segment",
+					SecurityGroups:   []string{"public-networks"},
+					PrivateDomains:   []string{"apps.example.com"},
+					DisableSSH:       true,
+					SpaceQuota: &cloudfoundry.SpaceQuota{
+						Name:                "playground",
+						MemoryLimit:         2048,
+						InstanceMemoryLimit: -1,
+						TotalRoutes:         10,
+						TotalServices:       -1,
+						AppInstanceLimit:    -1,
+					},
+				}))
+			})
+
+			it("allows ssh by default", func() {
+				setRequiredEnv()
+				api, err := NewAPI("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Provisioner.Template.DisableSSH).To(BeFalse())
+				Expect(api.Provisioner.Template.Empty()).To(BeTrue())
+			})
+
+			it("rejects an invalid org template", func() {
+				path := writeConfig("ignition.yml", `
+org_template:
+  private_domains: ["https://apps.example.com"]
+  space_quota:
+    memory_limit: 0
+`)
+				setRequiredEnv()
+				_, err := NewAPI(path)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("org_template.space_quota.name is required"))
+				Expect(err.Error()).To(ContainSubstring("org_template.space_quota.memory_limit must be greater than zero"))
+				Expect(err.Error()).To(ContainSubstring("org_template.private_domains[0] [https://apps.example.com] is not a domain name"))
+			})
+
+			it("configures the starter services", func() {
+				path := writeConfig("ignition.yml", `
+starter_services:
+  - offering: p.mysql
+    plan: db-small
+    instance_name: my-database
+`)
+				setRequiredEnv()
+				api, err := NewAPI(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.Provisioner.StarterServices).To(Equal([]cloudfoundry.StarterService{
+					{Offering: "p.mysql", Plan: "db-small", InstanceName: "my-database"},
+				}))
+			})
+
+			it("rejects invalid starter services", func() {
+				path := writeConfig("ignition.yml", `
+starter_services:
+  - offering: p.mysql
+    instance_name: my-database
+  - offering: p.redis
+    plan: cache-small
+    instance_name: my-database
+`)
+				setRequiredEnv()
+				_, err := NewAPI(path)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("starter_services[0].plan is required"))
+				Expect(err.Error()).To(ContainSubstring("starter_services[1].instance_name [my-database] is used by another starter service"))
+			})
+
+			it("reads the branding", func() {
+				dir, err := ioutil.TempDir("", "ignition-branding")
+				Expect(err).NotTo(HaveOccurred())
+				defer os.RemoveAll(dir)
+				path := writeConfig("ignition.yml", `
+branding_dir: `+dir+`
+branding:
+  title: Acme Cloud
+  logo_url: /branding/logo.svg
+  support:
+    name: Platform Team
+    email: platform@example.com
+  steps:
+    - text: Read the
+      link_text: handbook
+      link_url: https://handbook.example.com
+  cli_downloads:
+    - name: Linux
+      url: https://cli.example.com/linux
+foundations:
+  - name: east
+    api_url: https://api.example.com
+`)
+				setRequiredEnv()
+				api, err := NewAPI(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.BrandingDir).To(Equal(dir))
+				Expect(api.Branding.Title).To(Equal("Acme Cloud"))
+				Expect(api.Branding.LogoURL).To(Equal("/branding/logo.svg"))
+				Expect(api.Branding.Support).To(Equal(http.Support{Name: "Platform Team", Email: "platform@example.com"}))
+				Expect(api.Branding.Steps).To(Equal([]http.BrandingStep{{Text: "Read the", LinkText: "handbook", LinkURL: "https://handbook.example.com"}}))
+				Expect(api.Branding.CLIDownloads).To(Equal([]http.Link{{Name: "Linux", URL: "https://cli.example.com/linux"}}))
+				Expect(api.FoundationNames).To(Equal([]string{"east"}))
+			})
+
+			it("rejects invalid branding", func() {
+				path := writeConfig("ignition.yml", `
+branding_dir: /does/not/exist
+branding:
+  logo_url: logo.svg
+  support:
+    email: platform
+  steps:
+    - link_url: https://handbook.example.com
+  cli_downloads:
+    - url: ftp://cli.example.com
+`)
+				setRequiredEnv()
+				_, err := NewAPI(path)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("branding_dir (IGNITION_BRANDING_DIR) refers to [/does/not/exist], which is not a directory"))
+				Expect(err.Error()).To(ContainSubstring("branding.logo_url must be a path or an http(s) URL"))
+				Expect(err.Error()).To(ContainSubstring("branding.support.email [platform] is not an email address"))
+				Expect(err.Error()).To(ContainSubstring("branding.steps[0].link_text is required when link_url is set"))
+				Expect(err.Error()).To(ContainSubstring("branding.cli_downloads[0].name is required"))
+				Expect(err.Error()).To(ContainSubstring("branding.cli_downloads[0].url must be an http(s) URL"))
+			})
+
+			it("reads json files", func() {
+				path := writeConfig("ignition.json", `{
+					"client_id": "json-client-id",
+					"client_secret": "json-client-secret",
+					"auth_url": "https://login.example.com/oauth/authorize",
+					"token_url": "https://login.example.com/oauth/token",
+					"jwks_url": "https://login.example.com/token_keys",
+					"issuer_url": "https://login.example.com/oauth/token",
+					"authorized_domain": "@example.com",
+					"session_secret": "json-session-secret",
+					"uaa_url": "https://uaa.example.com",
+					"uaa_origin": "json-origin",
+					"apps_url": "https://apps.example.com",
+					"ccapi_url": "https://api.example.com",
+					"ccapi_username": "json-username",
+					"ccapi_password": "json-password",
+					"quota_id": "json-quota-id"
+				}`)
+				api, err := NewAPI(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(api.UserConfig.ClientID).To(Equal("json-client-id"))
+				Expect(api.QuotaID).To(Equal("json-quota-id"))
+			})
+
+			it("reports every invalid structured setting", func() {
+				path := writeConfig("ignition.yml", `
+quota_tier: missing
+foundations:
+  - api_url: https://api.example.com
+quota_tiers:
+  - name: small
+policies:
+  - quota_tier: unknown
+`)
+				_, err := NewAPI(path)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("quota_tier (IGNITION_QUOTA_TIER) refers to unknown quota tier [missing]"))
+				Expect(err.Error()).To(ContainSubstring("foundations[0].name is required"))
+				Expect(err.Error()).To(ContainSubstring("quota_tiers[0].quota_id is required"))
+				Expect(err.Error()).To(ContainSubstring("policies[0].domain is required"))
+				Expect(err.Error()).To(ContainSubstring("policies[0].quota_tier refers to unknown quota tier [unknown]"))
+			})
+		})
+
+		when("all required env vars have been set", func() {
+			it.Before(setRequiredEnv)
+
 			it("does not return an error", func() {
-				api, err := NewAPI()
+				api, err := NewAPI("")
 				Expect(err).NotTo(HaveOccurred())
 				Expect(api).NotTo(BeNil())
 			})
 
 			it("fails if the ccapi url is empty", func() {
 				os.Unsetenv("IGNITION_CCAPI_URL")
-				api, err := NewAPI()
+				api, err := NewAPI("")
 				Expect(err).To(HaveOccurred())
 				Expect(api).To(BeNil())
 			})
 
 			it("fails if the client id is empty", func() {
 				os.Unsetenv("IGNITION_CLIENT_ID")
-				api, err := NewAPI()
+				api, err := NewAPI("")
 				Expect(err).To(HaveOccurred())
 				Expect(api).To(BeNil())
 			})
 
 			it("defaults the ccapi client id and secret to default values", func() {
-				api, err := NewAPI()
+				api, err := NewAPI("")
 				Expect(err).NotTo(HaveOccurred())
 				Expect(api).NotTo(BeNil())
 				Expect(api.APIConfig.ClientID).To(Equal("cf"))
@@ -111,28 +960,28 @@ func TestIgnitionMain(t *testing.T) {
 
 			it("fails if the ccapi username is empty", func() {
 				os.Unsetenv("IGNITION_CCAPI_USERNAME")
-				api, err := NewAPI()
+				api, err := NewAPI("")
 				Expect(err).To(HaveOccurred())
 				Expect(api).To(BeNil())
 			})
 
 			it("fails if the client secret is empty", func() {
 				os.Unsetenv("IGNITION_CLIENT_SECRET")
-				api, err := NewAPI()
+				api, err := NewAPI("")
 				Expect(err).To(HaveOccurred())
 				Expect(api).To(BeNil())
 			})
 
 			it("fails if the ccapi password is empty", func() {
 				os.Unsetenv("IGNITION_CCAPI_PASSWORD")
-				api, err := NewAPI()
+				api, err := NewAPI("")
 				Expect(err).To(HaveOccurred())
 				Expect(api).To(BeNil())
 			})
 
 			it("fails if the quotaid is empty", func() {
 				os.Unsetenv("IGNITION_QUOTA_ID")
-				api, err := NewAPI()
+				api, err := NewAPI("")
 				Expect(err).To(HaveOccurred())
 				Expect(api).To(BeNil())
 			})
@@ -145,14 +994,14 @@ func TestIgnitionMain(t *testing.T) {
 				})
 
 				it("sets the webroot to the current directory", func() {
-					api, err := NewAPI()
+					api, err := NewAPI("")
 					Expect(err).NotTo(HaveOccurred())
 					Expect(api).NotTo(BeNil())
 					Expect(api.WebRoot).To(Equal(currentDir))
 				})
 
 				it("sets the port correctly", func() {
-					api, err := NewAPI()
+					api, err := NewAPI("")
 					Expect(err).NotTo(HaveOccurred())
 					Expect(api).NotTo(BeNil())
 					Expect(api.Port).To(Equal(443))
@@ -160,14 +1009,14 @@ func TestIgnitionMain(t *testing.T) {
 				})
 
 				it("sets the scheme to https", func() {
-					api, err := NewAPI()
+					api, err := NewAPI("")
 					Expect(err).NotTo(HaveOccurred())
 					Expect(api).NotTo(BeNil())
 					Expect(api.Scheme).To(Equal("https"))
 				})
 
 				it("sets the domain", func() {
-					api, err := NewAPI()
+					api, err := NewAPI("")
 					Expect(err).NotTo(HaveOccurred())
 					Expect(api).NotTo(BeNil())
 					Expect(api.Domain).To(Equal("ignition.pcfbeta.io"))
@@ -175,18 +1024,164 @@ func TestIgnitionMain(t *testing.T) {
 
 				it("returns an error if there are no application uris set", func() {
 					os.Setenv("VCAP_APPLICATION", `{"cf_api": "https://api.run.pcfbeta.io","limits": {"fds": 16384},"application_name": "ignition","application_uris": [],"name": "ignition","space_name": "development","space_id": "test-space-id","uris": ["ignition.pcfbeta.io"],"users": null,"application_id": "test-app-id"}`)
-					api, err := NewAPI()
+					api, err := NewAPI("")
 					Expect(err).To(HaveOccurred())
 					Expect(api).To(BeNil())
 				})
 
+				when("discovering credentials from bound services", func() {
+					it.Before(func() {
+						os.Setenv("VCAP_SERVICES", `{
+							"user-provided": [
+								{
+									"credentials": {
+										"session_secret": "ups-session-secret",
+										"ccapi_password": "ups-ccapi-password",
+										"serve_port": 4567,
+										"webhook_max_attempts": 1000000,
+										"login_next_paths": ["/organization", "/profile"],
+										"cors_allowed_methods": "GET,POST",
+										"session_cookie_secure": false
+									},
+									"label": "user-provided",
+									"name": "ignition-secrets",
+									"tags": []
+								}
+							],
+							"credhub": [
+								{
+									"credentials": {
+										"username": "credhub-username",
+										"secret": "credhub-client-secret"
+									},
+									"label": "credhub",
+									"name": "ignition-admin",
+									"tags": ["ignition-admin-client"]
+								}
+							]
+						}`)
+					})
+
+					it("reads every setting from the credentials service", func() {
+						os.Setenv("IGNITION_CREDENTIALS_SERVICE", "ignition-secrets")
+						defer os.Unsetenv("IGNITION_CREDENTIALS_SERVICE")
+						api, err := NewAPI("")
+						Expect(err).NotTo(HaveOccurred())
+						Expect(api.APIPassword).To(Equal("ups-ccapi-password"))
+					})
+
+					it("fails if the credentials service is not bound", func() {
+						os.Setenv("IGNITION_CREDENTIALS_SERVICE", "missing")
+						defer os.Unsetenv("IGNITION_CREDENTIALS_SERVICE")
+						api, err := NewAPI("")
+						Expect(err).To(HaveOccurred())
+						Expect(api).To(BeNil())
+						Expect(err.Error()).To(ContainSubstring("credentials_service (IGNITION_CREDENTIALS_SERVICE) refers to service [missing], which is not bound to the app"))
+					})
+
+					when("service credentials are configured in the config file", func() {
+						var dir string
+
+						it.Before(func() {
+							var err error
+							dir, err = ioutil.TempDir("", "ignition-config")
+							Expect(err).NotTo(HaveOccurred())
+						})
+
+						it.After(func() {
+							os.RemoveAll(dir)
+						})
+
+						newAPIWithConfig := func(contents string) (*http.API, error) {
+							path := filepath.Join(dir, "ignition.yml")
+							Expect(ioutil.WriteFile(path, []byte(contents), 0600)).To(Succeed())
+							return NewAPI(path)
+						}
+
+						it("reads settings from services found by name, label and tag", func() {
+							api, err := newAPIWithConfig(`
+service_credentials:
+  - setting: ccapi_username
+    tag: ignition-admin-client
+    credential: username
+  - setting: ccapi_client_secret
+    label: credhub
+    credential: secret
+  - setting: ccapi_password
+    name: ignition-secrets
+`)
+							Expect(err).NotTo(HaveOccurred())
+							Expect(api.APIUsername).To(Equal("credhub-username"))
+							Expect(api.APIConfig.ClientSecret).To(Equal("credhub-client-secret"))
+							Expect(api.APIPassword).To(Equal("ups-ccapi-password"))
+						})
+
+						it("converts numeric credentials", func() {
+							api, err := newAPIWithConfig(`
+service_credentials:
+  - setting: serve_port
+    name: ignition-secrets
+`)
+							Expect(err).NotTo(HaveOccurred())
+							Expect(api.ServePort).To(Equal(4567))
+						})
+
+						it("converts large numbers, lists and booleans", func() {
+							api, err := newAPIWithConfig(`
+service_credentials:
+  - setting: webhook_max_attempts
+    name: ignition-secrets
+  - setting: login_next_paths
+    name: ignition-secrets
+  - setting: cors_allowed_methods
+    name: ignition-secrets
+  - setting: session_cookie_secure
+    name: ignition-secrets
+`)
+							Expect(err).NotTo(HaveOccurred())
+							Expect(api.Webhooks.MaxAttempts).To(Equal(1000000))
+							Expect(api.ReturnTo.Paths).To(Equal([]string{"/organization", "/profile"}))
+							Expect(api.CORSAllowedMethods).To(Equal([]string{"GET", "POST"}))
+							Expect(api.SessionCookie.Secure).To(BeFalse())
+						})
+
+						it("reports every service credential that cannot be read", func() {
+							_, err := newAPIWithConfig(`
+service_credentials:
+  - setting: not_a_setting
+    name: ignition-secrets
+  - setting: ccapi_password
+    name: ignition-secrets
+    label: credhub
+  - setting: ccapi_password
+    tag: unknown-tag
+`)
+							Expect(err).To(HaveOccurred())
+							Expect(err.Error()).To(ContainSubstring("service_credentials[0].setting refers to unknown setting [not_a_setting]"))
+							Expect(err.Error()).To(ContainSubstring("service_credentials[1] requires exactly one of name, label or tag"))
+							Expect(err.Error()).To(ContainSubstring("service_credentials[2] could not find a service with tag [unknown-tag]"))
+						})
+
+						it("fails if the credential is missing from the service", func() {
+							_, err := newAPIWithConfig(`
+service_credentials:
+  - setting: ccapi_password
+    name: ignition-admin
+    credential: password
+`)
+							Expect(err).To(HaveOccurred())
+							Expect(err.Error()).To(ContainSubstring("ccapi_password (IGNITION_CCAPI_PASSWORD) could not be read from credential [password] of the service with name [ignition-admin]"))
+						})
+					})
+				})
+
 				when("using the p-identity variant", func() {
 					it.Before(func() {
 						os.Setenv("IGNITION_AUTH_VARIANT", "p-identity")
 					})
 
 					it("fails if there is no service with the name identity", func() {
-						api, err := NewAPI()
+						api, err := NewAPI("")
 						Expect(err).To(HaveOccurred())
 						Expect(api).To(BeNil())
 						Expect(err.Error()).To(ContainSubstring("a Single Sign On service instance with the name \"identity\" is required to use this app"))
@@ -211,7 +1206,7 @@ func TestIgnitionMain(t *testing.T) {
 							]
 						}`)
 
-						api, err := NewAPI()
+						api, err := NewAPI("")
 						Expect(err).To(HaveOccurred())
 						Expect(api).To(BeNil())
 						Expect(err.Error()).To(ContainSubstring("could not retrieve the client_id; make sure you have created and bound a Single Sign On service instance with the name \"identity\""))
@@ -236,7 +1231,7 @@ func TestIgnitionMain(t *testing.T) {
 							]
 						}`)
 
-						api, err := NewAPI()
+						api, err := NewAPI("")
 						Expect(err).To(HaveOccurred())
 						Expect(api).To(BeNil())
 						Expect(err.Error()).To(ContainSubstring("could not retrieve the client_secret; make sure you have created and bound a Single Sign On service instance with the name \"identity\""))
@@ -262,32 +1257,89 @@ func TestIgnitionMain(t *testing.T) {
 							]
 						}`)
 
-						api, err := NewAPI()
+						api, err := NewAPI("")
 						Expect(err).NotTo(HaveOccurred())
 						Expect(api).NotTo(BeNil())
 						Expect(api.UserConfig.ClientID).To(Equal("test-cf-client-id"))
 						Expect(api.UserConfig.ClientSecret).To(Equal("test-cf-client-secret"))
 					})
+
+					when("identity_service is set in the config file", func() {
+						var dir string
+
+						it.Before(func() {
+							var err error
+							dir, err = ioutil.TempDir("", "ignition-config")
+							Expect(err).NotTo(HaveOccurred())
+							os.Setenv("VCAP_SERVICES", `{
+								"p-identity": [
+									{
+										"credentials": {
+											"client_id": "renamed-client-id",
+											"client_secret": "renamed-client-secret"
+										},
+										"label": "p-identity",
+										"plan": "ignition",
+										"name": "ignition-sso",
+										"tags": ["sso"]
+									}
+								]
+							}`)
+						})
+
+						it.After(func() {
+							os.RemoveAll(dir)
+						})
+
+						newAPIWithConfig := func(contents string) (*http.API, error) {
+							path := filepath.Join(dir, "ignition.yml")
+							Expect(ioutil.WriteFile(path, []byte(contents), 0600)).To(Succeed())
+							return NewAPI(path)
+						}
+
+						it("uses the service found by name, label or tag", func() {
+							for _, selector := range []string{"name: ignition-sso", "label: p-identity", "tag: sso"} {
+								api, err := newAPIWithConfig("identity_service:\n  " + selector + "\n")
+								Expect(err).NotTo(HaveOccurred(), selector)
+								Expect(api.UserConfig.ClientID).To(Equal("renamed-client-id"))
+								Expect(api.UserConfig.ClientSecret).To(Equal("renamed-client-secret"))
+							}
+						})
+
+						it("fails if the service is not bound", func() {
+							api, err := newAPIWithConfig("identity_service:\n  label: missing\n")
+							Expect(err).To(HaveOccurred())
+							Expect(api).To(BeNil())
+							Expect(err.Error()).To(ContainSubstring("a Single Sign On service instance with the label \"missing\" is required to use this app"))
+						})
+
+						it("requires exactly one of name, label or tag", func() {
+							api, err := newAPIWithConfig("identity_service:\n  name: ignition-sso\n  tag: sso\n")
+							Expect(err).To(HaveOccurred())
+							Expect(api).To(BeNil())
+							Expect(err.Error()).To(ContainSubstring("identity_service requires exactly one of name, label or tag"))
+						})
+					})
 				})
 			})
 
 			when("not running on CF", func() {
 				it("sets the webroot for local development", func() {
-					api, err := NewAPI()
+					api, err := NewAPI("")
 					Expect(err).NotTo(HaveOccurred())
 					Expect(api).NotTo(BeNil())
 					Expect(api.WebRoot).To(Equal(filepath.Join(currentDir, "web", "dist")))
 				})
 
 				it("uses the correct client id", func() {
-					api, err := NewAPI()
+					api, err := NewAPI("")
 					Expect(err).NotTo(HaveOccurred())
 					Expect(api).NotTo(BeNil())
 					Expect(api.UserConfig.ClientID).To(Equal("test-ignition-client-id"))
 				})
 
 				it("uses the correct client secret", func() {
-					api, err := NewAPI()
+					api, err := NewAPI("")
 					Expect(err).NotTo(HaveOccurred())
 					Expect(api).NotTo(BeNil())
 					Expect(api.UserConfig.ClientSecret).To(Equal("test-ignition-client-secret"))
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"flag"
+	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
@@ -8,47 +10,27 @@ import (
 
 	cfclient "github.com/cloudfoundry-community/go-cfclient"
 	"github.com/cloudfoundry-community/go-cfenv"
-	"github.com/dghubble/sessions"
-	"github.com/kelseyhightower/envconfig"
+	"github.com/pivotalservices/ignition/audit"
+	"github.com/pivotalservices/ignition/cloudfoundry"
 	"github.com/pivotalservices/ignition/http"
+	"github.com/pivotalservices/ignition/http/events"
+	"github.com/pivotalservices/ignition/http/organization"
+	"github.com/pivotalservices/ignition/http/session"
+	"github.com/pivotalservices/ignition/http/terms"
+	"github.com/pivotalservices/ignition/notify"
 	"github.com/pivotalservices/ignition/uaa"
 	"github.com/pivotalservices/ignition/user/openid"
+	"github.com/pivotalservices/ignition/web"
+	"github.com/pivotalservices/ignition/webhook"
 	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
 )
 
-type envConfig struct {
-	AuthVariant       string   `envconfig:"auth_variant" default:"openid"`                        // IGNITION_AUTH_VARIANT
-	ClientID          string   `envconfig:"client_id"`                                            // IGNITION_CLIENT_ID
-	ClientSecret      string   `envconfig:"client_secret"`                                        // IGNITION_CLIENT_SECRET
-	AuthURL           string   `envconfig:"auth_url" required:"true"`                             // IGNITION_AUTH_URL
-	TokenURL          string   `envconfig:"token_url" required:"true"`                            // IGNITION_TOKEN_URL
-	JWKSURL           string   `envconfig:"jwks_url" required:"true"`                             // IGNITION_JWKS_URL
-	IssuerURL         string   `envconfig:"issuer_url" required:"true"`                           // IGNITION_ISSUER_URL
-	AuthScopes        []string `envconfig:"auth_scopes" default:"openid,profile,user_attributes"` // IGNITION_AUTH_SCOPES
-	AuthorizedDomain  string   `envconfig:"authorized_domain" required:"true"`                    // IGNITION_AUTHORIZED_DOMAIN
-	SessionSecret     string   `envconfig:"session_secret" required:"true"`                       // IGNITION_SESSION_SECRET
-	Port              int      `envconfig:"port" default:"3000"`                                  // IGNITION_PORT
-	ServePort         int      `envconfig:"serve_port" default:"3000"`                            // IGNITION_SERVE_PORT
-	Domain            string   `envconfig:"domain" default:"localhost"`                           // IGNITION_DOMAIN
-	Scheme            string   `envconfig:"scheme" default:"http"`                                // IGNITION_SCHEME
-	WebRoot           string   `envconfig:"web_root"`                                             // IGNITION_WEB_ROOT
-	UAAURL            string   `envconfig:"uaa_url" required:"true"`                              // IGNITION_UAA_URL
-	UAAOrigin         string   `envconfig:"uaa_origin" required:"true"`                           // IGNITION_UAA_ORIGIN
-	AppsURL           string   `envconfig:"apps_url" required:"true"`                             // IGNITION_APPS_URL
-	CCAPIURL          string   `envconfig:"ccapi_url" required:"true"`                            // IGNITION_CCAPI_URL
-	CCAPIClientID     string   `envconfig:"ccapi_client_id" default:"cf"`                         // IGNITION_CCAPI_CLIENT_ID
-	CCAPIClientSecret string   `envconfig:"ccapi_client_secret" default:""`                       // IGNITION_CCAPI_CLIENT_SECRET
-	CCAPIUsername     string   `envconfig:"ccapi_username" required:"true"`                       // IGNITION_CCAPI_USERNAME
-	CCAPIPassword     string   `envconfig:"ccapi_password" required:"true"`                       // IGNITION_CCAPI_PASSWORD
-	OrgPrefix         string   `envconfig:"org_prefix" default:"ignition"`                        // IGNITION_ORG_PREFIX
-	QuotaID           string   `envconfig:"quota_id" required:"true"`                             // IGNITION_QUOTA_ID
-	SpaceName         string   `envconfig:"space_name" default:"playground"`                      // IGNITION_SPACE_NAME
-}
-
 func main() {
 	log.SetFlags(log.Lshortfile | log.Ldate | log.Ltime | log.LUTC)
-	api, err := NewAPI()
+	configFile := flag.String("config", "", "path to a YAML or JSON config file (defaults to $IGNITION_CONFIG_FILE)")
+	flag.Parse()
+	api, err := NewAPI(configFilePath(*configFile))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -62,14 +44,15 @@ func main() {
 		log.Fatal(err)
 	}
 	api.CCAPI = client
+	api.Provisioner.API = client
 	log.Printf("Starting Server listening on %s\n", api.URI())
 	log.Fatal(api.Run())
 }
 
-// NewAPI builds an http.API
-func NewAPI() (*http.API, error) {
-	var c envConfig
-	err := envconfig.Process("ignition", &c)
+// NewAPI builds an http.API from the config file at configFile (which may be
+// empty) and the environment
+func NewAPI(configFile string) (*http.API, error) {
+	c, err := loadConfig(configFile)
 	if err != nil {
 		return nil, err
 	}
@@ -78,7 +61,8 @@ func NewAPI() (*http.API, error) {
 		return nil, err
 	}
 
-	if strings.TrimSpace(c.WebRoot) == "" {
+	// the web bundle is served from disk unless it is compiled into the binary
+	if strings.TrimSpace(c.WebRoot) == "" && web.FileSystem() == nil {
 		if cfenv.IsRunningOnCF() {
 			c.WebRoot = root
 		} else {
@@ -91,6 +75,10 @@ func NewAPI() (*http.API, error) {
 		return nil, err
 	}
 
+	if !cfenv.IsRunningOnCF() && strings.TrimSpace(c.TLSCertFile) != "" && strings.EqualFold(c.Scheme, "http") {
+		c.Scheme = "https"
+	}
+
 	if cfenv.IsRunningOnCF() {
 		c.Scheme = "https"
 		c.Port = 443
@@ -102,27 +90,177 @@ func NewAPI() (*http.API, error) {
 	}
 
 	if cfenv.IsRunningOnCF() && strings.EqualFold(strings.TrimSpace(c.AuthVariant), "p-identity") {
-		service, err := env.Services.WithName("identity")
+		if err := c.applyIdentityService(env.Services); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfenv.IsRunningOnCF() {
+		err = c.applyServiceCredentials(env.Services)
 		if err != nil {
-			return nil, errors.Wrap(err, "a Single Sign On service instance with the name \"identity\" is required to use this app")
+			return nil, err
 		}
-		clientid, ok := service.CredentialString("client_id")
-		if !ok {
-			return nil, errors.New("could not retrieve the client_id; make sure you have created and bound a Single Sign On service instance with the name \"identity\"")
+	}
+
+	c.resolve()
+	err = c.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []http.DomainPolicy
+	for _, p := range c.Policies {
+		dp := http.DomainPolicy{Domain: p.Domain}
+		if t, ok := c.quotaTier(p.QuotaTier); ok {
+			dp.QuotaID = t.QuotaID
+		}
+		policies = append(policies, dp)
+	}
+
+	var foundationNames []string
+	for _, f := range c.Foundations {
+		foundationNames = append(foundationNames, f.Name)
+	}
+	brand := http.Branding{
+		Title:   c.Branding.Title,
+		LogoURL: c.Branding.LogoURL,
+		Support: http.Support{
+			Name:  c.Branding.Support.Name,
+			Email: c.Branding.Support.Email,
+			URL:   c.Branding.Support.URL,
+		},
+	}
+	for _, s := range c.Branding.Steps {
+		brand.Steps = append(brand.Steps, http.BrandingStep{Text: s.Text, LinkText: s.LinkText, LinkURL: s.LinkURL, Suffix: s.Suffix})
+	}
+	for _, l := range c.Branding.CLIDownloads {
+		brand.CLIDownloads = append(brand.CLIDownloads, http.Link{Name: l.Name, URL: l.URL})
+	}
+
+	var sampleApp *cloudfoundry.SampleApp
+	if strings.TrimSpace(c.SampleAppPath) != "" {
+		sampleApp = &cloudfoundry.SampleApp{
+			Name:           c.SampleAppName,
+			Path:           c.SampleAppPath,
+			Memory:         c.SampleAppMemory,
+			Buildpack:      c.SampleAppBuildpack,
+			StagingTimeout: c.SampleAppTimeout,
 		}
-		c.ClientID = clientid
-		clientsecret, ok := service.CredentialString("client_secret")
-		if !ok {
-			return nil, errors.New("could not retrieve the client_secret; make sure you have created and bound a Single Sign On service instance with the name \"identity\"")
+	}
+
+	template := cloudfoundry.OrgTemplate{
+		IsolationSegment: c.OrgTemplate.IsolationSegment,
+		SecurityGroups:   c.OrgTemplate.SecurityGroups,
+		PrivateDomains:   c.OrgTemplate.PrivateDomains,
+		DisableSSH:       c.OrgTemplate.AllowSSH != nil && !*c.OrgTemplate.AllowSSH,
+	}
+	if q := c.OrgTemplate.SpaceQuota; q != nil {
+		template.SpaceQuota = &cloudfoundry.SpaceQuota{
+			Name:                    q.Name,
+			MemoryLimit:             q.MemoryLimit,
+			InstanceMemoryLimit:     unlimited(q.InstanceMemoryLimit),
+			TotalRoutes:             unlimited(q.TotalRoutes),
+			TotalServices:           unlimited(q.TotalServices),
+			AppInstanceLimit:        unlimited(q.AppInstanceLimit),
+			NonBasicServicesAllowed: q.NonBasicServicesAllowed,
 		}
-		c.ClientSecret = clientsecret
 	}
 
-	if strings.TrimSpace(c.ClientID) == "" {
-		return nil, errors.New("a client id must be set")
+	teams := &organization.Teams{
+		AppsURL:   c.AppsURL,
+		SpaceName: c.SpaceName,
+		Template:  template,
+	}
+	for _, t := range c.TeamOrgs {
+		roles := t.Roles
+		if len(roles) == 0 {
+			roles = []string{cloudfoundry.RoleSpaceDeveloper}
+		}
+		q, _ := c.quotaTier(t.QuotaTier)
+		teams.Orgs = append(teams.Orgs, organization.TeamOrg{Group: t.Group, Name: t.Name, QuotaID: q.QuotaID, Roles: roles})
 	}
-	if strings.TrimSpace(c.ClientSecret) == "" {
-		return nil, errors.New("a client secret must be set")
+
+	var starterServices []cloudfoundry.StarterService
+	for _, s := range c.StarterServices {
+		starterServices = append(starterServices, cloudfoundry.StarterService{
+			Offering:     s.Offering,
+			Plan:         s.Plan,
+			InstanceName: s.InstanceName,
+		})
+	}
+
+	jobs, err := organization.NewJobStore(c.JobStorePath)
+	if err != nil {
+		return nil, err
+	}
+
+	broker := events.NewBroker()
+
+	var sinks []audit.Sink
+	if strings.TrimSpace(c.AuditLogPath) != "" {
+		sinks = append(sinks, &audit.FileSink{Path: c.AuditLogPath})
+	}
+	if strings.TrimSpace(c.AuditSyslogURL) != "" {
+		network, address, _ := syslogAddress(c.AuditSyslogURL)
+		sinks = append(sinks, &audit.SyslogSink{Network: network, Address: address, AppName: "ignition"})
+	}
+	if strings.TrimSpace(c.AuditWebhookURL) != "" {
+		sinks = append(sinks, &audit.WebhookSink{URL: c.AuditWebhookURL})
+	}
+	auditLog := audit.NewLog(sinks...)
+	teams.Audit = auditLog
+
+	webhookStore, err := webhook.NewStore(c.WebhookStorePath)
+	if err != nil {
+		return nil, err
+	}
+	webhooks := &webhook.Dispatcher{
+		Store:       webhookStore,
+		MaxAttempts: c.WebhookMaxAttempts,
+	}
+	for _, w := range c.Webhooks {
+		webhooks.Endpoints = append(webhooks.Endpoints, webhook.Endpoint{URL: w.URL, Secret: w.Secret, Events: w.Events})
+	}
+	teams.Webhooks = webhooks
+
+	registry, err := session.NewRegistry(c.SessionStorePath)
+	if err != nil {
+		return nil, err
+	}
+	registry.Lifetime = c.SessionLifetime
+	registry.IdleTimeout = c.SessionIdleTimeout
+	registry.Audit = auditLog
+
+	var t *terms.Terms
+	if strings.TrimSpace(c.TermsPath) != "" {
+		document, err := ioutil.ReadFile(c.TermsPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read the terms of use [%s]", c.TermsPath)
+		}
+		store, err := terms.NewStore(c.TermsStorePath)
+		if err != nil {
+			return nil, err
+		}
+		t = terms.New(c.TermsPath, document, c.TermsVersion, store)
+		t.Audit = auditLog
+	}
+
+	var mail *notify.Notifier
+	if c.emailEnabled() {
+		templates, err := notify.NewTemplates(c.EmailTemplatesDir)
+		if err != nil {
+			return nil, err
+		}
+		var sender notify.Sender = &notify.SMTPSender{
+			Host:     c.SMTPHost,
+			Port:     c.SMTPPort,
+			Username: c.SMTPUsername,
+			Password: c.SMTPPassword,
+		}
+		if strings.TrimSpace(c.EmailDryRunDir) != "" {
+			sender = &notify.DirSender{Dir: c.EmailDryRunDir}
+		}
+		mail = &notify.Notifier{From: c.EmailFrom, Sender: sender, Templates: templates}
 	}
 
 	apiconfig := &oauth2.Config{
@@ -160,16 +298,68 @@ func NewAPI() (*http.API, error) {
 		APIConfig:        apiconfig,
 		AuthorizedDomain: c.AuthorizedDomain,
 		Fetcher: &openid.Fetcher{
-			Verifier: openid.NewVerifier(c.IssuerURL, c.ClientID, c.JWKSURL),
+			Verifier: openid.NewVerifier(c.IssuerURL, c.ClientID, c.JWKSURL, c.GroupsClaim),
+		},
+		SessionStore:         session.NewCookieStore(c.sessionCookie(), c.sessionKeys()...),
+		SessionCookie:        c.sessionCookie(),
+		Sessions:             registry,
+		Logout:               c.logout(),
+		ReturnTo:             c.returnTo(),
+		APIUsername:          c.CCAPIUsername,
+		APIPassword:          c.CCAPIPassword,
+		OrgPrefix:            c.OrgPrefix,
+		QuotaID:              c.QuotaID,
+		UAAAPI:               uaaAPI,
+		SpaceName:            c.SpaceName,
+		UAAOrigin:            c.UAAOrigin,
+		Policies:             policies,
+		ReadTimeout:          c.ReadTimeout,
+		WriteTimeout:         c.WriteTimeout,
+		IdleTimeout:          c.IdleTimeout,
+		ShutdownTimeout:      c.ShutdownTimeout,
+		TLSCertFile:          c.TLSCertFile,
+		TLSKeyFile:           c.TLSKeyFile,
+		JWKSURL:              c.JWKSURL,
+		HealthCacheTTL:       c.HealthCacheTTL,
+		HealthCheckTimeout:   c.HealthCheckTimeout,
+		CORSAllowedOrigins:   c.CORSAllowedOrigins,
+		CORSAllowedMethods:   c.CORSAllowedMethods,
+		CORSAllowedHeaders:   c.CORSAllowedHeaders,
+		CORSAllowCredentials: c.CORSAllowCredentials,
+		Provisioner: &organization.Provisioner{
+			AppsURL:   c.AppsURL,
+			SpaceName: c.SpaceName,
+			Store:     jobs,
+			Workers:   c.ProvisionWorkers,
+			Events:    broker,
+			SampleApp: sampleApp,
+
+			Template:        template,
+			StarterServices: starterServices,
+			Audit:           auditLog,
+			Webhooks:        webhooks,
+			Mail:            mail,
+			APIURL:          c.CCAPIURL,
+			UAAURL:          c.UAAURL,
 		},
-		SessionStore: sessions.NewCookieStore([]byte(c.SessionSecret), nil),
-		APIUsername:  c.CCAPIUsername,
-		APIPassword:  c.CCAPIPassword,
-		OrgPrefix:    c.OrgPrefix,
-		QuotaID:      c.QuotaID,
-		UAAAPI:       uaaAPI,
-		SpaceName:    c.SpaceName,
-		UAAOrigin:    c.UAAOrigin,
+		Teams:           teams,
+		Events:          broker,
+		Audit:           auditLog,
+		Webhooks:        webhooks,
+		Terms:           t,
+		Admins:          c.Admins,
+		Branding:        brand,
+		BrandingDir:     c.BrandingDir,
+		FoundationNames: foundationNames,
 	}
 	return &api, nil
 }
+
+// unlimited is -1, which the Cloud Controller treats as no limit, for a quota
+// limit that is not set
+func unlimited(limit int) int {
+	if limit == 0 {
+		return -1
+	}
+	return limit
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-community/go-cfenv"
+	"github.com/pkg/errors"
+)
+
+// serviceSelector finds a bound service by exactly one of Name, Label or Tag
+type serviceSelector struct {
+	Name  string `yaml:"name"`
+	Label string `yaml:"label"`
+	Tag   string `yaml:"tag"`
+}
+
+// serviceCredential reads a setting from the credentials of a bound service.
+// The service is found by its serviceSelector, and Credential is the
+// credentials key to read (defaulting to the setting name)
+type serviceCredential struct {
+	Setting         string `yaml:"setting"`
+	serviceSelector `yaml:",inline"`
+	Credential      string `yaml:"credential"`
+}
+
+func (s serviceCredential) key() string {
+	if strings.TrimSpace(s.Credential) != "" {
+		return s.Credential
+	}
+	return s.Setting
+}
+
+// valid is true when exactly one of Name, Label or Tag is set
+func (s serviceSelector) valid() bool {
+	selectors := 0
+	for _, v := range []string{s.Name, s.Label, s.Tag} {
+		if strings.TrimSpace(v) != "" {
+			selectors++
+		}
+	}
+	return selectors == 1
+}
+
+// by returns what the service is found by, and the name, label or tag
+func (s serviceSelector) by() (string, string) {
+	switch {
+	case strings.TrimSpace(s.Name) != "":
+		return "name", s.Name
+	case strings.TrimSpace(s.Label) != "":
+		return "label", s.Label
+	default:
+		return "tag", s.Tag
+	}
+}
+
+func (s serviceSelector) selector() string {
+	by, value := s.by()
+	return fmt.Sprintf("%s [%s]", by, value)
+}
+
+// find returns the single bound service that the serviceSelector refers to
+func (s serviceSelector) find(services cfenv.Services) (*cfenv.Service, error) {
+	var matches []cfenv.Service
+	switch {
+	case strings.TrimSpace(s.Name) != "":
+		service, err := services.WithName(s.Name)
+		if err != nil {
+			return nil, err
+		}
+		return service, nil
+	case strings.TrimSpace(s.Label) != "":
+		m, err := services.WithLabel(s.Label)
+		if err != nil {
+			return nil, err
+		}
+		matches = m
+	default:
+		m, err := services.WithTag(s.Tag)
+		if err != nil {
+			return nil, err
+		}
+		matches = m
+	}
+	if len(matches) != 1 {
+		return nil, errors.Errorf("found %d services with %s; exactly one is required", len(matches), s.selector())
+	}
+	return &matches[0], nil
+}
+
+// applyServiceCredentials sets every setting that can be discovered from the
+// bound services, and reports all of the settings that cannot
+func (c *config) applyServiceCredentials(services cfenv.Services) error {
+	var invalid invalidConfigError
+	if strings.TrimSpace(c.CredentialsService) != "" {
+		if _, err := services.WithName(c.CredentialsService); err != nil {
+			invalid.add("credentials_service", fmt.Sprintf("refers to service [%s], which is not bound to the app", c.CredentialsService))
+		}
+	}
+	for i, s := range c.ServiceCredentials {
+		field := fmt.Sprintf("service_credentials[%d]", i)
+		if !isSetting(s.Setting) {
+			invalid.addField(field+".setting", fmt.Sprintf("refers to unknown setting [%s]", s.Setting))
+			continue
+		}
+		if !s.valid() {
+			invalid.addField(field, "requires exactly one of name, label or tag")
+			continue
+		}
+		if _, err := s.find(services); err != nil {
+			invalid.addField(field, fmt.Sprintf("could not find a service with %s: %v", s.selector(), err))
+		}
+	}
+	if len(invalid) > 0 {
+		return invalid
+	}
+
+	if strings.TrimSpace(c.CredentialsService) != "" {
+		// the credentials service may hold any of the settings, keyed by name
+		service, _ := services.WithName(c.CredentialsService)
+		for _, setting := range settingNames() {
+			value, ok := service.Credentials[setting]
+			if !ok || value == nil || setting == "credentials_service" {
+				continue
+			}
+			if err := c.set(setting, value); err != nil {
+				invalid.add(setting, err.Error())
+			}
+		}
+	}
+	for _, s := range c.ServiceCredentials {
+		service, _ := s.find(services)
+		value, ok := service.Credentials[s.key()]
+		if !ok || value == nil {
+			invalid.add(s.Setting, fmt.Sprintf("could not be read from credential [%s] of the service with %s", s.key(), s.selector()))
+			continue
+		}
+		if err := c.set(s.Setting, value); err != nil {
+			invalid.add(s.Setting, err.Error())
+		}
+	}
+	if len(invalid) > 0 {
+		return invalid
+	}
+	return nil
+}
+
+// applyIdentityService sets the client_id and client_secret from the bound
+// Single Sign On service, which is the service named identity unless
+// identity_service selects another one
+func (c *config) applyIdentityService(services cfenv.Services) error {
+	s := serviceSelector{Name: "identity"}
+	if c.IdentityService != nil {
+		s = *c.IdentityService
+	}
+	if !s.valid() {
+		var invalid invalidConfigError
+		invalid.addField("identity_service", "requires exactly one of name, label or tag")
+		return invalid
+	}
+	by, value := s.by()
+	described := fmt.Sprintf("%s %q", by, value)
+	service, err := s.find(services)
+	if err != nil {
+		return errors.Wrapf(err, "a Single Sign On service instance with the %s is required to use this app", described)
+	}
+	clientid, ok := service.CredentialString("client_id")
+	if !ok {
+		return errors.Errorf("could not retrieve the client_id; make sure you have created and bound a Single Sign On service instance with the %s", described)
+	}
+	c.ClientID = clientid
+	clientsecret, ok := service.CredentialString("client_secret")
+	if !ok {
+		return errors.Errorf("could not retrieve the client_secret; make sure you have created and bound a Single Sign On service instance with the %s", described)
+	}
+	c.ClientSecret = clientsecret
+	return nil
+}
+
+// settingNames returns the names of all settings that can be set from a string
+func settingNames() []string {
+	var names []string
+	t := reflect.TypeOf(config{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Tag.Get("envconfig") == "" || f.Tag.Get("ignored") == "true" {
+			continue
+		}
+		names = append(names, f.Tag.Get("envconfig"))
+	}
+	return names
+}
+
+func isSetting(name string) bool {
+	for _, s := range settingNames() {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// set assigns the credential value to the setting with the given name
+func (c *config) set(name string, value interface{}) error {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("envconfig") != name {
+			continue
+		}
+		return setField(v.Field(i), value)
+	}
+	return errors.Errorf("is not a setting")
+}
+
+// setField assigns the credential value to the field. Credentials are decoded
+// from JSON, so numbers are float64 and lists are []interface{}; a list can
+// also be given as a comma separated string
+func setField(f reflect.Value, value interface{}) error {
+	if f.Kind() == reflect.Ptr {
+		p := reflect.New(f.Type().Elem())
+		if err := setField(p.Elem(), value); err != nil {
+			return err
+		}
+		f.Set(p)
+		return nil
+	}
+	if f.Kind() == reflect.Slice {
+		var values []string
+		if list, ok := value.([]interface{}); ok {
+			for _, item := range list {
+				values = append(values, credentialString(item))
+			}
+		} else {
+			values = strings.Split(credentialString(value), ",")
+		}
+		f.Set(reflect.ValueOf(values))
+		return nil
+	}
+	s := credentialString(value)
+	if f.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return errors.Errorf("must be a duration, not [%s]", s)
+		}
+		f.SetInt(int64(d))
+		return nil
+	}
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return errors.Errorf("must be a number, not [%s]", s)
+		}
+		f.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return errors.Errorf("must be true or false, not [%s]", s)
+		}
+		f.SetBool(b)
+	default:
+		return errors.Errorf("cannot be set from a service binding")
+	}
+	return nil
+}
+
+// credentialString formats a single credential value. Numbers are written out
+// in full, so that 1000000 is not read as 1e+06
+func credentialString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pivotalservices/ignition/audit"
+	"github.com/pivotalservices/ignition/http/apierror"
+	"github.com/pivotalservices/ignition/user"
+	"github.com/pivotalservices/ignition/webhook"
+)
+
+// requireAdmin only allows users with an email address in admins; there are
+// no admins when admins is empty
+func requireAdmin(next http.Handler, admins []string) http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		profile, err := user.ProfileFromContext(req.Context())
+		if err != nil || profile == nil {
+			apierror.Write(w, req, http.StatusUnauthorized, "not_authenticated", "You are not logged in.", false)
+			return
+		}
+		if !isAdmin(profile.Email, admins) {
+			apierror.Write(w, req, http.StatusForbidden, "not_admin", "Only administrators can do this.", false)
+			return
+		}
+		next.ServeHTTP(w, req)
+	}
+	return http.HandlerFunc(fn)
+}
+
+func isAdmin(email string, admins []string) bool {
+	if strings.TrimSpace(email) == "" {
+		return false
+	}
+	for _, a := range admins {
+		if strings.EqualFold(strings.TrimSpace(a), email) {
+			return true
+		}
+	}
+	return false
+}
+
+// auditHandler returns the audit entries selected by the actor, action,
+// target, outcome, since (RFC 3339) and limit query parameters, newest first;
+// each query is itself audited
+func auditHandler(l *audit.Log) http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		f := audit.Filter{
+			Actor:   q.Get("actor"),
+			Action:  q.Get("action"),
+			Target:  q.Get("target"),
+			Outcome: q.Get("outcome"),
+		}
+		if since := q.Get("since"); since != "" {
+			t, err := time.Parse(time.RFC3339, since)
+			if err != nil {
+				apierror.Write(w, req, http.StatusBadRequest, "invalid_query", "since must be an RFC 3339 time.", false)
+				return
+			}
+			f.Since = t
+		}
+		if limit := q.Get("limit"); limit != "" {
+			n, err := strconv.Atoi(limit)
+			if err != nil || n <= 0 {
+				apierror.Write(w, req, http.StatusBadRequest, "invalid_query", "limit must be a positive number.", false)
+				return
+			}
+			f.Limit = n
+		}
+
+		entries, err := l.Query(f)
+		e := audit.Entry{Action: audit.ActionAuditQuery, Target: "audit", Outcome: audit.Outcome(err), Details: map[string]string{"query": req.URL.RawQuery}}
+		if profile, perr := user.ProfileFromContext(req.Context()); perr == nil && profile != nil {
+			e.Actor = profile.AccountName
+		}
+		l.Record(e)
+		if err != nil {
+			apierror.Write(w, req, http.StatusInternalServerError, "audit_unavailable", "The audit log could not be read.", true)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(entries)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// webhookDeliveriesHandler returns the webhook deliveries selected by the
+// event, status, url and limit query parameters, newest first
+func webhookDeliveriesHandler(d *webhook.Dispatcher) http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		f := webhook.Filter{
+			Event:  q.Get("event"),
+			Status: q.Get("status"),
+			URL:    q.Get("url"),
+		}
+		if limit := q.Get("limit"); limit != "" {
+			n, err := strconv.Atoi(limit)
+			if err != nil || n <= 0 {
+				apierror.Write(w, req, http.StatusBadRequest, "invalid_query", "limit must be a positive number.", false)
+				return
+			}
+			f.Limit = n
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(d.History(f))
+	}
+	return http.HandlerFunc(fn)
+}
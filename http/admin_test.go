@@ -0,0 +1,112 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/audit"
+	"github.com/pivotalservices/ignition/user"
+	"github.com/pivotalservices/ignition/webhook"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestAdmin(t *testing.T) {
+	spec.Run(t, "Admin", testAdmin, spec.Report(report.Terminal{}))
+}
+
+func testAdmin(t *testing.T, when spec.G, it spec.S) {
+	var (
+		l *audit.Log
+		h http.Handler
+		w *httptest.ResponseRecorder
+	)
+
+	request := func(email string, query string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/admin/audit?"+query, nil)
+		return r.WithContext(user.WithProfile(r.Context(), &user.Profile{Email: email, AccountName: "admin"}))
+	}
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		l = audit.NewLog()
+		l.Record(audit.Entry{Actor: "jdoe", Action: audit.ActionOrgCreate, Target: "ignition-jdoe", Outcome: audit.OutcomeSuccess})
+		l.Record(audit.Entry{Actor: "asmith", Action: audit.ActionLogin, Target: "asmith", Outcome: audit.OutcomeSuccess})
+		h = requireAdmin(auditHandler(l), []string{"Admin@example.com"})
+		w = httptest.NewRecorder()
+	})
+
+	it("is forbidden for users that are not admins", func() {
+		h.ServeHTTP(w, request("jdoe@example.com", ""))
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(w.Body.String()).To(ContainSubstring("not_admin"))
+	})
+
+	it("is forbidden for everyone when there are no admins", func() {
+		requireAdmin(auditHandler(l), nil).ServeHTTP(w, request("admin@example.com", ""))
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+	})
+
+	it("returns the selected entries and audits the query", func() {
+		h.ServeHTTP(w, request("admin@example.com", "actor=jdoe"))
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var entries []audit.Entry
+		Expect(json.Unmarshal(w.Body.Bytes(), &entries)).To(Succeed())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Target).To(Equal("ignition-jdoe"))
+
+		queries, err := l.Query(audit.Filter{Action: audit.ActionAuditQuery})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(queries).To(HaveLen(1))
+		Expect(queries[0].Actor).To(Equal("admin"))
+		Expect(queries[0].Details["query"]).To(Equal("actor=jdoe"))
+	})
+
+	it("rejects invalid queries", func() {
+		for _, query := range []string{"since=yesterday", "limit=0", "limit=ten"} {
+			w = httptest.NewRecorder()
+			h.ServeHTTP(w, request("admin@example.com", query))
+			Expect(w.Code).To(Equal(http.StatusBadRequest), query)
+		}
+	})
+
+	when("listing webhook deliveries", func() {
+		var d *webhook.Dispatcher
+
+		it.Before(func() {
+			d = &webhook.Dispatcher{Endpoints: []webhook.Endpoint{{URL: "https://hooks.example.com", Secret: "test-secret"}}}
+			d.Notify(webhook.EventUserCreated, map[string]string{"user_id": "test-user-id"})
+			d.Notify(webhook.EventOrgCreated, map[string]string{"org_name": "ignition-jdoe"})
+			h = requireAdmin(webhookDeliveriesHandler(d), []string{"admin@example.com"})
+		})
+
+		it("returns the selected deliveries", func() {
+			h.ServeHTTP(w, request("admin@example.com", "event=org.created&status=pending"))
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var deliveries []webhook.Delivery
+			Expect(json.Unmarshal(w.Body.Bytes(), &deliveries)).To(Succeed())
+			Expect(deliveries).To(HaveLen(1))
+			Expect(deliveries[0].Event.Data).To(HaveKeyWithValue("org_name", "ignition-jdoe"))
+			Expect(deliveries[0].URL).To(Equal("https://hooks.example.com"))
+		})
+
+		it("returns an empty list when there are no webhooks", func() {
+			requireAdmin(webhookDeliveriesHandler(nil), []string{"admin@example.com"}).ServeHTTP(w, request("admin@example.com", ""))
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(Equal("[]\n"))
+		})
+
+		it("rejects an invalid limit", func() {
+			h.ServeHTTP(w, request("admin@example.com", "limit=-1"))
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		it("is forbidden for users that are not admins", func() {
+			h.ServeHTTP(w, request("jdoe@example.com", ""))
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+		})
+	})
+}
@@ -0,0 +1,54 @@
+// Package apierror writes the JSON error responses of the ignition API
+package apierror
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// RequestIDHeader is the header that correlates a request with the logs
+const RequestIDHeader = "X-Request-Id"
+
+// requestIDHeaders are checked in order for an existing request ID; the Cloud
+// Foundry router sets X-Vcap-Request-Id
+var requestIDHeaders = []string{RequestIDHeader, "X-Vcap-Request-Id"}
+
+// Error is the body of an error response
+type Error struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Retryable bool   `json:"retryable"`
+	RequestID string `json:"request_id"`
+}
+
+// Write responds with the status and an Error body
+func Write(w http.ResponseWriter, req *http.Request, status int, code string, message string, retryable bool) {
+	id := RequestID(req)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(RequestIDHeader, id)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Error{
+		Code:      code,
+		Message:   message,
+		Retryable: retryable,
+		RequestID: id,
+	})
+}
+
+// RequestID returns the ID of the request from its headers, or a new random ID
+// when the request has none
+func RequestID(req *http.Request) string {
+	for _, h := range requestIDHeaders {
+		if id := strings.TrimSpace(req.Header.Get(h)); id != "" {
+			return id
+		}
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,57 @@
+package apierror_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/http/apierror"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestWrite(t *testing.T) {
+	spec.Run(t, "Write", testWrite, spec.Report(report.Terminal{}))
+}
+
+func testWrite(t *testing.T, when spec.G, it spec.S) {
+	var (
+		w   *httptest.ResponseRecorder
+		req *http.Request
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "/organization", nil)
+	})
+
+	it("writes the status and a json body", func() {
+		req.Header.Set("X-Request-Id", "test-request-id")
+		apierror.Write(w, req, http.StatusServiceUnavailable, "platform_unavailable", "try again", true)
+		Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(w.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(w.Header().Get("X-Request-Id")).To(Equal("test-request-id"))
+		var body apierror.Error
+		Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+		Expect(body).To(Equal(apierror.Error{
+			Code:      "platform_unavailable",
+			Message:   "try again",
+			Retryable: true,
+			RequestID: "test-request-id",
+		}))
+	})
+
+	it("uses the cloud foundry router's request id", func() {
+		req.Header.Set("X-Vcap-Request-Id", "vcap-request-id")
+		Expect(apierror.RequestID(req)).To(Equal("vcap-request-id"))
+	})
+
+	it("generates a request id when the request has none", func() {
+		id := apierror.RequestID(req)
+		Expect(id).To(HaveLen(32))
+		Expect(apierror.RequestID(req)).NotTo(Equal(id))
+	})
+}
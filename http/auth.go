@@ -9,9 +9,13 @@ import (
 	dgoauth2 "github.com/dghubble/gologin/oauth2"
 	"github.com/dghubble/sessions"
 	"github.com/gorilla/mux"
+	"github.com/pivotalservices/ignition/audit"
+	"github.com/pivotalservices/ignition/http/events"
+	"github.com/pivotalservices/ignition/http/organization"
 	"github.com/pivotalservices/ignition/http/session"
 	"github.com/pivotalservices/ignition/uaa"
 	"github.com/pivotalservices/ignition/user"
+	"github.com/pivotalservices/ignition/webhook"
 	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
 )
@@ -21,12 +25,42 @@ func (a *API) handleAuth(r *mux.Router) {
 	if a.Domain == "localhost" {
 		stateConfig = gologin.DebugOnlyCookieConfig
 	}
-	r.Handle("/login", ensureHTTPS(dgoauth2.StateHandler(stateConfig, dgoauth2.LoginHandler(a.UserConfig, nil)))).Name("login")
-	r.Handle("/oauth2", ensureHTTPS(dgoauth2.StateHandler(stateConfig, CallbackHandler(a.UserConfig, a.Fetcher, session.IssueSession(a.SessionStore, a.UAAAPI), session.LogoutHandler(a.SessionStore))))).Name("oauth2")
-	r.Handle("/logout", ensureHTTPS(session.LogoutHandler(a.SessionStore))).Name("logout")
+	r.Handle("/login", ensureHTTPS(dgoauth2.StateHandler(stateConfig, a.ReturnTo.Remember(dgoauth2.LoginHandler(a.UserConfig, nil))))).Name("login")
+	success := recordLogin(session.IssueSession(a.SessionStore, a.UAAAPI, a.Sessions, a.ReturnTo), a.Audit)
+	failure := recordLoginFailure(session.LogoutHandler(a.SessionStore, a.Sessions, nil), a.Audit)
+	r.Handle("/oauth2", ensureHTTPS(dgoauth2.StateHandler(stateConfig, CallbackHandler(a.UserConfig, a.Fetcher, success, failure)))).Name("oauth2")
+	r.Handle("/logout", ensureHTTPS(session.LogoutHandler(a.SessionStore, a.Sessions, a.Logout))).Name("logout")
 }
 
-func ensureUser(next http.Handler, uaa uaa.API, origin string, s sessions.Store) http.Handler {
+// recordLogin audits a successful login by the user in the context
+func recordLogin(next http.Handler, r audit.Recorder) http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		if profile, err := user.ProfileFromContext(req.Context()); err == nil {
+			audit.Record(r, audit.Entry{Actor: profile.AccountName, Action: audit.ActionLogin, Target: profile.AccountName, Outcome: audit.OutcomeSuccess})
+		}
+		next.ServeHTTP(w, req)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// recordLoginFailure audits a login that failed, with the reason in the
+// context
+func recordLoginFailure(next http.Handler, r audit.Recorder) http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		e := audit.Entry{Action: audit.ActionLogin, Outcome: audit.OutcomeFailure}
+		if err := gologin.ErrorFromContext(req.Context()); err != nil {
+			e.Details = map[string]string{"error": err.Error()}
+		}
+		audit.Record(r, e)
+		next.ServeHTTP(w, req)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// ensureUser creates a UAA user in the origin for a user who does not have one
+// yet, once they have accepted the terms; until then, next is called without
+// a user ID
+func ensureUser(next http.Handler, terms organization.TermsChecker, uaa uaa.API, origin string, s sessions.Store, p events.Publisher, a audit.Recorder, n webhook.Notifier) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		userID, err := session.UserIDFromContext(r.Context())
 		if strings.TrimSpace(userID) != "" {
@@ -39,22 +73,37 @@ func ensureUser(next http.Handler, uaa uaa.API, origin string, s sessions.Store)
 				w.WriteHeader(http.StatusUnauthorized)
 				return
 			}
+			if terms != nil && !terms.Accepted(profile.AccountName) {
+				next.ServeHTTP(w, r)
+				return
+			}
 
 			userID, err = uaa.CreateUser(profile.AccountName, origin, profile.AccountName, profile.Email)
+			e := audit.Entry{Actor: profile.AccountName, ActorID: userID, Action: audit.ActionUserCreate, Target: profile.AccountName, Outcome: audit.OutcomeSuccess}
+			if err != nil || strings.TrimSpace(userID) == "" {
+				e.Outcome = audit.OutcomeFailure
+				if err != nil {
+					e.Details = map[string]string{"error": err.Error()}
+				}
+			}
+			audit.Record(a, e)
 			if err != nil || strings.TrimSpace(userID) == "" {
 				w.WriteHeader(http.StatusUnauthorized)
 				return
 			}
 			r = r.WithContext(session.ContextWithUserID(r.Context(), userID))
 			session.UpdateSessionWithUserID(w, r, s, userID)
+			p.Publish(userID, events.UserCreated, map[string]string{"account_name": profile.AccountName})
+			webhook.Notify(n, webhook.EventUserCreated, map[string]string{"user_id": userID, "account_name": profile.AccountName, "email": profile.Email, "origin": origin})
 		}
 		next.ServeHTTP(w, r)
 	}
 	return http.HandlerFunc(fn)
 }
 
-// Authorize guards access to protected resources by inspecting the user's token
-func Authorize(next http.Handler, domain string) http.Handler {
+// Authorize guards access to protected resources by inspecting the user's token;
+// when domains are given the user's email must end with one of them
+func Authorize(next http.Handler, domains ...string) http.Handler {
 	fn := func(w http.ResponseWriter, req *http.Request) {
 		token, err := session.TokenFromContext(req.Context())
 		if err != nil {
@@ -70,7 +119,7 @@ func Authorize(next http.Handler, domain string) http.Handler {
 			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 			return
 		}
-		if strings.TrimSpace(domain) != "" && !strings.HasSuffix(strings.ToLower(profile.Email), domain) {
+		if !emailInDomains(profile.Email, domains) {
 			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 			return
 		}
@@ -79,6 +128,22 @@ func Authorize(next http.Handler, domain string) http.Handler {
 	return http.HandlerFunc(fn)
 }
 
+// emailInDomains is true when there are no domains to check or the email ends
+// with any of them; emails and domains are compared without regard to case
+func emailInDomains(email string, domains []string) bool {
+	checked := false
+	for _, domain := range domains {
+		if strings.TrimSpace(domain) == "" {
+			continue
+		}
+		checked = true
+		if strings.HasSuffix(strings.ToLower(email), strings.ToLower(domain)) {
+			return true
+		}
+	}
+	return !checked
+}
+
 // CallbackHandler handles Google redirection URI requests and adds the Google
 // access token and Userinfoplus to the ctx. If authentication succeeds,
 // handling delegates to the success handler, otherwise to the failure handler.
@@ -11,12 +11,15 @@ import (
 	dgoauth2 "github.com/dghubble/gologin/oauth2"
 	"github.com/dghubble/sessions"
 	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/audit"
+	"github.com/pivotalservices/ignition/http/events"
 	"github.com/pivotalservices/ignition/http/session"
 	"github.com/pivotalservices/ignition/http/session/sessionfakes"
 	"github.com/pivotalservices/ignition/uaa/uaafakes"
 	"github.com/pivotalservices/ignition/user"
 	"github.com/pivotalservices/ignition/user/openid"
 	"github.com/pivotalservices/ignition/user/openid/openidfakes"
+	"github.com/pivotalservices/ignition/webhook"
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 	"golang.org/x/oauth2"
@@ -132,6 +135,35 @@ func testAuthorize(t *testing.T, when spec.G, it spec.S) {
 				Expect(w.Code).To(Equal(http.StatusForbidden))
 			})
 
+			it("calls the next handler if the user's email ends with any of the domains", func() {
+				called := false
+				next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					called = true
+					w.WriteHeader(http.StatusOK)
+				})
+				Authorize(next, "example.com", "example.net").ServeHTTP(w, req)
+				Expect(called).To(BeTrue())
+				Expect(w.Code).To(Equal(http.StatusOK))
+			})
+
+			it("compares the email and the domains without regard to case", func() {
+				called := false
+				next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					called = true
+					w.WriteHeader(http.StatusOK)
+				})
+				profile := &user.Profile{Email: "Alice@Example.COM", AccountName: "alice"}
+				req = req.WithContext(user.WithProfile(req.Context(), profile))
+				Authorize(next, "example.com").ServeHTTP(w, req)
+				Expect(called).To(BeTrue())
+
+				w = httptest.NewRecorder()
+				called = false
+				Authorize(next, "@EXAMPLE.com").ServeHTTP(w, req)
+				Expect(called).To(BeTrue())
+				Expect(w.Code).To(Equal(http.StatusOK))
+			})
+
 			it("calls the next handler if the user's email does end with the domain", func() {
 				called := false
 				next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -232,6 +264,9 @@ func testEnsureUser(t *testing.T, when spec.G, it spec.S) {
 		r                *http.Request
 		uaa              *uaafakes.FakeAPI
 		fakeSessionStore *sessionfakes.FakeStore
+		broker           *events.Broker
+		auditLog         *audit.Log
+		webhooks         *webhook.Dispatcher
 	)
 
 	it.Before(func() {
@@ -246,7 +281,10 @@ func testEnsureUser(t *testing.T, when spec.G, it spec.S) {
 		s := sessions.NewSession(fakeSessionStore, "ignition-test")
 		fakeSessionStore.SaveReturns(nil)
 		fakeSessionStore.GetReturns(s, nil)
-		handler = ensureUser(next, uaa, "origin", fakeSessionStore)
+		broker = events.NewBroker()
+		auditLog = audit.NewLog()
+		webhooks = &webhook.Dispatcher{Endpoints: []webhook.Endpoint{{URL: "https://hooks.example.com", Secret: "test-secret"}}}
+		handler = ensureUser(next, nil, uaa, "origin", fakeSessionStore, broker, auditLog, webhooks)
 		w = httptest.NewRecorder()
 		r = httptest.NewRequest(http.MethodGet, "/", nil)
 	})
@@ -285,11 +323,69 @@ func testEnsureUser(t *testing.T, when spec.G, it spec.S) {
 				Expect(uaa.CreateUserCallCount()).To(Equal(1))
 			})
 
+			it("publishes that the user was created", func() {
+				uaa.CreateUserReturns("test-user-id", nil)
+				handler.ServeHTTP(w, r.WithContext(ctx))
+				missed, _, cancel := broker.Subscribe("test-user-id", 0)
+				defer cancel()
+				Expect(missed).To(HaveLen(1))
+				Expect(missed[0].Type).To(Equal(events.UserCreated))
+			})
+
+			it("notifies the webhooks that the user was created", func() {
+				uaa.CreateUserReturns("test-user-id", nil)
+				handler.ServeHTTP(w, r.WithContext(ctx))
+				deliveries := webhooks.History(webhook.Filter{Event: webhook.EventUserCreated})
+				Expect(deliveries).To(HaveLen(1))
+				Expect(deliveries[0].Event.Data).To(HaveKeyWithValue("user_id", "test-user-id"))
+				Expect(deliveries[0].Event.Data).To(HaveKeyWithValue("origin", "origin"))
+			})
+
+			it("does not create the user until they accept the terms", func() {
+				accepted := acceptedTerms{}
+				handler = ensureUser(next, accepted, uaa, "origin", fakeSessionStore, broker, auditLog, webhooks)
+				handler.ServeHTTP(w, r.WithContext(ctx))
+				Expect(called).To(BeTrue())
+				Expect(uaa.CreateUserCallCount()).To(Equal(0))
+				Expect(webhooks.History(webhook.Filter{Event: webhook.EventUserCreated})).To(BeEmpty())
+
+				accepted["testaccount"] = true
+				uaa.CreateUserReturns("test-user-id", nil)
+				handler.ServeHTTP(httptest.NewRecorder(), r.WithContext(ctx))
+				Expect(uaa.CreateUserCallCount()).To(Equal(1))
+			})
+
 			it("is unauthorized if the user cannot be created", func() {
 				uaa.CreateUserReturns("", errors.New("test error"))
 				handler.ServeHTTP(w, r.WithContext(ctx))
 				Expect(w.Code).To(Equal(http.StatusUnauthorized))
 			})
+
+			it("audits the creation of the user", func() {
+				uaa.CreateUserReturnsOnCall(0, "test-user-id", nil)
+				uaa.CreateUserReturnsOnCall(1, "", errors.New("test error"))
+				handler.ServeHTTP(w, r.WithContext(ctx))
+				handler.ServeHTTP(httptest.NewRecorder(), r.WithContext(ctx))
+				entries, err := auditLog.Query(audit.Filter{Action: audit.ActionUserCreate})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(entries).To(HaveLen(2))
+				Expect(entries[0].Outcome).To(Equal(audit.OutcomeFailure))
+				Expect(entries[0].Details["error"]).To(Equal("test error"))
+				Expect(entries[1].ActorID).To(Equal("test-user-id"))
+				Expect(entries[1].Target).To(Equal("testaccount"))
+			})
 		})
 	})
 }
+
+// acceptedTerms has terms of use that only the account names in it have
+// accepted
+type acceptedTerms map[string]bool
+
+func (t acceptedTerms) Accepted(accountName string) bool {
+	return t[accountName]
+}
+
+func (t acceptedTerms) CurrentVersion() string {
+	return "v1"
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Branding is the operator's content for the web app
+type Branding struct {
+	Title        string         `json:"title"`
+	LogoURL      string         `json:"logo_url"`
+	Support      Support        `json:"support"`
+	Steps        []BrandingStep `json:"steps"`
+	CLIDownloads []Link         `json:"cli_downloads"`
+}
+
+// Support is who users should contact for help
+type Support struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	URL   string `json:"url"`
+}
+
+// BrandingStep is one of the getting started steps on the landing page,
+// displayed as "Text LinkText Suffix" with LinkText linking to LinkURL
+type BrandingStep struct {
+	Text     string `json:"text"`
+	LinkText string `json:"link_text"`
+	LinkURL  string `json:"link_url"`
+	Suffix   string `json:"suffix"`
+}
+
+// Link is a named URL
+type Link struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// DefaultBranding is the content used for any branding that is not configured
+func DefaultBranding() Branding {
+	return Branding{
+		Title: "Pivotal Ignition",
+		Steps: []BrandingStep{
+			{Text: "Get the", LinkText: "Cloud Foundry CLI", LinkURL: "https://docs.pivotal.io/pivotalcf/latest/cf-cli/", Suffix: "from Pivotal"},
+			{Text: "Download the", LinkText: "sample app", LinkURL: "https://github.com/cloudfoundry-samples/spring-music", Suffix: "from Github"},
+			{Text: "Learn to", LinkText: "deploy an app", LinkURL: "https://docs.pivotal.io/pivotalcf/latest/devguide/deploy-apps/deploy-app.html"},
+		},
+		CLIDownloads: []Link{},
+	}
+}
+
+// webConfig is the configuration of the web app
+type webConfig struct {
+	Branding
+	Foundations []string `json:"foundations"`
+}
+
+// branding returns the API's Branding, with the defaults for anything that is
+// not configured
+func (a *API) branding() Branding {
+	b := a.Branding
+	d := DefaultBranding()
+	if strings.TrimSpace(b.Title) == "" {
+		b.Title = d.Title
+	}
+	if len(b.Steps) == 0 {
+		b.Steps = d.Steps
+	}
+	if b.CLIDownloads == nil {
+		b.CLIDownloads = d.CLIDownloads
+	}
+	return b
+}
+
+// configHandler serves the configuration of the web app
+func (a *API) configHandler() http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		c := webConfig{
+			Branding:    a.branding(),
+			Foundations: a.FoundationNames,
+		}
+		if c.Foundations == nil {
+			c.Foundations = []string{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", noCache)
+		json.NewEncoder(w).Encode(c)
+	}
+	return http.HandlerFunc(fn)
+}
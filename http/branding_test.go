@@ -0,0 +1,107 @@
+package http
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestBranding(t *testing.T) {
+	spec.Run(t, "branding", testBranding, spec.Report(report.Terminal{}))
+}
+
+func testBranding(t *testing.T, when spec.G, it spec.S) {
+	var api *API
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		api = &API{}
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Host = "localhost:3000"
+		w := httptest.NewRecorder()
+		api.createRouter().ServeHTTP(w, req)
+		return w
+	}
+
+	getConfig := func() webConfig {
+		w := get("/config")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(w.Header().Get("Cache-Control")).To(Equal("no-cache"))
+		var c webConfig
+		Expect(json.Unmarshal(w.Body.Bytes(), &c)).To(Succeed())
+		return c
+	}
+
+	it("serves the default content when nothing is configured", func() {
+		c := getConfig()
+		Expect(c.Branding).To(Equal(DefaultBranding()))
+		Expect(c.Foundations).To(BeEmpty())
+	})
+
+	it("serves the configured content", func() {
+		api.Branding = Branding{
+			Title:        "Acme Cloud",
+			LogoURL:      "/branding/logo.svg",
+			Support:      Support{Name: "Platform Team", Email: "platform@example.com"},
+			Steps:        []BrandingStep{{Text: "Read the", LinkText: "handbook", LinkURL: "https://handbook.example.com"}},
+			CLIDownloads: []Link{{Name: "Linux", URL: "https://cli.example.com/linux"}},
+		}
+		api.FoundationNames = []string{"east", "west"}
+		c := getConfig()
+		Expect(c.Branding).To(Equal(api.Branding))
+		Expect(c.Foundations).To(Equal([]string{"east", "west"}))
+	})
+
+	it("keeps the default steps and title when only the logo is configured", func() {
+		api.Branding = Branding{LogoURL: "https://cdn.example.com/logo.png"}
+		c := getConfig()
+		Expect(c.Title).To(Equal("Pivotal Ignition"))
+		Expect(c.LogoURL).To(Equal("https://cdn.example.com/logo.png"))
+		Expect(c.Steps).To(Equal(DefaultBranding().Steps))
+	})
+
+	when("a branding directory is configured", func() {
+		var dir string
+
+		it.Before(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "ignition-branding")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "logo.svg"), []byte("<svg></svg>"), 0644)).To(Succeed())
+			api.BrandingDir = dir
+		})
+
+		it.After(func() {
+			os.RemoveAll(dir)
+		})
+
+		it("serves the files so that they are revalidated", func() {
+			w := get("/branding/logo.svg")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(Equal("<svg></svg>"))
+			Expect(w.Header().Get("Content-Type")).To(Equal("image/svg+xml"))
+			Expect(w.Header().Get("Cache-Control")).To(Equal("no-cache"))
+			Expect(w.Header().Get("ETag")).NotTo(BeEmpty())
+		})
+
+		it("does not serve missing files", func() {
+			Expect(get("/branding/missing.png").Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	it("does not serve branding files when no directory is configured", func() {
+		Expect(get("/branding/logo.svg").Code).To(Equal(http.StatusNotFound))
+	})
+}
@@ -0,0 +1,35 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/handlers"
+	"github.com/pivotalservices/ignition/http/session"
+)
+
+// DefaultCORSMethods are the methods cross-origin requests may use when
+// API.CORSAllowedMethods is not set
+var DefaultCORSMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodDelete}
+
+// cors allows cross-origin requests to next from the CORSAllowedOrigins, which
+// may send and read the X-CSRF-Token header. No cross-origin requests are
+// allowed when CORSAllowedOrigins is empty
+func (a *API) cors(next http.Handler) http.Handler {
+	if len(a.CORSAllowedOrigins) == 0 {
+		return next
+	}
+	methods := a.CORSAllowedMethods
+	if len(methods) == 0 {
+		methods = DefaultCORSMethods
+	}
+	opts := []handlers.CORSOption{
+		handlers.AllowedOrigins(a.CORSAllowedOrigins),
+		handlers.AllowedMethods(methods),
+		handlers.AllowedHeaders(append([]string{session.CSRFHeader}, a.CORSAllowedHeaders...)),
+		handlers.ExposedHeaders([]string{session.CSRFHeader}),
+	}
+	if a.CORSAllowCredentials {
+		opts = append(opts, handlers.AllowCredentials())
+	}
+	return handlers.CORS(opts...)(next)
+}
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestCORS(t *testing.T) {
+	spec.Run(t, "cors", testCORS, spec.Report(report.Terminal{}))
+}
+
+func testCORS(t *testing.T, when spec.G, it spec.S) {
+	var (
+		api    *API
+		called bool
+		next   http.Handler
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		called = false
+		next = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			w.WriteHeader(http.StatusOK)
+		})
+		api = &API{
+			CORSAllowedOrigins: []string{"https://portal.example.com"},
+		}
+	})
+
+	preflight := func(h http.Handler, origin string, method string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, "/organization", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", method)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	when("no origins are allowed", func() {
+		it.Before(func() {
+			api.CORSAllowedOrigins = nil
+		})
+
+		it("does not add cors headers", func() {
+			req := httptest.NewRequest(http.MethodGet, "/organization", nil)
+			req.Header.Set("Origin", "https://evil.example.com")
+			w := httptest.NewRecorder()
+			api.cors(next).ServeHTTP(w, req)
+			Expect(called).To(BeTrue())
+			Expect(w.Header().Get("Access-Control-Allow-Origin")).To(BeEmpty())
+		})
+	})
+
+	it("answers a preflight request from an allowed origin", func() {
+		w := preflight(api.cors(next), "https://portal.example.com", http.MethodPost)
+		Expect(called).To(BeFalse())
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Access-Control-Allow-Origin")).To(Equal("https://portal.example.com"))
+		Expect(w.Header().Get("Access-Control-Allow-Credentials")).To(BeEmpty())
+	})
+
+	it("does not allow a preflight request from another origin", func() {
+		w := preflight(api.cors(next), "https://evil.example.com", http.MethodPost)
+		Expect(called).To(BeFalse())
+		Expect(w.Header().Get("Access-Control-Allow-Origin")).To(BeEmpty())
+	})
+
+	it("allows DELETE by default", func() {
+		w := preflight(api.cors(next), "https://portal.example.com", http.MethodDelete)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Access-Control-Allow-Origin")).To(Equal("https://portal.example.com"))
+	})
+
+	it("rejects a preflight request for a method that is not allowed", func() {
+		w := preflight(api.cors(next), "https://portal.example.com", http.MethodPut)
+		Expect(w.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(w.Header().Get("Access-Control-Allow-Origin")).To(BeEmpty())
+	})
+
+	it("allows the configured methods", func() {
+		api.CORSAllowedMethods = []string{http.MethodPut}
+		w := preflight(api.cors(next), "https://portal.example.com", http.MethodPut)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Access-Control-Allow-Methods")).To(Equal(http.MethodPut))
+	})
+
+	it("allows the configured headers", func() {
+		api.CORSAllowedHeaders = []string{"Content-Type"}
+		req := httptest.NewRequest(http.MethodOptions, "/organization", nil)
+		req.Header.Set("Origin", "https://portal.example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "Content-Type")
+		w := httptest.NewRecorder()
+		api.cors(next).ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Access-Control-Allow-Headers")).To(Equal("Content-Type"))
+	})
+
+	it("allows credentials when configured", func() {
+		api.CORSAllowCredentials = true
+		w := preflight(api.cors(next), "https://portal.example.com", http.MethodPost)
+		Expect(w.Header().Get("Access-Control-Allow-Credentials")).To(Equal("true"))
+	})
+
+	it("adds cors headers to requests from an allowed origin", func() {
+		req := httptest.NewRequest(http.MethodGet, "/organization", nil)
+		req.Header.Set("Origin", "https://portal.example.com")
+		w := httptest.NewRecorder()
+		api.cors(next).ServeHTTP(w, req)
+		Expect(called).To(BeTrue())
+		Expect(w.Header().Get("Access-Control-Allow-Origin")).To(Equal("https://portal.example.com"))
+	})
+
+	when("routing", func() {
+		it("applies cors to the api routes", func() {
+			r := api.createRouter()
+			for _, path := range []string{"/profile", "/organization"} {
+				req := httptest.NewRequest(http.MethodOptions, path, nil)
+				req.Header.Set("Origin", "https://portal.example.com")
+				req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				Expect(w.Header().Get("Access-Control-Allow-Origin")).To(Equal("https://portal.example.com"), path)
+			}
+		})
+
+		it("answers preflight requests for the routes that change state", func() {
+			r := api.createRouter()
+			for path, method := range map[string]string{
+				"/organization":                 http.MethodPost,
+				"/organization/members":         http.MethodPost,
+				"/organization/members/user-id": http.MethodDelete,
+				"/terms/accept":                 http.MethodPost,
+				"/sessions/session-id":          http.MethodDelete,
+				"/admin/users/someone/sessions": http.MethodDelete,
+			} {
+				req := httptest.NewRequest(http.MethodOptions, path, nil)
+				req.Header.Set("Origin", "https://portal.example.com")
+				req.Header.Set("Access-Control-Request-Method", method)
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK), path)
+				Expect(w.Header().Get("Access-Control-Allow-Origin")).To(Equal("https://portal.example.com"), path)
+			}
+		})
+
+		it("does not apply cors to other routes", func() {
+			r := api.createRouter()
+			req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+			req.Header.Set("Origin", "https://portal.example.com")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Access-Control-Allow-Origin")).To(BeEmpty())
+		})
+	})
+}
@@ -0,0 +1,203 @@
+// Package events streams each user's onboarding progress to the browser with
+// Server-Sent Events
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotalservices/ignition/http/session"
+	"github.com/pivotalservices/ignition/user"
+)
+
+// The onboarding state transitions
+const (
+	UserCreated  = "user_created"
+	OrgCreated   = "org_created"
+	RolesGranted = "roles_granted"
+	SpaceReady   = "space_ready"
+	// ServicesReady is published when the starter services have been created
+	ServicesReady = "services_ready"
+	// SampleAppReady is published when the sample app has been pushed
+	SampleAppReady = "sample_app_ready"
+	// ProvisioningFailed is published when the user's org cannot be created
+	ProvisioningFailed = "provisioning_failed"
+)
+
+// DefaultHistory is the number of events kept for each user, so that a
+// browser that connects late (or reconnects) sees the events it missed
+const DefaultHistory = 16
+
+// pendingRetry is how long a browser waits to reconnect when the user does not
+// have a UAA user yet; it has one once their org is requested
+const pendingRetry = 2 * time.Second
+
+// DefaultHeartbeat is how often a comment is sent on an idle stream, so that
+// routers do not close it
+const DefaultHeartbeat = 15 * time.Second
+
+// Event is an onboarding state transition
+type Event struct {
+	ID   int64       `json:"id"`
+	Type string      `json:"type"`
+	Data interface{} `json:"data,omitempty"`
+	Time time.Time   `json:"time"`
+}
+
+// Publisher publishes a user's events
+type Publisher interface {
+	Publish(userID string, eventType string, data interface{})
+}
+
+// Broker delivers each user's events to the user's subscribers
+type Broker struct {
+	History   int
+	Heartbeat time.Duration
+
+	mu          sync.Mutex
+	lastID      int64
+	history     map[string][]Event
+	subscribers map[string]map[chan Event]bool
+	now         func() time.Time
+	closeOnce   sync.Once
+	done        chan struct{}
+}
+
+// NewBroker returns a Broker with the default history and heartbeat
+func NewBroker() *Broker {
+	return &Broker{
+		History:     DefaultHistory,
+		Heartbeat:   DefaultHeartbeat,
+		history:     map[string][]Event{},
+		subscribers: map[string]map[chan Event]bool{},
+		now:         time.Now,
+		done:        make(chan struct{}),
+	}
+}
+
+// Close ends every stream, so that a server can shut down without waiting for
+// the streams to time out
+func (b *Broker) Close() {
+	b.closeOnce.Do(func() {
+		close(b.done)
+	})
+}
+
+// Publish sends an event to the user's subscribers, and keeps it in the user's
+// history
+func (b *Broker) Publish(userID string, eventType string, data interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastID++
+	e := Event{ID: b.lastID, Type: eventType, Data: data, Time: b.now()}
+	h := append(b.history[userID], e)
+	if len(h) > b.History {
+		h = h[len(h)-b.History:]
+	}
+	b.history[userID] = h
+	for c := range b.subscribers[userID] {
+		select {
+		case c <- e:
+		default:
+			// a subscriber that cannot keep up catches up from the history
+			// when it reconnects
+		}
+	}
+}
+
+// Subscribe returns the user's events after lastID from the history, and a
+// channel of the events that follow; cancel must be called to unsubscribe
+func (b *Broker) Subscribe(userID string, lastID int64) (missed []Event, events <-chan Event, cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, e := range b.history[userID] {
+		if e.ID > lastID {
+			missed = append(missed, e)
+		}
+	}
+	c := make(chan Event, b.History)
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = map[chan Event]bool{}
+	}
+	b.subscribers[userID][c] = true
+	cancel = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers[userID], c)
+		if len(b.subscribers[userID]) == 0 {
+			delete(b.subscribers, userID)
+		}
+	}
+	return missed, c, cancel
+}
+
+// Handler streams the events of the user in the request context
+func (b *Broker) Handler() http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+		userID, err := session.UserIDFromContext(req.Context())
+		if err != nil || strings.TrimSpace(userID) == "" {
+			if profile, err := user.ProfileFromContext(req.Context()); err != nil || profile == nil {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			// the user has no UAA user, and so no events, until their org is
+			// requested; the browser reconnects after the retry to pick them up
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, "retry: %d\n\n", pendingRetry/time.Millisecond)
+			flusher.Flush()
+			return
+		}
+
+		lastID, _ := strconv.ParseInt(req.Header.Get("Last-Event-ID"), 10, 64)
+		missed, events, cancel := b.Subscribe(userID, lastID)
+		defer cancel()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		for _, e := range missed {
+			write(w, e)
+		}
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(b.Heartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-req.Context().Done():
+				return
+			case <-b.done:
+				return
+			case e := <-events:
+				write(w, e)
+				flusher.Flush()
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+				flusher.Flush()
+			}
+		}
+	}
+	return http.HandlerFunc(fn)
+}
+
+func write(w http.ResponseWriter, e Event) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+}
@@ -0,0 +1,172 @@
+package events_test
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/http/events"
+	"github.com/pivotalservices/ignition/http/session"
+	"github.com/pivotalservices/ignition/user"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestBroker(t *testing.T) {
+	spec.Run(t, "Broker", testBroker, spec.Report(report.Terminal{}))
+}
+
+func testBroker(t *testing.T, when spec.G, it spec.S) {
+	var b *events.Broker
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		b = events.NewBroker()
+	})
+
+	it("delivers events to the user's subscribers only", func() {
+		_, mine, cancel := b.Subscribe("user-1", 0)
+		defer cancel()
+		_, theirs, cancel2 := b.Subscribe("user-2", 0)
+		defer cancel2()
+
+		b.Publish("user-1", events.OrgCreated, nil)
+		var e events.Event
+		Eventually(mine).Should(Receive(&e))
+		Expect(e.Type).To(Equal(events.OrgCreated))
+		Consistently(theirs, 50*time.Millisecond).ShouldNot(Receive())
+	})
+
+	it("replays the events after the last event id", func() {
+		b.Publish("user-1", events.UserCreated, nil)
+		b.Publish("user-1", events.OrgCreated, nil)
+		b.Publish("user-1", events.RolesGranted, nil)
+
+		missed, _, cancel := b.Subscribe("user-1", 0)
+		cancel()
+		Expect(missed).To(HaveLen(3))
+
+		missed, _, cancel = b.Subscribe("user-1", missed[0].ID)
+		cancel()
+		Expect(missed).To(HaveLen(2))
+		Expect(missed[0].Type).To(Equal(events.OrgCreated))
+	})
+
+	it("keeps a limited history", func() {
+		b.History = 2
+		b.Publish("user-1", events.UserCreated, nil)
+		b.Publish("user-1", events.OrgCreated, nil)
+		b.Publish("user-1", events.RolesGranted, nil)
+		missed, _, cancel := b.Subscribe("user-1", 0)
+		cancel()
+		Expect(missed).To(HaveLen(2))
+		Expect(missed[0].Type).To(Equal(events.OrgCreated))
+	})
+
+	when("streaming", func() {
+		var (
+			s       *httptest.Server
+			userID  string
+			profile *user.Profile
+		)
+
+		it.Before(func() {
+			userID = "user-1"
+			profile = &user.Profile{AccountName: "test"}
+			h := b.Handler()
+			s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx := session.ContextWithUserID(r.Context(), userID)
+				if profile != nil {
+					ctx = user.WithProfile(ctx, profile)
+				}
+				h.ServeHTTP(w, r.WithContext(ctx))
+			}))
+		})
+
+		it.After(func() {
+			b.Close()
+			s.Close()
+		})
+
+		connect := func(lastEventID string) (*http.Response, *bufio.Reader) {
+			req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+			Expect(err).NotTo(HaveOccurred())
+			if lastEventID != "" {
+				req.Header.Set("Last-Event-ID", lastEventID)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			return resp, bufio.NewReader(resp.Body)
+		}
+
+		readEvent := func(r *bufio.Reader) []string {
+			var lines []string
+			for {
+				line, err := r.ReadString('\n')
+				Expect(err).NotTo(HaveOccurred())
+				line = strings.TrimSuffix(line, "\n")
+				if line == "" {
+					return lines
+				}
+				lines = append(lines, line)
+			}
+		}
+
+		it("streams the history and then new events", func() {
+			b.Publish("user-1", events.UserCreated, map[string]string{"account_name": "test"})
+			resp, r := connect("")
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+
+			lines := readEvent(r)
+			Expect(lines[0]).To(Equal("id: 1"))
+			Expect(lines[1]).To(Equal("event: user_created"))
+			Expect(lines[2]).To(HavePrefix("data: "))
+			Expect(lines[2]).To(ContainSubstring(`"account_name":"test"`))
+
+			b.Publish("user-1", events.SpaceReady, nil)
+			lines = readEvent(r)
+			Expect(lines[1]).To(Equal("event: space_ready"))
+		})
+
+		it("resumes from the Last-Event-ID", func() {
+			b.Publish("user-1", events.UserCreated, nil)
+			b.Publish("user-1", events.OrgCreated, nil)
+			resp, r := connect("1")
+			defer resp.Body.Close()
+			lines := readEvent(r)
+			Expect(lines[0]).To(Equal("id: 2"))
+		})
+
+		it("ends the stream when the broker is closed", func() {
+			resp, r := connect("")
+			defer resp.Body.Close()
+			b.Close()
+			_, err := r.ReadString('\n')
+			Expect(err).To(HaveOccurred())
+		})
+
+		it("is unauthorized without a user", func() {
+			userID = ""
+			profile = nil
+			resp, _ := connect("")
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+
+		it("asks a user without a UAA user yet to reconnect later", func() {
+			userID = ""
+			resp, r := connect("")
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(readEvent(r)).To(Equal([]string{"retry: 2000"}))
+			_, err := r.ReadString('\n')
+			Expect(err).To(HaveOccurred())
+		})
+	})
+}
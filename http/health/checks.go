@@ -0,0 +1,43 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// JWKSChecker checks that the JSON Web Key Set used to verify ID tokens can be
+// fetched and contains at least one key
+func JWKSChecker(jwksURL string, client *http.Client) Checker {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	fn := func(ctx context.Context) error {
+		req, err := http.NewRequest(http.MethodGet, jwksURL, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			return errors.Wrap(err, "could not fetch the jwks")
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return errors.Errorf("fetching the jwks returned status %d", resp.StatusCode)
+		}
+		var keySet struct {
+			Keys []json.RawMessage `json:"keys"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&keySet)
+		if err != nil {
+			return errors.Wrap(err, "could not decode the jwks")
+		}
+		if len(keySet.Keys) == 0 {
+			return errors.New("the jwks contains no keys")
+		}
+		return nil
+	}
+	return CheckerFunc(fn)
+}
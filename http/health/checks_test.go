@@ -0,0 +1,55 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestJWKSChecker(t *testing.T) {
+	spec.Run(t, "JWKSChecker", func(t *testing.T, when spec.G, it spec.S) {
+		var (
+			s      *httptest.Server
+			status int
+			body   string
+		)
+
+		it.Before(func() {
+			RegisterTestingT(t)
+			status = http.StatusOK
+			body = `{"keys":[{"kty":"RSA","kid":"key-1"}]}`
+			s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+				w.Write([]byte(body))
+			}))
+		})
+
+		it.After(func() {
+			s.Close()
+		})
+
+		it("is healthy when the key set has keys", func() {
+			Expect(JWKSChecker(s.URL, nil).Check(context.Background())).To(Succeed())
+		})
+
+		it("fails when the key set is empty", func() {
+			body = `{"keys":[]}`
+			Expect(JWKSChecker(s.URL, nil).Check(context.Background())).To(MatchError("the jwks contains no keys"))
+		})
+
+		it("fails when the key set cannot be fetched", func() {
+			status = http.StatusInternalServerError
+			Expect(JWKSChecker(s.URL, nil).Check(context.Background())).To(HaveOccurred())
+		})
+
+		it("fails when the key set is not json", func() {
+			body = "<html></html>"
+			Expect(JWKSChecker(s.URL, nil).Check(context.Background())).To(HaveOccurred())
+		})
+	}, spec.Report(report.Terminal{}))
+}
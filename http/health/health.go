@@ -0,0 +1,195 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Checker checks that a dependency is available
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc is a function that can be used as a Checker
+type CheckerFunc func(ctx context.Context) error
+
+// Check calls f(ctx)
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Check is a named dependency check
+type Check struct {
+	Name    string
+	Checker Checker
+}
+
+// Status is the result of a single dependency check
+type Status struct {
+	Name      string    `json:"name"`
+	Healthy   bool      `json:"healthy"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+	Duration  string    `json:"duration"`
+}
+
+// Report is the result of all dependency checks
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Status `json:"checks"`
+}
+
+// LivenessHandler reports that the process is able to serve requests
+func LivenessHandler() http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(Report{Status: "ok", Checks: []Status{}})
+	}
+	return http.HandlerFunc(fn)
+}
+
+// Readiness runs dependency checks, caching each result for the TTL so that
+// frequent probes do not hammer the dependencies
+type Readiness struct {
+	Checks  []Check
+	TTL     time.Duration
+	Timeout time.Duration
+
+	mu      sync.Mutex
+	results map[string]Status
+	// running holds the checks that are running, closed when they finish
+	running map[string]chan struct{}
+	now     func() time.Time
+}
+
+// NewReadiness returns a Readiness for the checks
+func NewReadiness(ttl time.Duration, timeout time.Duration, checks ...Check) *Readiness {
+	return &Readiness{
+		Checks:  checks,
+		TTL:     ttl,
+		Timeout: timeout,
+	}
+}
+
+func (r *Readiness) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+// Report runs every check whose cached result has expired, concurrently, and
+// returns the status of all checks. A check that another request is already
+// running is not run again: its previous result is reported, or, when there
+// is none, the result of the running check. The checks run without holding
+// the lock, and are not cancelled with ctx since their results are shared, so
+// a dependency that hangs does not hold up the other requests
+func (r *Readiness) Report(ctx context.Context) Report {
+	r.mu.Lock()
+	if r.results == nil {
+		r.results = map[string]Status{}
+		r.running = map[string]chan struct{}{}
+	}
+	now := r.clock()
+	statuses := make([]Status, len(r.Checks))
+	waits := make([]chan struct{}, len(r.Checks))
+	for i, c := range r.Checks {
+		cached, ok := r.results[c.Name]
+		if ok && now.Sub(cached.CheckedAt) < r.TTL {
+			statuses[i] = cached
+			continue
+		}
+		if done, running := r.running[c.Name]; running {
+			if ok {
+				statuses[i] = cached
+			} else {
+				waits[i] = done
+			}
+			continue
+		}
+		done := make(chan struct{})
+		r.running[c.Name] = done
+		waits[i] = done
+		go func(c Check) {
+			s := r.run(context.Background(), c)
+			r.mu.Lock()
+			r.results[c.Name] = s
+			delete(r.running, c.Name)
+			r.mu.Unlock()
+			close(done)
+		}(c)
+	}
+	r.mu.Unlock()
+
+	for i, done := range waits {
+		if done == nil {
+			continue
+		}
+		select {
+		case <-done:
+			r.mu.Lock()
+			statuses[i] = r.results[r.Checks[i].Name]
+			r.mu.Unlock()
+		case <-ctx.Done():
+			statuses[i] = Status{Name: r.Checks[i].Name, Error: ctx.Err().Error(), CheckedAt: r.clock(), Duration: "0s"}
+		}
+	}
+
+	report := Report{Status: "ok", Checks: statuses}
+	for _, s := range statuses {
+		if !s.Healthy {
+			report.Status = "unavailable"
+		}
+	}
+	return report
+}
+
+// run runs the check, giving up on it after the Timeout even when it does not
+// stop when its context is done
+func (r *Readiness) run(ctx context.Context, c Check) Status {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	start := r.clock()
+	result := make(chan error, 1)
+	go func() {
+		result <- c.Checker.Check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	s := Status{
+		Name:      c.Name,
+		Healthy:   err == nil,
+		CheckedAt: start,
+		Duration:  r.clock().Sub(start).String(),
+	}
+	if err != nil {
+		s.Error = err.Error()
+	}
+	return s
+}
+
+// ServeHTTP responds with the readiness report; the status code is 503 when
+// any dependency is unavailable
+func (r *Readiness) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	report := r.Report(req.Context())
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	json.NewEncoder(w).Encode(report)
+}
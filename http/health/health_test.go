@@ -0,0 +1,162 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestLivenessHandler(t *testing.T) {
+	spec.Run(t, "LivenessHandler", func(t *testing.T, when spec.G, it spec.S) {
+		it.Before(func() {
+			RegisterTestingT(t)
+		})
+
+		it("is ok", func() {
+			w := httptest.NewRecorder()
+			LivenessHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(ContainSubstring(`"status":"ok"`))
+		})
+	}, spec.Report(report.Terminal{}))
+}
+
+func TestReadiness(t *testing.T) {
+	spec.Run(t, "Readiness", testReadiness, spec.Report(report.Terminal{}))
+}
+
+func testReadiness(t *testing.T, when spec.G, it spec.S) {
+	var (
+		r        *Readiness
+		now      time.Time
+		uaaCalls int
+		ccCalls  int
+		ccErr    error
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		now = time.Date(2018, 4, 1, 12, 0, 0, 0, time.UTC)
+		uaaCalls, ccCalls, ccErr = 0, 0, nil
+		r = NewReadiness(10*time.Second, time.Second,
+			Check{Name: "uaa", Checker: CheckerFunc(func(ctx context.Context) error {
+				uaaCalls++
+				return nil
+			})},
+			Check{Name: "cloud_controller", Checker: CheckerFunc(func(ctx context.Context) error {
+				ccCalls++
+				return ccErr
+			})},
+		)
+		r.now = func() time.Time { return now }
+	})
+
+	serve := func() (*httptest.ResponseRecorder, Report) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var result Report
+		Expect(json.Unmarshal(w.Body.Bytes(), &result)).To(Succeed())
+		return w, result
+	}
+
+	it("is ok when every dependency is healthy", func() {
+		w, result := serve()
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(result.Status).To(Equal("ok"))
+		Expect(result.Checks).To(HaveLen(2))
+		Expect(result.Checks[0].Name).To(Equal("uaa"))
+		Expect(result.Checks[0].Healthy).To(BeTrue())
+	})
+
+	it("is unavailable and reports the failing dependency", func() {
+		ccErr = errors.New("connection refused")
+		w, result := serve()
+		Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(result.Status).To(Equal("unavailable"))
+		Expect(result.Checks[0].Healthy).To(BeTrue())
+		Expect(result.Checks[1].Healthy).To(BeFalse())
+		Expect(result.Checks[1].Error).To(Equal("connection refused"))
+	})
+
+	it("caches results until the ttl expires", func() {
+		serve()
+		serve()
+		Expect(uaaCalls).To(Equal(1))
+		Expect(ccCalls).To(Equal(1))
+
+		now = now.Add(11 * time.Second)
+		serve()
+		Expect(uaaCalls).To(Equal(2))
+		Expect(ccCalls).To(Equal(2))
+	})
+
+	it("caches failures too", func() {
+		ccErr = errors.New("connection refused")
+		serve()
+		ccErr = nil
+		w, _ := serve()
+		Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(ccCalls).To(Equal(1))
+	})
+
+	it("cancels checks that exceed the timeout", func() {
+		r = NewReadiness(time.Second, 10*time.Millisecond, Check{Name: "slow", Checker: CheckerFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})})
+		w, result := serve()
+		Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(result.Checks[0].Error).To(ContainSubstring("deadline exceeded"))
+	})
+
+	it("gives up on checks that do not stop when the timeout expires", func() {
+		release := make(chan struct{})
+		defer close(release)
+		r = NewReadiness(time.Second, 10*time.Millisecond, Check{Name: "hung", Checker: CheckerFunc(func(ctx context.Context) error {
+			<-release
+			return nil
+		})})
+		w, result := serve()
+		Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(result.Checks[0].Error).To(ContainSubstring("deadline exceeded"))
+	})
+
+	it("reports the previous result while a check is running, instead of waiting for it", func() {
+		release := make(chan struct{})
+		calls := make(chan struct{}, 2)
+		slow := false
+		r = NewReadiness(10*time.Second, time.Minute, Check{Name: "uaa", Checker: CheckerFunc(func(ctx context.Context) error {
+			calls <- struct{}{}
+			if slow {
+				<-release
+			}
+			return nil
+		})})
+		r.now = func() time.Time { return now }
+		serve()
+		<-calls
+
+		slow = true
+		now = now.Add(11 * time.Second)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			r.Report(context.Background())
+		}()
+		<-calls
+
+		_, result := serve()
+		Expect(result.Status).To(Equal("ok"))
+		close(release)
+		Eventually(done).Should(BeClosed())
+	})
+}
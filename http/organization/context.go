@@ -3,11 +3,14 @@ package organization
 import (
 	"context"
 	"errors"
+	"strings"
 
 	"github.com/pivotalservices/ignition/http/session"
 	"github.com/pivotalservices/ignition/user"
 )
 
+// userInfoFromContext returns the user's UAA user ID, which is empty until a
+// UAA user has been created for them, and their account name
 func userInfoFromContext(ctx context.Context) (userID string, accountName string, err error) {
 	var profile *user.Profile
 	profile, err = user.ProfileFromContext(ctx)
@@ -17,9 +20,26 @@ func userInfoFromContext(ctx context.Context) (userID string, accountName string
 	if profile == nil {
 		return "", "", errors.New("no profile was found")
 	}
-	userID, err = session.UserIDFromContext(ctx)
-	if err != nil {
-		return "", "", err
-	}
+	userID, _ = session.UserIDFromContext(ctx)
 	return userID, profile.AccountName, nil
 }
+
+type key int
+
+const quotaIDKey key = iota
+
+// ContextWithQuotaID returns a copy of ctx that stores the quota ID to use for
+// the user's organization
+func ContextWithQuotaID(ctx context.Context, quotaID string) context.Context {
+	return context.WithValue(ctx, quotaIDKey, quotaID)
+}
+
+// quotaIDFromContext returns the quota ID from the ctx, or the fallback when
+// the ctx has none
+func quotaIDFromContext(ctx context.Context, fallback string) string {
+	quotaID, ok := ctx.Value(quotaIDKey).(string)
+	if !ok || strings.TrimSpace(quotaID) == "" {
+		return fallback
+	}
+	return quotaID
+}
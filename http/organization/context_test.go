@@ -47,9 +47,11 @@ func testUserInfoFromContext(t *testing.T, when spec.G, it spec.S) {
 		})
 
 		when("there is no user id", func() {
-			it("errors", func() {
-				_, _, err := userInfoFromContext(ctx)
-				Expect(err).To(HaveOccurred())
+			it("returns the account name without a user id", func() {
+				userID, accountName, err := userInfoFromContext(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(userID).To(BeEmpty())
+				Expect(accountName).To(Equal("test-user"))
 			})
 		})
 
@@ -67,3 +69,20 @@ func testUserInfoFromContext(t *testing.T, when spec.G, it spec.S) {
 		})
 	})
 }
+
+func TestQuotaIDFromContext(t *testing.T) {
+	spec.Run(t, "QuotaIDFromContext", func(t *testing.T, when spec.G, it spec.S) {
+		it.Before(func() {
+			RegisterTestingT(t)
+		})
+
+		it("uses the fallback when the context has no quota id", func() {
+			Expect(quotaIDFromContext(context.Background(), "fallback")).To(Equal("fallback"))
+		})
+
+		it("uses the quota id from the context", func() {
+			ctx := ContextWithQuotaID(context.Background(), "test-quota-id")
+			Expect(quotaIDFromContext(ctx, "fallback")).To(Equal("test-quota-id"))
+		})
+	}, spec.Report(report.Terminal{}))
+}
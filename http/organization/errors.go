@@ -0,0 +1,63 @@
+package organization
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/http/apierror"
+)
+
+// writeError logs err and responds with the status that describes it, so that
+// clients can tell failures that are worth retrying from those that are not
+func writeError(w http.ResponseWriter, req *http.Request, err error) {
+	if _, ok := err.(OrgNotFoundError); !ok {
+		log.Printf("request [%s]: %v\n", apierror.RequestID(req), err)
+	}
+	status, code, message, retryable := describeError(err)
+	apierror.Write(w, req, status, code, message, retryable)
+}
+
+// describeError returns the response status, error code, message and
+// retryable flag that describe err
+func describeError(err error) (status int, code string, message string, retryable bool) {
+	switch e := err.(type) {
+	case NotAuthenticatedError:
+		return http.StatusUnauthorized, "not_authenticated", "You are not logged in.", false
+	case OrgNotFoundError:
+		return http.StatusNotFound, "org_not_found", fmt.Sprintf("Organization %s has not been created yet.", string(e)), false
+	case OrgNameTakenError:
+		return http.StatusConflict, "org_name_taken", fmt.Sprintf("An organization named %s already exists, and you are not a member of it.", string(e)), false
+	case NotOrgOwnerError:
+		return http.StatusForbidden, "not_org_owner", fmt.Sprintf("Only the managers of %s can manage its members.", string(e)), false
+	case InvalidInvitationError:
+		return http.StatusBadRequest, "invalid_invitation", fmt.Sprintf("The invitation is not valid: %s.", string(e)), false
+	case InviteeNotAuthorizedError:
+		return http.StatusForbidden, "invitee_not_authorized", fmt.Sprintf("%s is not allowed to sign in, so cannot be invited.", string(e)), false
+	case MemberNotFoundError:
+		return http.StatusNotFound, "member_not_found", "The user is not a member of the organization.", false
+	case TermsNotAcceptedError:
+		return http.StatusPreconditionRequired, "terms_not_accepted", "You must accept the terms of use before your organization is created.", false
+	case CannotRemoveSelfError:
+		return http.StatusConflict, "cannot_remove_self", "You cannot remove yourself from your organization.", false
+	}
+
+	switch err {
+	case ErrQueueFull, ErrStopped:
+		return http.StatusServiceUnavailable, "provisioning_unavailable", "Too many organizations are being created. Please try again.", true
+	}
+
+	switch cloudfoundry.KindOf(err) {
+	case cloudfoundry.ErrForbidden:
+		return http.StatusForbidden, "forbidden", "You are not allowed to access this organization.", false
+	case cloudfoundry.ErrConflict:
+		return http.StatusConflict, "conflict", "The organization was changed by another request.", true
+	case cloudfoundry.ErrUnavailable:
+		return http.StatusServiceUnavailable, "platform_unavailable", "The platform is unavailable. Please try again.", true
+	case cloudfoundry.ErrUnauthorized:
+		return http.StatusBadGateway, "platform_error", "The platform rejected the request.", false
+	default:
+		return http.StatusBadGateway, "platform_error", "The platform could not complete the request. Please try again.", true
+	}
+}
@@ -0,0 +1,76 @@
+package organization
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pivotalservices/ignition/cloudfoundry"
+)
+
+// GettingStarted is what the user needs to start using their org from the cf
+// CLI
+type GettingStarted struct {
+	APIURL      string   `json:"api_url"`
+	PasscodeURL string   `json:"passcode_url"`
+	Org         string   `json:"org"`
+	Space       string   `json:"space"`
+	OrgURL      string   `json:"org_url"`
+	Commands    Commands `json:"commands"`
+}
+
+// Commands are cf CLI commands that the user can copy and paste
+type Commands struct {
+	Login  string `json:"login"`
+	Target string `json:"target"`
+}
+
+// NewGettingStarted returns the getting started info for the space in the org;
+// the passcode URL is the UAA page that shows the one-time passcode that
+// `cf login --sso` asks for
+func NewGettingStarted(apiURL string, uaaURL string, org *cloudfoundry.Organization, spaceName string) GettingStarted {
+	return GettingStarted{
+		APIURL:      apiURL,
+		PasscodeURL: strings.TrimSuffix(uaaURL, "/") + "/passcode",
+		Org:         org.Name,
+		Space:       spaceName,
+		OrgURL:      org.URL,
+		Commands: Commands{
+			Login:  fmt.Sprintf("cf login -a %s --sso", quoteArg(apiURL)),
+			Target: fmt.Sprintf("cf target -o %s -s %s", quoteArg(org.Name), quoteArg(spaceName)),
+		},
+	}
+}
+
+// GettingStartedHandler returns the getting started info for the user's
+// development organization; it is not found until the org has been created
+func GettingStartedHandler(apiURL string, uaaURL string, appsURL string, orgPrefix string, quotaID string, spaceName string, a cloudfoundry.OrganizationQuerier) http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		userID, orgName, quotaID, err := orgInfoFromRequest(req, orgPrefix, quotaID)
+		if err != nil {
+			writeError(w, req, err)
+			return
+		}
+
+		org, err := FindOrgForUser(orgName, appsURL, userID, quotaID, a)
+		if err != nil {
+			writeError(w, req, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(NewGettingStarted(apiURL, uaaURL, org, spaceName))
+	}
+	return http.HandlerFunc(fn)
+}
+
+// quoteArg quotes a command argument that would otherwise be split by the
+// shell
+func quoteArg(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t'\"$`\\&|;<>()*?!#~") {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,77 @@
+package organization_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/cloudfoundry/cloudfoundryfakes"
+	"github.com/pivotalservices/ignition/http/organization"
+	"github.com/pivotalservices/ignition/http/session"
+	"github.com/pivotalservices/ignition/user"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestGettingStarted(t *testing.T) {
+	spec.Run(t, "GettingStarted", testGettingStarted, spec.Report(report.Terminal{}))
+}
+
+func testGettingStarted(t *testing.T, when spec.G, it spec.S) {
+	var (
+		r *http.Request
+		w *httptest.ResponseRecorder
+		c *cloudfoundryfakes.FakeAPI
+		h http.Handler
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		w = httptest.NewRecorder()
+		c = &cloudfoundryfakes.FakeAPI{}
+		r = httptest.NewRequest(http.MethodGet, "/getting-started", nil)
+		profile := &user.Profile{
+			AccountName: "testuser@test.com",
+		}
+		r = r.WithContext(user.WithProfile(session.ContextWithUserID(r.Context(), "test-user-id"), profile))
+		h = organization.GettingStartedHandler("https://api.example.net", "https://login.example.net/", "https://apps.example.net", "ignition", "test-quota-id", "playground", c)
+	})
+
+	it("returns the commands for the user's org", func() {
+		c.ListOrgsByQueryReturns([]cfclient.Org{
+			cfclient.Org{Guid: "test-org-1", Name: "ignition-testuser"},
+		}, nil)
+		h.ServeHTTP(w, r)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var body organization.GettingStarted
+		Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+		Expect(body).To(Equal(organization.GettingStarted{
+			APIURL:      "https://api.example.net",
+			PasscodeURL: "https://login.example.net/passcode",
+			Org:         "ignition-testuser",
+			Space:       "playground",
+			OrgURL:      "https://apps.example.net/organizations/test-org-1",
+			Commands: organization.Commands{
+				Login:  "cf login -a https://api.example.net --sso",
+				Target: "cf target -o ignition-testuser -s playground",
+			},
+		}))
+	})
+
+	it("is not found before the org has been created", func() {
+		c.ListOrgsByQueryReturns(nil, nil)
+		h.ServeHTTP(w, r)
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+		Expect(w.Body.String()).To(ContainSubstring("org_not_found"))
+	})
+
+	it("quotes names that the shell would split", func() {
+		org := &cloudfoundry.Organization{Name: "Bob's Org"}
+		g := organization.NewGettingStarted("https://api.example.net", "https://login.example.net", org, "dev space")
+		Expect(g.Commands.Target).To(Equal(`cf target -o 'Bob'\''s Org' -s 'dev space'`))
+	})
+}
@@ -7,101 +7,318 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/gorilla/mux"
 	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/http/apierror"
+	"github.com/pivotalservices/ignition/user"
 	"github.com/pkg/errors"
 )
 
-// Handler retrieves or creates the user's development organization
-func Handler(appsURL string, orgPrefix string, quotaID string, spaceName string, a cloudfoundry.API) http.Handler {
+// orgResponse is the user's development organization, with the team orgs of
+// their groups
+type orgResponse struct {
+	*cloudfoundry.Organization
+	TeamOrgs []TeamOrganization `json:"team_orgs,omitempty"`
+	// TeamOrgsPending is true when the user is yet to be added to the team
+	// orgs of some of their groups, which CreateHandler does
+	TeamOrgsPending bool `json:"team_orgs_pending,omitempty"`
+}
+
+// TermsChecker tells whether the user with the account name has accepted the
+// current version of the terms of use
+type TermsChecker interface {
+	Accepted(accountName string) bool
+	CurrentVersion() string
+}
+
+// termsAccepted is true when there are no terms, or the user in the request
+// has accepted them
+func termsAccepted(terms TermsChecker, req *http.Request) bool {
+	if terms == nil {
+		return true
+	}
+	profile, err := user.ProfileFromContext(req.Context())
+	return err == nil && profile != nil && terms.Accepted(profile.AccountName)
+}
+
+// Handler retrieves the user's development organization, with the sample app
+// named sampleAppName when it has been pushed, and the team orgs of the
+// user's groups that they are a member of. It does not change anything: the
+// org, and the team orgs, are created with CreateHandler
+func Handler(appsURL string, orgPrefix string, quotaID string, sampleAppName string, teams *Teams, terms TermsChecker, a cloudfoundry.API) http.Handler {
 	fn := func(w http.ResponseWriter, req *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		userID, accountName, err := userInfoFromContext(req.Context())
+		userID, orgName, quotaID, err := orgInfoFromRequest(req, orgPrefix, quotaID)
 		if err != nil {
-			log.Println(err)
-			w.WriteHeader(http.StatusNotFound)
+			writeError(w, req, err)
 			return
 		}
 
-		if strings.TrimSpace(userID) == "" {
-			w.WriteHeader(http.StatusNotFound)
+		org, err := FindOrgForUser(orgName, appsURL, userID, quotaID, a)
+		if err != nil {
+			writeError(w, req, err)
 			return
 		}
+		addSampleApp(org, sampleAppName, a)
+		response := orgResponse{Organization: org}
+		// team orgs are not shown or joined until the terms are accepted
+		if profile, err := user.ProfileFromContext(req.Context()); err == nil && profile != nil && termsAccepted(terms, req) {
+			response.TeamOrgs, response.TeamOrgsPending = teams.Find(userID, profile.Groups, a)
+		}
 
-		orgName := Name(orgPrefix, accountName)
-		org, err := FindOrgForUser(orgName, appsURL, userID, quotaID, a)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// ensureTeamOrgs returns the team orgs of the groups in the user's profile,
+// creating them and adding the user to them as needed; there are none until
+// the user has accepted the terms. It changes state, so it is only used by
+// CreateHandler
+func ensureTeamOrgs(req *http.Request, userID string, teams *Teams, terms TermsChecker, a cloudfoundry.API) []TeamOrganization {
+	if !termsAccepted(terms, req) {
+		return nil
+	}
+	profile, err := user.ProfileFromContext(req.Context())
+	if err != nil || profile == nil {
+		return nil
+	}
+	return teams.Ensure(userID, profile.Groups, a)
+}
+
+// addSampleApp adds the sample app to the org when it has been pushed; the org
+// is still usable without it, so errors are only logged
+func addSampleApp(org *cloudfoundry.Organization, sampleAppName string, a cloudfoundry.AppQuerier) {
+	if strings.TrimSpace(sampleAppName) == "" {
+		return
+	}
+	app, err := cloudfoundry.FindApp(sampleAppName, org.GUID, a)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	org.SampleApp = app
+}
+
+// jobResponse is the body of a response that refers to a provisioning job
+type jobResponse struct {
+	Job
+	StatusURL string `json:"status_url"`
+}
+
+// CreateHandler enqueues a job that creates the user's development
+// organization, and answers 202 Accepted with the job. It answers 200 OK with
+// the org when the org already exists, and 428 Precondition Required when the
+// user has not accepted the current terms. The user must have a UAA user,
+// which the route creates before it once the terms are accepted
+func CreateHandler(appsURL string, orgPrefix string, quotaID string, teams *Teams, terms TermsChecker, a cloudfoundry.API, p *Provisioner) http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		userID, orgName, quotaID, err := orgInfoFromRequest(req, orgPrefix, quotaID)
 		if err != nil {
-			switch err.(type) {
-			case OrgNotFoundError:
-				org, err = CreateOrgForUser(orgName, appsURL, userID, quotaID, spaceName, a)
-				if err != nil {
-					log.Println(err)
-					w.WriteHeader(http.StatusNotFound)
-					return
-				}
-			default:
-				log.Println(err)
-				w.WriteHeader(http.StatusNotFound)
+			writeError(w, req, err)
+			return
+		}
+		if strings.TrimSpace(userID) == "" {
+			// the UAA user is only created once the terms are accepted
+			if !termsAccepted(terms, req) {
+				writeError(w, req, TermsNotAcceptedError(terms.CurrentVersion()))
 				return
 			}
+			writeError(w, req, NotAuthenticatedError("no user id was found"))
+			return
+		}
+
+		teamOrgs := ensureTeamOrgs(req, userID, teams, terms, a)
+		org, err := FindOrgForUser(orgName, appsURL, userID, quotaID, a)
+		if err == nil {
+			if p.SampleApp != nil {
+				addSampleApp(org, p.SampleApp.Name, a)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(orgResponse{Organization: org, TeamOrgs: teamOrgs})
+			return
+		}
+		if _, ok := err.(OrgNotFoundError); !ok {
+			writeError(w, req, err)
+			return
+		}
+		if !termsAccepted(terms, req) {
+			writeError(w, req, TermsNotAcceptedError(terms.CurrentVersion()))
+			return
 		}
 
+		var email, name string
+		if profile, err := user.ProfileFromContext(req.Context()); err == nil && profile != nil {
+			email, name = profile.Email, profile.Name
+		}
+		j, err := p.Enqueue(userID, email, name, orgName, quotaID)
+		if err != nil {
+			writeError(w, req, err)
+			return
+		}
+		statusURL := jobURL(j.ID)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", statusURL)
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(jobResponse{Job: j, StatusURL: statusURL})
+	}
+	return http.HandlerFunc(fn)
+}
+
+// JobHandler reports the progress of the user's provisioning job with the id
+// in the {id} route variable
+func JobHandler(p *Provisioner) http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		userID, _, err := userInfoFromContext(req.Context())
+		if err != nil {
+			writeError(w, req, NotAuthenticatedError(err.Error()))
+			return
+		}
+
+		id := mux.Vars(req)["id"]
+		j, ok := p.Store.Job(id)
+		if !ok || strings.TrimSpace(userID) == "" || j.UserID != userID {
+			apierror.Write(w, req, http.StatusNotFound, "job_not_found", "The provisioning job could not be found.", false)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(org)
+		json.NewEncoder(w).Encode(jobResponse{Job: j, StatusURL: jobURL(j.ID)})
 	}
 	return http.HandlerFunc(fn)
 }
 
+func jobURL(id string) string {
+	return fmt.Sprintf("/organization/jobs/%s", id)
+}
+
+// orgInfoFromRequest returns the user ID, which is empty when the user does
+// not have a UAA user yet, and the name and quota of the user's org
+func orgInfoFromRequest(req *http.Request, orgPrefix string, quotaID string) (userID string, orgName string, orgQuotaID string, err error) {
+	userID, accountName, err := userInfoFromContext(req.Context())
+	if err != nil {
+		return "", "", "", NotAuthenticatedError(err.Error())
+	}
+	return userID, Name(orgPrefix, accountName), quotaIDFromContext(req.Context(), quotaID), nil
+}
+
 // OrgNotFoundError indicates that an org cannot be found for the user
 type OrgNotFoundError string
 
 func (o OrgNotFoundError) Error() string {
-	return fmt.Sprintf("organization %s not found", o)
+	return fmt.Sprintf("organization %s not found", string(o))
+}
+
+// OrgNameTakenError indicates that the user's org cannot be created because
+// an org the user is not a member of already has its name
+type OrgNameTakenError string
+
+func (o OrgNameTakenError) Error() string {
+	return fmt.Sprintf("organization %s already exists", string(o))
+}
+
+// TermsNotAcceptedError indicates that the user's org cannot be created until
+// they accept the version of the terms of use
+type TermsNotAcceptedError string
+
+func (t TermsNotAcceptedError) Error() string {
+	return fmt.Sprintf("terms of use version %s have not been accepted", string(t))
+}
+
+// NotAuthenticatedError indicates that the request has no user
+type NotAuthenticatedError string
+
+func (n NotAuthenticatedError) Error() string {
+	return fmt.Sprintf("not authenticated: %s", string(n))
 }
 
 // CreateOrgForUser creates an org, a default space, and creates or retreieves
 // the user and then assigns that user to org manager, org auditor, space manager,
 // space developer, and space auditor roles
 func CreateOrgForUser(name string, appsURL string, userID string, quotaID string, spaceName string, a cloudfoundry.API) (*cloudfoundry.Organization, error) {
+	org, _, err := createOrgForUser(name, appsURL, userID, quotaID, spaceName, true, a, func(string, string, error) {})
+	return org, err
+}
+
+// createOrgForUser is CreateOrgForUser, reporting the status of each step to
+// progress as it goes; the space is nil when it could not be created
+func createOrgForUser(name string, appsURL string, userID string, quotaID string, spaceName string, allowSSH bool, a cloudfoundry.API, progress func(step string, status string, err error)) (*cloudfoundry.Organization, *cloudfoundry.Space, error) {
 	// create the user if needed
 	if strings.TrimSpace(userID) == "" {
-		return nil, errors.New("cannot create an org without a valid userID")
+		return nil, nil, errors.New("cannot create an org without a valid userID")
 	}
 
-	// check for org existence by name
-
 	// create the org
+	progress(StepCreateOrg, StatusRunning, nil)
 	org, err := cloudfoundry.CreateOrg(name, appsURL, quotaID, a)
 	if err != nil {
-		return nil, err
+		if cloudfoundry.KindOf(err) == cloudfoundry.ErrConflict {
+			err = OrgNameTakenError(name)
+		}
+		progress(StepCreateOrg, StatusFailed, err)
+		return nil, nil, err
 	}
+	progress(StepCreateOrg, StatusSucceeded, nil)
 
-	// assign the user to org roles
-	_, err = a.AssociateOrgUser(org.GUID, userID)
+	assignOrgRoles(org.GUID, userID, a, progress)
+	space := createSpace(spaceName, org.GUID, userID, allowSSH, a, progress)
+	return org, space, nil
+}
+
+// assignOrgRoles assigns the user to the org user, manager and auditor roles;
+// the org is still usable when a role cannot be assigned, so a failure only
+// fails the step
+func assignOrgRoles(orgGUID string, userID string, a cloudfoundry.API, progress func(step string, status string, err error)) {
+	progress(StepAssignOrgRoles, StatusRunning, nil)
+	var roleErr error
+	_, err := a.AssociateOrgUser(orgGUID, userID)
 	if err != nil {
 		log.Println(err)
+		roleErr = err
 	}
-	_, err = a.AssociateOrgManager(org.GUID, userID)
+	_, err = a.AssociateOrgManager(orgGUID, userID)
 	if err != nil {
 		log.Println(err)
+		roleErr = err
 	}
-	_, err = a.AssociateOrgAuditor(org.GUID, userID)
+	_, err = a.AssociateOrgAuditor(orgGUID, userID)
 	if err != nil {
 		log.Println(err)
+		roleErr = err
 	}
+	progress(StepAssignOrgRoles, stepStatus(roleErr), roleErr)
+}
 
-	// create the space and assign the user to all space roles
-	err = cloudfoundry.CreateSpace(spaceName, org.GUID, userID, a)
+// createSpace creates the space and assigns the user to all space roles; it
+// returns nil when the space could not be created
+func createSpace(spaceName string, orgGUID string, userID string, allowSSH bool, a cloudfoundry.API, progress func(step string, status string, err error)) *cloudfoundry.Space {
+	progress(StepCreateSpace, StatusRunning, nil)
+	space, err := cloudfoundry.CreateSpace(spaceName, orgGUID, userID, allowSSH, a)
 	if err != nil {
 		log.Println(err)
 	}
+	progress(StepCreateSpace, stepStatus(err), err)
+	return space
+}
 
-	// return the org
-	return org, nil
+func stepStatus(err error) string {
+	if err != nil {
+		return StatusFailed
+	}
+	return StatusSucceeded
 }
 
 // FindOrgForUser returns an orgNotFoundError if the org is not found, and a
-// single org with a name or quota match, when it exists
+// single org with a name match, or a quota match among the orgs the user
+// manages, when it exists. An org the user was invited into shares the
+// inviter's quota, so it only matches by quota when the user manages it. A
+// user without a UAA user ID has no orgs
 func FindOrgForUser(name string, appsURL string, userID string, quotaID string, a cloudfoundry.OrganizationQuerier) (*cloudfoundry.Organization, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, OrgNotFoundError(name)
+	}
 	o, err := cloudfoundry.OrgsForUserID(userID, appsURL, a)
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not find orgs for user id: [%s]", userID)
@@ -111,21 +328,23 @@ func FindOrgForUser(name string, appsURL string, userID string, quotaID string,
 		return nil, OrgNotFoundError(name)
 	}
 
-	var quotaMatches []cloudfoundry.Organization
 	for i := range o {
-		if strings.EqualFold(quotaID, o[i].QuotaDefinitionGUID) {
-			quotaMatches = append(quotaMatches, o[i])
-		}
 		if strings.EqualFold(name, o[i].Name) {
 			return &o[i], nil
 		}
 	}
 
-	if len(quotaMatches) == 0 {
-		return nil, OrgNotFoundError(name)
+	managed, err := cloudfoundry.OrgsManagedByUserID(userID, appsURL, a)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find orgs managed by user id: [%s]", userID)
+	}
+	for i := range managed {
+		if strings.EqualFold(quotaID, managed[i].QuotaDefinitionGUID) {
+			return &managed[i], nil
+		}
 	}
 
-	return &quotaMatches[0], nil
+	return nil, OrgNotFoundError(name)
 }
 
 // Name returns the organization name for the user's development organization
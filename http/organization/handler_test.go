@@ -1,14 +1,22 @@
 package organization_test
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	"github.com/gorilla/mux"
 	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/audit"
+	"github.com/pivotalservices/ignition/cloudfoundry"
 	"github.com/pivotalservices/ignition/cloudfoundry/cloudfoundryfakes"
+	"github.com/pivotalservices/ignition/http/apierror"
 	"github.com/pivotalservices/ignition/http/organization"
 	"github.com/pivotalservices/ignition/http/session"
 	"github.com/pivotalservices/ignition/user"
@@ -32,23 +40,38 @@ func testHandler(t *testing.T, when spec.G, it spec.S) {
 		c = &cloudfoundryfakes.FakeAPI{}
 	})
 
+	errorBody := func() apierror.Error {
+		var body apierror.Error
+		Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+		return body
+	}
+
 	when("there is no profile in the context", func() {
-		it("is not found", func() {
+		it("is unauthorized", func() {
 			r = httptest.NewRequest(http.MethodGet, "/", nil)
-			organization.Handler("http://example.net", "ignition", "test-quota-id", "playground", c).ServeHTTP(w, r)
-			Expect(w.Code).To(Equal(http.StatusNotFound))
+			r.Header.Set("X-Request-Id", "test-request-id")
+			organization.Handler("http://example.net", "ignition", "test-quota-id", "", nil, nil, c).ServeHTTP(w, r)
+			Expect(w.Code).To(Equal(http.StatusUnauthorized))
+			Expect(errorBody()).To(Equal(apierror.Error{
+				Code:      "not_authenticated",
+				Message:   "You are not logged in.",
+				Retryable: false,
+				RequestID: "test-request-id",
+			}))
 		})
 	})
 
 	when("there is a profile in the context but no user id", func() {
-		it("is not found", func() {
+		it("has no org, without asking the platform", func() {
 			r = httptest.NewRequest(http.MethodGet, "/", nil)
 			profile := &user.Profile{
 				AccountName: "testuser@test.com",
 			}
 			r = r.WithContext(user.WithProfile(r.Context(), profile))
-			organization.Handler("http://example.net", "ignition", "test-quota-id", "playground", c).ServeHTTP(w, r)
+			organization.Handler("http://example.net", "ignition", "test-quota-id", "", nil, nil, c).ServeHTTP(w, r)
 			Expect(w.Code).To(Equal(http.StatusNotFound))
+			Expect(w.Body.String()).To(ContainSubstring("org_not_found"))
+			Expect(c.ListOrgsByQueryCallCount()).To(Equal(0))
 		})
 	})
 
@@ -66,9 +89,38 @@ func testHandler(t *testing.T, when spec.G, it spec.S) {
 				c.ListOrgsByQueryReturns(nil, errors.New("test error"))
 			})
 
-			it("is not found", func() {
-				organization.Handler("http://example.net", "ignition", "test-quota-id", "playground", c).ServeHTTP(w, r)
-				Expect(w.Code).To(Equal(http.StatusNotFound))
+			it("is a bad gateway", func() {
+				organization.Handler("http://example.net", "ignition", "test-quota-id", "", nil, nil, c).ServeHTTP(w, r)
+				Expect(w.Code).To(Equal(http.StatusBadGateway))
+				Expect(errorBody().Code).To(Equal("platform_error"))
+				Expect(errorBody().Retryable).To(BeTrue())
+				Expect(errorBody().RequestID).NotTo(BeEmpty())
+			})
+		})
+
+		when("the cloud controller cannot be reached", func() {
+			it.Before(func() {
+				c.ListOrgsByQueryReturns(nil, &url.Error{Op: "Get", URL: "https://api.example.net", Err: errors.New("connection refused")})
+			})
+
+			it("is unavailable and retryable", func() {
+				organization.Handler("http://example.net", "ignition", "test-quota-id", "", nil, nil, c).ServeHTTP(w, r)
+				Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+				Expect(errorBody().Code).To(Equal("platform_unavailable"))
+				Expect(errorBody().Retryable).To(BeTrue())
+			})
+		})
+
+		when("the cloud controller forbids the request", func() {
+			it.Before(func() {
+				c.ListOrgsByQueryReturns(nil, cfclient.CloudFoundryHTTPError{StatusCode: http.StatusForbidden, Status: "403 Forbidden"})
+			})
+
+			it("is forbidden", func() {
+				organization.Handler("http://example.net", "ignition", "test-quota-id", "", nil, nil, c).ServeHTTP(w, r)
+				Expect(w.Code).To(Equal(http.StatusForbidden))
+				Expect(errorBody().Code).To(Equal("forbidden"))
+				Expect(errorBody().Retryable).To(BeFalse())
 			})
 		})
 
@@ -77,16 +129,11 @@ func testHandler(t *testing.T, when spec.G, it spec.S) {
 				c.ListOrgsByQueryReturns(nil, nil)
 			})
 
-			it("creates the org", func() {
-				c.CreateOrgReturns(cfclient.Org{
-					Guid:                        "test-org-guid",
-					Name:                        "ignition-testuser",
-					QuotaDefinitionGuid:         "test-quota-id",
-					DefaultIsolationSegmentGuid: "test-iso-segment-id",
-				}, nil)
-				organization.Handler("http://example.net", "ignition", "test-quota-id", "playground", c).ServeHTTP(w, r)
-				Expect(w.Code).To(Equal(http.StatusOK))
-				Expect(w.Body.String()).To(ContainSubstring("ignition-testuser"))
+			it("is not found and does not create the org", func() {
+				organization.Handler("http://example.net", "ignition", "test-quota-id", "", nil, nil, c).ServeHTTP(w, r)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+				Expect(errorBody().Code).To(Equal("org_not_found"))
+				Expect(c.CreateOrgCallCount()).To(Equal(0))
 			})
 		})
 
@@ -113,45 +160,324 @@ func testHandler(t *testing.T, when spec.G, it spec.S) {
 			})
 
 			it("selects the correct org when there is a name match", func() {
-				organization.Handler("http://example.net", "ignition", "test-quota2-id", "playground", c).ServeHTTP(w, r)
+				organization.Handler("http://example.net", "ignition", "test-quota2-id", "", nil, nil, c).ServeHTTP(w, r)
 				Expect(w.Code).To(Equal(http.StatusOK))
 				Expect(w.Body.String()).To(ContainSubstring("test-org-1"))
 			})
 
-			when("creating an org succeeds", func() {
-				it.Before(func() {
-					c.CreateOrgReturns(cfclient.Org{
-						Guid:                        "test-org-guid",
-						Name:                        "ignition1-testuser",
-						QuotaDefinitionGuid:         "test-quota2-id",
-						DefaultIsolationSegmentGuid: "test-iso-segment-id",
-					}, nil)
-				})
+			it("includes the sample app when it has been pushed", func() {
+				c.ListAppsByQueryReturns([]cfclient.App{{Guid: "test-app-guid", Name: "spring-music", State: "STARTED"}}, nil)
+				c.GetAppRoutesReturns([]cfclient.Route{{Host: "spring-music-ignition-testuser", DomainGuid: "test-domain-guid"}}, nil)
+				c.GetSharedDomainByGuidReturns(cfclient.SharedDomain{Name: "apps.example.net"}, nil)
+				organization.Handler("http://example.net", "ignition", "test-quota2-id", "spring-music", nil, nil, c).ServeHTTP(w, r)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				var org cloudfoundry.Organization
+				Expect(json.Unmarshal(w.Body.Bytes(), &org)).To(Succeed())
+				Expect(org.SampleApp).NotTo(BeNil())
+				Expect(org.SampleApp.URL).To(Equal("https://spring-music-ignition-testuser.apps.example.net"))
+			})
 
-				it("creates the org when there is no name or quota match", func() {
-					organization.Handler("http://example.net", "ignition1", "test-quota2-id", "playground", c).ServeHTTP(w, r)
-					Expect(w.Code).To(Equal(http.StatusOK))
-					Expect(w.Body.String()).To(ContainSubstring("ignition1-testuser"))
-				})
+			it("returns the org without the sample app when the app cannot be found", func() {
+				c.ListAppsByQueryReturns(nil, errors.New("test error"))
+				organization.Handler("http://example.net", "ignition", "test-quota2-id", "spring-music", nil, nil, c).ServeHTTP(w, r)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(w.Body.String()).NotTo(ContainSubstring("sample_app"))
 			})
 
-			when("creating an org fails", func() {
-				it.Before(func() {
-					c.CreateOrgReturns(cfclient.Org{}, errors.New("test error"))
-				})
+			it("includes the team orgs of the user's groups", func() {
+				profile := &user.Profile{AccountName: "testuser@test.com", Groups: []string{"platform-team"}}
+				r = r.WithContext(user.WithProfile(r.Context(), profile))
+				teams := &organization.Teams{
+					AppsURL: "http://example.net",
+					Orgs:    []organization.TeamOrg{{Group: "platform-team", Name: "ignition-testuser1", QuotaID: "ignition-quota2-id"}},
+				}
+				organization.Handler("http://example.net", "ignition", "test-quota-id", "", teams, nil, c).ServeHTTP(w, r)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				var body struct {
+					GUID     string                          `json:"guid"`
+					TeamOrgs []organization.TeamOrganization `json:"team_orgs"`
+				}
+				Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+				Expect(body.GUID).To(Equal("test-org-1"))
+				Expect(body.TeamOrgs).To(HaveLen(1))
+				Expect(body.TeamOrgs[0].GUID).To(Equal("test-org-2"))
+				Expect(body.TeamOrgs[0].Group).To(Equal("platform-team"))
+			})
+
+			it("does not create team orgs or add the user to them", func() {
+				profile := &user.Profile{AccountName: "testuser@test.com", Groups: []string{"platform-team"}}
+				r = r.WithContext(user.WithProfile(r.Context(), profile))
+				teams := &organization.Teams{
+					AppsURL: "http://example.net",
+					Orgs:    []organization.TeamOrg{{Group: "platform-team", Name: "ignition-platform", QuotaID: "ignition-quota2-id"}},
+				}
+				organization.Handler("http://example.net", "ignition", "test-quota-id", "", teams, nil, c).ServeHTTP(w, r)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(w.Body.String()).To(ContainSubstring(`"team_orgs_pending":true`))
+				Expect(c.CreateOrgCallCount()).To(Equal(0))
+				Expect(c.AssociateOrgUserCallCount()).To(Equal(0))
+			})
 
-				it("is not found", func() {
-					organization.Handler("http://example.net", "ignition1", "test-quota2-id", "playground", c).ServeHTTP(w, r)
-					Expect(w.Code).To(Equal(http.StatusNotFound))
-				})
+			it("leaves out the team orgs until the user accepts the terms", func() {
+				profile := &user.Profile{AccountName: "testuser@test.com", Groups: []string{"platform-team"}}
+				r = r.WithContext(user.WithProfile(r.Context(), profile))
+				teams := &organization.Teams{
+					AppsURL: "http://example.net",
+					Orgs:    []organization.TeamOrg{{Group: "platform-team", Name: "ignition-testuser1", QuotaID: "ignition-quota2-id"}},
+				}
+				organization.Handler("http://example.net", "ignition", "test-quota-id", "", teams, termsChecker{}, c).ServeHTTP(w, r)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(w.Body.String()).NotTo(ContainSubstring("team_orgs"))
+			})
+
+			it("is not found when there is no name or quota match", func() {
+				organization.Handler("http://example.net", "ignition1", "test-quota2-id", "", nil, nil, c).ServeHTTP(w, r)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
 			})
 
 			it("selects the correct org when there is a quota match (but not a name match)", func() {
-				organization.Handler("http://example.net", "ignition2", "ignition-quota-id", "playground", c).ServeHTTP(w, r)
+				organization.Handler("http://example.net", "ignition2", "ignition-quota-id", "", nil, nil, c).ServeHTTP(w, r)
 				Expect(w.Code).To(Equal(http.StatusOK))
 				Expect(w.Body.String()).To(ContainSubstring("test-org-1"))
 			})
+
+			it("is not found when the quota match is an org the user was invited into", func() {
+				c.ListOrgsByQueryStub = func(q url.Values) ([]cfclient.Org, error) {
+					if strings.HasPrefix(q.Get("q"), "manager_guid:") {
+						return nil, nil
+					}
+					return []cfclient.Org{{Guid: "inviter-org", Name: "ignition-inviter", QuotaDefinitionGuid: "ignition-quota-id"}}, nil
+				}
+				organization.Handler("http://example.net", "ignition", "ignition-quota-id", "", nil, nil, c).ServeHTTP(w, r)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+				Expect(errorBody().Code).To(Equal("org_not_found"))
+			})
+		})
+	})
+}
+
+func TestCreateHandler(t *testing.T) {
+	spec.Run(t, "CreateHandler", testCreateHandler, spec.Report(report.Terminal{}))
+}
+
+func testCreateHandler(t *testing.T, when spec.G, it spec.S) {
+	var (
+		r *http.Request
+		w *httptest.ResponseRecorder
+		c *cloudfoundryfakes.FakeAPI
+		p *organization.Provisioner
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		w = httptest.NewRecorder()
+		c = &cloudfoundryfakes.FakeAPI{}
+		store, err := organization.NewJobStore("")
+		Expect(err).NotTo(HaveOccurred())
+		p = &organization.Provisioner{
+			AppsURL:   "http://example.net",
+			SpaceName: "playground",
+			API:       c,
+			Store:     store,
+		}
+		r = httptest.NewRequest(http.MethodPost, "/organization", nil)
+		profile := &user.Profile{
+			AccountName: "testuser@test.com",
+			Email:       "testuser@test.com",
+			Name:        "Test User",
+		}
+		r = r.WithContext(user.WithProfile(session.ContextWithUserID(r.Context(), "test-user-id"), profile))
+	})
+
+	when("the org already exists", func() {
+		it.Before(func() {
+			c.ListOrgsByQueryReturns([]cfclient.Org{
+				cfclient.Org{Guid: "test-org-1", Name: "ignition-testuser"},
+			}, nil)
+		})
+
+		it("returns the org even when the user has not accepted the terms", func() {
+			organization.CreateHandler("http://example.net", "ignition", "test-quota-id", nil, termsChecker{}, c, p).ServeHTTP(w, r)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(ContainSubstring("test-org-1"))
+		})
+
+		it("adds the user to the team orgs of their groups", func() {
+			profile := &user.Profile{AccountName: "testuser@test.com", Groups: []string{"platform-team"}}
+			r = r.WithContext(user.WithProfile(r.Context(), profile))
+			l := audit.NewLog()
+			teams := &organization.Teams{
+				AppsURL: "http://example.net",
+				Orgs:    []organization.TeamOrg{{Group: "platform-team", Name: "ignition-platform", QuotaID: "ignition-quota2-id"}},
+				Audit:   l,
+			}
+			organization.CreateHandler("http://example.net", "ignition", "test-quota-id", teams, nil, c, p).ServeHTTP(w, r)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			entries, _ := l.Query(audit.Filter{Action: audit.ActionRoleGrant, Target: "ignition-platform"})
+			Expect(entries).To(HaveLen(1))
 		})
+
+		it("returns the org", func() {
+			organization.CreateHandler("http://example.net", "ignition", "test-quota-id", nil, nil, c, p).ServeHTTP(w, r)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(ContainSubstring("test-org-1"))
+			Expect(p.Store.Jobs()).To(BeEmpty())
+		})
+	})
+
+	when("the user was invited into another org with the same quota", func() {
+		it.Before(func() {
+			c.ListOrgsByQueryStub = func(q url.Values) ([]cfclient.Org, error) {
+				if strings.HasPrefix(q.Get("q"), "manager_guid:") {
+					return nil, nil
+				}
+				return []cfclient.Org{{Guid: "inviter-org", Name: "ignition-inviter", QuotaDefinitionGuid: "test-quota-id"}}, nil
+			}
+		})
+
+		it("enqueues a job for the user's own org", func() {
+			organization.CreateHandler("http://example.net", "ignition", "test-quota-id", nil, nil, c, p).ServeHTTP(w, r)
+			Expect(w.Code).To(Equal(http.StatusAccepted))
+			Expect(w.Body.String()).To(ContainSubstring(`"org_name":"ignition-testuser"`))
+			Expect(p.Store.Jobs()).To(HaveLen(1))
+		})
+	})
+
+	when("the org does not exist", func() {
+		it.Before(func() {
+			c.ListOrgsByQueryReturns(nil, nil)
+		})
+
+		it("enqueues a job", func() {
+			organization.CreateHandler("http://example.net", "ignition", "test-quota-id", nil, nil, c, p).ServeHTTP(w, r)
+			Expect(w.Code).To(Equal(http.StatusAccepted))
+			var body struct {
+				ID        string `json:"id"`
+				Status    string `json:"status"`
+				OrgName   string `json:"org_name"`
+				StatusURL string `json:"status_url"`
+			}
+			Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+			Expect(body.ID).NotTo(BeEmpty())
+			Expect(body.Status).To(Equal("queued"))
+			Expect(body.OrgName).To(Equal("ignition-testuser"))
+			Expect(body.StatusURL).To(Equal("/organization/jobs/" + body.ID))
+			Expect(w.Header().Get("Location")).To(Equal(body.StatusURL))
+			Expect(c.CreateOrgCallCount()).To(Equal(0))
+
+			j, ok := p.Store.Job(body.ID)
+			Expect(ok).To(BeTrue())
+			Expect(j.Email).To(Equal("testuser@test.com"))
+			Expect(j.Name).To(Equal("Test User"))
+			Expect(j.UserID).To(Equal("test-user-id"))
+			Expect(j.QuotaID).To(Equal("test-quota-id"))
+		})
+
+		it("returns the unfinished job when the request is repeated", func() {
+			organization.CreateHandler("http://example.net", "ignition", "test-quota-id", nil, nil, c, p).ServeHTTP(w, r)
+			w2 := httptest.NewRecorder()
+			organization.CreateHandler("http://example.net", "ignition", "test-quota-id", nil, nil, c, p).ServeHTTP(w2, r)
+			Expect(w2.Code).To(Equal(http.StatusAccepted))
+			Expect(p.Store.Jobs()).To(HaveLen(1))
+		})
+
+		when("there are terms of use", func() {
+			it("refuses to create the org until the user accepts them", func() {
+				organization.CreateHandler("http://example.net", "ignition", "test-quota-id", nil, termsChecker{}, c, p).ServeHTTP(w, r)
+				Expect(w.Code).To(Equal(http.StatusPreconditionRequired))
+				Expect(w.Body.String()).To(ContainSubstring(`"code":"terms_not_accepted"`))
+				Expect(p.Store.Jobs()).To(BeEmpty())
+			})
+
+			it("asks a user without a UAA user to accept them first", func() {
+				r = r.WithContext(user.WithProfile(context.Background(), &user.Profile{AccountName: "newuser@test.com"}))
+				organization.CreateHandler("http://example.net", "ignition", "test-quota-id", nil, termsChecker{}, c, p).ServeHTTP(w, r)
+				Expect(w.Code).To(Equal(http.StatusPreconditionRequired))
+				Expect(c.ListOrgsByQueryCallCount()).To(Equal(0))
+			})
+
+			it("enqueues a job once the user has accepted them", func() {
+				organization.CreateHandler("http://example.net", "ignition", "test-quota-id", nil, termsChecker{accepted: "testuser@test.com"}, c, p).ServeHTTP(w, r)
+				Expect(w.Code).To(Equal(http.StatusAccepted))
+				Expect(p.Store.Jobs()).To(HaveLen(1))
+			})
+		})
+
+		it("is unavailable when the provisioner is stopped", func() {
+			p.Stop()
+			organization.CreateHandler("http://example.net", "ignition", "test-quota-id", nil, nil, c, p).ServeHTTP(w, r)
+			Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+		})
+	})
+}
+
+// termsChecker has terms of use that only the accepted account has accepted
+type termsChecker struct {
+	accepted string
+}
+
+func (t termsChecker) Accepted(accountName string) bool {
+	return accountName == t.accepted
+}
+
+func (t termsChecker) CurrentVersion() string {
+	return "v1"
+}
+
+func TestJobHandler(t *testing.T) {
+	spec.Run(t, "JobHandler", testJobHandler, spec.Report(report.Terminal{}))
+}
+
+func testJobHandler(t *testing.T, when spec.G, it spec.S) {
+	var (
+		w      *httptest.ResponseRecorder
+		p      *organization.Provisioner
+		router *mux.Router
+		job    organization.Job
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		w = httptest.NewRecorder()
+		store, err := organization.NewJobStore("")
+		Expect(err).NotTo(HaveOccurred())
+		p = &organization.Provisioner{API: &cloudfoundryfakes.FakeAPI{}, Store: store}
+		job, err = p.Enqueue("test-user-id", "", "", "ignition-testuser", "test-quota-id")
+		Expect(err).NotTo(HaveOccurred())
+		router = mux.NewRouter()
+		router.Handle("/organization/jobs/{id}", organization.JobHandler(p))
+	})
+
+	request := func(id string, userID string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/organization/jobs/"+id, nil)
+		profile := &user.Profile{AccountName: "testuser@test.com"}
+		return r.WithContext(user.WithProfile(session.ContextWithUserID(r.Context(), userID), profile))
+	}
+
+	it("reports the progress of the job", func() {
+		router.ServeHTTP(w, request(job.ID, "test-user-id"))
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var body organization.Job
+		Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.ID).To(Equal(job.ID))
+		Expect(body.Status).To(Equal(organization.StatusQueued))
+		Expect(body.Steps).To(HaveLen(3))
+		Expect(body.Steps[0].Name).To(Equal(organization.StepCreateOrg))
+	})
+
+	it("is not found for another user's job", func() {
+		router.ServeHTTP(w, request(job.ID, "other-user-id"))
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+	})
+
+	it("is not found for an unknown job", func() {
+		router.ServeHTTP(w, request("unknown", "test-user-id"))
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+	})
+
+	it("is unauthorized without a user", func() {
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/organization/jobs/"+job.ID, nil))
+		Expect(w.Code).To(Equal(http.StatusUnauthorized))
 	})
 }
 
@@ -0,0 +1,224 @@
+package organization
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/internal/jsonfile"
+	"github.com/pkg/errors"
+)
+
+// The statuses of a provisioning job and of each of its steps
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped"
+)
+
+// The steps of a provisioning job
+const (
+	StepCreateOrg      = "create_org"
+	StepAssignOrgRoles = "assign_org_roles"
+	StepCreateSpace    = "create_space"
+	StepApplyTemplate  = "apply_template"
+	StepCreateServices = "create_services"
+	StepPushSampleApp  = "push_sample_app"
+	StepSendWelcome    = "send_welcome"
+)
+
+// jobRetention is how long finished jobs are kept
+const jobRetention = 24 * time.Hour
+
+// Job provisions a user's development organization in the background
+type Job struct {
+	ID        string                         `json:"id"`
+	UserID    string                         `json:"user_id"`
+	Email     string                         `json:"email,omitempty"`
+	Name      string                         `json:"name,omitempty"`
+	OrgName   string                         `json:"org_name"`
+	QuotaID   string                         `json:"quota_id"`
+	Status    string                         `json:"status"`
+	Steps     []Step                         `json:"steps"`
+	Org       *cloudfoundry.Organization     `json:"org,omitempty"`
+	Services  []cloudfoundry.ServiceInstance `json:"services,omitempty"`
+	Error     *JobError                      `json:"error,omitempty"`
+	CreatedAt time.Time                      `json:"created_at"`
+	UpdatedAt time.Time                      `json:"updated_at"`
+}
+
+// Step is the progress of one step of a Job
+type Step struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// JobError describes why a Job failed
+type JobError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Retryable bool   `json:"retryable"`
+}
+
+// Finished is true when the job will make no further progress
+func (j *Job) Finished() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed
+}
+
+// succeeded is true when the step of the job has succeeded
+func (j *Job) succeeded(step string) bool {
+	for _, s := range j.Steps {
+		if s.Name == step {
+			return s.Status == StatusSucceeded
+		}
+	}
+	return false
+}
+
+// stepStarted is true when the step of the job has been started, by this run
+// or by one that was interrupted
+func (j *Job) stepStarted(step string) bool {
+	for _, s := range j.Steps {
+		if s.Name == step {
+			return s.Status != StatusQueued
+		}
+	}
+	return false
+}
+
+func newJob(userID string, email string, name string, orgName string, quotaID string, stepNames []string, now time.Time) (Job, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return Job{}, errors.Wrap(err, "could not generate a job id")
+	}
+	var steps []Step
+	for _, name := range stepNames {
+		steps = append(steps, Step{Name: name, Status: StatusQueued, UpdatedAt: now})
+	}
+	return Job{
+		ID:        hex.EncodeToString(b),
+		UserID:    userID,
+		Email:     email,
+		Name:      name,
+		OrgName:   orgName,
+		QuotaID:   quotaID,
+		Status:    StatusQueued,
+		Steps:     steps,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+func (j Job) copy() Job {
+	j.Steps = append([]Step(nil), j.Steps...)
+	j.Services = append([]cloudfoundry.ServiceInstance(nil), j.Services...)
+	if j.Org != nil {
+		org := *j.Org
+		j.Org = &org
+	}
+	if j.Error != nil {
+		e := *j.Error
+		j.Error = &e
+	}
+	return j
+}
+
+// JobStore keeps provisioning jobs, and persists them to a file so that
+// unfinished jobs survive a restart. The file is only read by the instance
+// that writes it, so a job is only known to the instance that runs it
+type JobStore struct {
+	path string
+	mu   sync.Mutex
+	jobs map[string]Job
+	now  func() time.Time
+}
+
+// NewJobStore returns a JobStore that persists to the file at path, loading
+// any jobs that are already in it. Jobs are only kept in memory when path is
+// empty
+func NewJobStore(path string) (*JobStore, error) {
+	s := &JobStore{
+		path: path,
+		jobs: map[string]Job{},
+		now:  time.Now,
+	}
+	if strings.TrimSpace(path) == "" {
+		return s, nil
+	}
+	var jobs []Job
+	if err := jsonfile.Read(path, "job store", &jobs); err != nil {
+		return nil, err
+	}
+	for _, j := range jobs {
+		s.jobs[j.ID] = j
+	}
+	return s, nil
+}
+
+// Save stores the job, replacing any job with the same ID
+func (s *JobStore) Save(j Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[j.ID] = j.copy()
+	return s.persist()
+}
+
+// Job returns the job with the id
+func (s *JobStore) Job(id string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	return j.copy(), ok
+}
+
+// Jobs returns every job, oldest first
+func (s *JobStore) Jobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sorted()
+}
+
+// ActiveJobForUser returns the user's unfinished job, if there is one
+func (s *JobStore) ActiveJobForUser(userID string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.sorted() {
+		if j.UserID == userID && !j.Finished() {
+			return j, true
+		}
+	}
+	return Job{}, false
+}
+
+func (s *JobStore) sorted() []Job {
+	result := make([]Job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		result = append(result, j.copy())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+// persist prunes old finished jobs and writes the rest to the file
+func (s *JobStore) persist() error {
+	cutoff := s.now().Add(-jobRetention)
+	for id, j := range s.jobs {
+		if j.Finished() && j.UpdatedAt.Before(cutoff) {
+			delete(s.jobs, id)
+		}
+	}
+	if strings.TrimSpace(s.path) == "" {
+		return nil
+	}
+	return jsonfile.Write(s.path, "job store", s.sorted())
+}
@@ -0,0 +1,92 @@
+package organization_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/http/organization"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestJobStore(t *testing.T) {
+	spec.Run(t, "JobStore", testJobStore, spec.Report(report.Terminal{}))
+}
+
+func testJobStore(t *testing.T, when spec.G, it spec.S) {
+	var (
+		dir  string
+		path string
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		var err error
+		dir, err = ioutil.TempDir("", "ignition-jobs")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "jobs.json")
+	})
+
+	it.After(func() {
+		os.RemoveAll(dir)
+	})
+
+	it("persists jobs to the file", func() {
+		s, err := organization.NewJobStore(path)
+		Expect(err).NotTo(HaveOccurred())
+		now := time.Now()
+		Expect(s.Save(organization.Job{ID: "job-1", UserID: "user-1", Status: organization.StatusRunning, CreatedAt: now, UpdatedAt: now})).To(Succeed())
+		Expect(s.Save(organization.Job{ID: "job-2", UserID: "user-2", Status: organization.StatusSucceeded, CreatedAt: now.Add(time.Second), UpdatedAt: now})).To(Succeed())
+
+		reloaded, err := organization.NewJobStore(path)
+		Expect(err).NotTo(HaveOccurred())
+		jobs := reloaded.Jobs()
+		Expect(jobs).To(HaveLen(2))
+		Expect(jobs[0].ID).To(Equal("job-1"))
+		Expect(jobs[1].ID).To(Equal("job-2"))
+
+		active, ok := reloaded.ActiveJobForUser("user-1")
+		Expect(ok).To(BeTrue())
+		Expect(active.ID).To(Equal("job-1"))
+		_, ok = reloaded.ActiveJobForUser("user-2")
+		Expect(ok).To(BeFalse())
+	})
+
+	it("starts empty when the file does not exist", func() {
+		s, err := organization.NewJobStore(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Jobs()).To(BeEmpty())
+	})
+
+	it("fails when the file cannot be parsed", func() {
+		Expect(ioutil.WriteFile(path, []byte("not json"), 0600)).To(Succeed())
+		_, err := organization.NewJobStore(path)
+		Expect(err).To(MatchError(ContainSubstring("could not parse job store")))
+	})
+
+	it("forgets old finished jobs", func() {
+		s, err := organization.NewJobStore(path)
+		Expect(err).NotTo(HaveOccurred())
+		old := time.Now().Add(-48 * time.Hour)
+		Expect(s.Save(organization.Job{ID: "old-finished", Status: organization.StatusFailed, UpdatedAt: old})).To(Succeed())
+		Expect(s.Save(organization.Job{ID: "old-running", Status: organization.StatusRunning, UpdatedAt: old})).To(Succeed())
+		_, ok := s.Job("old-finished")
+		Expect(ok).To(BeFalse())
+		_, ok = s.Job("old-running")
+		Expect(ok).To(BeTrue())
+	})
+
+	it("returns copies of the jobs", func() {
+		s, err := organization.NewJobStore("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Save(organization.Job{ID: "job-1", Steps: []organization.Step{{Name: "create_org", Status: organization.StatusQueued}}})).To(Succeed())
+		j, _ := s.Job("job-1")
+		j.Steps[0].Status = organization.StatusFailed
+		j, _ = s.Job("job-1")
+		Expect(j.Steps[0].Status).To(Equal(organization.StatusQueued))
+	})
+}
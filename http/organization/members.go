@@ -0,0 +1,269 @@
+package organization
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/pivotalservices/ignition/audit"
+	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/uaa"
+	"github.com/pivotalservices/ignition/webhook"
+	"github.com/pkg/errors"
+)
+
+// Members lets the owner of a development organization invite other users
+// into it; the owner is a user with the org manager role
+type Members struct {
+	AppsURL   string
+	OrgPrefix string
+	QuotaID   string
+	SpaceName string
+	// UAAOrigin is the origin of the UAA users that are created for invitees
+	UAAOrigin string
+	// Authorized is true for the email addresses of users that may sign in
+	Authorized func(email string) bool
+	CCAPI      cloudfoundry.API
+	UAAAPI     uaa.API
+	// Audit records the invitations and removals
+	Audit audit.Recorder
+	// Webhooks are notified of the UAA users that are created for invitees
+	Webhooks webhook.Notifier
+}
+
+// Invitation is the body of a request to invite a user into the org; the
+// user is a space developer when no roles are given
+type Invitation struct {
+	Email string   `json:"email"`
+	Roles []string `json:"roles"`
+}
+
+// NotOrgOwnerError indicates that the user cannot manage the members of the
+// org because they are not one of its managers
+type NotOrgOwnerError string
+
+func (n NotOrgOwnerError) Error() string {
+	return fmt.Sprintf("user is not a manager of organization %s", string(n))
+}
+
+// InvalidInvitationError indicates that an invitation cannot be sent
+type InvalidInvitationError string
+
+func (i InvalidInvitationError) Error() string {
+	return fmt.Sprintf("invalid invitation: %s", string(i))
+}
+
+// InviteeNotAuthorizedError indicates that the invitee's email is not in an
+// authorized domain, so they could not sign in
+type InviteeNotAuthorizedError string
+
+func (i InviteeNotAuthorizedError) Error() string {
+	return fmt.Sprintf("%s is not in an authorized domain", string(i))
+}
+
+// MemberNotFoundError indicates that a user is not a member of the org
+type MemberNotFoundError string
+
+func (m MemberNotFoundError) Error() string {
+	return fmt.Sprintf("user %s is not a member of the organization", string(m))
+}
+
+// CannotRemoveSelfError indicates that the owner asked to remove themselves
+type CannotRemoveSelfError string
+
+func (c CannotRemoveSelfError) Error() string {
+	return fmt.Sprintf("user %s cannot remove themselves from the organization", string(c))
+}
+
+// ListHandler returns the members of the user's org, with their roles
+func (m *Members) ListHandler() http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		_, org, space, err := m.ownedOrg(req)
+		if err != nil {
+			writeError(w, req, err)
+			return
+		}
+		members, err := cloudfoundry.ListMembers(org.GUID, spaceGUID(space), m.CCAPI)
+		if err != nil {
+			writeError(w, req, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(members)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// InviteHandler adds the user with the email in the Invitation to the org,
+// creating their UAA user when it does not exist, and answers 201 Created
+// with the new member
+func (m *Members) InviteHandler() http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		_, org, space, err := m.ownedOrg(req)
+		if err != nil {
+			writeError(w, req, err)
+			return
+		}
+		var invitation Invitation
+		if err := json.NewDecoder(req.Body).Decode(&invitation); err != nil {
+			writeError(w, req, InvalidInvitationError("the body is not an invitation"))
+			return
+		}
+		email, roles, err := m.validate(invitation)
+		if err != nil {
+			writeError(w, req, err)
+			return
+		}
+
+		userID, err := m.ensureUAAUser(req, email)
+		if err != nil {
+			writeError(w, req, err)
+			return
+		}
+		err = cloudfoundry.GrantRoles(userID, org.GUID, spaceGUID(space), roles, m.CCAPI)
+		m.record(req, audit.ActionRoleGrant, email, err, map[string]string{"org": org.Name, "user_id": userID, "roles": strings.Join(roles, ",")})
+		if err != nil {
+			writeError(w, req, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(cloudfoundry.Member{UserID: userID, Username: email, Roles: roles})
+	}
+	return http.HandlerFunc(fn)
+}
+
+// RemoveHandler removes the member with the id in the {id} route variable
+// from the org and all of its spaces
+func (m *Members) RemoveHandler() http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		userID, org, _, err := m.ownedOrg(req)
+		if err != nil {
+			writeError(w, req, err)
+			return
+		}
+		id := mux.Vars(req)["id"]
+		if id == userID {
+			writeError(w, req, CannotRemoveSelfError(id))
+			return
+		}
+		members, err := cloudfoundry.ListMembers(org.GUID, "", m.CCAPI)
+		if err != nil {
+			writeError(w, req, err)
+			return
+		}
+		if !hasMember(members, id) {
+			writeError(w, req, MemberNotFoundError(id))
+			return
+		}
+		err = cloudfoundry.RemoveMember(id, org.GUID, m.CCAPI)
+		m.record(req, audit.ActionMemberRemove, id, err, map[string]string{"org": org.Name})
+		if err != nil {
+			writeError(w, req, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// ownedOrg returns the user's ID, their org and its space, which is nil when
+// it does not exist; it fails when the user is not a manager of the org
+func (m *Members) ownedOrg(req *http.Request) (string, *cloudfoundry.Organization, *cloudfoundry.Space, error) {
+	userID, orgName, quotaID, err := orgInfoFromRequest(req, m.OrgPrefix, m.QuotaID)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	org, err := FindOrgForUser(orgName, m.AppsURL, userID, quotaID, m.CCAPI)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	managers, err := m.CCAPI.ListOrgManagers(org.GUID)
+	if err != nil {
+		return "", nil, nil, errors.Wrapf(err, "could not find the managers of org [%s]", org.Name)
+	}
+	owner := false
+	for _, u := range managers {
+		if u.Guid == userID {
+			owner = true
+			break
+		}
+	}
+	if !owner {
+		return "", nil, nil, NotOrgOwnerError(org.Name)
+	}
+	space, err := cloudfoundry.FindSpace(m.SpaceName, org.GUID, m.CCAPI)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return userID, org, space, nil
+}
+
+// validate returns the invitee's email and the roles to grant them
+func (m *Members) validate(invitation Invitation) (string, []string, error) {
+	address, err := mail.ParseAddress(strings.TrimSpace(invitation.Email))
+	if err != nil {
+		return "", nil, InvalidInvitationError(fmt.Sprintf("[%s] is not an email address", invitation.Email))
+	}
+	email := strings.ToLower(address.Address)
+	if m.Authorized != nil && !m.Authorized(email) {
+		return "", nil, InviteeNotAuthorizedError(email)
+	}
+	roles := invitation.Roles
+	if len(roles) == 0 {
+		roles = []string{cloudfoundry.RoleSpaceDeveloper}
+	}
+	for _, role := range roles {
+		if !cloudfoundry.IsRole(role) {
+			return "", nil, InvalidInvitationError(fmt.Sprintf("[%s] is not one of the roles %s", role, strings.Join(cloudfoundry.Roles, ", ")))
+		}
+	}
+	return email, roles, nil
+}
+
+// ensureUAAUser returns the ID of the UAA user with the email as their
+// username, creating the user when they do not exist, in the same way that
+// users are created when they first sign in
+func (m *Members) ensureUAAUser(req *http.Request, email string) (string, error) {
+	userID, err := m.UAAAPI.UserIDForAccountName(email)
+	if err == nil && strings.TrimSpace(userID) != "" {
+		return userID, nil
+	}
+	userID, err = m.UAAAPI.CreateUser(email, m.UAAOrigin, email, email)
+	m.record(req, audit.ActionUserCreate, email, err, map[string]string{"user_id": userID})
+	if err != nil {
+		return "", errors.Wrapf(err, "could not create a user for invitee [%s]", email)
+	}
+	webhook.Notify(m.Webhooks, webhook.EventUserCreated, map[string]string{"user_id": userID, "account_name": email, "email": email, "origin": m.UAAOrigin})
+	return userID, nil
+}
+
+// record audits an action taken by the user making the request
+func (m *Members) record(req *http.Request, action string, target string, err error, details map[string]string) {
+	e := audit.Entry{Action: action, Target: target, Outcome: audit.Outcome(err), Details: details}
+	e.ActorID, e.Actor, _ = userInfoFromContext(req.Context())
+	if err != nil {
+		e.Details["error"] = err.Error()
+	}
+	audit.Record(m.Audit, e)
+}
+
+func hasMember(members []cloudfoundry.Member, userID string) bool {
+	for _, member := range members {
+		if member.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func spaceGUID(space *cloudfoundry.Space) string {
+	if space == nil {
+		return ""
+	}
+	return space.GUID
+}
@@ -0,0 +1,202 @@
+package organization_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	"github.com/gorilla/mux"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/audit"
+	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/cloudfoundry/cloudfoundryfakes"
+	"github.com/pivotalservices/ignition/http/organization"
+	"github.com/pivotalservices/ignition/http/session"
+	"github.com/pivotalservices/ignition/uaa/uaafakes"
+	"github.com/pivotalservices/ignition/user"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestMembers(t *testing.T) {
+	spec.Run(t, "Members", testMembers, spec.Report(report.Terminal{}))
+}
+
+func testMembers(t *testing.T, when spec.G, it spec.S) {
+	var (
+		w *httptest.ResponseRecorder
+		c *cloudfoundryfakes.FakeAPI
+		u *uaafakes.FakeAPI
+		m *organization.Members
+	)
+
+	request := func(method string, path string, body string) *http.Request {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		profile := &user.Profile{AccountName: "testuser@test.com", Email: "testuser@test.com"}
+		return r.WithContext(user.WithProfile(session.ContextWithUserID(r.Context(), "test-user-id"), profile))
+	}
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		w = httptest.NewRecorder()
+		c = &cloudfoundryfakes.FakeAPI{}
+		c.ListOrgsByQueryReturns([]cfclient.Org{{Guid: "test-org-guid", Name: "ignition-testuser"}}, nil)
+		c.ListOrgManagersReturns([]cfclient.User{{Guid: "test-user-id"}}, nil)
+		c.ListSpacesByQueryReturns([]cfclient.Space{{Guid: "test-space-guid", Name: "playground"}}, nil)
+		u = &uaafakes.FakeAPI{}
+		m = &organization.Members{
+			AppsURL:    "https://apps.example.net",
+			OrgPrefix:  "ignition",
+			QuotaID:    "test-quota-id",
+			SpaceName:  "playground",
+			UAAOrigin:  "test-origin",
+			Authorized: func(email string) bool { return strings.HasSuffix(email, "@test.com") },
+			CCAPI:      c,
+			UAAAPI:     u,
+			Audit:      audit.NewLog(),
+		}
+	})
+
+	it("is forbidden for users that do not manage the org", func() {
+		c.ListOrgManagersReturns([]cfclient.User{{Guid: "other-user-id"}}, nil)
+		m.ListHandler().ServeHTTP(w, request(http.MethodGet, "/organization/members", ""))
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(w.Body.String()).To(ContainSubstring("not_org_owner"))
+	})
+
+	it("is not found before the org has been created", func() {
+		c.ListOrgsByQueryReturns(nil, nil)
+		m.ListHandler().ServeHTTP(w, request(http.MethodGet, "/organization/members", ""))
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+	})
+
+	it("lists the members of the org", func() {
+		c.ListOrgUsersReturns([]cfclient.User{{Guid: "test-user-id", Username: "testuser@test.com"}}, nil)
+		c.ListSpaceDevelopersReturns([]cfclient.User{{Guid: "test-user-id"}}, nil)
+		m.ListHandler().ServeHTTP(w, request(http.MethodGet, "/organization/members", ""))
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var members []cloudfoundry.Member
+		Expect(json.Unmarshal(w.Body.Bytes(), &members)).To(Succeed())
+		Expect(members).To(Equal([]cloudfoundry.Member{
+			{UserID: "test-user-id", Username: "testuser@test.com", Roles: []string{cloudfoundry.RoleOrgManager, cloudfoundry.RoleSpaceDeveloper}},
+		}))
+	})
+
+	when("inviting a user", func() {
+		it("creates the UAA user and grants the roles", func() {
+			u.UserIDForAccountNameReturns("", errors.New("user not found"))
+			u.CreateUserReturns("new-user-id", nil)
+			m.InviteHandler().ServeHTTP(w, request(http.MethodPost, "/organization/members", `{"email": "Teammate@test.com", "roles": ["space_manager"]}`))
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			var member cloudfoundry.Member
+			Expect(json.Unmarshal(w.Body.Bytes(), &member)).To(Succeed())
+			Expect(member).To(Equal(cloudfoundry.Member{UserID: "new-user-id", Username: "teammate@test.com", Roles: []string{cloudfoundry.RoleSpaceManager}}))
+
+			username, origin, externalID, email := u.CreateUserArgsForCall(0)
+			Expect(username).To(Equal("teammate@test.com"))
+			Expect(origin).To(Equal("test-origin"))
+			Expect(externalID).To(Equal("teammate@test.com"))
+			Expect(email).To(Equal("teammate@test.com"))
+			orgGUID, userID := c.AssociateOrgUserArgsForCall(0)
+			Expect(orgGUID).To(Equal("test-org-guid"))
+			Expect(userID).To(Equal("new-user-id"))
+			spaceGUID, userID := c.AssociateSpaceManagerArgsForCall(0)
+			Expect(spaceGUID).To(Equal("test-space-guid"))
+			Expect(userID).To(Equal("new-user-id"))
+
+			entries, err := m.Audit.(*audit.Log).Query(audit.Filter{Actor: "testuser@test.com"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(2))
+			Expect(entries[1].Action).To(Equal(audit.ActionUserCreate))
+			Expect(entries[0].Action).To(Equal(audit.ActionRoleGrant))
+			Expect(entries[0].Target).To(Equal("teammate@test.com"))
+			Expect(entries[0].Details["roles"]).To(Equal("space_manager"))
+		})
+
+		it("uses an existing UAA user and makes them a space developer by default", func() {
+			u.UserIDForAccountNameReturns("existing-user-id", nil)
+			m.InviteHandler().ServeHTTP(w, request(http.MethodPost, "/organization/members", `{"email": "teammate@test.com"}`))
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			Expect(u.CreateUserCallCount()).To(Equal(0))
+			_, userID := c.AssociateSpaceDeveloperArgsForCall(0)
+			Expect(userID).To(Equal("existing-user-id"))
+		})
+
+		it("rejects invitees outside the authorized domains", func() {
+			m.InviteHandler().ServeHTTP(w, request(http.MethodPost, "/organization/members", `{"email": "someone@example.com"}`))
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			Expect(w.Body.String()).To(ContainSubstring("invitee_not_authorized"))
+			Expect(u.UserIDForAccountNameCallCount()).To(Equal(0))
+		})
+
+		it("rejects invalid invitations", func() {
+			for _, body := range []string{`{"email": "not an email"}`, `{"email": "teammate@test.com", "roles": ["admin"]}`, `[]`} {
+				w = httptest.NewRecorder()
+				m.InviteHandler().ServeHTTP(w, request(http.MethodPost, "/organization/members", body))
+				Expect(w.Code).To(Equal(http.StatusBadRequest), body)
+				Expect(w.Body.String()).To(ContainSubstring("invalid_invitation"))
+			}
+			Expect(c.AssociateOrgUserCallCount()).To(Equal(0))
+		})
+	})
+
+	when("removing a member", func() {
+		remove := func(id string) {
+			r := mux.SetURLVars(request(http.MethodDelete, "/organization/members/"+id, ""), map[string]string{"id": id})
+			m.RemoveHandler().ServeHTTP(w, r)
+		}
+
+		it.Before(func() {
+			c.ListOrgUsersReturns([]cfclient.User{{Guid: "test-user-id"}, {Guid: "teammate-id"}}, nil)
+		})
+
+		it("removes them from the org and all of its spaces", func() {
+			remove("teammate-id")
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+			spaceGUID, userID := c.RemoveSpaceDeveloperArgsForCall(0)
+			Expect(spaceGUID).To(Equal("test-space-guid"))
+			Expect(userID).To(Equal("teammate-id"))
+			orgGUID, userID := c.RemoveOrgUserArgsForCall(0)
+			Expect(orgGUID).To(Equal("test-org-guid"))
+			Expect(userID).To(Equal("teammate-id"))
+			entries, _ := m.Audit.(*audit.Log).Query(audit.Filter{Action: audit.ActionMemberRemove})
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Target).To(Equal("teammate-id"))
+			Expect(entries[0].ActorID).To(Equal("test-user-id"))
+		})
+
+		it("revokes their roles in a space other than the playground", func() {
+			c.ListSpacesByQueryStub = func(q url.Values) ([]cfclient.Space, error) {
+				if q["q"][0] == "organization_guid:test-org-guid" {
+					return []cfclient.Space{{Guid: "test-space-guid", Name: "playground"}, {Guid: "staging-space-guid", Name: "staging"}}, nil
+				}
+				return []cfclient.Space{{Guid: "test-space-guid", Name: "playground"}}, nil
+			}
+			remove("teammate-id")
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+			Expect(c.RemoveSpaceDeveloperCallCount()).To(Equal(2))
+			spaceGUID, userID := c.RemoveSpaceDeveloperArgsForCall(1)
+			Expect(spaceGUID).To(Equal("staging-space-guid"))
+			Expect(userID).To(Equal("teammate-id"))
+			Expect(c.RemoveOrgUserCallCount()).To(Equal(1))
+		})
+
+		it("does not remove users that are not members", func() {
+			remove("stranger-id")
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+			Expect(w.Body.String()).To(ContainSubstring("member_not_found"))
+			Expect(c.RemoveOrgUserCallCount()).To(Equal(0))
+		})
+
+		it("does not let the owner remove themselves", func() {
+			remove("test-user-id")
+			Expect(w.Code).To(Equal(http.StatusConflict))
+			Expect(c.RemoveOrgUserCallCount()).To(Equal(0))
+		})
+	})
+}
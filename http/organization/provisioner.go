@@ -0,0 +1,450 @@
+package organization
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotalservices/ignition/audit"
+	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/http/events"
+	"github.com/pivotalservices/ignition/notify"
+	"github.com/pivotalservices/ignition/webhook"
+	"github.com/pkg/errors"
+)
+
+// DefaultWorkers is the number of jobs that are provisioned at the same time
+// when Provisioner.Workers is not set
+const DefaultWorkers = 4
+
+// DefaultQueueSize is the number of jobs that may wait for a worker when
+// Provisioner.QueueSize is not set
+const DefaultQueueSize = 100
+
+// ErrQueueFull is returned by Enqueue when no more jobs can be accepted
+var ErrQueueFull = errors.New("the provisioning queue is full")
+
+// ErrStopped is returned by Enqueue after the Provisioner has been stopped
+var ErrStopped = errors.New("the provisioner is stopped")
+
+// Provisioner creates organizations in the background with a pool of workers
+type Provisioner struct {
+	AppsURL   string
+	SpaceName string
+	API       cloudfoundry.API
+	Store     *JobStore
+	Workers   int
+	QueueSize int
+	Events    events.Publisher
+	// Template is applied to the new org and space
+	Template cloudfoundry.OrgTemplate
+	// StarterServices are created in the new space
+	StarterServices []cloudfoundry.StarterService
+	// SampleApp is pushed into the new space when it is set
+	SampleApp *cloudfoundry.SampleApp
+	// Audit records the outcome of each step that changes the platform
+	Audit audit.Recorder
+	// Webhooks are notified of the orgs that are created
+	Webhooks webhook.Notifier
+	// Mail sends the welcome email when the org has been created, with the
+	// cf CLI commands for the APIURL and UAAURL
+	Mail   *notify.Notifier
+	APIURL string
+	UAAURL string
+
+	once    sync.Once
+	mu      sync.Mutex
+	queue   chan string
+	done    chan struct{}
+	stopped bool
+	wg      sync.WaitGroup
+	now     func() time.Time
+}
+
+func (p *Provisioner) init() {
+	p.once.Do(func() {
+		size := p.QueueSize
+		if size <= 0 {
+			size = DefaultQueueSize
+		}
+		p.queue = make(chan string, size)
+		p.done = make(chan struct{})
+		if p.now == nil {
+			p.now = time.Now
+		}
+	})
+}
+
+// Start runs the workers, and requeues the jobs that were unfinished when the
+// process last stopped
+func (p *Provisioner) Start() {
+	p.init()
+	workers := p.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+	for _, j := range p.Store.Jobs() {
+		if j.Finished() {
+			continue
+		}
+		select {
+		case p.queue <- j.ID:
+		default:
+			log.Printf("could not requeue provisioning job [%s]: %v\n", j.ID, ErrQueueFull)
+		}
+	}
+}
+
+// Stop stops accepting jobs and waits for the workers to finish the jobs they
+// have started, without starting the queued jobs; they are still queued in the
+// store, and are resumed by the next Start
+func (p *Provisioner) Stop() {
+	p.init()
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return
+	}
+	p.stopped = true
+	close(p.done)
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+// Enqueue queues a job to provision the org for the user, or returns the user's
+// unfinished job when there already is one; the welcome email is sent to the
+// email address, greeting the user by name, when the org has been created
+func (p *Provisioner) Enqueue(userID string, email string, name string, orgName string, quotaID string) (Job, error) {
+	p.init()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return Job{}, ErrStopped
+	}
+	if j, ok := p.Store.ActiveJobForUser(userID); ok {
+		return j, nil
+	}
+	j, err := newJob(userID, email, name, orgName, quotaID, p.steps(), p.now())
+	if err != nil {
+		return Job{}, err
+	}
+	if len(p.queue) == cap(p.queue) {
+		return Job{}, ErrQueueFull
+	}
+	if err := p.Store.Save(j); err != nil {
+		return Job{}, err
+	}
+	p.queue <- j.ID
+	return j, nil
+}
+
+// steps are the names of the steps of a new job
+func (p *Provisioner) steps() []string {
+	steps := []string{StepCreateOrg, StepAssignOrgRoles, StepCreateSpace}
+	if !p.Template.Empty() {
+		steps = append(steps, StepApplyTemplate)
+	}
+	if len(p.StarterServices) > 0 {
+		steps = append(steps, StepCreateServices)
+	}
+	if p.SampleApp != nil {
+		steps = append(steps, StepPushSampleApp)
+	}
+	if p.Mail != nil {
+		steps = append(steps, StepSendWelcome)
+	}
+	return steps
+}
+
+func (p *Provisioner) work() {
+	defer p.wg.Done()
+	for {
+		// a stopped provisioner does not start another job, even when one is
+		// queued
+		select {
+		case <-p.done:
+			return
+		default:
+		}
+		select {
+		case <-p.done:
+			return
+		case id := <-p.queue:
+			j, ok := p.Store.Job(id)
+			if !ok || j.Finished() {
+				continue
+			}
+			p.run(j)
+		}
+	}
+}
+
+// run provisions the org for the job, saving its progress after each step. A
+// job that was interrupted may already have created the org; it is resumed
+// from its first unfinished step
+func (p *Provisioner) run(j Job) {
+	j.Status = StatusRunning
+	p.save(&j)
+
+	progress := p.progress(&j)
+	org, err := FindOrgForUser(j.OrgName, p.AppsURL, j.UserID, j.QuotaID, p.API)
+	var space *cloudfoundry.Space
+	switch err.(type) {
+	case nil:
+		space, err = p.resume(&j, org, progress)
+	case OrgNotFoundError:
+		org, space, err = createOrgForUser(j.OrgName, p.AppsURL, j.UserID, j.QuotaID, p.SpaceName, !p.Template.DisableSSH, p.API, progress)
+	}
+	if err != nil {
+		p.finish(&j, nil, err)
+		return
+	}
+	if !p.Template.Empty() && !j.succeeded(StepApplyTemplate) {
+		p.applyTemplate(org, space, progress)
+	}
+	if len(p.StarterServices) > 0 && !j.succeeded(StepCreateServices) {
+		j.Services = p.createServices(org, space, progress)
+	}
+	if p.SampleApp != nil {
+		org.SampleApp = p.pushSampleApp(&j, org, space, progress)
+	}
+	if p.Mail != nil && !j.stepStarted(StepSendWelcome) {
+		p.welcome(j, org, progress)
+	}
+	p.finish(&j, org, nil)
+}
+
+// resume completes the org and space steps of an interrupted job whose org
+// exists, and returns the space; a space that was created before the job was
+// interrupted is found rather than created again
+func (p *Provisioner) resume(j *Job, org *cloudfoundry.Organization, progress func(step string, status string, err error)) (*cloudfoundry.Space, error) {
+	if !j.succeeded(StepCreateOrg) {
+		progress(StepCreateOrg, StatusSucceeded, nil)
+	}
+	if !j.succeeded(StepAssignOrgRoles) {
+		assignOrgRoles(org.GUID, j.UserID, p.API, progress)
+	}
+	space, err := cloudfoundry.FindSpace(p.SpaceName, org.GUID, p.API)
+	if err != nil {
+		return nil, err
+	}
+	if space == nil {
+		return createSpace(p.SpaceName, org.GUID, j.UserID, !p.Template.DisableSSH, p.API, progress), nil
+	}
+	if !j.succeeded(StepCreateSpace) {
+		progress(StepCreateSpace, StatusSucceeded, nil)
+	}
+	return space, nil
+}
+
+// welcome emails the user the details of their new org. The step is started
+// before the email is sent, so that a job that is resumed after it was
+// interrupted does not send it again; the org is usable without it, so a
+// failure only fails the step
+func (p *Provisioner) welcome(j Job, org *cloudfoundry.Organization, progress func(step string, status string, err error)) {
+	progress(StepSendWelcome, StatusRunning, nil)
+	g := NewGettingStarted(p.APIURL, p.UAAURL, org, p.SpaceName)
+	err := p.Mail.Welcome(notify.Welcome{
+		Name:          j.Name,
+		Email:         j.Email,
+		Org:           g.Org,
+		Space:         g.Space,
+		OrgURL:        g.OrgURL,
+		APIURL:        g.APIURL,
+		PasscodeURL:   g.PasscodeURL,
+		LoginCommand:  g.Commands.Login,
+		TargetCommand: g.Commands.Target,
+	})
+	if err != nil {
+		log.Printf("could not send the welcome email for provisioning job [%s]: %v\n", j.ID, err)
+	}
+	progress(StepSendWelcome, stepStatus(err), err)
+}
+
+// progress returns a function that records the progress of each of the job's
+// steps, and publishes an event when a step succeeds
+func (p *Provisioner) progress(j *Job) func(step string, status string, err error) {
+	return func(step string, status string, err error) {
+		for i := range j.Steps {
+			if j.Steps[i].Name != step {
+				continue
+			}
+			j.Steps[i].Status = status
+			j.Steps[i].Error = ""
+			if err != nil {
+				j.Steps[i].Error = err.Error()
+			}
+			j.Steps[i].UpdatedAt = p.now()
+		}
+		p.save(j)
+		if eventType, ok := stepEvents[step]; ok && status == StatusSucceeded {
+			p.publish(*j, eventType)
+		}
+		if step == StepCreateOrg && status == StatusSucceeded {
+			webhook.Notify(p.Webhooks, webhook.EventOrgCreated, map[string]string{"org_name": j.OrgName, "quota_id": j.QuotaID, "user_id": j.UserID, "job_id": j.ID})
+		}
+		if status == StatusSucceeded || status == StatusFailed {
+			p.audit(*j, step, err)
+		}
+	}
+}
+
+// applyTemplate applies the template to the new org and space; like the other
+// steps after the org is created, a failure only fails the step
+func (p *Provisioner) applyTemplate(org *cloudfoundry.Organization, space *cloudfoundry.Space, progress func(step string, status string, err error)) {
+	progress(StepApplyTemplate, StatusRunning, nil)
+	err := cloudfoundry.ApplyOrgTemplate(p.Template, org, space, p.API)
+	if err != nil {
+		log.Printf("could not apply the org template to org [%s]: %v\n", org.Name, err)
+	}
+	progress(StepApplyTemplate, stepStatus(err), err)
+}
+
+// createServices creates the starter services in the new space, and returns
+// the status of each of them; like the other space steps, a failure only fails
+// the step
+func (p *Provisioner) createServices(org *cloudfoundry.Organization, space *cloudfoundry.Space, progress func(step string, status string, err error)) []cloudfoundry.ServiceInstance {
+	if space == nil {
+		progress(StepCreateServices, StatusSkipped, nil)
+		return nil
+	}
+	progress(StepCreateServices, StatusRunning, nil)
+	var (
+		result   []cloudfoundry.ServiceInstance
+		failures []string
+	)
+	for _, s := range p.StarterServices {
+		instance, err := cloudfoundry.CreateServiceInstance(s, org.GUID, space.GUID, p.API)
+		if err != nil {
+			log.Printf("could not create service instance [%s] in org [%s]: %v\n", s.InstanceName, org.Name, err)
+			failures = append(failures, err.Error())
+			instance = &cloudfoundry.ServiceInstance{Name: s.InstanceName, Offering: s.Offering, Plan: s.Plan, State: StatusFailed}
+		}
+		result = append(result, *instance)
+	}
+	var err error
+	if len(failures) > 0 {
+		err = errors.New(strings.Join(failures, "; "))
+	}
+	progress(StepCreateServices, stepStatus(err), err)
+	return result
+}
+
+// pushSampleApp pushes the sample app into the new space, unless an
+// interrupted run of the job already pushed it; the org is usable without it,
+// so a failure only fails the step and not the job
+func (p *Provisioner) pushSampleApp(j *Job, org *cloudfoundry.Organization, space *cloudfoundry.Space, progress func(step string, status string, err error)) *cloudfoundry.App {
+	if space == nil {
+		progress(StepPushSampleApp, StatusSkipped, nil)
+		return nil
+	}
+	if j.stepStarted(StepPushSampleApp) {
+		app, err := cloudfoundry.FindApp(p.SampleApp.Name, org.GUID, p.API)
+		if err != nil {
+			log.Printf("could not find the sample app in org [%s]: %v\n", org.Name, err)
+		}
+		if app != nil {
+			if !j.succeeded(StepPushSampleApp) {
+				progress(StepPushSampleApp, StatusSucceeded, nil)
+			}
+			return app
+		}
+		if j.succeeded(StepPushSampleApp) {
+			return nil
+		}
+	}
+	progress(StepPushSampleApp, StatusRunning, nil)
+	app, err := cloudfoundry.PushApp(*p.SampleApp, space.GUID, cloudfoundry.AppHost(p.SampleApp.Name, org.Name), p.API)
+	if err != nil {
+		log.Printf("could not push the sample app to org [%s]: %v\n", org.Name, err)
+	}
+	progress(StepPushSampleApp, stepStatus(err), err)
+	return app
+}
+
+func (p *Provisioner) finish(j *Job, org *cloudfoundry.Organization, err error) {
+	if err != nil {
+		log.Printf("provisioning job [%s] failed: %v\n", j.ID, err)
+		_, code, message, retryable := describeError(err)
+		j.Status = StatusFailed
+		j.Error = &JobError{Code: code, Message: message, Retryable: retryable}
+		p.publish(*j, events.ProvisioningFailed)
+		for i := range j.Steps {
+			if j.Steps[i].Status == StatusQueued {
+				j.Steps[i].Status = StatusSkipped
+				j.Steps[i].UpdatedAt = p.now()
+			}
+		}
+	} else {
+		j.Status = StatusSucceeded
+		j.Org = org
+	}
+	p.save(j)
+}
+
+func (p *Provisioner) save(j *Job) {
+	j.UpdatedAt = p.now()
+	if err := p.Store.Save(*j); err != nil {
+		log.Printf("could not save provisioning job [%s]: %v\n", j.ID, err)
+	}
+}
+
+// stepEvents are the events that are published when each step succeeds
+var stepEvents = map[string]string{
+	StepCreateOrg:      events.OrgCreated,
+	StepAssignOrgRoles: events.RolesGranted,
+	StepCreateSpace:    events.SpaceReady,
+	StepCreateServices: events.ServicesReady,
+	StepPushSampleApp:  events.SampleAppReady,
+}
+
+// stepActions are the audited actions of the steps
+var stepActions = map[string]string{
+	StepCreateOrg:      audit.ActionOrgCreate,
+	StepAssignOrgRoles: audit.ActionRoleGrant,
+	StepCreateSpace:    audit.ActionSpaceCreate,
+	StepApplyTemplate:  audit.ActionTemplateApply,
+	StepCreateServices: audit.ActionServiceCreate,
+	StepPushSampleApp:  audit.ActionAppPush,
+}
+
+// audit records the outcome of the step; the space steps are audited with the
+// space as their target
+func (p *Provisioner) audit(j Job, step string, err error) {
+	action, ok := stepActions[step]
+	if !ok {
+		return
+	}
+	target := j.OrgName
+	switch step {
+	case StepCreateSpace, StepCreateServices, StepPushSampleApp:
+		target = j.OrgName + "/" + strings.ToLower(p.SpaceName)
+	}
+	e := audit.Entry{ActorID: j.UserID, Action: action, Target: target, Outcome: audit.Outcome(err), Details: map[string]string{"job_id": j.ID}}
+	if step == StepAssignOrgRoles {
+		e.Details["roles"] = "org_user,org_manager,org_auditor"
+	}
+	if err != nil {
+		e.Details["error"] = err.Error()
+	}
+	audit.Record(p.Audit, e)
+}
+
+func (p *Provisioner) publish(j Job, eventType string) {
+	if p.Events == nil {
+		return
+	}
+	data := map[string]interface{}{
+		"job_id":   j.ID,
+		"org_name": j.OrgName,
+	}
+	if j.Error != nil {
+		data["error"] = j.Error
+	}
+	p.Events.Publish(j.UserID, eventType, data)
+}
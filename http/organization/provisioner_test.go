@@ -0,0 +1,543 @@
+package organization_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/audit"
+	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/cloudfoundry/cloudfoundryfakes"
+	"github.com/pivotalservices/ignition/http/events"
+	"github.com/pivotalservices/ignition/http/organization"
+	"github.com/pivotalservices/ignition/notify"
+	"github.com/pivotalservices/ignition/webhook"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+// outbox records the emails it is asked to send
+type outbox struct {
+	mu       sync.Mutex
+	messages []notify.Message
+}
+
+func (o *outbox) Send(m notify.Message) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages = append(o.messages, m)
+	return nil
+}
+
+func (o *outbox) sent() []notify.Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]notify.Message(nil), o.messages...)
+}
+
+func TestProvisioner(t *testing.T) {
+	spec.Run(t, "Provisioner", testProvisioner, spec.Report(report.Terminal{}))
+}
+
+func testProvisioner(t *testing.T, when spec.G, it spec.S) {
+	var (
+		c *cloudfoundryfakes.FakeAPI
+		p *organization.Provisioner
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		c = &cloudfoundryfakes.FakeAPI{}
+		c.ListOrgsByQueryReturns(nil, nil)
+		c.CreateOrgReturns(cfclient.Org{Guid: "test-org-guid", Name: "ignition-testuser"}, nil)
+		c.CreateSpaceReturns(cfclient.Space{Guid: "test-space-guid"}, nil)
+		store, err := organization.NewJobStore("")
+		Expect(err).NotTo(HaveOccurred())
+		p = &organization.Provisioner{
+			AppsURL:   "http://example.net",
+			SpaceName: "playground",
+			API:       c,
+			Store:     store,
+			Workers:   2,
+		}
+	})
+
+	it.After(func() {
+		p.Stop()
+	})
+
+	finished := func(id string) func() bool {
+		return func() bool {
+			j, _ := p.Store.Job(id)
+			return j.Finished()
+		}
+	}
+
+	it("provisions the org step by step", func() {
+		p.Start()
+		j, err := p.Enqueue("test-user-id", "", "", "ignition-testuser", "test-quota-id")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(finished(j.ID), time.Second).Should(BeTrue())
+
+		j, _ = p.Store.Job(j.ID)
+		Expect(j.Status).To(Equal(organization.StatusSucceeded))
+		Expect(j.Org).NotTo(BeNil())
+		Expect(j.Org.GUID).To(Equal("test-org-guid"))
+		Expect(j.Org.URL).To(Equal("http://example.net/organizations/test-org-guid"))
+		for _, s := range j.Steps {
+			Expect(s.Status).To(Equal(organization.StatusSucceeded), s.Name)
+		}
+		Expect(c.CreateOrgArgsForCall(0).QuotaDefinitionGuid).To(Equal("test-quota-id"))
+		Expect(c.AssociateOrgManagerCallCount()).To(Equal(1))
+		Expect(c.CreateSpaceArgsForCall(0).Name).To(Equal("playground"))
+		Expect(c.CreateSpaceArgsForCall(0).AllowSSH).To(BeTrue())
+	})
+
+	it("publishes each onboarding state transition", func() {
+		broker := events.NewBroker()
+		p.Events = broker
+		p.Start()
+		j, err := p.Enqueue("test-user-id", "", "", "ignition-testuser", "test-quota-id")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(finished(j.ID), time.Second).Should(BeTrue())
+
+		published, _, cancel := broker.Subscribe("test-user-id", 0)
+		cancel()
+		var types []string
+		for _, e := range published {
+			types = append(types, e.Type)
+		}
+		Expect(types).To(Equal([]string{events.OrgCreated, events.RolesGranted, events.SpaceReady}))
+	})
+
+	it("publishes when provisioning fails", func() {
+		broker := events.NewBroker()
+		p.Events = broker
+		c.CreateOrgReturns(cfclient.Org{}, errors.New("test error"))
+		p.Start()
+		j, err := p.Enqueue("test-user-id", "", "", "ignition-testuser", "test-quota-id")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(finished(j.ID), time.Second).Should(BeTrue())
+
+		published, _, cancel := broker.Subscribe("test-user-id", 0)
+		cancel()
+		Expect(published).To(HaveLen(1))
+		Expect(published[0].Type).To(Equal(events.ProvisioningFailed))
+	})
+
+	it("records failed steps that do not fail the job", func() {
+		c.AssociateOrgManagerReturns(cfclient.Org{}, errors.New("test error"))
+		p.Start()
+		j, err := p.Enqueue("test-user-id", "", "", "ignition-testuser", "test-quota-id")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(finished(j.ID), time.Second).Should(BeTrue())
+
+		j, _ = p.Store.Job(j.ID)
+		Expect(j.Status).To(Equal(organization.StatusSucceeded))
+		Expect(j.Steps[1].Name).To(Equal(organization.StepAssignOrgRoles))
+		Expect(j.Steps[1].Status).To(Equal(organization.StatusFailed))
+		Expect(j.Steps[1].Error).To(Equal("test error"))
+		Expect(j.Steps[2].Status).To(Equal(organization.StatusSucceeded))
+	})
+
+	it("audits each step that changes the platform", func() {
+		l := audit.NewLog()
+		p.Audit = l
+		c.AssociateOrgManagerReturns(cfclient.Org{}, errors.New("test error"))
+		p.Start()
+		j, err := p.Enqueue("test-user-id", "", "", "ignition-testuser", "test-quota-id")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(finished(j.ID), time.Second).Should(BeTrue())
+
+		entries, err := l.Query(audit.Filter{Actor: "test-user-id"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(3))
+		Expect(entries[2].Action).To(Equal(audit.ActionOrgCreate))
+		Expect(entries[2].Target).To(Equal("ignition-testuser"))
+		Expect(entries[2].Outcome).To(Equal(audit.OutcomeSuccess))
+		Expect(entries[1].Action).To(Equal(audit.ActionRoleGrant))
+		Expect(entries[1].Outcome).To(Equal(audit.OutcomeFailure))
+		Expect(entries[1].Details["error"]).To(Equal("test error"))
+		Expect(entries[0].Action).To(Equal(audit.ActionSpaceCreate))
+		Expect(entries[0].Target).To(Equal("ignition-testuser/playground"))
+	})
+
+	it("notifies the webhooks that the org was created", func() {
+		d := &webhook.Dispatcher{Endpoints: []webhook.Endpoint{{URL: "https://hooks.example.com", Secret: "test-secret"}}}
+		p.Webhooks = d
+		p.Start()
+		j, err := p.Enqueue("test-user-id", "", "", "ignition-testuser", "test-quota-id")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(finished(j.ID), time.Second).Should(BeTrue())
+
+		deliveries := d.History(webhook.Filter{})
+		Expect(deliveries).To(HaveLen(1))
+		Expect(deliveries[0].Event.Type).To(Equal(webhook.EventOrgCreated))
+		Expect(deliveries[0].Event.Data).To(HaveKeyWithValue("org_name", "ignition-testuser"))
+		Expect(deliveries[0].Event.Data).To(HaveKeyWithValue("quota_id", "test-quota-id"))
+		Expect(deliveries[0].Event.Data).To(HaveKeyWithValue("user_id", "test-user-id"))
+	})
+
+	it("emails the user the details of their new org", func() {
+		o := &outbox{}
+		p.Mail = &notify.Notifier{From: "ignition@example.net", Sender: o}
+		p.APIURL = "https://api.example.net"
+		p.UAAURL = "https://login.example.net"
+		p.Start()
+		j, err := p.Enqueue("test-user-id", "testuser@test.com", "Test User", "ignition-testuser", "test-quota-id")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(o.sent, time.Second).Should(HaveLen(1))
+
+		m := o.sent()[0]
+		Expect(m.To).To(Equal("testuser@test.com"))
+		Expect(m.Subject).To(Equal("Your development org ignition-testuser is ready"))
+		Expect(m.Body).To(ContainSubstring("Hi Test User,"))
+		Expect(m.Body).To(ContainSubstring("http://example.net/organizations/test-org-guid"))
+		Expect(m.Body).To(ContainSubstring("https://login.example.net/passcode"))
+		Expect(m.Body).To(ContainSubstring("cf login -a https://api.example.net --sso"))
+		Expect(m.Body).To(ContainSubstring("cf target -o ignition-testuser -s playground"))
+		Eventually(finished(j.ID), time.Second).Should(BeTrue())
+		j, _ = p.Store.Job(j.ID)
+		Expect(j.Email).To(Equal("testuser@test.com"))
+		Expect(j.Steps[3].Name).To(Equal(organization.StepSendWelcome))
+		Expect(j.Steps[3].Status).To(Equal(organization.StatusSucceeded))
+	})
+
+	it("does not email the user again when a job that started the email is resumed", func() {
+		out := &outbox{}
+		p.Mail = &notify.Notifier{From: "ignition@example.com", Sender: out}
+		now := time.Now()
+		Expect(p.Store.Save(organization.Job{
+			ID:      "interrupted",
+			UserID:  "test-user-id",
+			Email:   "testuser@example.com",
+			OrgName: "ignition-testuser",
+			QuotaID: "test-quota-id",
+			Status:  organization.StatusRunning,
+			Steps: []organization.Step{
+				{Name: organization.StepCreateOrg, Status: organization.StatusSucceeded},
+				{Name: organization.StepAssignOrgRoles, Status: organization.StatusSucceeded},
+				{Name: organization.StepCreateSpace, Status: organization.StatusSucceeded},
+				{Name: organization.StepSendWelcome, Status: organization.StatusRunning},
+			},
+			CreatedAt: now,
+			UpdatedAt: now,
+		})).To(Succeed())
+		c.ListOrgsByQueryReturns([]cfclient.Org{{Guid: "test-org-guid", Name: "ignition-testuser"}}, nil)
+		c.ListSpacesByQueryReturns([]cfclient.Space{{Guid: "test-space-guid", Name: "playground"}}, nil)
+		p.Start()
+		Eventually(finished("interrupted"), time.Second).Should(BeTrue())
+
+		j, _ := p.Store.Job("interrupted")
+		Expect(j.Status).To(Equal(organization.StatusSucceeded))
+		Expect(out.sent()).To(BeEmpty())
+	})
+
+	it("does not email the user when the org is not created", func() {
+		o := &outbox{}
+		p.Mail = &notify.Notifier{From: "ignition@example.net", Sender: o}
+		c.CreateOrgReturns(cfclient.Org{}, errors.New("test error"))
+		p.Start()
+		j, err := p.Enqueue("test-user-id", "testuser@test.com", "", "ignition-testuser", "test-quota-id")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(finished(j.ID), time.Second).Should(BeTrue())
+		Consistently(o.sent, 100*time.Millisecond).Should(BeEmpty())
+		j, _ = p.Store.Job(j.ID)
+		Expect(j.Steps[3].Name).To(Equal(organization.StepSendWelcome))
+		Expect(j.Steps[3].Status).To(Equal(organization.StatusSkipped))
+	})
+
+	it("fails the job when the org name is taken", func() {
+		c.CreateOrgReturns(cfclient.Org{}, cfclient.CloudFoundryError{Code: 30002})
+		p.Start()
+		j, err := p.Enqueue("test-user-id", "", "", "ignition-testuser", "test-quota-id")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(finished(j.ID), time.Second).Should(BeTrue())
+
+		j, _ = p.Store.Job(j.ID)
+		Expect(j.Status).To(Equal(organization.StatusFailed))
+		Expect(j.Error).To(Equal(&organization.JobError{
+			Code:      "org_name_taken",
+			Message:   "An organization named ignition-testuser already exists, and you are not a member of it.",
+			Retryable: false,
+		}))
+		Expect(j.Steps[0].Status).To(Equal(organization.StatusFailed))
+		Expect(j.Steps[1].Status).To(Equal(organization.StatusSkipped))
+		Expect(j.Steps[2].Status).To(Equal(organization.StatusSkipped))
+	})
+
+	when("an org template is configured", func() {
+		it.Before(func() {
+			p.Template = cloudfoundry.OrgTemplate{SecurityGroups: []string{"public-networks"}, DisableSSH: true}
+			c.GetSecGroupByNameReturns(cfclient.SecGroup{Guid: "test-group-guid"}, nil)
+		})
+
+		it("applies it to the new org and space", func() {
+			p.Start()
+			j, err := p.Enqueue("test-user-id", "", "", "ignition-testuser", "test-quota-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(j.Steps[3].Name).To(Equal(organization.StepApplyTemplate))
+			Eventually(finished(j.ID), time.Second).Should(BeTrue())
+
+			j, _ = p.Store.Job(j.ID)
+			Expect(j.Steps[3].Status).To(Equal(organization.StatusSucceeded))
+			Expect(c.CreateSpaceArgsForCall(0).AllowSSH).To(BeFalse())
+			_, spaceGUID := c.BindSecGroupArgsForCall(0)
+			Expect(spaceGUID).To(Equal("test-space-guid"))
+		})
+
+		it("fails the step but not the job when it cannot be applied", func() {
+			c.BindSecGroupReturns(errors.New("test error"))
+			p.Start()
+			j, err := p.Enqueue("test-user-id", "", "", "ignition-testuser", "test-quota-id")
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(finished(j.ID), time.Second).Should(BeTrue())
+
+			j, _ = p.Store.Job(j.ID)
+			Expect(j.Status).To(Equal(organization.StatusSucceeded))
+			Expect(j.Steps[3].Status).To(Equal(organization.StatusFailed))
+		})
+	})
+
+	when("starter services are configured", func() {
+		it.Before(func() {
+			p.StarterServices = []cloudfoundry.StarterService{
+				{Offering: "p.mysql", Plan: "db-small", InstanceName: "my-database"},
+				{Offering: "p.redis", Plan: "cache-small", InstanceName: "my-cache"},
+			}
+			c.ListServicesByQueryReturns([]cfclient.Service{{Guid: "test-service-guid"}}, nil)
+			c.ListServicePlansByQueryReturnsOnCall(0, []cfclient.ServicePlan{{Guid: "mysql-plan-guid", Name: "db-small", Public: true}}, nil)
+			c.ListServicePlansByQueryReturnsOnCall(1, []cfclient.ServicePlan{{Guid: "redis-plan-guid", Name: "cache-large", Public: true}}, nil)
+			c.CreateServiceInstanceReturns(cfclient.ServiceInstance{Guid: "test-instance-guid", LastOperation: cfclient.LastOperation{State: "succeeded"}}, nil)
+		})
+
+		it("creates them in the new space and reports the status of each", func() {
+			p.Start()
+			j, err := p.Enqueue("test-user-id", "", "", "ignition-testuser", "test-quota-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(j.Steps[3].Name).To(Equal(organization.StepCreateServices))
+			Eventually(finished(j.ID), time.Second).Should(BeTrue())
+
+			j, _ = p.Store.Job(j.ID)
+			Expect(j.Status).To(Equal(organization.StatusSucceeded))
+			Expect(j.Steps[3].Status).To(Equal(organization.StatusFailed))
+			Expect(j.Steps[3].Error).To(Equal("service offering [p.redis] has no plan [cache-small]"))
+			Expect(j.Services).To(Equal([]cloudfoundry.ServiceInstance{
+				{GUID: "test-instance-guid", Name: "my-database", Offering: "p.mysql", Plan: "db-small", State: "succeeded"},
+				{Name: "my-cache", Offering: "p.redis", Plan: "cache-small", State: organization.StatusFailed},
+			}))
+			Expect(c.CreateServiceInstanceArgsForCall(0).SpaceGuid).To(Equal("test-space-guid"))
+		})
+	})
+
+	when("a sample app is configured", func() {
+		var zip string
+
+		it.Before(func() {
+			f, err := ioutil.TempFile("", "sample-app")
+			Expect(err).NotTo(HaveOccurred())
+			f.Close()
+			zip = f.Name()
+			p.SampleApp = &cloudfoundry.SampleApp{Name: "spring-music", Path: zip, Memory: 1024}
+			c.CreateAppReturns(cfclient.App{Guid: "test-app-guid", Name: "spring-music"}, nil)
+			c.ListSharedDomainsReturns([]cfclient.SharedDomain{{Guid: "test-domain-guid", Name: "apps.example.net"}}, nil)
+			c.CreateRouteReturns(cfclient.Route{Guid: "test-route-guid"}, nil)
+			c.GetAppByGuidNoInlineCallReturns(cfclient.App{Guid: "test-app-guid", Name: "spring-music", State: "STARTED", PackageState: "STAGED"}, nil)
+		})
+
+		it.After(func() {
+			os.Remove(zip)
+		})
+
+		it("pushes the sample app into the new space", func() {
+			p.Start()
+			j, err := p.Enqueue("test-user-id", "", "", "ignition-testuser", "test-quota-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(j.Steps).To(HaveLen(4))
+			Eventually(finished(j.ID), time.Second).Should(BeTrue())
+
+			j, _ = p.Store.Job(j.ID)
+			Expect(j.Status).To(Equal(organization.StatusSucceeded))
+			Expect(j.Steps[3].Name).To(Equal(organization.StepPushSampleApp))
+			Expect(j.Steps[3].Status).To(Equal(organization.StatusSucceeded))
+			Expect(j.Org.SampleApp).To(Equal(&cloudfoundry.App{
+				GUID:  "test-app-guid",
+				Name:  "spring-music",
+				State: "STARTED",
+				URL:   "https://spring-music-ignition-testuser.apps.example.net",
+			}))
+			Expect(c.CreateAppArgsForCall(0).SpaceGuid).To(Equal("test-space-guid"))
+		})
+
+		it("fails the step but not the job when the app cannot be pushed", func() {
+			c.CreateAppReturns(cfclient.App{}, errors.New("test error"))
+			p.Start()
+			j, err := p.Enqueue("test-user-id", "", "", "ignition-testuser", "test-quota-id")
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(finished(j.ID), time.Second).Should(BeTrue())
+
+			j, _ = p.Store.Job(j.ID)
+			Expect(j.Status).To(Equal(organization.StatusSucceeded))
+			Expect(j.Steps[3].Status).To(Equal(organization.StatusFailed))
+			Expect(j.Org.SampleApp).To(BeNil())
+		})
+
+		it("skips the push when the space could not be created", func() {
+			c.CreateSpaceReturns(cfclient.Space{}, errors.New("test error"))
+			p.Start()
+			j, err := p.Enqueue("test-user-id", "", "", "ignition-testuser", "test-quota-id")
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(finished(j.ID), time.Second).Should(BeTrue())
+
+			j, _ = p.Store.Job(j.ID)
+			Expect(j.Steps[3].Status).To(Equal(organization.StatusSkipped))
+			Expect(c.CreateAppCallCount()).To(Equal(0))
+		})
+	})
+
+	it("resumes unfinished jobs from their first unfinished step", func() {
+		out := &outbox{}
+		p.Mail = &notify.Notifier{From: "ignition@example.com", Sender: out}
+		now := time.Now()
+		Expect(p.Store.Save(organization.Job{
+			ID:      "interrupted",
+			UserID:  "test-user-id",
+			Email:   "testuser@example.com",
+			OrgName: "ignition-testuser",
+			QuotaID: "test-quota-id",
+			Status:  organization.StatusRunning,
+			Steps: []organization.Step{
+				{Name: organization.StepCreateOrg, Status: organization.StatusSucceeded},
+				{Name: organization.StepAssignOrgRoles, Status: organization.StatusRunning},
+				{Name: organization.StepCreateSpace, Status: organization.StatusQueued},
+			},
+			CreatedAt: now,
+			UpdatedAt: now,
+		})).To(Succeed())
+		c.ListOrgsByQueryReturns([]cfclient.Org{{Guid: "test-org-guid", Name: "ignition-testuser"}}, nil)
+		p.Start()
+		Eventually(finished("interrupted"), time.Second).Should(BeTrue())
+
+		j, _ := p.Store.Job("interrupted")
+		Expect(j.Status).To(Equal(organization.StatusSucceeded))
+		Expect(j.Org.GUID).To(Equal("test-org-guid"))
+		for _, s := range j.Steps {
+			Expect(s.Status).To(Equal(organization.StatusSucceeded), s.Name)
+		}
+		Expect(c.CreateOrgCallCount()).To(Equal(0))
+		Expect(c.AssociateOrgManagerCallCount()).To(Equal(1))
+		Expect(c.CreateSpaceCallCount()).To(Equal(1))
+		Expect(out.sent()).To(HaveLen(1))
+	})
+
+	when("a job was interrupted after its space was created", func() {
+		var zip string
+
+		it.Before(func() {
+			f, err := ioutil.TempFile("", "sample-app")
+			Expect(err).NotTo(HaveOccurred())
+			f.Close()
+			zip = f.Name()
+			p.SampleApp = &cloudfoundry.SampleApp{Name: "spring-music", Path: zip, Memory: 1024}
+			c.CreateAppReturns(cfclient.App{Guid: "test-app-guid", Name: "spring-music"}, nil)
+			c.ListSharedDomainsReturns([]cfclient.SharedDomain{{Guid: "test-domain-guid", Name: "apps.example.net"}}, nil)
+			c.CreateRouteReturns(cfclient.Route{Guid: "test-route-guid"}, nil)
+			c.GetAppByGuidNoInlineCallReturns(cfclient.App{Guid: "test-app-guid", Name: "spring-music", State: "STARTED", PackageState: "STAGED"}, nil)
+			now := time.Now()
+			Expect(p.Store.Save(organization.Job{
+				ID:      "interrupted",
+				UserID:  "test-user-id",
+				OrgName: "ignition-testuser",
+				QuotaID: "test-quota-id",
+				Status:  organization.StatusRunning,
+				Steps: []organization.Step{
+					{Name: organization.StepCreateOrg, Status: organization.StatusSucceeded},
+					{Name: organization.StepAssignOrgRoles, Status: organization.StatusSucceeded},
+					{Name: organization.StepCreateSpace, Status: organization.StatusRunning},
+					{Name: organization.StepPushSampleApp, Status: organization.StatusRunning},
+				},
+				CreatedAt: now,
+				UpdatedAt: now,
+			})).To(Succeed())
+			c.ListOrgsByQueryReturns([]cfclient.Org{{Guid: "test-org-guid", Name: "ignition-testuser"}}, nil)
+			c.ListSpacesByQueryReturns([]cfclient.Space{{Guid: "test-space-guid", Name: "playground"}}, nil)
+		})
+
+		it.After(func() {
+			os.Remove(zip)
+		})
+
+		it("uses the space and the sample app that were created", func() {
+			c.ListAppsByQueryReturns([]cfclient.App{{Guid: "test-app-guid", Name: "spring-music", State: "STARTED"}}, nil)
+			p.Start()
+			Eventually(finished("interrupted"), time.Second).Should(BeTrue())
+
+			j, _ := p.Store.Job("interrupted")
+			Expect(j.Status).To(Equal(organization.StatusSucceeded))
+			for _, s := range j.Steps {
+				Expect(s.Status).To(Equal(organization.StatusSucceeded), s.Name)
+			}
+			Expect(j.Org.SampleApp.GUID).To(Equal("test-app-guid"))
+			Expect(c.AssociateOrgManagerCallCount()).To(Equal(0))
+			Expect(c.CreateSpaceCallCount()).To(Equal(0))
+			Expect(c.CreateAppCallCount()).To(Equal(0))
+		})
+
+		it("pushes the sample app when it was not pushed", func() {
+			p.Start()
+			Eventually(finished("interrupted"), time.Second).Should(BeTrue())
+			Expect(c.CreateSpaceCallCount()).To(Equal(0))
+			Expect(c.CreateAppCallCount()).To(Equal(1))
+		})
+	})
+
+	it("stops without starting the queued jobs", func() {
+		p.Workers = 1
+		release := make(chan struct{})
+		c.CreateOrgStub = func(cfclient.OrgRequest) (cfclient.Org, error) {
+			<-release
+			return cfclient.Org{Guid: "test-org-guid", Name: "ignition-user1"}, nil
+		}
+		p.Start()
+		first, err := p.Enqueue("user-1", "", "", "ignition-user1", "test-quota-id")
+		Expect(err).NotTo(HaveOccurred())
+		second, err := p.Enqueue("user-2", "", "", "ignition-user2", "test-quota-id")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(c.CreateOrgCallCount, time.Second).Should(Equal(1))
+
+		stopped := make(chan struct{})
+		go func() {
+			p.Stop()
+			close(stopped)
+		}()
+		Consistently(stopped, 100*time.Millisecond).ShouldNot(BeClosed())
+		close(release)
+		Eventually(stopped, time.Second).Should(BeClosed())
+
+		j, _ := p.Store.Job(first.ID)
+		Expect(j.Status).To(Equal(organization.StatusSucceeded))
+		j, _ = p.Store.Job(second.ID)
+		Expect(j.Status).To(Equal(organization.StatusQueued))
+		Expect(c.CreateOrgCallCount()).To(Equal(1))
+	})
+
+	it("rejects jobs when the queue is full", func() {
+		p.QueueSize = 1
+		_, err := p.Enqueue("user-1", "", "", "ignition-user1", "test-quota-id")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = p.Enqueue("user-2", "", "", "ignition-user2", "test-quota-id")
+		Expect(err).To(Equal(organization.ErrQueueFull))
+	})
+
+	it("rejects jobs once stopped", func() {
+		p.Start()
+		p.Stop()
+		_, err := p.Enqueue("test-user-id", "", "", "ignition-testuser", "test-quota-id")
+		Expect(err).To(Equal(organization.ErrStopped))
+	})
+}
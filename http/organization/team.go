@@ -0,0 +1,196 @@
+package organization
+
+import (
+	"log"
+	"strings"
+
+	"github.com/pivotalservices/ignition/audit"
+	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/webhook"
+	"github.com/pkg/errors"
+)
+
+// TeamOrg is an org that is shared by the members of an identity provider
+// group, instead of each of them having a personal org
+type TeamOrg struct {
+	Group   string
+	Name    string
+	QuotaID string
+	// Roles are granted to each member of the group
+	Roles []string
+}
+
+// TeamOrganization is a team org that the user is a member of
+type TeamOrganization struct {
+	cloudfoundry.Organization
+	Group string `json:"group"`
+}
+
+// Teams creates team orgs when a member of their group first signs in, and
+// adds each member of the group to them
+type Teams struct {
+	Orgs      []TeamOrg
+	AppsURL   string
+	SpaceName string
+	Template  cloudfoundry.OrgTemplate
+	// Audit records the team orgs that are created and the members that are
+	// added to them
+	Audit audit.Recorder
+	// Webhooks are notified of the team orgs that are created
+	Webhooks webhook.Notifier
+}
+
+// forGroups returns the team orgs of the groups
+func (t *Teams) forGroups(groups []string) []TeamOrg {
+	if t == nil {
+		return nil
+	}
+	var result []TeamOrg
+	for _, team := range t.Orgs {
+		for _, g := range groups {
+			if strings.EqualFold(team.Group, g) {
+				result = append(result, team)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Ensure returns the team orgs of the groups, creating the orgs that do not
+// exist and adding the user to the orgs they are not a member of. A team org
+// that cannot be ensured is skipped, as the user can still use the others
+func (t *Teams) Ensure(userID string, groups []string, a cloudfoundry.API) []TeamOrganization {
+	teams := t.forGroups(groups)
+	if len(teams) == 0 {
+		return nil
+	}
+	userOrgs, err := cloudfoundry.OrgsForUserID(userID, t.AppsURL, a)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+
+	var result []TeamOrganization
+	for _, team := range teams {
+		org, err := t.ensure(team, userID, userOrgs, a)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		result = append(result, TeamOrganization{Organization: *org, Group: team.Group})
+	}
+	return result
+}
+
+// Find returns the team orgs of the groups that the user is already a member
+// of, without changing anything; pending is true when the user is yet to be
+// added to one of them, with Ensure
+func (t *Teams) Find(userID string, groups []string, a cloudfoundry.OrganizationQuerier) (result []TeamOrganization, pending bool) {
+	teams := t.forGroups(groups)
+	if len(teams) == 0 {
+		return nil, false
+	}
+	userOrgs, err := cloudfoundry.OrgsForUserID(userID, t.AppsURL, a)
+	if err != nil {
+		log.Println(err)
+		return nil, false
+	}
+	for _, team := range teams {
+		found := false
+		for i := range userOrgs {
+			if strings.EqualFold(userOrgs[i].Name, team.Name) {
+				result = append(result, TeamOrganization{Organization: userOrgs[i], Group: team.Group})
+				found = true
+				break
+			}
+		}
+		if !found {
+			pending = true
+		}
+	}
+	return result, pending
+}
+
+func (t *Teams) ensure(team TeamOrg, userID string, userOrgs []cloudfoundry.Organization, a cloudfoundry.API) (*cloudfoundry.Organization, error) {
+	for i := range userOrgs {
+		if strings.EqualFold(userOrgs[i].Name, team.Name) {
+			return &userOrgs[i], nil
+		}
+	}
+
+	org, err := cloudfoundry.OrgByName(team.Name, t.AppsURL, a)
+	if err != nil {
+		return nil, err
+	}
+	var space *cloudfoundry.Space
+	if org == nil {
+		org, space, err = t.create(team, userID, a)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		space, err = cloudfoundry.FindSpace(t.SpaceName, org.GUID, a)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var spaceGUID string
+	if space != nil {
+		spaceGUID = space.GUID
+	}
+	err = cloudfoundry.GrantRoles(userID, org.GUID, spaceGUID, team.Roles, a)
+	t.record(audit.ActionRoleGrant, userID, team.Name, err, map[string]string{"user_id": userID, "roles": strings.Join(team.Roles, ",")})
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not add user [%s] to team org [%s]", userID, team.Name)
+	}
+	return org, nil
+}
+
+// create creates the team org and its space, and applies the org template; the
+// space has no members until they are granted roles
+func (t *Teams) create(team TeamOrg, userID string, a cloudfoundry.API) (*cloudfoundry.Organization, *cloudfoundry.Space, error) {
+	org, err := cloudfoundry.CreateOrg(team.Name, t.AppsURL, team.QuotaID, a)
+	if cloudfoundry.KindOf(err) != cloudfoundry.ErrConflict {
+		t.record(audit.ActionOrgCreate, userID, team.Name, err, map[string]string{"group": team.Group})
+	}
+	if cloudfoundry.KindOf(err) == cloudfoundry.ErrConflict {
+		// another member of the group created it first
+		org, err = cloudfoundry.OrgByName(team.Name, t.AppsURL, a)
+		if err == nil && org == nil {
+			err = errors.Errorf("team org [%s] could not be created or found", team.Name)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		space, err := cloudfoundry.FindSpace(t.SpaceName, org.GUID, a)
+		return org, space, err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	webhook.Notify(t.Webhooks, webhook.EventOrgCreated, map[string]string{"org_name": org.Name, "org_guid": org.GUID, "quota_id": team.QuotaID, "group": team.Group, "user_id": userID})
+
+	space, err := cloudfoundry.CreateSpace(t.SpaceName, org.GUID, "", !t.Template.DisableSSH, a)
+	t.record(audit.ActionSpaceCreate, userID, team.Name+"/"+strings.ToLower(t.SpaceName), err, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cloudfoundry.ApplyOrgTemplate(t.Template, org, space, a); err != nil {
+		// the org is usable without its template
+		log.Println(err)
+	}
+	return org, space, nil
+}
+
+// record audits an action taken for the user
+func (t *Teams) record(action string, userID string, target string, err error, details map[string]string) {
+	if err != nil {
+		if details == nil {
+			details = map[string]string{}
+		}
+		details["error"] = err.Error()
+	}
+	audit.Record(t.Audit, audit.Entry{ActorID: userID, Action: action, Target: target, Outcome: audit.Outcome(err), Details: details})
+}
@@ -0,0 +1,98 @@
+package organization_test
+
+import (
+	"testing"
+
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/cloudfoundry/cloudfoundryfakes"
+	"github.com/pivotalservices/ignition/http/organization"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestTeams(t *testing.T) {
+	spec.Run(t, "Teams", testTeams, spec.Report(report.Terminal{}))
+}
+
+func testTeams(t *testing.T, when spec.G, it spec.S) {
+	var (
+		c     *cloudfoundryfakes.FakeAPI
+		teams *organization.Teams
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		c = &cloudfoundryfakes.FakeAPI{}
+		teams = &organization.Teams{
+			AppsURL:   "https://apps.example.net",
+			SpaceName: "playground",
+			Orgs: []organization.TeamOrg{
+				{Group: "platform-team", Name: "platform-sandbox", QuotaID: "team-quota-id", Roles: []string{cloudfoundry.RoleSpaceDeveloper}},
+				{Group: "data-team", Name: "data-sandbox", QuotaID: "team-quota-id", Roles: []string{cloudfoundry.RoleSpaceDeveloper}},
+			},
+		}
+	})
+
+	it("returns nothing for users without team groups", func() {
+		Expect(teams.Ensure("test-user-id", []string{"other-team"}, c)).To(BeEmpty())
+		Expect(c.Invocations()).To(BeEmpty())
+	})
+
+	it("returns the team orgs the user is already a member of", func() {
+		c.ListOrgsByQueryReturns([]cfclient.Org{{Guid: "team-org-guid", Name: "platform-sandbox"}}, nil)
+		orgs := teams.Ensure("test-user-id", []string{"Platform-Team"}, c)
+		Expect(orgs).To(HaveLen(1))
+		Expect(orgs[0].GUID).To(Equal("team-org-guid"))
+		Expect(orgs[0].Group).To(Equal("platform-team"))
+		Expect(c.AssociateOrgUserCallCount()).To(Equal(0))
+	})
+
+	it("adds the user to a team org that exists", func() {
+		c.ListOrgsByQueryReturnsOnCall(1, []cfclient.Org{{Guid: "team-org-guid", Name: "platform-sandbox"}}, nil)
+		c.ListSpacesByQueryReturns([]cfclient.Space{{Guid: "team-space-guid", Name: "playground"}}, nil)
+		orgs := teams.Ensure("test-user-id", []string{"platform-team"}, c)
+		Expect(orgs).To(HaveLen(1))
+		Expect(c.ListOrgsByQueryArgsForCall(1).Get("q")).To(Equal("name:platform-sandbox"))
+		Expect(c.CreateOrgCallCount()).To(Equal(0))
+		orgGUID, userID := c.AssociateOrgUserArgsForCall(0)
+		Expect(orgGUID).To(Equal("team-org-guid"))
+		Expect(userID).To(Equal("test-user-id"))
+		spaceGUID, _ := c.AssociateSpaceDeveloperArgsForCall(0)
+		Expect(spaceGUID).To(Equal("team-space-guid"))
+	})
+
+	it("creates a team org that does not exist, with its own quota", func() {
+		teams.Template = cloudfoundry.OrgTemplate{SecurityGroups: []string{"public-networks"}}
+		c.CreateOrgReturns(cfclient.Org{Guid: "team-org-guid", Name: "platform-sandbox"}, nil)
+		c.CreateSpaceReturns(cfclient.Space{Guid: "team-space-guid", Name: "playground"}, nil)
+		orgs := teams.Ensure("test-user-id", []string{"platform-team"}, c)
+		Expect(orgs).To(HaveLen(1))
+		Expect(c.CreateOrgArgsForCall(0).QuotaDefinitionGuid).To(Equal("team-quota-id"))
+		Expect(c.CreateSpaceArgsForCall(0).ManagerGuid).To(BeEmpty())
+		Expect(c.BindSecGroupCallCount()).To(Equal(1))
+		spaceGUID, userID := c.AssociateSpaceDeveloperArgsForCall(0)
+		Expect(spaceGUID).To(Equal("team-space-guid"))
+		Expect(userID).To(Equal("test-user-id"))
+		Expect(c.AssociateOrgManagerCallCount()).To(Equal(0))
+	})
+
+	it("joins a team org that another member created first", func() {
+		c.CreateOrgReturns(cfclient.Org{}, cfclient.CloudFoundryHTTPError{StatusCode: 409})
+		c.ListOrgsByQueryReturnsOnCall(2, []cfclient.Org{{Guid: "team-org-guid", Name: "platform-sandbox"}}, nil)
+		orgs := teams.Ensure("test-user-id", []string{"platform-team"}, c)
+		Expect(orgs).To(HaveLen(1))
+		Expect(orgs[0].GUID).To(Equal("team-org-guid"))
+		Expect(c.CreateSpaceCallCount()).To(Equal(0))
+		Expect(c.AssociateOrgUserCallCount()).To(Equal(1))
+	})
+
+	it("skips team orgs that cannot be ensured", func() {
+		c.CreateOrgReturnsOnCall(0, cfclient.Org{}, cfclient.CloudFoundryHTTPError{StatusCode: 503})
+		c.CreateOrgReturnsOnCall(1, cfclient.Org{Guid: "data-org-guid", Name: "data-sandbox"}, nil)
+		orgs := teams.Ensure("test-user-id", []string{"platform-team", "data-team"}, c)
+		Expect(orgs).To(HaveLen(1))
+		Expect(orgs[0].Name).To(Equal("data-sandbox"))
+	})
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pivotalservices/ignition/http/organization"
+	"github.com/pivotalservices/ignition/user"
+)
+
+// DomainPolicy authorizes users with an email address ending with Domain and,
+// when QuotaID is set, creates their org with that quota
+type DomainPolicy struct {
+	Domain  string
+	QuotaID string
+}
+
+// policyForProfile returns the first policy that matches the profile's email
+func policyForProfile(profile *user.Profile, policies []DomainPolicy) (DomainPolicy, bool) {
+	if profile == nil {
+		return DomainPolicy{}, false
+	}
+	for _, p := range policies {
+		if strings.TrimSpace(p.Domain) != "" && strings.HasSuffix(strings.ToLower(profile.Email), strings.ToLower(p.Domain)) {
+			return p, true
+		}
+	}
+	return DomainPolicy{}, false
+}
+
+// applyPolicies stores the quota of the user's matching policy in the context
+func applyPolicies(next http.Handler, policies []DomainPolicy) http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		profile, err := user.ProfileFromContext(req.Context())
+		if err == nil {
+			p, ok := policyForProfile(profile, policies)
+			if ok && strings.TrimSpace(p.QuotaID) != "" {
+				req = req.WithContext(organization.ContextWithQuotaID(req.Context(), p.QuotaID))
+			}
+		}
+		next.ServeHTTP(w, req)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// authorizedDomains returns the authorized domain and the domains of all policies
+func (a *API) authorizedDomains() []string {
+	var domains []string
+	if strings.TrimSpace(a.AuthorizedDomain) != "" {
+		domains = append(domains, a.AuthorizedDomain)
+	}
+	for _, p := range a.Policies {
+		domains = append(domains, p.Domain)
+	}
+	return domains
+}
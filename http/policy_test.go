@@ -0,0 +1,61 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/user"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestPolicies(t *testing.T) {
+	spec.Run(t, "Policies", testPolicies, spec.Report(report.Terminal{}))
+}
+
+func testPolicies(t *testing.T, when spec.G, it spec.S) {
+	var policies []DomainPolicy
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		policies = []DomainPolicy{
+			{Domain: "@research.example.net", QuotaID: "large-quota-id"},
+			{Domain: "@example.net"},
+		}
+	})
+
+	it("does not match a nil profile", func() {
+		_, ok := policyForProfile(nil, policies)
+		Expect(ok).To(BeFalse())
+	})
+
+	it("matches the first policy for the user's email", func() {
+		p, ok := policyForProfile(&user.Profile{Email: "Test@Research.example.net"}, policies)
+		Expect(ok).To(BeTrue())
+		Expect(p.QuotaID).To(Equal("large-quota-id"))
+	})
+
+	it("does not match users outside of the policy domains", func() {
+		_, ok := policyForProfile(&user.Profile{Email: "test@example.com"}, policies)
+		Expect(ok).To(BeFalse())
+	})
+
+	it("combines the authorized domain with the policy domains", func() {
+		a := &API{AuthorizedDomain: "@example.org", Policies: policies}
+		Expect(a.authorizedDomains()).To(Equal([]string{"@example.org", "@research.example.net", "@example.net"}))
+	})
+
+	it("calls the next handler when no policy matches", func() {
+		called := false
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		})
+		ctx := user.WithProfile(context.Background(), &user.Profile{Email: "test@example.com"})
+		req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+		applyPolicies(next, policies).ServeHTTP(httptest.NewRecorder(), req)
+		Expect(called).To(BeTrue())
+	})
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/http/health"
+)
+
+const (
+	defaultHealthCacheTTL     = 10 * time.Second
+	defaultHealthCheckTimeout = 5 * time.Second
+)
+
+// readiness checks each dependency that ignition needs to onboard users
+func (a *API) readiness() *health.Readiness {
+	ttl := a.HealthCacheTTL
+	if ttl <= 0 {
+		ttl = defaultHealthCacheTTL
+	}
+	timeout := a.HealthCheckTimeout
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+	return health.NewReadiness(ttl, timeout,
+		health.Check{Name: "uaa", Checker: health.CheckerFunc(func(ctx context.Context) error {
+			if a.UAAAPI == nil {
+				return errors.New("no uaa client is configured")
+			}
+			// Authenticate only contacts UAA when its token has expired
+			c, ok := a.UAAAPI.(health.Checker)
+			if !ok {
+				return errors.New("the uaa client cannot be checked")
+			}
+			return c.Check(ctx)
+		})},
+		health.Check{Name: "cloud_controller", Checker: health.CheckerFunc(func(ctx context.Context) error {
+			if a.CCAPI == nil {
+				return errors.New("no cloud controller client is configured")
+			}
+			return cloudfoundry.Ping(ctx, a.CCAPI)
+		})},
+		health.Check{Name: "jwks", Checker: health.JWKSChecker(a.JWKSURL, nil)},
+		health.Check{Name: "session", Checker: a.sessionChecker()},
+	)
+}
+
+// sessionChecker uses the session store's own check when it has one; stores
+// that keep sessions in cookies have no backend to check
+func (a *API) sessionChecker() health.Checker {
+	if c, ok := a.SessionStore.(health.Checker); ok {
+		return c
+	}
+	fn := func(ctx context.Context) error {
+		if a.SessionStore == nil {
+			return errors.New("no session store is configured")
+		}
+		return nil
+	}
+	return health.CheckerFunc(fn)
+}
@@ -4,45 +4,87 @@ import (
 	_ "expvar" // metrics
 	"fmt"
 	"net/http"
-	"os"
-	"path"
-	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/dghubble/sessions"
-	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"github.com/pivotalservices/ignition/audit"
 	"github.com/pivotalservices/ignition/cloudfoundry"
+	"github.com/pivotalservices/ignition/http/events"
+	"github.com/pivotalservices/ignition/http/health"
 	"github.com/pivotalservices/ignition/http/organization"
 	"github.com/pivotalservices/ignition/http/session"
+	"github.com/pivotalservices/ignition/http/terms"
 	"github.com/pivotalservices/ignition/uaa"
 	"github.com/pivotalservices/ignition/user"
+	"github.com/pivotalservices/ignition/webhook"
 	"golang.org/x/oauth2"
 )
 
 // API is the Ignition web app
 type API struct {
-	AuthorizedDomain string
-	SessionSecret    string
-	Domain           string
-	Port             int
-	ServePort        int
-	WebRoot          string
-	Scheme           string
-	APIURL           string
-	AppsURL          string
-	UAAURL           string
-	UAAOrigin        string
-	UserConfig       *oauth2.Config
-	APIConfig        *oauth2.Config
-	APIUsername      string
-	APIPassword      string
-	Fetcher          user.Fetcher
-	SessionStore     sessions.Store
-	CCAPI            cloudfoundry.API
-	UAAAPI           uaa.API
-	OrgPrefix        string
-	QuotaID          string
-	SpaceName        string
+	AuthorizedDomain   string
+	SessionSecret      string
+	Domain             string
+	Port               int
+	ServePort          int
+	WebRoot            string
+	Scheme             string
+	APIURL             string
+	AppsURL            string
+	UAAURL             string
+	UAAOrigin          string
+	UserConfig         *oauth2.Config
+	APIConfig          *oauth2.Config
+	APIUsername        string
+	APIPassword        string
+	Fetcher            user.Fetcher
+	SessionStore       sessions.Store
+	SessionCookie      session.CookieOptions
+	CCAPI              cloudfoundry.API
+	UAAAPI             uaa.API
+	OrgPrefix          string
+	QuotaID            string
+	SpaceName          string
+	Policies           []DomainPolicy
+	ReadTimeout        time.Duration
+	WriteTimeout       time.Duration
+	IdleTimeout        time.Duration
+	ShutdownTimeout    time.Duration
+	TLSCertFile        string
+	TLSKeyFile         string
+	JWKSURL            string
+	HealthCacheTTL     time.Duration
+	HealthCheckTimeout time.Duration
+
+	CORSAllowedOrigins   []string
+	CORSAllowedMethods   []string
+	CORSAllowedHeaders   []string
+	CORSAllowCredentials bool
+
+	Provisioner *organization.Provisioner
+	Teams       *organization.Teams
+	Events      *events.Broker
+	Audit       *audit.Log
+	Webhooks    *webhook.Dispatcher
+	// Terms must be accepted before an org is created for the user
+	Terms *terms.Terms
+	// Sessions keeps a record of each session, so that they can be listed
+	// and revoked
+	Sessions *session.Registry
+	// Logout ends the user's session at the identity provider when they log
+	// out
+	Logout *session.Logout
+	// ReturnTo sends users back to the page they asked for after they log in
+	ReturnTo *session.ReturnTo
+	// Admins are the email addresses of the users that may use the admin
+	// endpoints
+	Admins []string
+
+	Branding        Branding
+	BrandingDir     string
+	FoundationNames []string
 }
 
 // URI is the combination of the scheme, domain, and port
@@ -54,32 +96,112 @@ func (a *API) URI() string {
 	return s
 }
 
-// Run starts a server listening on the given serveURI
-func (a *API) Run() error {
-	a.UserConfig.RedirectURL = fmt.Sprintf("%s%s", a.URI(), "/oauth2")
-	r := a.createRouter()
-	return http.ListenAndServe(fmt.Sprintf(":%v", a.ServePort), handlers.LoggingHandler(os.Stdout, handlers.CORS()(r)))
+// csrf rejects requests to next that change state without the session's CSRF
+// token, or that come from an origin other than ignition's own and the
+// CORSAllowedOrigins
+func (a *API) csrf(next http.Handler) http.Handler {
+	origins := append([]string{a.URI()}, a.CORSAllowedOrigins...)
+	return session.ProtectCSRF(next, a.SessionStore, a.SessionCookie, origins)
+}
+
+// ensureUser creates a UAA user for the user when they do not have one yet,
+// and have accepted the terms. It changes state, so it is only used on the
+// route that creates the org
+func (a *API) ensureUser(next http.Handler) http.Handler {
+	var terms organization.TermsChecker
+	if a.Terms != nil {
+		terms = a.Terms
+	}
+	return ensureUser(next, terms, a.UAAAPI, a.UAAOrigin, a.SessionStore, a.Events, a.Audit, a.Webhooks)
+}
+
+// sampleAppName is the name of the app that is pushed into new spaces, or ""
+// when there is none
+func (a *API) sampleAppName() string {
+	if a.Provisioner == nil || a.Provisioner.SampleApp == nil {
+		return ""
+	}
+	return a.Provisioner.SampleApp.Name
 }
 
 func (a *API) createRouter() *mux.Router {
+	if a.Events == nil {
+		a.Events = events.NewBroker()
+	}
+	if a.Audit == nil {
+		a.Audit = audit.NewLog()
+	}
 	r := mux.NewRouter()
-	r.Handle("/", ensureHTTPS(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		http.ServeFile(w, req, filepath.Join(a.WebRoot, "index.html"))
-	}))).Name("index")
-	r.PathPrefix("/assets/").Handler(http.StripPrefix("/assets/", http.FileServer(http.Dir(path.Join(a.WebRoot, "assets")+string(os.PathSeparator))))).Name("assets")
-	r.Handle("/profile", ensureHTTPS(session.PopulateContext(Authorize(profileHandler(), a.AuthorizedDomain), a.SessionStore)))
+	static := newStaticFiles(a.webFileSystem())
+	r.Handle("/", ensureHTTPS(static.file("index.html", noCache))).Name("index")
+	r.PathPrefix("/assets/").Handler(static.dir("/assets/", "assets", immutable)).Name("assets")
+	r.Handle("/config", a.configHandler()).Methods(http.MethodGet).Name("config")
+	if strings.TrimSpace(a.BrandingDir) != "" {
+		// branding files are not renamed when they change, so they are revalidated
+		r.PathPrefix("/branding/").Handler(newStaticFiles(http.Dir(a.BrandingDir)).dir("/branding/", "/", noCache)).Name("branding")
+	}
+	r.Handle("/profile", a.cors(ensureHTTPS(session.PopulateContext(Authorize(profileHandler(), a.authorizedDomains()...), a.SessionStore, a.Sessions)))).Name("profile")
 
-	orgHandler := organization.Handler(a.AppsURL, a.OrgPrefix, a.QuotaID, a.SpaceName, a.CCAPI)
-	orgHandler = ensureUser(orgHandler, a.UAAAPI, a.UAAOrigin, a.SessionStore)
-	orgHandler = Authorize(orgHandler, a.AuthorizedDomain)
-	orgHandler = session.PopulateContext(orgHandler, a.SessionStore)
-	orgHandler = ensureHTTPS(orgHandler)
-	r.Handle("/organization", orgHandler)
+	orgRoute := func(h http.Handler) http.Handler {
+		h = applyPolicies(h, a.Policies)
+		h = a.csrf(h)
+		h = Authorize(h, a.authorizedDomains()...)
+		h = session.PopulateContext(h, a.SessionStore, a.Sessions)
+		h = ensureHTTPS(h)
+		return a.cors(h)
+	}
+	r.Handle("/organization", orgRoute(organization.Handler(a.AppsURL, a.OrgPrefix, a.QuotaID, a.sampleAppName(), a.Teams, a.Terms, a.CCAPI))).Methods(http.MethodGet, http.MethodHead, http.MethodOptions).Name("organization")
+	r.Handle("/organization", orgRoute(a.ensureUser(organization.CreateHandler(a.AppsURL, a.OrgPrefix, a.QuotaID, a.Teams, a.Terms, a.CCAPI, a.Provisioner)))).Methods(http.MethodPost).Name("create-organization")
+	r.Handle("/getting-started", orgRoute(organization.GettingStartedHandler(a.APIURL, a.UAAURL, a.AppsURL, a.OrgPrefix, a.QuotaID, a.SpaceName, a.CCAPI))).Methods(http.MethodGet, http.MethodOptions).Name("getting-started")
+	members := &organization.Members{
+		AppsURL:    a.AppsURL,
+		OrgPrefix:  a.OrgPrefix,
+		QuotaID:    a.QuotaID,
+		SpaceName:  a.SpaceName,
+		UAAOrigin:  a.UAAOrigin,
+		Authorized: func(email string) bool { return emailInDomains(email, a.authorizedDomains()) },
+		CCAPI:      a.CCAPI,
+		UAAAPI:     a.UAAAPI,
+		Audit:      a.Audit,
+		Webhooks:   a.Webhooks,
+	}
+	r.Handle("/organization/members", orgRoute(members.ListHandler())).Methods(http.MethodGet, http.MethodOptions).Name("organization-members")
+	r.Handle("/organization/members", orgRoute(members.InviteHandler())).Methods(http.MethodPost).Name("invite-organization-member")
+	r.Handle("/organization/members/{id}", orgRoute(members.RemoveHandler())).Methods(http.MethodDelete, http.MethodOptions).Name("remove-organization-member")
+	r.Handle("/terms", orgRoute(a.Terms.Handler())).Methods(http.MethodGet, http.MethodOptions).Name("terms")
+	r.Handle("/terms/accept", orgRoute(a.Terms.AcceptHandler())).Methods(http.MethodPost, http.MethodOptions).Name("accept-terms")
+	r.Handle("/events", orgRoute(a.Events.Handler())).Methods(http.MethodGet).Name("events")
+	r.Handle("/organization/jobs/{id}", orgRoute(organization.JobHandler(a.Provisioner))).Methods(http.MethodGet, http.MethodOptions).Name("organization-job")
+
+	userRoute := func(h http.Handler) http.Handler {
+		h = a.csrf(h)
+		h = Authorize(h, a.authorizedDomains()...)
+		h = session.PopulateContext(h, a.SessionStore, a.Sessions)
+		h = ensureHTTPS(h)
+		return a.cors(h)
+	}
+	r.Handle("/sessions", userRoute(a.Sessions.ListHandler())).Methods(http.MethodGet, http.MethodOptions).Name("sessions")
+	r.Handle("/sessions/{id}", userRoute(a.Sessions.RevokeHandler())).Methods(http.MethodDelete, http.MethodOptions).Name("revoke-session")
+
+	adminRoute := func(h http.Handler) http.Handler {
+		h = requireAdmin(h, a.Admins)
+		h = a.csrf(h)
+		h = Authorize(h, a.authorizedDomains()...)
+		h = session.PopulateContext(h, a.SessionStore, a.Sessions)
+		h = ensureHTTPS(h)
+		return a.cors(h)
+	}
+	r.Handle("/admin/audit", adminRoute(auditHandler(a.Audit))).Methods(http.MethodGet, http.MethodOptions).Name("admin-audit")
+	r.Handle("/admin/webhooks/deliveries", adminRoute(webhookDeliveriesHandler(a.Webhooks))).Methods(http.MethodGet, http.MethodOptions).Name("admin-webhook-deliveries")
+	r.Handle("/admin/users/{user}/sessions", adminRoute(a.Sessions.UserSessionsHandler())).Methods(http.MethodGet, http.MethodOptions).Name("admin-user-sessions")
+	r.Handle("/admin/users/{user}/sessions", adminRoute(a.Sessions.RevokeUserHandler())).Methods(http.MethodDelete, http.MethodOptions).Name("admin-revoke-user-sessions")
 
 	a.handleAuth(r)
 	r.HandleFunc("/403", func(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 	})
+	r.Handle("/healthz", health.LivenessHandler()).Name("healthz")
+	r.Handle("/readyz", a.readiness()).Name("readyz")
 	r.Handle("/debug/vars", http.DefaultServeMux)
 	return r
 }
@@ -1,11 +1,19 @@
 package http
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/cloudfoundry/cloudfoundryfakes"
+	"github.com/pivotalservices/ignition/http/session/sessionfakes"
+	"github.com/pivotalservices/ignition/uaa"
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
+	"golang.org/x/oauth2"
 )
 
 func TestAPI(t *testing.T) {
@@ -34,7 +42,68 @@ func testAPI(t *testing.T, when spec.G, it spec.S) {
 		Expect(index).NotTo(BeNil())
 		assets := r.GetRoute("assets")
 		Expect(assets).NotTo(BeNil())
+		Expect(r.GetRoute("healthz")).NotTo(BeNil())
+		Expect(r.GetRoute("readyz")).NotTo(BeNil())
+		Expect(r.GetRoute("getting-started")).NotTo(BeNil())
+		Expect(r.GetRoute("organization-members")).NotTo(BeNil())
+		Expect(r.GetRoute("invite-organization-member")).NotTo(BeNil())
+		Expect(r.GetRoute("remove-organization-member")).NotTo(BeNil())
+		Expect(r.GetRoute("admin-audit")).NotTo(BeNil())
+		Expect(r.GetRoute("admin-webhook-deliveries")).NotTo(BeNil())
+		Expect(r.GetRoute("terms")).NotTo(BeNil())
+		Expect(r.GetRoute("accept-terms")).NotTo(BeNil())
 		nonexistent := r.GetRoute("nonexistent")
 		Expect(nonexistent).To(BeNil())
 	})
 }
+
+func TestReadiness(t *testing.T) {
+	spec.Run(t, "readiness", func(t *testing.T, when spec.G, it spec.S) {
+		it.Before(func() {
+			RegisterTestingT(t)
+		})
+
+		it("checks uaa, the cloud controller, the jwks and the session store", func() {
+			api := &API{}
+			r := api.readiness()
+			names := []string{}
+			for _, c := range r.Checks {
+				names = append(names, c.Name)
+			}
+			Expect(names).To(Equal([]string{"uaa", "cloud_controller", "jwks", "session"}))
+		})
+
+		it("reports unconfigured dependencies as unavailable", func() {
+			api := &API{}
+			report := api.readiness().Report(context.Background())
+			Expect(report.Status).To(Equal("unavailable"))
+			for _, s := range report.Checks {
+				Expect(s.Healthy).To(BeFalse())
+			}
+		})
+
+		it("reports healthy dependencies", func() {
+			jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"keys":[{"kid":"1"}]}`))
+			}))
+			defer jwks.Close()
+			tokens := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tokens++
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"access_token":"test-token","token_type":"bearer","expires_in":3600}`))
+			}))
+			defer server.Close()
+			api := &API{
+				UAAAPI:       &uaa.Client{URL: server.URL, ClientID: "ignition", Token: &oauth2.Token{AccessToken: "valid", Expiry: time.Now().Add(time.Hour)}},
+				CCAPI:        &cloudfoundryfakes.FakeAPI{},
+				JWKSURL:      jwks.URL,
+				SessionStore: &sessionfakes.FakeStore{},
+			}
+			report := api.readiness().Report(context.Background())
+			Expect(report.Status).To(Equal("ok"))
+			// uaa is contacted even though the client's token is still valid
+			Expect(tokens).To(Equal(1))
+		})
+	}, spec.Report(report.Terminal{}))
+}
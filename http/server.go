@@ -0,0 +1,107 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/handlers"
+)
+
+// DefaultShutdownTimeout is how long in-flight requests are given to finish
+// after a shutdown signal when API.ShutdownTimeout is not set
+const DefaultShutdownTimeout = 10 * time.Second
+
+// Run starts a server listening on the ServePort, and drains in-flight
+// requests when the process receives SIGTERM or SIGINT
+func (a *API) Run() error {
+	a.UserConfig.RedirectURL = fmt.Sprintf("%s%s", a.URI(), "/oauth2")
+	r := a.createRouter()
+	l, err := net.Listen("tcp", fmt.Sprintf(":%v", a.ServePort))
+	if err != nil {
+		return err
+	}
+	if a.Provisioner != nil {
+		a.Provisioner.Start()
+		defer a.Provisioner.Stop()
+	}
+	if a.Webhooks != nil {
+		a.Webhooks.Start()
+		defer a.Webhooks.Stop()
+	}
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM, os.Interrupt)
+	defer signal.Stop(stop)
+	return a.serve(l, handlers.LoggingHandler(os.Stdout, r), stop)
+}
+
+// TLSEnabled is true when the server should terminate TLS itself
+func (a *API) TLSEnabled() bool {
+	return strings.TrimSpace(a.TLSCertFile) != "" && strings.TrimSpace(a.TLSKeyFile) != ""
+}
+
+func (a *API) server(h http.Handler) *http.Server {
+	return &http.Server{
+		Handler:      streamEvents(h),
+		ReadTimeout:  a.ReadTimeout,
+		WriteTimeout: a.WriteTimeout,
+		IdleTimeout:  a.IdleTimeout,
+	}
+}
+
+// streamEvents clears the WriteTimeout for the /events stream, which would
+// otherwise be cut off once the timeout has passed. It has to wrap the logging
+// handler, whose response writer does not give access to the connection
+func streamEvents(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet && req.URL.Path == "/events" {
+			http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		}
+		next.ServeHTTP(w, req)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// serve handles requests on the listener until it fails, or until a signal is
+// received on stop; in-flight requests are then given the ShutdownTimeout to
+// finish
+func (a *API) serve(l net.Listener, h http.Handler, stop <-chan os.Signal) error {
+	srv := a.server(h)
+	if a.Events != nil {
+		srv.RegisterOnShutdown(a.Events.Close)
+	}
+	errs := make(chan error, 1)
+	go func() {
+		if a.TLSEnabled() {
+			errs <- srv.ServeTLS(l, a.TLSCertFile, a.TLSKeyFile)
+			return
+		}
+		errs <- srv.Serve(l)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case sig := <-stop:
+		timeout := a.ShutdownTimeout
+		if timeout <= 0 {
+			timeout = DefaultShutdownTimeout
+		}
+		log.Printf("Received %v; waiting up to %v for in-flight requests to finish\n", sig, timeout)
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		err := srv.Shutdown(ctx)
+		if err != nil {
+			return err
+		}
+		log.Println("Server stopped")
+		return nil
+	}
+}
@@ -0,0 +1,217 @@
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestServe(t *testing.T) {
+	spec.Run(t, "serve", testServe, spec.Report(report.Terminal{}))
+}
+
+func testServe(t *testing.T, when spec.G, it spec.S) {
+	var (
+		api     *API
+		l       net.Listener
+		stop    chan os.Signal
+		started chan struct{}
+		release chan struct{}
+		handler http.Handler
+		served  chan error
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		api = &API{ShutdownTimeout: 5 * time.Second}
+		var err error
+		l, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		stop = make(chan os.Signal, 1)
+		started = make(chan struct{})
+		release = make(chan struct{})
+		// simulates a slow provisioning request
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("provisioned"))
+		})
+		served = make(chan error, 1)
+	})
+
+	it("lets in-flight requests finish after a shutdown signal", func() {
+		go func() {
+			served <- api.serve(l, handler, stop)
+		}()
+
+		responses := make(chan *http.Response, 1)
+		go func() {
+			resp, err := http.Get("http://" + l.Addr().String() + "/organization")
+			if err != nil {
+				close(responses)
+				return
+			}
+			responses <- resp
+		}()
+
+		Eventually(started).Should(BeClosed())
+		stop <- syscall.SIGTERM
+		Consistently(served, 100*time.Millisecond).ShouldNot(Receive())
+
+		close(release)
+		var resp *http.Response
+		Eventually(responses).Should(Receive(&resp))
+		Expect(resp).NotTo(BeNil())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("provisioned"))
+		Eventually(served).Should(Receive(BeNil()))
+	})
+
+	it("stops accepting new connections after a shutdown signal", func() {
+		close(release)
+		go func() {
+			served <- api.serve(l, handler, stop)
+		}()
+		stop <- syscall.SIGTERM
+		Eventually(served).Should(Receive(BeNil()))
+		_, err := http.Get("http://" + l.Addr().String() + "/")
+		Expect(err).To(HaveOccurred())
+	})
+
+	it("gives up on requests that exceed the shutdown timeout", func() {
+		api.ShutdownTimeout = 50 * time.Millisecond
+		go func() {
+			served <- api.serve(l, handler, stop)
+		}()
+		go http.Get("http://" + l.Addr().String() + "/organization")
+		Eventually(started).Should(BeClosed())
+		stop <- syscall.SIGTERM
+		Eventually(served).Should(Receive(HaveOccurred()))
+		close(release)
+	})
+
+	it("applies the configured timeouts", func() {
+		api.ReadTimeout = time.Second
+		api.WriteTimeout = 2 * time.Second
+		api.IdleTimeout = 3 * time.Second
+		srv := api.server(handler)
+		Expect(srv.ReadTimeout).To(Equal(time.Second))
+		Expect(srv.WriteTimeout).To(Equal(2 * time.Second))
+		Expect(srv.IdleTimeout).To(Equal(3 * time.Second))
+		l.Close()
+	})
+
+	it("does not cut off the event stream after the write timeout", func() {
+		api.WriteTimeout = 100 * time.Millisecond
+		// writes a little at a time for longer than the write timeout
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			for i := 0; i < 3; i++ {
+				time.Sleep(75 * time.Millisecond)
+				w.Write([]byte("data\n"))
+				w.(http.Flusher).Flush()
+			}
+		})
+		go func() {
+			served <- api.serve(l, handler, stop)
+		}()
+
+		read := func(path string) (string, error) {
+			resp, err := http.Get("http://" + l.Addr().String() + path)
+			if err != nil {
+				return "", err
+			}
+			defer resp.Body.Close()
+			body, err := ioutil.ReadAll(resp.Body)
+			return string(body), err
+		}
+		body, err := read("/events")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(Equal("data\ndata\ndata\n"))
+
+		body, _ = read("/organization")
+		Expect(body).NotTo(Equal("data\ndata\ndata\n"))
+
+		stop <- syscall.SIGTERM
+		Eventually(served).Should(Receive(BeNil()))
+	})
+
+	when("a tls certificate and key are configured", func() {
+		var dir string
+
+		it.Before(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "ignition-tls")
+			Expect(err).NotTo(HaveOccurred())
+			api.TLSCertFile, api.TLSKeyFile = writeTestCertificate(dir)
+			close(release)
+		})
+
+		it.After(func() {
+			os.RemoveAll(dir)
+		})
+
+		it("serves https", func() {
+			Expect(api.TLSEnabled()).To(BeTrue())
+			go func() {
+				served <- api.serve(l, handler, stop)
+			}()
+			client := &http.Client{Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			}}
+			resp, err := client.Get("https://" + l.Addr().String() + "/")
+			Expect(err).NotTo(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.TLS).NotTo(BeNil())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			stop <- syscall.SIGTERM
+			Eventually(served).Should(Receive(BeNil()))
+		})
+	})
+}
+
+// writeTestCertificate writes a self-signed certificate and key for 127.0.0.1
+// to the dir and returns their paths
+func writeTestCertificate(dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{Organization: []string{"Ignition Test"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	Expect(ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)).To(Succeed())
+	Expect(ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)).To(Succeed())
+	return certFile, keyFile
+}
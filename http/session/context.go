@@ -14,6 +14,7 @@ type key int
 const (
 	contextTokenKey key = iota
 	contextUserIDKey
+	contextSessionIDKey
 )
 
 // TokenFromContext returns the Token from the ctx.
@@ -46,3 +47,20 @@ func ContextWithUserID(ctx context.Context, userID string) context.Context {
 	}
 	return context.WithValue(ctx, contextUserIDKey, userID)
 }
+
+// SessionIDFromContext returns the ID of the session from the ctx.
+func SessionIDFromContext(ctx context.Context) (string, error) {
+	id, ok := ctx.Value(contextSessionIDKey).(string)
+	if !ok {
+		return "", errors.New("context missing session ID")
+	}
+	return id, nil
+}
+
+// ContextWithSessionID returns a copy of ctx that stores the session ID.
+func ContextWithSessionID(ctx context.Context, id string) context.Context {
+	if strings.TrimSpace(id) == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, contextSessionIDKey, id)
+}
@@ -0,0 +1,139 @@
+package session
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"net/http"
+	"time"
+
+	"github.com/dghubble/sessions"
+	"github.com/gorilla/securecookie"
+)
+
+// DefaultMaxAge is how long a session lasts when CookieOptions.MaxAge is not
+// set
+const DefaultMaxAge = 7 * 24 * time.Hour
+
+// CookieOptions are the attributes of the session cookie
+type CookieOptions struct {
+	Domain   string
+	Path     string
+	MaxAge   time.Duration
+	Secure   bool
+	HTTPOnly bool
+	SameSite http.SameSite
+}
+
+// DefaultCookieOptions keep the session cookie away from scripts and
+// insecure connections, and off cross-site requests other than top-level
+// navigation
+var DefaultCookieOptions = CookieOptions{
+	Path:     "/",
+	MaxAge:   DefaultMaxAge,
+	Secure:   true,
+	HTTPOnly: true,
+	SameSite: http.SameSiteLaxMode,
+}
+
+// CookieStore keeps sessions in signed cookies, like sessions.CookieStore,
+// but with every attribute of the cookie (including SameSite) set from its
+// Options
+type CookieStore struct {
+	Codecs  []securecookie.Codec
+	Options CookieOptions
+}
+
+// NewCookieStore returns a CookieStore that signs (and, when an encryption key
+// follows the hash key, encrypts) its cookies with the keyPairs; see KeyPairs
+func NewCookieStore(options CookieOptions, keyPairs ...[]byte) *CookieStore {
+	return &CookieStore{
+		Codecs:  securecookie.CodecsFromPairs(keyPairs...),
+		Options: options,
+	}
+}
+
+// New returns a new session with the name
+func (s *CookieStore) New(name string) *sessions.Session {
+	return sessions.NewSession(s, name)
+}
+
+// Get returns the named session from the request's cookie
+func (s *CookieStore) Get(req *http.Request, name string) (*sessions.Session, error) {
+	cookie, err := req.Cookie(name)
+	if err != nil {
+		return nil, err
+	}
+	session := s.New(name)
+	if err := securecookie.DecodeMulti(name, cookie.Value, &session.Values, s.Codecs...); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Save sets the session's cookie on the response
+func (s *CookieStore) Save(w http.ResponseWriter, session *sessions.Session) error {
+	encoded, err := securecookie.EncodeMulti(session.Name(), &session.Values, s.Codecs...)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, s.Options.cookie(session.Name(), encoded))
+	return nil
+}
+
+// Destroy expires the named session's cookie
+func (s *CookieStore) Destroy(w http.ResponseWriter, name string) {
+	http.SetCookie(w, s.Options.expired(name))
+}
+
+// cookie returns a cookie with the value and these options
+func (o CookieOptions) cookie(name string, value string) *http.Cookie {
+	path := o.Path
+	if path == "" {
+		path = "/"
+	}
+	maxAge := o.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Domain:   o.Domain,
+		Path:     path,
+		MaxAge:   int(maxAge / time.Second),
+		Expires:  time.Now().Add(maxAge),
+		Secure:   o.Secure,
+		HttpOnly: o.HTTPOnly,
+		SameSite: o.SameSite,
+	}
+}
+
+// expired returns a cookie that removes the named cookie from the browser
+func (o CookieOptions) expired(name string) *http.Cookie {
+	c := o.cookie(name, "")
+	c.MaxAge = -1
+	c.Expires = time.Unix(1, 0)
+	return c
+}
+
+// KeyPairs derives a hash key and an encryption key from each secret, for
+// NewCookieStore. Cookies are signed and encrypted (AES-256) with the keys of
+// the first secret; the other secrets are previous secrets, whose cookies are
+// still read so that the secret can be rotated without ending every session
+func KeyPairs(secrets ...string) [][]byte {
+	var pairs [][]byte
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		pairs = append(pairs, deriveKey(secret, "ignition session hash key"), deriveKey(secret, "ignition session encryption key"))
+	}
+	return pairs
+}
+
+// deriveKey returns a 32 byte key for the purpose from the secret
+func deriveKey(secret string, purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
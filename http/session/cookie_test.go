@@ -0,0 +1,105 @@
+package session_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/http/session"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestCookieStore(t *testing.T) {
+	spec.Run(t, "CookieStore", testCookieStore, spec.Report(report.Terminal{}))
+}
+
+func testCookieStore(t *testing.T, when spec.G, it spec.S) {
+	it.Before(func() {
+		RegisterTestingT(t)
+	})
+
+	it("sets the cookie attributes from its options", func() {
+		store := session.NewCookieStore(session.CookieOptions{
+			MaxAge:   time.Hour,
+			HTTPOnly: true,
+			SameSite: http.SameSiteStrictMode,
+		}, []byte("test-secret"))
+		w := httptest.NewRecorder()
+		s := store.New("ignition")
+		s.Values["uaaid"] = "test-user-id"
+		Expect(store.Save(w, s)).To(Succeed())
+		c := w.Result().Cookies()[0]
+		Expect(c.Path).To(Equal("/"))
+		Expect(c.MaxAge).To(Equal(3600))
+		Expect(c.HttpOnly).To(BeTrue())
+		Expect(c.Secure).To(BeFalse())
+		Expect(c.SameSite).To(Equal(http.SameSiteStrictMode))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(c)
+		got, err := store.Get(req, "ignition")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got.Values["uaaid"]).To(Equal("test-user-id"))
+
+		w = httptest.NewRecorder()
+		store.Destroy(w, "ignition")
+		Expect(w.Result().Cookies()[0].MaxAge).To(Equal(-1))
+	})
+
+	it("rejects a cookie signed with another secret", func() {
+		w := httptest.NewRecorder()
+		s := session.NewCookieStore(session.DefaultCookieOptions, []byte("other-secret")).New("ignition")
+		Expect(s.Save(w)).To(Succeed())
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(w.Result().Cookies()[0])
+		_, err := session.NewCookieStore(session.DefaultCookieOptions, []byte("test-secret")).Get(req, "ignition")
+		Expect(err).To(HaveOccurred())
+	})
+
+	it("encrypts the session values", func() {
+		store := session.NewCookieStore(session.DefaultCookieOptions, session.KeyPairs("test-secret")...)
+		w := httptest.NewRecorder()
+		s := store.New("ignition")
+		s.Values["token"] = "secret-access-token"
+		Expect(store.Save(w, s)).To(Succeed())
+		value := w.Result().Cookies()[0].Value
+		decoded, err := base64.URLEncoding.DecodeString(value)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(decoded)).NotTo(ContainSubstring("secret-access-token"))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(w.Result().Cookies()[0])
+		got, err := store.Get(req, "ignition")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got.Values["token"]).To(Equal("secret-access-token"))
+	})
+
+	it("reads sessions saved with a previous secret", func() {
+		old := session.NewCookieStore(session.DefaultCookieOptions, session.KeyPairs("old-secret")...)
+		w := httptest.NewRecorder()
+		s := old.New("ignition")
+		s.Values["uaaid"] = "test-user-id"
+		Expect(old.Save(w, s)).To(Succeed())
+
+		rotated := session.NewCookieStore(session.DefaultCookieOptions, session.KeyPairs("new-secret", "old-secret")...)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(w.Result().Cookies()[0])
+		got, err := rotated.Get(req, "ignition")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got.Values["uaaid"]).To(Equal("test-user-id"))
+
+		// sessions are saved with the current secret
+		w = httptest.NewRecorder()
+		Expect(got.Save(w)).To(Succeed())
+		req = httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(w.Result().Cookies()[0])
+		_, err = session.NewCookieStore(session.DefaultCookieOptions, session.KeyPairs("new-secret")...).Get(req, "ignition")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = old.Get(req, "ignition")
+		Expect(err).To(HaveOccurred())
+	})
+}
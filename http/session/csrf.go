@@ -0,0 +1,146 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dghubble/sessions"
+	"github.com/pivotalservices/ignition/http/apierror"
+)
+
+const (
+	// CSRFCookieName is the cookie that holds the session's CSRF token; unlike
+	// the session cookie, scripts on the page can read it
+	CSRFCookieName = "ignition-csrf"
+	// CSRFHeader must carry the session's CSRF token on each request that
+	// changes state
+	CSRFHeader = "X-CSRF-Token"
+
+	sessionCSRFKey = "csrf"
+)
+
+// newCSRFToken returns a random token
+func newCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// safeMethod is true for the methods that must not change state
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// ProtectCSRF rejects each request that changes state unless it carries the
+// session's CSRF token in the X-CSRF-Token header and, when the browser says
+// where it came from, comes from one of the origins ("*" allows any origin).
+// Safe requests are given the token, in the ignition-csrf cookie and in the
+// X-CSRF-Token response header, creating it when the session has none
+func ProtectCSRF(next http.Handler, s sessions.Store, options CookieOptions, origins []string) http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		if safeMethod(req.Method) {
+			if token := csrfToken(w, req, s); token != "" {
+				w.Header().Set(CSRFHeader, token)
+				if c, err := req.Cookie(CSRFCookieName); err != nil || c.Value != token {
+					cookie := options.cookie(CSRFCookieName, token)
+					cookie.HttpOnly = false
+					http.SetCookie(w, cookie)
+				}
+			}
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		if origin := requestOrigin(req); origin != "" && !allowedOrigin(origin, origins) {
+			log.Printf("request [%s]: rejected a [%s] request from [%s]\n", apierror.RequestID(req), req.Method, origin)
+			apierror.Write(w, req, http.StatusForbidden, "csrf_failed", "The request did not come from ignition. Please reload the page and try again.", false)
+			return
+		}
+		session, err := s.Get(req, sessionName)
+		if err != nil {
+			apierror.Write(w, req, http.StatusForbidden, "csrf_failed", "The request is missing a valid CSRF token. Please reload the page and try again.", false)
+			return
+		}
+		token, _ := session.Values[sessionCSRFKey].(string)
+		sent := req.Header.Get(CSRFHeader)
+		if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(sent)) != 1 {
+			apierror.Write(w, req, http.StatusForbidden, "csrf_failed", "The request is missing a valid CSRF token. Please reload the page and try again.", false)
+			return
+		}
+		next.ServeHTTP(w, req)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// csrfToken returns the session's CSRF token, adding one to the session when
+// it has none; it is "" when there is no session
+func csrfToken(w http.ResponseWriter, req *http.Request, s sessions.Store) string {
+	session, err := s.Get(req, sessionName)
+	if err != nil {
+		return ""
+	}
+	if token, ok := session.Values[sessionCSRFKey].(string); ok && token != "" {
+		return token
+	}
+	token, err := newCSRFToken()
+	if err != nil {
+		log.Println(err)
+		return ""
+	}
+	session.Values[sessionCSRFKey] = token
+	if err := session.Save(w); err != nil {
+		log.Println(err)
+		return ""
+	}
+	return token
+}
+
+// requestOrigin is the origin that the browser says the request came from, or
+// "" when it does not say
+func requestOrigin(req *http.Request) string {
+	if origin := req.Header.Get("Origin"); origin != "" && origin != "null" {
+		return origin
+	}
+	if referer := req.Header.Get("Referer"); referer != "" {
+		if u, err := url.Parse(referer); err == nil && u.Host != "" {
+			return u.Scheme + "://" + u.Host
+		}
+		return referer
+	}
+	return req.Header.Get("Origin")
+}
+
+// allowedOrigin is true when the origin is one of the origins
+func allowedOrigin(origin string, origins []string) bool {
+	origin = normalizeOrigin(origin)
+	for _, o := range origins {
+		if o == "*" || normalizeOrigin(o) == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeOrigin lower cases the origin and drops the default port of its
+// scheme, so that https://example.com:443 matches https://example.com
+func normalizeOrigin(origin string) string {
+	origin = strings.TrimSuffix(strings.ToLower(origin), "/")
+	switch {
+	case strings.HasPrefix(origin, "https://"):
+		return strings.TrimSuffix(origin, ":443")
+	case strings.HasPrefix(origin, "http://"):
+		return strings.TrimSuffix(origin, ":80")
+	}
+	return origin
+}
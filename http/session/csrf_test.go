@@ -0,0 +1,153 @@
+package session_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/http/session"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestProtectCSRF(t *testing.T) {
+	spec.Run(t, "ProtectCSRF", testProtectCSRF, spec.Report(report.Terminal{}))
+}
+
+func testProtectCSRF(t *testing.T, when spec.G, it spec.S) {
+	var (
+		store   *session.CookieStore
+		handler http.Handler
+		called  bool
+		cookie  *http.Cookie
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		called = false
+		store = session.NewCookieStore(session.DefaultCookieOptions, []byte("test-secret"))
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			w.WriteHeader(http.StatusOK)
+		})
+		handler = session.ProtectCSRF(next, store, session.DefaultCookieOptions, []string{"https://ignition.example.com:443", "https://portal.example.com"})
+
+		w := httptest.NewRecorder()
+		s := store.New("ignition")
+		s.Values["uaaid"] = "test-user-id"
+		Expect(store.Save(w, s)).To(Succeed())
+		cookie = w.Result().Cookies()[0]
+	})
+
+	// token makes a safe request with the session, and returns the CSRF token
+	// that it is given
+	token := func() string {
+		req := httptest.NewRequest(http.MethodGet, "https://ignition.example.com/organization", nil)
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		Expect(called).To(BeTrue())
+		called = false
+		for _, c := range w.Result().Cookies() {
+			if c.Name == "ignition" {
+				cookie = c
+			}
+		}
+		return w.Header().Get(session.CSRFHeader)
+	}
+
+	post := func(origin string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "https://ignition.example.com/organization", nil)
+		req.AddCookie(cookie)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if token != "" {
+			req.Header.Set(session.CSRFHeader, token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	it("gives safe requests the token in a cookie that scripts can read", func() {
+		req := httptest.NewRequest(http.MethodGet, "https://ignition.example.com/organization", nil)
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		Expect(called).To(BeTrue())
+		t := w.Header().Get(session.CSRFHeader)
+		Expect(t).NotTo(BeEmpty())
+		var csrf *http.Cookie
+		for _, c := range w.Result().Cookies() {
+			if c.Name == session.CSRFCookieName {
+				csrf = c
+			}
+		}
+		Expect(csrf).NotTo(BeNil())
+		Expect(csrf.Value).To(Equal(t))
+		Expect(csrf.HttpOnly).To(BeFalse())
+		Expect(csrf.Secure).To(BeTrue())
+	})
+
+	it("keeps the same token for the session", func() {
+		t := token()
+		Expect(t).NotTo(BeEmpty())
+		Expect(token()).To(Equal(t))
+	})
+
+	it("allows a request with the session's token from ignition", func() {
+		t := token()
+		w := post("https://ignition.example.com", t)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(called).To(BeTrue())
+	})
+
+	it("allows a request with the session's token from an allowed origin", func() {
+		w := post("https://portal.example.com", token())
+		Expect(w.Code).To(Equal(http.StatusOK))
+	})
+
+	it("rejects a request without the token", func() {
+		token()
+		w := post("https://ignition.example.com", "")
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(w.Body.String()).To(ContainSubstring("csrf_failed"))
+		Expect(called).To(BeFalse())
+	})
+
+	it("rejects a request with another token", func() {
+		token()
+		w := post("https://ignition.example.com", "not-the-token")
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(called).To(BeFalse())
+	})
+
+	it("rejects a request from another origin, even with the token", func() {
+		w := post("https://evil.example.com", token())
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(called).To(BeFalse())
+	})
+
+	it("checks the referer when there is no origin", func() {
+		t := token()
+		req := httptest.NewRequest(http.MethodDelete, "https://ignition.example.com/organization/members/1", nil)
+		req.AddCookie(cookie)
+		req.Header.Set(session.CSRFHeader, t)
+		req.Header.Set("Referer", "https://evil.example.com/page")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(called).To(BeFalse())
+	})
+
+	it("rejects a request without a session", func() {
+		req := httptest.NewRequest(http.MethodPost, "https://ignition.example.com/organization", nil)
+		req.Header.Set(session.CSRFHeader, "any-token")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(called).To(BeFalse())
+	})
+}
@@ -0,0 +1,144 @@
+package session
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pivotalservices/ignition/audit"
+	"github.com/pivotalservices/ignition/http/apierror"
+	"github.com/pivotalservices/ignition/user"
+)
+
+// activeSession is one of the user's sessions, as they see it
+type activeSession struct {
+	ID         string    `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	IP         string    `json:"ip,omitempty"`
+	// Current is true for the session that made the request
+	Current bool `json:"current"`
+}
+
+// ListHandler returns the user's active sessions, newest first
+func (r *Registry) ListHandler() http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		profile, err := user.ProfileFromContext(req.Context())
+		if err != nil || profile == nil {
+			apierror.Write(w, req, http.StatusUnauthorized, "not_authenticated", "You are not logged in.", false)
+			return
+		}
+		if !r.Recorded() {
+			notRecorded(w, req)
+			return
+		}
+		current, _ := SessionIDFromContext(req.Context())
+		sessions := []activeSession{}
+		for _, rec := range r.List(profile.AccountName) {
+			sessions = append(sessions, activeSession{
+				ID:         rec.ID,
+				CreatedAt:  rec.CreatedAt,
+				LastSeenAt: rec.LastSeenAt,
+				UserAgent:  rec.UserAgent,
+				IP:         rec.IP,
+				Current:    rec.ID == current,
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string][]activeSession{"sessions": sessions})
+	}
+	return http.HandlerFunc(fn)
+}
+
+// RevokeHandler ends the user's session with the ID in the path
+func (r *Registry) RevokeHandler() http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		profile, err := user.ProfileFromContext(req.Context())
+		if err != nil || profile == nil {
+			apierror.Write(w, req, http.StatusUnauthorized, "not_authenticated", "You are not logged in.", false)
+			return
+		}
+		id := mux.Vars(req)["id"]
+		err = r.Revoke(profile.AccountName, id)
+		if err == ErrSessionsNotRecorded {
+			notRecorded(w, req)
+			return
+		}
+		if err == ErrSessionNotFound {
+			apierror.Write(w, req, http.StatusNotFound, "session_not_found", "You have no active session with that ID.", false)
+			return
+		}
+		audit.Record(r.audit(), audit.Entry{Actor: profile.AccountName, Action: audit.ActionSessionRevoke, Target: profile.AccountName, Outcome: audit.Outcome(err), Details: map[string]string{"session": id}})
+		if err != nil {
+			log.Printf("request [%s]: %v\n", apierror.RequestID(req), err)
+			apierror.Write(w, req, http.StatusInternalServerError, "sessions_unavailable", "The session could not be revoked. Please try again.", true)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// UserSessionsHandler returns the active sessions of the user with the
+// account name in the path, newest first
+func (r *Registry) UserSessionsHandler() http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		if !r.Recorded() {
+			notRecorded(w, req)
+			return
+		}
+		records := r.List(mux.Vars(req)["user"])
+		if records == nil {
+			records = []Record{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string][]Record{"sessions": records})
+	}
+	return http.HandlerFunc(fn)
+}
+
+// RevokeUserHandler ends every session of the user with the account name in
+// the path
+func (r *Registry) RevokeUserHandler() http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		if !r.Recorded() {
+			notRecorded(w, req)
+			return
+		}
+		account := mux.Vars(req)["user"]
+		revoked, err := r.RevokeAll(account)
+		e := audit.Entry{Action: audit.ActionSessionRevoke, Target: account, Outcome: audit.Outcome(err), Details: map[string]string{"sessions": strconv.Itoa(revoked)}}
+		if profile, perr := user.ProfileFromContext(req.Context()); perr == nil && profile != nil {
+			e.Actor = profile.AccountName
+		}
+		audit.Record(r.audit(), e)
+		if err != nil {
+			log.Printf("request [%s]: %v\n", apierror.RequestID(req), err)
+			apierror.Write(w, req, http.StatusInternalServerError, "sessions_unavailable", "The sessions could not be revoked. Please try again.", true)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]int{"revoked": revoked})
+	}
+	return http.HandlerFunc(fn)
+}
+
+// notRecorded answers a request to list or revoke sessions when the registry
+// has no store to record them in
+func notRecorded(w http.ResponseWriter, req *http.Request) {
+	apierror.Write(w, req, http.StatusNotImplemented, "sessions_not_recorded", "Sessions are not recorded, so they cannot be listed or revoked.", false)
+}
+
+func (r *Registry) audit() audit.Recorder {
+	if r == nil {
+		return nil
+	}
+	return r.Audit
+}
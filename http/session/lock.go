@@ -0,0 +1,30 @@
+//go:build !windows
+// +build !windows
+
+package session
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive lock on the file at path, creating it when it
+// does not exist, and returns the function that releases it. The lock is held
+// across processes on the same machine, so that an old and a new process do
+// not overwrite each other's changes during a restart. It does not coordinate
+// instances on different machines: Cloud Foundry instances have separate
+// disks, and flock is unreliable over NFS
+func lockFile(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,135 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+// Logout ends the user's session at the identity provider when they log out
+// of ignition, so that they are not logged straight back in
+type Logout struct {
+	// RevokeURL is UAA's token revocation endpoint (…/oauth/token/revoke);
+	// the session's tokens are revoked there when it is set
+	RevokeURL string
+	// EndSessionURL is the provider's OIDC end_session_endpoint, or UAA's
+	// /logout.do; the user is sent there to log out when it is set
+	EndSessionURL string
+	// ReturnURL is where the provider sends the user after logging them out
+	ReturnURL string
+	// ClientID is ignition's OAuth client
+	ClientID string
+	// Client makes the revocation requests (default: a client with a 10s
+	// timeout)
+	Client *http.Client
+}
+
+// redirectURL is where the user is sent once they are logged out of ignition
+func (l *Logout) redirectURL() string {
+	if l == nil || strings.TrimSpace(l.EndSessionURL) == "" {
+		return "/"
+	}
+	u, err := url.Parse(l.EndSessionURL)
+	if err != nil {
+		return "/"
+	}
+	q := u.Query()
+	if l.ReturnURL != "" {
+		// UAA's /logout.do names the return URL redirect, and only follows it
+		// when it is allowed for the client
+		if strings.HasSuffix(u.Path, "/logout.do") {
+			q.Set("redirect", l.ReturnURL)
+		} else {
+			q.Set("post_logout_redirect_uri", l.ReturnURL)
+		}
+	}
+	if l.ClientID != "" {
+		q.Set("client_id", l.ClientID)
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// revoke revokes the access token and the refresh token at UAA; the access
+// token authorizes each revocation
+func (l *Logout) revoke(ctx context.Context, token *oauth2.Token) error {
+	if l == nil || strings.TrimSpace(l.RevokeURL) == "" || token == nil || token.AccessToken == "" {
+		return nil
+	}
+	client := l.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	var errs []string
+	for _, t := range []string{token.AccessToken, token.RefreshToken} {
+		if t == "" {
+			continue
+		}
+		endpoint := strings.TrimSuffix(l.RevokeURL, "/") + "/" + url.PathEscape(tokenID(t))
+		req, err := http.NewRequest(http.MethodDelete, endpoint, nil)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+			errs = append(errs, fmt.Sprintf("[%s] answered %d", strings.TrimSuffix(l.RevokeURL, "/"), resp.StatusCode))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("could not revoke the session's tokens: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// tokenID is the ID that UAA revokes a token by: the jti claim of a JWT, or
+// the token itself when it is opaque
+func tokenID(token string) string {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return token
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return token
+	}
+	var claims struct {
+		JTI string `json:"jti"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.JTI == "" {
+		return token
+	}
+	return claims.JTI
+}
+
+// sessionToken returns the OAuth token stored in the session, or nil
+func sessionToken(values map[string]interface{}) *oauth2.Token {
+	rawToken, ok := values[sessionTokenKey].(string)
+	if !ok {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := GunzipWrite(&buf, []byte(rawToken)); err != nil {
+		return nil
+	}
+	token := oauth2.Token{}
+	if err := json.Unmarshal(buf.Bytes(), &token); err != nil {
+		return nil
+	}
+	return &token
+}
@@ -0,0 +1,119 @@
+package session_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/http/session"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestLogout(t *testing.T) {
+	spec.Run(t, "Logout", testLogout, spec.Report(report.Terminal{}))
+}
+
+func testLogout(t *testing.T, when spec.G, it spec.S) {
+	var (
+		store   *session.CookieStore
+		uaa     *httptest.Server
+		mu      sync.Mutex
+		revoked []string
+		bearers []string
+		status  int
+	)
+
+	// accessToken is a JWT with the jti claim; its signature is not checked
+	accessToken := "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(`{"jti":"access-token-id","sub":"test-user-id"}`)) + ".c2lnbmF0dXJl"
+
+	logout := func(l *session.Logout) *httptest.ResponseRecorder {
+		s := store.New("ignition")
+		var token bytes.Buffer
+		session.GzipWrite(&token, []byte(`{"access_token": "`+accessToken+`", "refresh_token": "opaque-refresh-token"}`))
+		s.Values["token"] = token.String()
+		w := httptest.NewRecorder()
+		Expect(store.Save(w, s)).To(Succeed())
+
+		req := httptest.NewRequest(http.MethodGet, "https://ignition.example.com/logout", nil)
+		req.AddCookie(w.Result().Cookies()[0])
+		w = httptest.NewRecorder()
+		session.LogoutHandler(store, nil, l).ServeHTTP(w, req)
+		return w
+	}
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		store = session.NewCookieStore(session.DefaultCookieOptions, session.KeyPairs("test-secret")...)
+		revoked = nil
+		bearers = nil
+		status = http.StatusOK
+		uaa = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			if r.Method == http.MethodDelete {
+				revoked = append(revoked, r.URL.Path)
+				bearers = append(bearers, r.Header.Get("Authorization"))
+			}
+			w.WriteHeader(status)
+		}))
+	})
+
+	it.After(func() {
+		uaa.Close()
+	})
+
+	it("only logs the user out of ignition by default", func() {
+		w := logout(nil)
+		Expect(w.Code).To(Equal(http.StatusFound))
+		Expect(w.Header().Get("Location")).To(Equal("/"))
+		Expect(w.Result().Cookies()[0].MaxAge).To(Equal(-1))
+	})
+
+	it("revokes the session's tokens at UAA", func() {
+		w := logout(&session.Logout{RevokeURL: uaa.URL + "/oauth/token/revoke"})
+		Expect(w.Code).To(Equal(http.StatusFound))
+		Expect(revoked).To(Equal([]string{"/oauth/token/revoke/access-token-id", "/oauth/token/revoke/opaque-refresh-token"}))
+		Expect(bearers).To(ConsistOf("Bearer "+accessToken, "Bearer "+accessToken))
+	})
+
+	it("logs the user out even when the tokens cannot be revoked", func() {
+		status = http.StatusUnauthorized
+		w := logout(&session.Logout{RevokeURL: uaa.URL + "/oauth/token/revoke"})
+		Expect(w.Code).To(Equal(http.StatusFound))
+		Expect(w.Result().Cookies()[0].MaxAge).To(Equal(-1))
+	})
+
+	it("sends the user to UAA's logout.do", func() {
+		w := logout(&session.Logout{
+			EndSessionURL: "https://login.example.com/logout.do",
+			ReturnURL:     "https://ignition.example.com/",
+			ClientID:      "ignition",
+		})
+		location, err := url.Parse(w.Header().Get("Location"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(location.Host).To(Equal("login.example.com"))
+		Expect(location.Path).To(Equal("/logout.do"))
+		Expect(location.Query().Get("redirect")).To(Equal("https://ignition.example.com/"))
+		Expect(location.Query().Get("client_id")).To(Equal("ignition"))
+	})
+
+	it("sends the user to the OIDC end session endpoint", func() {
+		w := logout(&session.Logout{
+			EndSessionURL: "https://idp.example.com/oidc/logout?tenant=engineering",
+			ReturnURL:     "https://ignition.example.com/",
+			ClientID:      "ignition",
+		})
+		location, err := url.Parse(w.Header().Get("Location"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(location.Path).To(Equal("/oidc/logout"))
+		Expect(location.Query().Get("tenant")).To(Equal("engineering"))
+		Expect(location.Query().Get("post_logout_redirect_uri")).To(Equal("https://ignition.example.com/"))
+		Expect(location.Query().Get("client_id")).To(Equal("ignition"))
+	})
+}
@@ -0,0 +1,377 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotalservices/ignition/audit"
+	"github.com/pivotalservices/ignition/internal/jsonfile"
+	"github.com/pkg/errors"
+)
+
+// ErrSessionRevoked is returned for a session that was revoked
+var ErrSessionRevoked = errors.New("the session was revoked")
+
+// ErrSessionNotFound is returned for a session that the registry does not
+// have
+var ErrSessionNotFound = errors.New("the session was not found")
+
+// ErrSessionsNotRecorded is returned when sessions are listed or revoked, but
+// the registry has no store to record them in
+var ErrSessionsNotRecorded = errors.New("sessions are not recorded")
+
+// touchInterval is how often the last time a session was seen is updated
+const touchInterval = time.Minute
+
+// Record describes a session
+type Record struct {
+	ID          string     `json:"id"`
+	AccountName string     `json:"account_name"`
+	UserID      string     `json:"user_id,omitempty"`
+	UserAgent   string     `json:"user_agent,omitempty"`
+	IP          string     `json:"ip,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastSeenAt  time.Time  `json:"last_seen_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Registry ends sessions that are older than their Lifetime or idle for
+// longer than their IdleTimeout. When it has a store (a file on the local
+// disk), it also keeps a record of each session, so that sessions can be
+// listed and revoked. Only recorded sessions are valid then, so a revoked
+// session stays revoked after a restart. The store is not shared between
+// instances, so the registry only works when ignition runs one instance: a
+// session that is revoked on one instance stays valid on the others
+type Registry struct {
+	// Lifetime is how long a session lasts after the user logs in (0: until
+	// the cookie expires)
+	Lifetime time.Duration
+	// IdleTimeout ends a session that has not been used for this long (0:
+	// sessions do not time out)
+	IdleTimeout time.Duration
+	// Audit records each revocation
+	Audit audit.Recorder
+
+	path    string
+	mu      sync.Mutex
+	records map[string]Record
+	// loaded identifies the version of the file that records was read from
+	loaded os.FileInfo
+	now    func() time.Time
+}
+
+// NewRegistry returns a Registry that records sessions in the file at path,
+// loading any records that are already in it. Sessions are not recorded, and
+// so cannot be listed or revoked, when path is empty
+func NewRegistry(path string) (*Registry, error) {
+	r := &Registry{
+		path:    path,
+		records: map[string]Record{},
+	}
+	if !r.Recorded() {
+		return r, nil
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Recorded is true when the registry records sessions, so that they can be
+// listed and revoked
+func (r *Registry) Recorded() bool {
+	return r != nil && strings.TrimSpace(r.path) != ""
+}
+
+// Start records a new session for the user, from the request that logged
+// them in
+func (r *Registry) Start(req *http.Request, accountName string, userID string) (Record, error) {
+	if r == nil {
+		return Record{}, nil
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return Record{}, errors.Wrap(err, "could not create a session ID")
+	}
+	now := r.time()
+	rec := Record{
+		ID:          hex.EncodeToString(b),
+		AccountName: accountName,
+		UserID:      userID,
+		UserAgent:   req.UserAgent(),
+		IP:          clientIP(req),
+		CreatedAt:   now,
+		LastSeenAt:  now,
+	}
+	if !r.Recorded() {
+		return rec, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.update(func() bool {
+		r.records[rec.ID] = rec
+		return true
+	})
+	if err != nil {
+		return Record{}, err
+	}
+	return rec, nil
+}
+
+// Expired is true when a session created and last seen at these times has
+// outlived its Lifetime or IdleTimeout
+func (r *Registry) Expired(createdAt time.Time, lastSeenAt time.Time) bool {
+	if r == nil {
+		return false
+	}
+	now := r.time()
+	if r.Lifetime > 0 && now.After(createdAt.Add(r.Lifetime)) {
+		return true
+	}
+	return r.IdleTimeout > 0 && now.After(lastSeenAt.Add(r.IdleTimeout))
+}
+
+// Touch records that the session was used by the request. It fails with
+// ErrSessionRevoked when the session was revoked, and with ErrSessionNotFound
+// when the registry has no record of it (because it was revoked and then
+// pruned, or the store was lost). Without a store, only the time the session
+// was last seen is updated
+func (r *Registry) Touch(req *http.Request, rec Record) (Record, error) {
+	if r == nil {
+		return rec, nil
+	}
+	now := r.time()
+	if !r.Recorded() {
+		if now.Sub(rec.LastSeenAt) >= touchInterval {
+			rec.LastSeenAt = now
+		}
+		return rec, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reload()
+	existing, ok := r.records[rec.ID]
+	if !ok {
+		return rec, ErrSessionNotFound
+	}
+	if existing.RevokedAt != nil {
+		return existing, ErrSessionRevoked
+	}
+	if now.Sub(existing.LastSeenAt) < touchInterval && (rec.UserID == "" || existing.UserID == rec.UserID) {
+		return existing, nil
+	}
+	var touched Record
+	err := r.update(func() bool {
+		// the record may have changed in another process since it was read
+		current, ok := r.records[rec.ID]
+		if !ok || current.RevokedAt != nil {
+			touched = current
+			return false
+		}
+		if rec.UserID != "" {
+			current.UserID = rec.UserID
+		}
+		if now.Sub(current.LastSeenAt) >= touchInterval {
+			current.LastSeenAt = now
+			current.IP = clientIP(req)
+		}
+		r.records[rec.ID] = current
+		touched = current
+		return true
+	})
+	if touched.ID == "" {
+		return rec, ErrSessionNotFound
+	}
+	if touched.RevokedAt != nil {
+		return touched, ErrSessionRevoked
+	}
+	return touched, err
+}
+
+// List returns the active sessions of the user, newest first
+func (r *Registry) List(accountName string) []Record {
+	if !r.Recorded() {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reload()
+	r.prune(r.time())
+	var records []Record
+	for _, rec := range r.records {
+		if rec.RevokedAt == nil && strings.EqualFold(rec.AccountName, accountName) {
+			records = append(records, rec)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.After(records[j].CreatedAt)
+	})
+	return records
+}
+
+// Revoke ends one of the user's sessions; it fails with ErrSessionNotFound
+// when the user has no active session with the ID, and with
+// ErrSessionsNotRecorded when the registry has no store
+func (r *Registry) Revoke(accountName string, id string) error {
+	if !r.Recorded() {
+		return ErrSessionsNotRecorded
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	found := false
+	err := r.update(func() bool {
+		rec, ok := r.records[id]
+		if !ok || rec.RevokedAt != nil || !strings.EqualFold(rec.AccountName, accountName) {
+			return false
+		}
+		now := r.time()
+		rec.RevokedAt = &now
+		r.records[id] = rec
+		found = true
+		return true
+	})
+	if err == nil && !found {
+		return ErrSessionNotFound
+	}
+	return err
+}
+
+// RevokeAll ends every session of the user, and returns how many were ended;
+// it fails with ErrSessionsNotRecorded when the registry has no store
+func (r *Registry) RevokeAll(accountName string) (int, error) {
+	if !r.Recorded() {
+		return 0, ErrSessionsNotRecorded
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	revoked := 0
+	err := r.update(func() bool {
+		now := r.time()
+		for id, rec := range r.records {
+			if rec.RevokedAt == nil && strings.EqualFold(rec.AccountName, accountName) {
+				rec.RevokedAt = &now
+				r.records[id] = rec
+				revoked++
+			}
+		}
+		return revoked > 0
+	})
+	if err != nil {
+		return 0, err
+	}
+	return revoked, nil
+}
+
+// prune forgets the sessions that have expired; revoked sessions are kept
+// until they would have expired, so that they cannot be used again
+func (r *Registry) prune(now time.Time) {
+	for id, rec := range r.records {
+		if (r.Lifetime > 0 && now.After(rec.CreatedAt.Add(r.Lifetime))) ||
+			(r.IdleTimeout > 0 && now.After(rec.LastSeenAt.Add(r.IdleTimeout))) {
+			delete(r.records, id)
+		}
+	}
+}
+
+// update makes a change to the records while holding the store's lock, after
+// reading any changes made by other processes; the records are written back
+// when change returns true. r.mu must be held
+func (r *Registry) update(change func() bool) error {
+	unlock, err := lockFile(r.path + ".lock")
+	if err != nil {
+		return errors.Wrapf(err, "could not lock session store [%s]", r.path)
+	}
+	defer unlock()
+	r.reload()
+	if !change() {
+		return nil
+	}
+	r.prune(r.time())
+	return r.persist()
+}
+
+// reload reads the records again when another process has changed the file
+// since they were read; the records that were read are kept when the file
+// cannot be read. r.mu must be held
+func (r *Registry) reload() {
+	info, err := os.Stat(r.path)
+	if err == nil && r.loaded != nil && os.SameFile(info, r.loaded) && info.ModTime().Equal(r.loaded.ModTime()) && info.Size() == r.loaded.Size() {
+		return
+	}
+	if err := r.load(); err != nil {
+		log.Println(err)
+	}
+}
+
+// load reads every record from the file
+func (r *Registry) load() error {
+	info, err := os.Stat(r.path)
+	if os.IsNotExist(err) {
+		r.records = map[string]Record{}
+		r.loaded = nil
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "could not read session store [%s]", r.path)
+	}
+	var records []Record
+	if err := jsonfile.Read(r.path, "session store", &records); err != nil {
+		return err
+	}
+	r.records = map[string]Record{}
+	for _, rec := range records {
+		r.records[rec.ID] = rec
+	}
+	r.loaded = info
+	return nil
+}
+
+// persist writes every record to the file, oldest first
+func (r *Registry) persist() error {
+	if strings.TrimSpace(r.path) == "" {
+		return nil
+	}
+	records := make([]Record, 0, len(r.records))
+	for _, rec := range r.records {
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.Before(records[j].CreatedAt)
+	})
+	if err := jsonfile.Write(r.path, "session store", records); err != nil {
+		return err
+	}
+	// the file that was written is the version that the records are from
+	if info, err := os.Stat(r.path); err == nil {
+		r.loaded = info
+	}
+	return nil
+}
+
+func (r *Registry) time() time.Time {
+	if r.now != nil {
+		return r.now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+// clientIP is the address of the client that made the request, as reported by
+// the proxies in front of ignition when there are any
+func clientIP(req *http.Request) string {
+	if forwarded := req.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
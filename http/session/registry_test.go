@@ -0,0 +1,291 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/audit"
+	"github.com/pivotalservices/ignition/user"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestRegistry(t *testing.T) {
+	spec.Run(t, "Registry", testRegistry, spec.Report(report.Terminal{}))
+}
+
+func testRegistry(t *testing.T, when spec.G, it spec.S) {
+	var (
+		dir   string
+		r     *Registry
+		store *CookieStore
+		now   time.Time
+	)
+
+	login := func(userAgent string) *http.Cookie {
+		req := httptest.NewRequest(http.MethodGet, "/oauth2", nil)
+		req.Header.Set("User-Agent", userAgent)
+		req.RemoteAddr = "203.0.113.7:52100"
+		rec, err := r.Start(req, "testuser", "test-user-id")
+		Expect(err).NotTo(HaveOccurred())
+		s := store.New(sessionName)
+		var token bytes.Buffer
+		GzipWrite(&token, []byte(`{"access_token": "test-token"}`))
+		s.Values[sessionTokenKey] = token.String()
+		s.Values[sessionProfileKey] = `{"AccountName": "testuser"}`
+		s.Values[sessionIDKey] = rec.ID
+		s.Values[sessionCreatedKey] = rec.CreatedAt.Unix()
+		s.Values[sessionSeenKey] = rec.LastSeenAt.Unix()
+		w := httptest.NewRecorder()
+		Expect(store.Save(w, s)).To(Succeed())
+		return w.Result().Cookies()[0]
+	}
+
+	// populate makes a request with the cookie, and returns whether it was
+	// logged in and the response
+	populate := func(c *http.Cookie) (bool, *httptest.ResponseRecorder) {
+		loggedIn := false
+		next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			_, err := SessionIDFromContext(req.Context())
+			loggedIn = err == nil
+		})
+		req := httptest.NewRequest(http.MethodGet, "/organization", nil)
+		req.AddCookie(c)
+		w := httptest.NewRecorder()
+		PopulateContext(next, store, r).ServeHTTP(w, req)
+		return loggedIn, w
+	}
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		var err error
+		dir, err = ioutil.TempDir("", "sessions")
+		Expect(err).NotTo(HaveOccurred())
+		r, err = NewRegistry(filepath.Join(dir, "sessions.json"))
+		Expect(err).NotTo(HaveOccurred())
+		now = time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)
+		r.now = func() time.Time { return now }
+		r.Lifetime = 7 * 24 * time.Hour
+		r.IdleTimeout = 24 * time.Hour
+		store = NewCookieStore(DefaultCookieOptions, KeyPairs("test-secret")...)
+	})
+
+	it.After(func() {
+		os.RemoveAll(dir)
+	})
+
+	it("lists the user's sessions, newest first", func() {
+		login("laptop")
+		now = now.Add(time.Hour)
+		login("phone")
+		records := r.List("testuser")
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].UserAgent).To(Equal("phone"))
+		Expect(records[0].IP).To(Equal("203.0.113.7"))
+		Expect(records[0].UserID).To(Equal("test-user-id"))
+		Expect(records[1].UserAgent).To(Equal("laptop"))
+		Expect(r.List("otheruser")).To(BeEmpty())
+	})
+
+	it("ends a revoked session", func() {
+		c := login("laptop")
+		loggedIn, _ := populate(c)
+		Expect(loggedIn).To(BeTrue())
+
+		id := r.List("testuser")[0].ID
+		Expect(r.Revoke("otheruser", id)).To(Equal(ErrSessionNotFound))
+		Expect(r.Revoke("testuser", id)).To(Succeed())
+		loggedIn, w := populate(c)
+		Expect(loggedIn).To(BeFalse())
+		Expect(w.Result().Cookies()[0].MaxAge).To(Equal(-1))
+		Expect(r.List("testuser")).To(BeEmpty())
+		Expect(r.Revoke("testuser", id)).To(Equal(ErrSessionNotFound))
+	})
+
+	it("keeps revocations across restarts", func() {
+		c := login("laptop")
+		n, err := r.RevokeAll("testuser")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+		r, err = NewRegistry(filepath.Join(dir, "sessions.json"))
+		Expect(err).NotTo(HaveOccurred())
+		r.now = func() time.Time { return now }
+		loggedIn, _ := populate(c)
+		Expect(loggedIn).To(BeFalse())
+	})
+
+	it("ends a session that it has no record of", func() {
+		c := login("laptop")
+		Expect(os.Remove(filepath.Join(dir, "sessions.json"))).To(Succeed())
+		r, _ = NewRegistry(filepath.Join(dir, "sessions.json"))
+		r.now = func() time.Time { return now }
+		loggedIn, _ := populate(c)
+		Expect(loggedIn).To(BeFalse())
+	})
+
+	it("shares sessions and revocations with the other processes on the machine that use the store", func() {
+		other, err := NewRegistry(filepath.Join(dir, "sessions.json"))
+		Expect(err).NotTo(HaveOccurred())
+		other.now = func() time.Time { return now }
+		c := login("laptop")
+		Expect(other.List("testuser")).To(HaveLen(1))
+
+		_, err = other.RevokeAll("testuser")
+		Expect(err).NotTo(HaveOccurred())
+		loggedIn, _ := populate(c)
+		Expect(loggedIn).To(BeFalse())
+	})
+
+	when("there is no store", func() {
+		it.Before(func() {
+			r, _ = NewRegistry("")
+			r.now = func() time.Time { return now }
+			r.Lifetime = 7 * 24 * time.Hour
+			r.IdleTimeout = 24 * time.Hour
+		})
+
+		it("still ends sessions that are idle for too long", func() {
+			c := login("laptop")
+			now = now.Add(23 * time.Hour)
+			loggedIn, _ := populate(c)
+			Expect(loggedIn).To(BeTrue())
+			now = now.Add(25 * time.Hour)
+			loggedIn, _ = populate(c)
+			Expect(loggedIn).To(BeFalse())
+		})
+
+		it("cannot list or revoke sessions", func() {
+			login("laptop")
+			Expect(r.Recorded()).To(BeFalse())
+			Expect(r.List("testuser")).To(BeEmpty())
+			Expect(r.Revoke("testuser", "any")).To(Equal(ErrSessionsNotRecorded))
+			_, err := r.RevokeAll("testuser")
+			Expect(err).To(Equal(ErrSessionsNotRecorded))
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/sessions", nil)
+			req = req.WithContext(user.WithProfile(req.Context(), &user.Profile{AccountName: "testuser"}))
+			r.ListHandler().ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusNotImplemented))
+			Expect(w.Body.String()).To(ContainSubstring("sessions_not_recorded"))
+		})
+	})
+
+	it("ends a session that has been idle for too long", func() {
+		c := login("laptop")
+		now = now.Add(23 * time.Hour)
+		loggedIn, w := populate(c)
+		Expect(loggedIn).To(BeTrue())
+		// the session was saved with the time it was last seen
+		c = w.Result().Cookies()[0]
+
+		now = now.Add(23 * time.Hour)
+		loggedIn, _ = populate(c)
+		Expect(loggedIn).To(BeTrue())
+
+		now = now.Add(25 * time.Hour)
+		loggedIn, _ = populate(c)
+		Expect(loggedIn).To(BeFalse())
+	})
+
+	it("ends a session that is older than its lifetime", func() {
+		c := login("laptop")
+		for i := 0; i < 7; i++ {
+			now = now.Add(23 * time.Hour)
+			loggedIn, w := populate(c)
+			Expect(loggedIn).To(BeTrue())
+			c = w.Result().Cookies()[0]
+		}
+		now = now.Add(8 * time.Hour)
+		loggedIn, _ := populate(c)
+		Expect(loggedIn).To(BeFalse())
+	})
+
+	it("ends a session that was issued before sessions were registered", func() {
+		s := store.New(sessionName)
+		s.Values[sessionProfileKey] = `{"AccountName": "testuser"}`
+		w := httptest.NewRecorder()
+		Expect(store.Save(w, s)).To(Succeed())
+		loggedIn, _ := populate(w.Result().Cookies()[0])
+		Expect(loggedIn).To(BeFalse())
+	})
+
+	when("handling requests", func() {
+		var l *audit.Log
+
+		request := func(method string, path string, vars map[string]string, current string) *http.Request {
+			req := httptest.NewRequest(method, path, nil)
+			ctx := user.WithProfile(req.Context(), &user.Profile{AccountName: "testuser"})
+			req = req.WithContext(ContextWithSessionID(ctx, current))
+			return mux.SetURLVars(req, vars)
+		}
+
+		it.Before(func() {
+			l = audit.NewLog()
+			r.Audit = l
+			login("laptop")
+			now = now.Add(time.Hour)
+			login("phone")
+		})
+
+		it("lists the user's sessions", func() {
+			current := r.List("testuser")[1].ID
+			w := httptest.NewRecorder()
+			r.ListHandler().ServeHTTP(w, request(http.MethodGet, "/sessions", nil, current))
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var body struct {
+				Sessions []activeSession `json:"sessions"`
+			}
+			Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+			Expect(body.Sessions).To(HaveLen(2))
+			Expect(body.Sessions[0].UserAgent).To(Equal("phone"))
+			Expect(body.Sessions[0].Current).To(BeFalse())
+			Expect(body.Sessions[1].Current).To(BeTrue())
+			Expect(w.Body.String()).NotTo(ContainSubstring("test-user-id"))
+		})
+
+		it("revokes one of the user's sessions", func() {
+			id := r.List("testuser")[0].ID
+			w := httptest.NewRecorder()
+			r.RevokeHandler().ServeHTTP(w, request(http.MethodDelete, "/sessions/"+id, map[string]string{"id": id}, ""))
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+			Expect(r.List("testuser")).To(HaveLen(1))
+
+			w = httptest.NewRecorder()
+			r.RevokeHandler().ServeHTTP(w, request(http.MethodDelete, "/sessions/"+id, map[string]string{"id": id}, ""))
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+			Expect(w.Body.String()).To(ContainSubstring("session_not_found"))
+
+			entries, _ := l.Query(audit.Filter{Action: audit.ActionSessionRevoke})
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Details).To(HaveKeyWithValue("session", id))
+		})
+
+		it("lets admins list and revoke every session of a user", func() {
+			w := httptest.NewRecorder()
+			r.UserSessionsHandler().ServeHTTP(w, request(http.MethodGet, "/admin/users/testuser/sessions", map[string]string{"user": "testuser"}, ""))
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(strings.Count(w.Body.String(), `"account_name":"testuser"`)).To(Equal(2))
+
+			w = httptest.NewRecorder()
+			r.RevokeUserHandler().ServeHTTP(w, request(http.MethodDelete, "/admin/users/testuser/sessions", map[string]string{"user": "testuser"}, ""))
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(MatchJSON(`{"revoked": 2}`))
+			Expect(r.List("testuser")).To(BeEmpty())
+
+			entries, _ := l.Query(audit.Filter{Action: audit.ActionSessionRevoke, Target: "testuser"})
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Details).To(HaveKeyWithValue("sessions", "2"))
+		})
+	})
+}
@@ -0,0 +1,157 @@
+package session
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	dgoauth2 "github.com/dghubble/gologin/oauth2"
+)
+
+// ReturnToCookieName is the cookie that holds the page to return the user to
+// while they log in
+const ReturnToCookieName = "ignition-next"
+
+// returnToMaxAge is how long the user has to log in at the identity provider
+// before the page they asked for is forgotten
+const returnToMaxAge = 10 * time.Minute
+
+// maxReturnToLength bounds the length of the page to return to
+const maxReturnToLength = 2048
+
+// authPaths are never returned to: returning to them would start another
+// login, or end the session that was just issued
+var authPaths = []string{"/login", "/logout", "/oauth2"}
+
+// ReturnTo sends the user back to the page they asked for after they log in,
+// from /login?next=/path. Only paths on ignition itself that are under one of
+// the allowed Paths are returned to, so the login cannot be used as an open
+// redirect
+type ReturnTo struct {
+	// Paths are the path prefixes that may be returned to (default: "/",
+	// every page)
+	Paths []string
+	// Options are the attributes of the cookie that holds the page while the
+	// user logs in
+	Options CookieOptions
+}
+
+// Remember keeps the page to return to (from the next query parameter) in a
+// cookie for the login that next starts. The cookie is bound to the OAuth
+// state, so it is only used by the callback that completes this login
+func (rt *ReturnTo) Remember(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		if rt == nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+		state, err := dgoauth2.StateFromContext(req.Context())
+		target, ok := rt.allowed(req.URL.Query().Get("next"))
+		if err == nil && ok {
+			http.SetCookie(w, rt.cookie(state+"."+base64.RawURLEncoding.EncodeToString([]byte(target))))
+		} else if _, err := req.Cookie(ReturnToCookieName); err == nil {
+			// forget the page from an earlier login that was not completed
+			http.SetCookie(w, rt.Options.expired(ReturnToCookieName))
+		}
+		next.ServeHTTP(w, req)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// target is the page to send the user to once they are logged in: the page
+// remembered for the login's OAuth state when it is still allowed, or "/". The
+// cookie is removed
+func (rt *ReturnTo) target(w http.ResponseWriter, req *http.Request) string {
+	if rt == nil {
+		return "/"
+	}
+	cookie, err := req.Cookie(ReturnToCookieName)
+	if err != nil {
+		return "/"
+	}
+	http.SetCookie(w, rt.Options.expired(ReturnToCookieName))
+	i := strings.LastIndex(cookie.Value, ".")
+	if i < 0 {
+		return "/"
+	}
+	state, err := dgoauth2.StateFromContext(req.Context())
+	if err != nil || state != cookie.Value[:i] {
+		return "/"
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cookie.Value[i+1:])
+	if err != nil {
+		return "/"
+	}
+	target, ok := rt.allowed(string(b))
+	if !ok {
+		return "/"
+	}
+	return target
+}
+
+// allowed returns the page to return to, and whether it is allowed: it must
+// be a path on ignition itself (not another host, or a path that a browser
+// could read as one), and under one of the allowed Paths
+func (rt *ReturnTo) allowed(next string) (string, bool) {
+	if next == "" || len(next) > maxReturnToLength {
+		return "", false
+	}
+	// "//host" and "/\host" are read by browsers as another host
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.ContainsRune(next, '\\') {
+		return "", false
+	}
+	for _, r := range next {
+		if r < 0x20 || r == 0x7f {
+			return "", false
+		}
+	}
+	u, err := url.Parse(next)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.User != nil || u.Opaque != "" {
+		return "", false
+	}
+	if strings.HasPrefix(u.Path, "//") {
+		return "", false
+	}
+	for _, segment := range strings.Split(u.Path, "/") {
+		if segment == "." || segment == ".." {
+			return "", false
+		}
+	}
+	for _, p := range authPaths {
+		if underPath(u.Path, p) {
+			return "", false
+		}
+	}
+	paths := rt.Paths
+	if len(paths) == 0 {
+		paths = []string{"/"}
+	}
+	for _, p := range paths {
+		if underPath(u.Path, p) {
+			target := &url.URL{Path: u.Path, RawPath: u.RawPath, RawQuery: u.RawQuery}
+			return target.String(), true
+		}
+	}
+	return "", false
+}
+
+// cookie returns the cookie that holds the page to return to. It is always
+// kept from scripts, and is sent on the identity provider's redirect back to
+// ignition even when the session cookie is SameSite=Strict
+func (rt *ReturnTo) cookie(value string) *http.Cookie {
+	options := rt.Options
+	options.MaxAge = returnToMaxAge
+	options.HTTPOnly = true
+	if options.SameSite == http.SameSiteStrictMode {
+		options.SameSite = http.SameSiteLaxMode
+	}
+	return options.cookie(ReturnToCookieName, value)
+}
+
+// underPath is true when the path is prefix, or is below it
+func underPath(path string, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
@@ -0,0 +1,146 @@
+package session_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/dghubble/gologin"
+	dgoauth2 "github.com/dghubble/gologin/oauth2"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/http/session"
+	"github.com/pivotalservices/ignition/uaa/uaafakes"
+	"github.com/pivotalservices/ignition/user"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"golang.org/x/oauth2"
+)
+
+func TestReturnTo(t *testing.T) {
+	spec.Run(t, "ReturnTo", testReturnTo, spec.Report(report.Terminal{}))
+}
+
+func testReturnTo(t *testing.T, when spec.G, it spec.S) {
+	var (
+		rt    *session.ReturnTo
+		store *session.CookieStore
+	)
+
+	// startLogin requests /login?next=... and returns the cookies it set
+	startLogin := func(next string, cookies ...*http.Cookie) []*http.Cookie {
+		req := httptest.NewRequest(http.MethodGet, "https://ignition.example.com/login?next="+url.QueryEscape(next), nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		login := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusFound)
+		})
+		dgoauth2.StateHandler(gologin.DefaultCookieConfig, rt.Remember(login)).ServeHTTP(w, req)
+		return w.Result().Cookies()
+	}
+
+	// finishLogin completes the login with the cookies, and returns where the
+	// user was sent
+	finishLogin := func(cookies []*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "https://ignition.example.com/oauth2", nil)
+		for _, c := range cookies {
+			if c.MaxAge >= 0 {
+				req.AddCookie(c)
+			}
+		}
+		ctx := user.WithProfile(req.Context(), &user.Profile{AccountName: "testuser"})
+		ctx = dgoauth2.WithToken(ctx, &oauth2.Token{AccessToken: "test-token"})
+		w := httptest.NewRecorder()
+		issue := session.IssueSession(store, &uaafakes.FakeAPI{}, nil, rt)
+		dgoauth2.StateHandler(gologin.DefaultCookieConfig, issue).ServeHTTP(w, req.WithContext(ctx))
+		return w
+	}
+
+	returnsTo := func(next string) string {
+		return finishLogin(startLogin(next)).Header().Get("Location")
+	}
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		rt = &session.ReturnTo{Options: session.DefaultCookieOptions}
+		store = session.NewCookieStore(session.DefaultCookieOptions, session.KeyPairs("test-secret")...)
+	})
+
+	it("returns the user to the page they asked for", func() {
+		Expect(returnsTo("/organization?foundation=east")).To(Equal("/organization?foundation=east"))
+		Expect(returnsTo("/profile")).To(Equal("/profile"))
+		Expect(returnsTo("")).To(Equal("/"))
+	})
+
+	it("keeps the page away from scripts, and forgets it once the user is logged in", func() {
+		cookies := startLogin("/profile")
+		var returnTo *http.Cookie
+		for _, c := range cookies {
+			if c.Name == session.ReturnToCookieName {
+				returnTo = c
+			}
+		}
+		Expect(returnTo).NotTo(BeNil())
+		Expect(returnTo.HttpOnly).To(BeTrue())
+		Expect(returnTo.MaxAge).To(BeNumerically(">", 0))
+
+		w := finishLogin(cookies)
+		Expect(w.Result().Cookies()).To(ContainElement(And(
+			WithTransform(func(c *http.Cookie) string { return c.Name }, Equal(session.ReturnToCookieName)),
+			WithTransform(func(c *http.Cookie) int { return c.MaxAge }, Equal(-1)),
+		)))
+	})
+
+	it("does not return the user to another site", func() {
+		for _, next := range []string{
+			"https://evil.example.com/",
+			"//evil.example.com/",
+			"/\\evil.example.com",
+			"/%2F/evil.example.com",
+			"javascript:alert(1)",
+			"/organization/../../evil",
+			"/organization\r\nLocation: https://evil.example.com",
+			"organization",
+		} {
+			Expect(returnsTo(next)).To(Equal("/"), next)
+		}
+	})
+
+	it("does not return the user to the login or logout pages", func() {
+		Expect(returnsTo("/login?next=/profile")).To(Equal("/"))
+		Expect(returnsTo("/logout")).To(Equal("/"))
+		Expect(returnsTo("/oauth2?code=abc")).To(Equal("/"))
+	})
+
+	it("only returns the user to the allowed paths", func() {
+		rt.Paths = []string{"/organization"}
+		Expect(returnsTo("/organization")).To(Equal("/organization"))
+		Expect(returnsTo("/organization/spaces")).To(Equal("/organization/spaces"))
+		Expect(returnsTo("/organizations")).To(Equal("/"))
+		Expect(returnsTo("/profile")).To(Equal("/"))
+	})
+
+	it("only uses the page for the login that asked for it", func() {
+		cookies := startLogin("/profile")
+		for _, c := range cookies {
+			if c.Name == gologin.DefaultCookieConfig.Name {
+				c.Value = "another-state"
+			}
+		}
+		Expect(finishLogin(cookies).Header().Get("Location")).To(Equal("/"))
+	})
+
+	it("forgets the page from a login that was not completed", func() {
+		first := startLogin("/profile")
+		var returnTo *http.Cookie
+		for _, c := range first {
+			if c.Name == session.ReturnToCookieName {
+				returnTo = c
+			}
+		}
+		cookies := startLogin("", returnTo)
+		Expect(cookies).To(ContainElement(WithTransform(func(c *http.Cookie) int { return c.MaxAge }, Equal(-1))))
+	})
+}
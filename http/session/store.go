@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	dgoauth2 "github.com/dghubble/gologin/oauth2"
 	"github.com/dghubble/sessions"
@@ -19,6 +20,9 @@ const (
 	sessionProfileKey = "profile"
 	sessionEmailKey   = "email"
 	sessionUAAIDKey   = "uaaid"
+	sessionIDKey      = "sid"
+	sessionCreatedKey = "created"
+	sessionSeenKey    = "seen"
 	sessionName       = "ignition"
 )
 
@@ -37,8 +41,9 @@ func UpdateSessionWithUserID(w http.ResponseWriter, req *http.Request, s session
 }
 
 // IssueSession stores the user's authentication state and profile in the
-// session
-func IssueSession(s sessions.Store, u uaa.API) http.Handler {
+// session, records the new session in the registry, and sends the user to the
+// page they asked for before logging in
+func IssueSession(s sessions.Store, u uaa.API, r *Registry, rt *ReturnTo) http.Handler {
 	fn := func(w http.ResponseWriter, req *http.Request) {
 		profile, err := user.ProfileFromContext(req.Context())
 		if err != nil {
@@ -78,20 +83,40 @@ func IssueSession(s sessions.Store, u uaa.API) http.Handler {
 		if err == nil {
 			session.Values[sessionUAAIDKey] = userID
 		}
+		rec, err := r.Start(req, profile.AccountName, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if rec.ID != "" {
+			session.Values[sessionIDKey] = rec.ID
+			session.Values[sessionCreatedKey] = rec.CreatedAt.Unix()
+			session.Values[sessionSeenKey] = rec.LastSeenAt.Unix()
+		}
+		// each login gets a new CSRF token
+		if token, err := newCSRFToken(); err == nil {
+			session.Values[sessionCSRFKey] = token
+		}
 		session.Save(w)
-		http.Redirect(w, req, "/", http.StatusFound)
+		http.Redirect(w, req, rt.target(w, req), http.StatusFound)
 	}
 	return http.HandlerFunc(fn)
 }
 
-// PopulateContext populates the context with session information
-func PopulateContext(next http.Handler, s sessions.Store) http.Handler {
+// PopulateContext populates the context with session information. A session
+// that the registry has revoked, or that has expired, is destroyed instead
+func PopulateContext(next http.Handler, s sessions.Store, r *Registry) http.Handler {
 	fn := func(w http.ResponseWriter, req *http.Request) {
 		session, err := s.Get(req, sessionName)
 		if err != nil {
 			next.ServeHTTP(w, req)
 			return
 		}
+		if !r.valid(w, req, session) {
+			s.Destroy(w, sessionName)
+			next.ServeHTTP(w, req)
+			return
+		}
 		rawToken, ok := session.Values[sessionTokenKey].(string)
 		var buf bytes.Buffer
 		err = GunzipWrite(&buf, []byte(rawToken))
@@ -123,17 +148,77 @@ func PopulateContext(next http.Handler, s sessions.Store) http.Handler {
 		if ok {
 			ctx = ContextWithUserID(ctx, userID)
 		}
+		if id, ok := session.Values[sessionIDKey].(string); ok {
+			ctx = ContextWithSessionID(ctx, id)
+		}
 
 		next.ServeHTTP(w, req.WithContext(ctx))
 	}
 	return http.HandlerFunc(fn)
 }
 
-// LogoutHandler logs a user out and deletes their session
-func LogoutHandler(s sessions.Store) http.Handler {
+// LogoutHandler logs a user out: their tokens are revoked at the identity
+// provider and their session is revoked in the registry and deleted, and they
+// are then sent to the provider to log out there too
+func LogoutHandler(s sessions.Store, r *Registry, l *Logout) http.Handler {
 	fn := func(w http.ResponseWriter, req *http.Request) {
+		if session, err := s.Get(req, sessionName); err == nil && session != nil {
+			if err := l.revoke(req.Context(), sessionToken(session.Values)); err != nil {
+				log.Println(err)
+			}
+			id, _ := session.Values[sessionIDKey].(string)
+			rawProfile, _ := session.Values[sessionProfileKey].(string)
+			profile := user.Profile{}
+			if id != "" && json.Unmarshal([]byte(rawProfile), &profile) == nil {
+				if err := r.Revoke(profile.AccountName, id); err != nil && err != ErrSessionNotFound {
+					log.Println(err)
+				}
+			}
+		}
 		s.Destroy(w, sessionName)
-		http.Redirect(w, req, "/", http.StatusFound)
+		http.Redirect(w, req, l.redirectURL(), http.StatusFound)
 	}
 	return http.HandlerFunc(fn)
 }
+
+// valid is true when the session may be used: it has not expired, and the
+// registry (when it records sessions) has a record of it that is not revoked.
+// The time the session was last seen is updated in the session at most once a
+// minute. Every session is valid when there is no registry
+func (r *Registry) valid(w http.ResponseWriter, req *http.Request, session *sessions.Session) bool {
+	if r == nil {
+		return true
+	}
+	id, _ := session.Values[sessionIDKey].(string)
+	created, _ := session.Values[sessionCreatedKey].(int64)
+	seen, _ := session.Values[sessionSeenKey].(int64)
+	if id == "" {
+		// sessions issued before sessions were registered cannot be revoked
+		return false
+	}
+	if r.Expired(time.Unix(created, 0), time.Unix(seen, 0)) {
+		return false
+	}
+	rec := Record{ID: id, CreatedAt: time.Unix(created, 0).UTC(), LastSeenAt: time.Unix(seen, 0).UTC()}
+	rec.UserID, _ = session.Values[sessionUAAIDKey].(string)
+	if rawProfile, ok := session.Values[sessionProfileKey].(string); ok {
+		profile := user.Profile{}
+		if json.Unmarshal([]byte(rawProfile), &profile) == nil {
+			rec.AccountName = profile.AccountName
+		}
+	}
+	touched, err := r.Touch(req, rec)
+	if err == ErrSessionRevoked || err == ErrSessionNotFound {
+		return false
+	}
+	if err != nil {
+		log.Println(err)
+	}
+	if touched.LastSeenAt.Unix()-seen >= int64(touchInterval/time.Second) {
+		session.Values[sessionSeenKey] = touched.LastSeenAt.Unix()
+		if err := session.Save(w); err != nil {
+			log.Println(err)
+		}
+	}
+	return true
+}
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -44,7 +47,7 @@ func testIssueSession(t *testing.T, when spec.G, it spec.S) {
 
 	when("there is no user profile", func() {
 		it("is an internal server error", func() {
-			handler := session.IssueSession(fakeSessionStore, fakeUAAAPI)
+			handler := session.IssueSession(fakeSessionStore, fakeUAAAPI, nil, nil)
 			req := httptest.NewRequest("GET", "http://example.com/oauth2", nil)
 			ctx := context.Background()
 			req = req.WithContext(ctx)
@@ -56,7 +59,7 @@ func testIssueSession(t *testing.T, when spec.G, it spec.S) {
 
 	when("there is no token", func() {
 		it("is an internal server error", func() {
-			handler := session.IssueSession(fakeSessionStore, fakeUAAAPI)
+			handler := session.IssueSession(fakeSessionStore, fakeUAAAPI, nil, nil)
 			req := httptest.NewRequest("GET", "http://example.com/oauth2", nil)
 			ctx := user.WithProfile(context.Background(), &user.Profile{
 				Email:       "test@pivotal.io",
@@ -89,7 +92,7 @@ func testIssueSession(t *testing.T, when spec.G, it spec.S) {
 
 		it("is an internal server error if the session cannot be created", func() {
 			fakeSessionStore.NewReturns(nil)
-			handler := session.IssueSession(fakeSessionStore, fakeUAAAPI)
+			handler := session.IssueSession(fakeSessionStore, fakeUAAAPI, nil, nil)
 			req := httptest.NewRequest("GET", "http://example.com/oauth2", nil).WithContext(ctx)
 			w := httptest.NewRecorder()
 			handler.ServeHTTP(w, req)
@@ -97,20 +100,38 @@ func testIssueSession(t *testing.T, when spec.G, it spec.S) {
 		})
 
 		it("issues a session", func() {
-			handler := session.IssueSession(fakeSessionStore, fakeUAAAPI)
+			handler := session.IssueSession(fakeSessionStore, fakeUAAAPI, nil, nil)
 			req := httptest.NewRequest("GET", "http://example.com/oauth2", nil).WithContext(ctx)
 			w := httptest.NewRecorder()
 			handler.ServeHTTP(w, req)
 			Expect(w.Code).Should(Equal(http.StatusFound))
 		})
 
+		it("records the session in the registry", func() {
+			dir, err := ioutil.TempDir("", "sessions")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+			registry, err := session.NewRegistry(filepath.Join(dir, "sessions.json"))
+			Expect(err).NotTo(HaveOccurred())
+			handler := session.IssueSession(fakeSessionStore, fakeUAAAPI, registry, nil)
+			req := httptest.NewRequest("GET", "http://example.com/oauth2", nil).WithContext(ctx)
+			req.Header.Set("User-Agent", "test-browser")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			Expect(w.Code).Should(Equal(http.StatusFound))
+			records := registry.List("test@pivotal.io")
+			Expect(records).To(HaveLen(1))
+			Expect(records[0].UserAgent).To(Equal("test-browser"))
+			Expect(s.Values).To(HaveKeyWithValue("sid", records[0].ID))
+		})
+
 		when("there is no user ID for the account name", func() {
 			it.Before(func() {
 				fakeUAAAPI.UserIDForAccountNameReturns("", errors.New("test error"))
 			})
 
 			it("does not store the user ID in the session", func() {
-				handler := session.IssueSession(fakeSessionStore, fakeUAAAPI)
+				handler := session.IssueSession(fakeSessionStore, fakeUAAAPI, nil, nil)
 				req := httptest.NewRequest("GET", "http://example.com/oauth2", nil).WithContext(ctx)
 				w := httptest.NewRecorder()
 				handler.ServeHTTP(w, req)
@@ -125,7 +146,7 @@ func testIssueSession(t *testing.T, when spec.G, it spec.S) {
 			})
 
 			it("stores the user ID in the session", func() {
-				handler := session.IssueSession(fakeSessionStore, fakeUAAAPI)
+				handler := session.IssueSession(fakeSessionStore, fakeUAAAPI, nil, nil)
 				req := httptest.NewRequest("GET", "http://example.com/oauth2", nil).WithContext(ctx)
 				w := httptest.NewRecorder()
 				handler.ServeHTTP(w, req)
@@ -159,7 +180,7 @@ func testPopulateContext(t *testing.T, when spec.G, it spec.S) {
 	it("invokes the next handler", func() {
 		called := false
 		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
-		handler := session.PopulateContext(next, fakeSessionStore)
+		handler := session.PopulateContext(next, fakeSessionStore, nil)
 
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -181,7 +202,7 @@ func testPopulateContext(t *testing.T, when spec.G, it spec.S) {
 			s.Values["uaaid"] = "testuser"
 
 			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { nextContext = r.Context() })
-			handler = session.PopulateContext(next, fakeSessionStore)
+			handler = session.PopulateContext(next, fakeSessionStore, nil)
 		})
 
 		when("the session cannot be retrieved", func() {
@@ -242,7 +263,7 @@ func testLogoutHandler(t *testing.T, when spec.G, it spec.S) {
 	it("destroys the session", func() {
 		w := httptest.NewRecorder()
 		s := &sessionfakes.FakeStore{}
-		handler := session.LogoutHandler(s)
+		handler := session.LogoutHandler(s, nil, nil)
 		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
 		Expect(s.DestroyCallCount()).To(Equal(1))
 		Expect(w.Code).To(Equal(http.StatusFound))
@@ -0,0 +1,178 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pivotalservices/ignition/web"
+)
+
+// The Cache-Control values for the web bundle: index.html is revalidated on
+// every request, and assets have a content hash in their name so they never
+// change
+const (
+	noCache   = "no-cache"
+	immutable = "public, max-age=31536000, immutable"
+)
+
+// encodings are the precompressed variants of the web bundle, in order of
+// preference
+var encodings = []struct {
+	name      string
+	extension string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// webFileSystem returns the WebRoot directory when it is set, and otherwise
+// the web bundle that is compiled into the binary
+func (a *API) webFileSystem() http.FileSystem {
+	if strings.TrimSpace(a.WebRoot) == "" {
+		if fs := web.FileSystem(); fs != nil {
+			return fs
+		}
+	}
+	return http.Dir(a.WebRoot)
+}
+
+// staticFiles serves files from the web bundle with cache headers, an ETag,
+// and a precompressed variant when the client accepts one
+type staticFiles struct {
+	fs    http.FileSystem
+	mu    sync.Mutex
+	etags map[string]string
+}
+
+func newStaticFiles(fs http.FileSystem) *staticFiles {
+	return &staticFiles{fs: fs, etags: map[string]string{}}
+}
+
+// file serves the named file
+func (s *staticFiles) file(name string, cacheControl string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		s.serve(w, req, name, cacheControl)
+	})
+}
+
+// dir serves the files below prefix from the dir
+func (s *staticFiles) dir(prefix string, dir string, cacheControl string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		name := strings.TrimPrefix(req.URL.Path, prefix)
+		s.serve(w, req, path.Join(dir, path.Clean("/"+name)), cacheControl)
+	})
+}
+
+func (s *staticFiles) serve(w http.ResponseWriter, req *http.Request, name string, cacheControl string) {
+	name = path.Clean("/" + name)
+	w.Header().Add("Vary", "Accept-Encoding")
+	for _, e := range encodings {
+		if !acceptsEncoding(req, e.name) {
+			continue
+		}
+		f, info, err := s.open(name + e.extension)
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+		w.Header().Set("Content-Encoding", e.name)
+		// the content type is that of the uncompressed file
+		ct := mime.TypeByExtension(path.Ext(name))
+		if ct == "" {
+			ct = "application/octet-stream"
+		}
+		w.Header().Set("Content-Type", ct)
+		s.serveContent(w, req, name+e.extension, f, info, cacheControl)
+		return
+	}
+
+	f, info, err := s.open(name)
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+	defer f.Close()
+	s.serveContent(w, req, name, f, info, cacheControl)
+}
+
+func (s *staticFiles) open(name string) (http.File, os.FileInfo, error) {
+	f, err := s.fs.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		f.Close()
+		return nil, nil, os.ErrNotExist
+	}
+	return f, info, nil
+}
+
+func (s *staticFiles) serveContent(w http.ResponseWriter, req *http.Request, name string, f http.File, info os.FileInfo, cacheControl string) {
+	etag, err := s.etag(name, f, info)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControl)
+	// ServeContent answers conditional and range requests
+	http.ServeContent(w, req, name, info.ModTime(), f)
+}
+
+// etag returns a strong ETag for the content of the file; ETags are cached by
+// name, size and modification time, so that a file that changes on disk
+// during development gets a new ETag
+func (s *staticFiles) etag(name string, f http.File, info os.FileInfo) (string, error) {
+	key := fmt.Sprintf("%s:%d:%d", name, info.Size(), info.ModTime().UnixNano())
+	s.mu.Lock()
+	etag, ok := s.etags[key]
+	s.mu.Unlock()
+	if ok {
+		return etag, nil
+	}
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Seek(0, os.SEEK_SET); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	etag = fmt.Sprintf(`"%s"`, hex.EncodeToString(sum[:16]))
+	s.mu.Lock()
+	s.etags[key] = etag
+	s.mu.Unlock()
+	return etag, nil
+}
+
+// acceptsEncoding is true when the request's Accept-Encoding header includes
+// the encoding and does not give it a q value of zero
+func acceptsEncoding(req *http.Request, encoding string) bool {
+	for _, v := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		parts := strings.Split(v, ";")
+		if !strings.EqualFold(strings.TrimSpace(parts[0]), encoding) {
+			continue
+		}
+		for _, p := range parts[1:] {
+			p = strings.TrimSpace(p)
+			if !strings.HasPrefix(p, "q=") {
+				continue
+			}
+			if q, err := strconv.ParseFloat(p[2:], 64); err == nil && q == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestStaticFiles(t *testing.T) {
+	spec.Run(t, "staticFiles", testStaticFiles, spec.Report(report.Terminal{}))
+}
+
+func testStaticFiles(t *testing.T, when spec.G, it spec.S) {
+	var (
+		dir string
+		r   http.Handler
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		var err error
+		dir, err = ioutil.TempDir("", "ignition-web")
+		Expect(err).NotTo(HaveOccurred())
+		files := map[string]string{
+			"index.html":                 "<html></html>",
+			"assets/bundle.abc123.js":    "console.log('plain')",
+			"assets/bundle.abc123.js.gz": "gzipped",
+			"assets/bundle.abc123.js.br": "brotli",
+			"assets/logo.def456.svg":     "<svg></svg>",
+			"assets/logo.def456.svg.gz":  "gzipped svg",
+			"assets/nested/image.png":    "png",
+			"../ignition-web-secret.txt": "secret",
+		}
+		for name, content := range files {
+			p := filepath.Join(dir, name)
+			Expect(os.MkdirAll(filepath.Dir(p), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(p, []byte(content), 0644)).To(Succeed())
+		}
+		api := &API{WebRoot: dir}
+		r = api.createRouter()
+	})
+
+	it.After(func() {
+		os.RemoveAll(dir)
+		os.Remove(filepath.Join(filepath.Dir(dir), "ignition-web-secret.txt"))
+	})
+
+	get := func(path string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Host = "localhost:3000"
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	it("serves index.html so that it is always revalidated", func() {
+		w := get("/", nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(Equal("<html></html>"))
+		Expect(w.Header().Get("Cache-Control")).To(Equal("no-cache"))
+		Expect(w.Header().Get("Content-Type")).To(HavePrefix("text/html"))
+		Expect(w.Header().Get("ETag")).To(MatchRegexp(`^"[0-9a-f]{32}"$`))
+	})
+
+	it("answers not modified when the ETag matches", func() {
+		etag := get("/", nil).Header().Get("ETag")
+		w := get("/", map[string]string{"If-None-Match": etag})
+		Expect(w.Code).To(Equal(http.StatusNotModified))
+		Expect(w.Body.Len()).To(BeZero())
+	})
+
+	it("serves assets as immutable", func() {
+		w := get("/assets/nested/image.png", nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(Equal("png"))
+		Expect(w.Header().Get("Cache-Control")).To(Equal("public, max-age=31536000, immutable"))
+		Expect(w.Header().Get("Content-Encoding")).To(BeEmpty())
+	})
+
+	it("prefers the brotli variant", func() {
+		w := get("/assets/bundle.abc123.js", map[string]string{"Accept-Encoding": "gzip, deflate, br"})
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(Equal("brotli"))
+		Expect(w.Header().Get("Content-Encoding")).To(Equal("br"))
+		Expect(w.Header().Get("Content-Type")).To(ContainSubstring("javascript"))
+		Expect(w.Header().Get("Vary")).To(Equal("Accept-Encoding"))
+	})
+
+	it("serves the gzip variant when brotli is not accepted", func() {
+		w := get("/assets/bundle.abc123.js", map[string]string{"Accept-Encoding": "gzip, br;q=0"})
+		Expect(w.Body.String()).To(Equal("gzipped"))
+		Expect(w.Header().Get("Content-Encoding")).To(Equal("gzip"))
+	})
+
+	it("serves the uncompressed file when there is no accepted variant", func() {
+		w := get("/assets/logo.def456.svg", map[string]string{"Accept-Encoding": "br"})
+		Expect(w.Body.String()).To(Equal("<svg></svg>"))
+		Expect(w.Header().Get("Content-Encoding")).To(BeEmpty())
+		Expect(w.Header().Get("Content-Type")).To(Equal("image/svg+xml"))
+	})
+
+	it("gives each variant its own ETag", func() {
+		plain := get("/assets/bundle.abc123.js", nil).Header().Get("ETag")
+		br := get("/assets/bundle.abc123.js", map[string]string{"Accept-Encoding": "br"}).Header().Get("ETag")
+		Expect(plain).NotTo(BeEmpty())
+		Expect(plain).NotTo(Equal(br))
+	})
+
+	it("does not serve files outside of the assets", func() {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.URL.Path = "/assets/../../ignition-web-secret.txt"
+		w := httptest.NewRecorder()
+		newStaticFiles(http.Dir(dir)).dir("/assets/", "assets", immutable).ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+		Expect(get("/assets/nested", nil).Code).To(Equal(http.StatusNotFound))
+		Expect(get("/assets/missing.js", nil).Code).To(Equal(http.StatusNotFound))
+	})
+}
+
+func TestAcceptsEncoding(t *testing.T) {
+	spec.Run(t, "acceptsEncoding", func(t *testing.T, when spec.G, it spec.S) {
+		it.Before(func() {
+			RegisterTestingT(t)
+		})
+
+		accepts := func(header string, encoding string) bool {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Encoding", header)
+			return acceptsEncoding(req, encoding)
+		}
+
+		it("matches listed encodings", func() {
+			Expect(accepts("gzip, deflate, br", "br")).To(BeTrue())
+			Expect(accepts("GZIP", "gzip")).To(BeTrue())
+			Expect(accepts("gzip;q=0.5", "gzip")).To(BeTrue())
+			Expect(accepts("deflate", "gzip")).To(BeFalse())
+			Expect(accepts("", "gzip")).To(BeFalse())
+		})
+
+		it("does not match encodings with a zero q value", func() {
+			Expect(accepts("br;q=0, gzip", "br")).To(BeFalse())
+			Expect(accepts("br; q=0.000", "br")).To(BeFalse())
+		})
+	}, spec.Report(report.Terminal{}))
+}
@@ -0,0 +1,93 @@
+package terms
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotalservices/ignition/internal/jsonfile"
+)
+
+// Acceptance records that a user accepted a version of the terms. It is kept
+// by the user's account name, because the terms are accepted before ignition
+// creates a UAA user for them; UserID is set when they already had one
+type Acceptance struct {
+	UserID      string    `json:"user_id,omitempty"`
+	AccountName string    `json:"account_name"`
+	Version     string    `json:"version"`
+	AcceptedAt  time.Time `json:"accepted_at"`
+}
+
+// Store keeps the acceptances of each version of the terms, and persists them
+// to a file so that users do not have to accept the terms again after a
+// restart
+type Store struct {
+	path        string
+	mu          sync.Mutex
+	acceptances map[string]Acceptance
+}
+
+// NewStore returns a Store that persists to the file at path, loading any
+// acceptances that are already in it. Acceptances are only kept in memory
+// when path is empty
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path:        path,
+		acceptances: map[string]Acceptance{},
+	}
+	if strings.TrimSpace(path) == "" {
+		return s, nil
+	}
+	var acceptances []Acceptance
+	if err := jsonfile.Read(path, "terms store", &acceptances); err != nil {
+		return nil, err
+	}
+	for _, a := range acceptances {
+		s.acceptances[key(a.AccountName, a.Version)] = a
+	}
+	return s, nil
+}
+
+// Accept stores the acceptance; a version that the user has already accepted
+// keeps the time it was first accepted
+func (s *Store) Accept(a Acceptance) (Acceptance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.acceptances[key(a.AccountName, a.Version)]; ok {
+		return existing, nil
+	}
+	s.acceptances[key(a.AccountName, a.Version)] = a
+	if err := s.persist(); err != nil {
+		delete(s.acceptances, key(a.AccountName, a.Version))
+		return Acceptance{}, err
+	}
+	return a, nil
+}
+
+// Acceptance returns the user's acceptance of the version
+func (s *Store) Acceptance(accountName string, version string) (Acceptance, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.acceptances[key(accountName, version)]
+	return a, ok
+}
+
+func key(accountName string, version string) string {
+	return accountName + "\x00" + version
+}
+
+// persist writes every acceptance to the file, oldest first
+func (s *Store) persist() error {
+	if strings.TrimSpace(s.path) == "" {
+		return nil
+	}
+	acceptances := make([]Acceptance, 0, len(s.acceptances))
+	for _, a := range s.acceptances {
+		acceptances = append(acceptances, a)
+	}
+	sort.Slice(acceptances, func(i, j int) bool {
+		return acceptances[i].AcceptedAt.Before(acceptances[j].AcceptedAt)
+	})
+	return jsonfile.Write(s.path, "terms store", acceptances)
+}
@@ -0,0 +1,161 @@
+// Package terms serves the terms of use that users must accept before an org
+// is created for them, and records who accepted which version
+package terms
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pivotalservices/ignition/audit"
+	"github.com/pivotalservices/ignition/http/apierror"
+	"github.com/pivotalservices/ignition/http/session"
+	"github.com/pivotalservices/ignition/user"
+)
+
+// Terms is a version of the terms of use; users must accept it when it has a
+// document
+type Terms struct {
+	Version     string
+	Document    []byte
+	ContentType string
+	Store       *Store
+	// Audit records each acceptance
+	Audit audit.Recorder
+
+	now func() time.Time
+}
+
+// New returns the terms with the document, of the content type of its file
+// name; the version is a digest of the document when it is not given
+func New(name string, document []byte, version string, store *Store) *Terms {
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".md", ".markdown":
+		contentType = "text/markdown; charset=utf-8"
+	}
+	if contentType == "" {
+		contentType = "text/plain; charset=utf-8"
+	}
+	if strings.TrimSpace(version) == "" {
+		sum := sha256.Sum256(document)
+		version = hex.EncodeToString(sum[:])[:12]
+	}
+	return &Terms{Version: version, Document: document, ContentType: contentType, Store: store}
+}
+
+// Required is true when users must accept the terms
+func (t *Terms) Required() bool {
+	return t != nil && len(t.Document) > 0
+}
+
+// Accepted is true when the user with the account name has accepted the
+// current version, or when there are no terms to accept
+func (t *Terms) Accepted(accountName string) bool {
+	if !t.Required() {
+		return true
+	}
+	_, ok := t.Store.Acceptance(accountName, t.Version)
+	return ok
+}
+
+// CurrentVersion is the version that users must accept
+func (t *Terms) CurrentVersion() string {
+	if t == nil {
+		return ""
+	}
+	return t.Version
+}
+
+// document is the body of a response with the terms
+type document struct {
+	Version     string     `json:"version"`
+	ContentType string     `json:"content_type"`
+	Document    string     `json:"document"`
+	Required    bool       `json:"required"`
+	Accepted    bool       `json:"accepted"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
+}
+
+// Handler returns the current terms, and whether the user has accepted them
+func (t *Terms) Handler() http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		d := document{Accepted: true}
+		if t.Required() {
+			d = document{
+				Version:     t.Version,
+				ContentType: t.ContentType,
+				Document:    string(t.Document),
+				Required:    true,
+			}
+			if profile, err := user.ProfileFromContext(req.Context()); err == nil && profile != nil {
+				if a, ok := t.Store.Acceptance(profile.AccountName, t.Version); ok {
+					d.Accepted = true
+					d.AcceptedAt = &a.AcceptedAt
+				}
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(d)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// acceptance is the body of a request to accept the terms
+type acceptance struct {
+	Version string `json:"version"`
+}
+
+// AcceptHandler records that the user accepted the version of the terms in
+// the request, which must be the current version
+func (t *Terms) AcceptHandler() http.Handler {
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		profile, err := user.ProfileFromContext(req.Context())
+		if err != nil || profile == nil || strings.TrimSpace(profile.AccountName) == "" {
+			apierror.Write(w, req, http.StatusUnauthorized, "not_authenticated", "You are not logged in.", false)
+			return
+		}
+		if !t.Required() {
+			apierror.Write(w, req, http.StatusNotFound, "no_terms", "There are no terms of use to accept.", false)
+			return
+		}
+		var body acceptance
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil || strings.TrimSpace(body.Version) == "" {
+			apierror.Write(w, req, http.StatusBadRequest, "invalid_acceptance", "The version of the terms that was accepted is required.", false)
+			return
+		}
+		if body.Version != t.Version {
+			apierror.Write(w, req, http.StatusConflict, "terms_changed", "The terms of use have changed. Please review and accept the current version.", false)
+			return
+		}
+
+		// users without a UAA user yet accept the terms before it is created
+		userID, _ := session.UserIDFromContext(req.Context())
+		a := Acceptance{UserID: userID, AccountName: profile.AccountName, Version: t.Version, AcceptedAt: t.time()}
+		accepted, err := t.Store.Accept(a)
+		audit.Record(t.Audit, audit.Entry{Actor: a.AccountName, ActorID: userID, Action: audit.ActionTermsAccept, Target: t.Version, Outcome: audit.Outcome(err)})
+		if err != nil {
+			log.Printf("request [%s]: %v\n", apierror.RequestID(req), err)
+			apierror.Write(w, req, http.StatusInternalServerError, "terms_unavailable", "Your acceptance could not be recorded. Please try again.", true)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(accepted)
+	}
+	return http.HandlerFunc(fn)
+}
+
+func (t *Terms) time() time.Time {
+	if t.now != nil {
+		return t.now().UTC()
+	}
+	return time.Now().UTC()
+}
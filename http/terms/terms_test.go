@@ -0,0 +1,152 @@
+package terms_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/audit"
+	"github.com/pivotalservices/ignition/http/session"
+	"github.com/pivotalservices/ignition/http/terms"
+	"github.com/pivotalservices/ignition/user"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestTerms(t *testing.T) {
+	spec.Run(t, "Terms", testTerms, spec.Report(report.Terminal{}))
+}
+
+func testTerms(t *testing.T, when spec.G, it spec.S) {
+	var (
+		dir   string
+		store *terms.Store
+		tm    *terms.Terms
+		w     *httptest.ResponseRecorder
+	)
+
+	request := func(method string, body string) *http.Request {
+		r := httptest.NewRequest(method, "/terms", strings.NewReader(body))
+		ctx := session.ContextWithUserID(r.Context(), "test-user-id")
+		return r.WithContext(user.WithProfile(ctx, &user.Profile{AccountName: "testuser"}))
+	}
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		var err error
+		dir, err = ioutil.TempDir("", "terms")
+		Expect(err).NotTo(HaveOccurred())
+		store, err = terms.NewStore(filepath.Join(dir, "terms.json"))
+		Expect(err).NotTo(HaveOccurred())
+		tm = terms.New("terms.md", []byte("# Sandbox Terms\n\nBe nice."), "2026-10", store)
+		w = httptest.NewRecorder()
+	})
+
+	it.After(func() {
+		os.RemoveAll(dir)
+	})
+
+	it("serves the current terms", func() {
+		tm.Handler().ServeHTTP(w, request(http.MethodGet, ""))
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var body map[string]interface{}
+		Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+		Expect(body).To(HaveKeyWithValue("version", "2026-10"))
+		Expect(body).To(HaveKeyWithValue("content_type", "text/markdown; charset=utf-8"))
+		Expect(body).To(HaveKeyWithValue("document", "# Sandbox Terms\n\nBe nice."))
+		Expect(body).To(HaveKeyWithValue("required", true))
+		Expect(body).To(HaveKeyWithValue("accepted", false))
+	})
+
+	it("records the acceptance of the current version", func() {
+		l := audit.NewLog()
+		tm.Audit = l
+		tm.AcceptHandler().ServeHTTP(w, request(http.MethodPost, `{"version": "2026-10"}`))
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var a terms.Acceptance
+		Expect(json.Unmarshal(w.Body.Bytes(), &a)).To(Succeed())
+		Expect(a.UserID).To(Equal("test-user-id"))
+		Expect(a.AccountName).To(Equal("testuser"))
+		Expect(a.Version).To(Equal("2026-10"))
+		Expect(a.AcceptedAt).NotTo(BeZero())
+		Expect(tm.Accepted("testuser")).To(BeTrue())
+		Expect(tm.Accepted("otheruser")).To(BeFalse())
+
+		entries, _ := l.Query(audit.Filter{Action: audit.ActionTermsAccept})
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].ActorID).To(Equal("test-user-id"))
+		Expect(entries[0].Target).To(Equal("2026-10"))
+
+		w = httptest.NewRecorder()
+		tm.Handler().ServeHTTP(w, request(http.MethodGet, ""))
+		Expect(w.Body.String()).To(ContainSubstring(`"accepted":true`))
+		Expect(w.Body.String()).To(ContainSubstring(`"accepted_at"`))
+	})
+
+	it("keeps acceptances across restarts", func() {
+		tm.AcceptHandler().ServeHTTP(w, request(http.MethodPost, `{"version": "2026-10"}`))
+		Expect(w.Code).To(Equal(http.StatusOK))
+		reloaded, err := terms.NewStore(filepath.Join(dir, "terms.json"))
+		Expect(err).NotTo(HaveOccurred())
+		a, ok := reloaded.Acceptance("testuser", "2026-10")
+		Expect(ok).To(BeTrue())
+		Expect(a.AccountName).To(Equal("testuser"))
+	})
+
+	it("requires the new version to be accepted when the terms change", func() {
+		tm.AcceptHandler().ServeHTTP(w, request(http.MethodPost, `{"version": "2026-10"}`))
+		changed := terms.New("terms.md", []byte("# Sandbox Terms\n\nBe very nice."), "2026-11", store)
+		Expect(changed.Accepted("testuser")).To(BeFalse())
+
+		w = httptest.NewRecorder()
+		changed.AcceptHandler().ServeHTTP(w, request(http.MethodPost, `{"version": "2026-10"}`))
+		Expect(w.Code).To(Equal(http.StatusConflict))
+		Expect(w.Body.String()).To(ContainSubstring("terms_changed"))
+	})
+
+	it("rejects an acceptance without a version", func() {
+		tm.AcceptHandler().ServeHTTP(w, request(http.MethodPost, `{}`))
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(tm.Accepted("testuser")).To(BeFalse())
+	})
+
+	it("records the acceptance of a user who does not have a UAA user yet", func() {
+		r := httptest.NewRequest(http.MethodPost, "/terms/accept", strings.NewReader(`{"version": "2026-10"}`))
+		r = r.WithContext(user.WithProfile(r.Context(), &user.Profile{AccountName: "newuser"}))
+		tm.AcceptHandler().ServeHTTP(w, r)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).NotTo(ContainSubstring("user_id"))
+		Expect(tm.Accepted("newuser")).To(BeTrue())
+	})
+
+	it("is unauthorized without a user", func() {
+		tm.AcceptHandler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/terms/accept", strings.NewReader(`{"version": "2026-10"}`)))
+		Expect(w.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	it("versions the terms by their content when no version is given", func() {
+		a := terms.New("terms.txt", []byte("Be nice."), "", store)
+		b := terms.New("terms.txt", []byte("Be very nice."), "", store)
+		Expect(a.Version).To(HaveLen(12))
+		Expect(a.Version).NotTo(Equal(b.Version))
+		Expect(a.ContentType).To(Equal("text/plain; charset=utf-8"))
+	})
+
+	when("there are no terms", func() {
+		it("treats every user as having accepted them", func() {
+			var none *terms.Terms
+			Expect(none.Accepted("testuser")).To(BeTrue())
+			none.Handler().ServeHTTP(w, request(http.MethodGet, ""))
+			Expect(w.Body.String()).To(ContainSubstring(`"required":false`))
+			w = httptest.NewRecorder()
+			none.AcceptHandler().ServeHTTP(w, request(http.MethodPost, `{"version": "2026-10"}`))
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+}
@@ -0,0 +1,47 @@
+// Package jsonfile reads and writes the JSON files that the stores persist to
+package jsonfile
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// Read decodes the JSON file at path into v. A file that does not exist yet
+// leaves v unchanged. The name describes the file in errors
+func Read(path string, name string, v interface{}) error {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "could not read %s [%s]", name, path)
+	}
+	return errors.Wrapf(json.Unmarshal(b, v), "could not parse %s [%s]", name, path)
+}
+
+// Write encodes v as JSON and replaces the file at path with it. The JSON is
+// written to a temporary file first so that a crash cannot leave a partial
+// file. The name describes the file in errors
+func Write(path string, name string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrapf(err, "could not encode %s", name)
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return errors.Wrapf(err, "could not write %s [%s]", name, path)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "could not write %s [%s]", name, path)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "could not write %s [%s]", name, path)
+	}
+	return errors.Wrapf(os.Rename(tmp.Name(), path), "could not write %s [%s]", name, path)
+}
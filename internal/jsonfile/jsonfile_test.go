@@ -0,0 +1,60 @@
+package jsonfile_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/internal/jsonfile"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestJSONFile(t *testing.T) {
+	spec.Run(t, "JSONFile", testJSONFile, spec.Report(report.Terminal{}))
+}
+
+func testJSONFile(t *testing.T, when spec.G, it spec.S) {
+	var path string
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		dir, err := ioutil.TempDir("", "jsonfile")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "store.json")
+	})
+
+	it.After(func() {
+		os.RemoveAll(filepath.Dir(path))
+	})
+
+	it("reads back what was written", func() {
+		Expect(jsonfile.Write(path, "test store", []string{"a", "b"})).To(Succeed())
+		var values []string
+		Expect(jsonfile.Read(path, "test store", &values)).To(Succeed())
+		Expect(values).To(Equal([]string{"a", "b"}))
+	})
+
+	it("leaves the value unchanged when there is no file yet", func() {
+		values := []string{"a"}
+		Expect(jsonfile.Read(path, "test store", &values)).To(Succeed())
+		Expect(values).To(Equal([]string{"a"}))
+	})
+
+	it("does not leave temporary files behind", func() {
+		Expect(jsonfile.Write(path, "test store", []string{"a"})).To(Succeed())
+		Expect(jsonfile.Write(path, "test store", []string{"b"})).To(Succeed())
+		files, err := ioutil.ReadDir(filepath.Dir(path))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+	})
+
+	it("names the file when it cannot be parsed", func() {
+		Expect(ioutil.WriteFile(path, []byte("{"), 0600)).To(Succeed())
+		var values []string
+		err := jsonfile.Read(path, "test store", &values)
+		Expect(err).To(MatchError(ContainSubstring("could not parse test store [" + path + "]")))
+	})
+}
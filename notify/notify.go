@@ -0,0 +1,143 @@
+// Package notify emails users about their development orgs, from templates
+// that operators can replace
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The kinds of email that are sent; each has a template with the same name
+const (
+	KindWelcome       = "welcome"
+	KindExpiryWarning = "expiry_warning"
+	KindQuotaDecision = "quota_decision"
+)
+
+// Kinds are all of the kinds of email that are sent
+var Kinds = []string{KindWelcome, KindExpiryWarning, KindQuotaDecision}
+
+// Welcome is the data of the welcome email, which is sent when the user's org
+// has been created
+type Welcome struct {
+	Name          string
+	Email         string
+	Org           string
+	Space         string
+	OrgURL        string
+	APIURL        string
+	PasscodeURL   string
+	LoginCommand  string
+	TargetCommand string
+}
+
+// ExpiryWarning is the data of the email that warns the user that their org
+// will be reclaimed. Ignition does not reclaim orgs yet, so it is not sent
+// until it does
+type ExpiryWarning struct {
+	Name      string
+	Email     string
+	Org       string
+	OrgURL    string
+	ExpiresAt time.Time
+}
+
+// QuotaDecision is the data of the email that tells the user whether their
+// request for a different quota was approved. Ignition does not take quota
+// requests yet, so it is not sent until it does
+type QuotaDecision struct {
+	Name     string
+	Email    string
+	Org      string
+	Quota    string
+	Approved bool
+	Reason   string
+}
+
+// Message is an email to one recipient
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+	Date    time.Time
+}
+
+// Bytes formats the message as a plain text email
+func (m Message) Bytes() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", header(m.From))
+	fmt.Fprintf(&b, "To: %s\r\n", header(m.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", header(m.Subject)))
+	fmt.Fprintf(&b, "Date: %s\r\n", m.Date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.Replace(strings.Replace(m.Body, "\r\n", "\n", -1), "\n", "\r\n", -1))
+	return b.Bytes()
+}
+
+// header removes line breaks, so that a value cannot add headers
+func header(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// Sender delivers email
+type Sender interface {
+	Send(m Message) error
+}
+
+// Notifier renders emails from its templates, and sends them from the From
+// address
+type Notifier struct {
+	From      string
+	Sender    Sender
+	Templates *Templates
+
+	now func() time.Time
+}
+
+// Notify renders the email of the kind with the data, and sends it to the
+// address; nothing is sent by a nil Notifier, or when there is no address
+func (n *Notifier) Notify(kind string, to string, data interface{}) error {
+	if n == nil || strings.TrimSpace(to) == "" {
+		return nil
+	}
+	templates := n.Templates
+	if templates == nil {
+		var err error
+		if templates, err = NewTemplates(""); err != nil {
+			return err
+		}
+	}
+	subject, body, err := templates.Render(kind, data)
+	if err != nil {
+		return err
+	}
+	now := time.Now
+	if n.now != nil {
+		now = n.now
+	}
+	m := Message{From: n.From, To: to, Subject: subject, Body: body, Date: now()}
+	return errors.Wrapf(n.Sender.Send(m), "could not send the %s email to [%s]", kind, to)
+}
+
+// Welcome sends the welcome email
+func (n *Notifier) Welcome(w Welcome) error {
+	return n.Notify(KindWelcome, w.Email, w)
+}
+
+// ExpiryWarning sends the expiry warning email
+func (n *Notifier) ExpiryWarning(e ExpiryWarning) error {
+	return n.Notify(KindExpiryWarning, e.Email, e)
+}
+
+// QuotaDecision sends the quota decision email
+func (n *Notifier) QuotaDecision(q QuotaDecision) error {
+	return n.Notify(KindQuotaDecision, q.Email, q)
+}
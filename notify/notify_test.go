@@ -0,0 +1,268 @@
+package notify_test
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/notify"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+// smtpServer is a local stand-in for an SMTP server, which accepts every
+// message and records it
+type smtpServer struct {
+	l        net.Listener
+	mu       sync.Mutex
+	from     []string
+	to       []string
+	messages []string
+}
+
+func newSMTPServer() (*smtpServer, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &smtpServer{l: l}
+	go s.serve()
+	return s, nil
+}
+
+func (s *smtpServer) port() int {
+	return s.l.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpServer) serve() {
+	for {
+		conn, err := s.l.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost ESMTP test")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch command {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			s.mu.Lock()
+			s.from = append(s.from, line)
+			s.mu.Unlock()
+			reply("250 OK")
+		case "RCPT":
+			s.mu.Lock()
+			s.to = append(s.to, line)
+			s.mu.Unlock()
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data []string
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data = append(data, l)
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, strings.Join(data, ""))
+			s.mu.Unlock()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func (s *smtpServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.messages...)
+}
+
+func TestNotifier(t *testing.T) {
+	spec.Run(t, "Notifier", testNotifier, spec.Report(report.Terminal{}))
+}
+
+func testNotifier(t *testing.T, when spec.G, it spec.S) {
+	var (
+		server *smtpServer
+		n      *notify.Notifier
+		dir    string
+	)
+
+	welcome := notify.Welcome{
+		Name:          "Test User",
+		Email:         "testuser@example.com",
+		Org:           "ignition-testuser",
+		Space:         "playground",
+		OrgURL:        "https://apps.example.com/organizations/test-org-guid",
+		APIURL:        "https://api.example.com",
+		PasscodeURL:   "https://login.example.com/passcode",
+		LoginCommand:  "cf login -a https://api.example.com --sso",
+		TargetCommand: "cf target -o ignition-testuser -s playground",
+	}
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		var err error
+		server, err = newSMTPServer()
+		Expect(err).NotTo(HaveOccurred())
+		dir, err = ioutil.TempDir("", "notify")
+		Expect(err).NotTo(HaveOccurred())
+		n = &notify.Notifier{
+			From:   "Ignition <ignition@example.com>",
+			Sender: &notify.SMTPSender{Host: "127.0.0.1", Port: server.port()},
+		}
+	})
+
+	it.After(func() {
+		server.l.Close()
+		os.RemoveAll(dir)
+	})
+
+	it("sends the welcome email through the SMTP server", func() {
+		Expect(n.Welcome(welcome)).To(Succeed())
+		Expect(server.from).To(ConsistOf("MAIL FROM:<ignition@example.com>"))
+		Expect(server.to).To(ConsistOf("RCPT TO:<testuser@example.com>"))
+		messages := server.received()
+		Expect(messages).To(HaveLen(1))
+		Expect(messages[0]).To(ContainSubstring("From: Ignition <ignition@example.com>\r\n"))
+		Expect(messages[0]).To(ContainSubstring("To: testuser@example.com\r\n"))
+		Expect(messages[0]).To(ContainSubstring("Subject: Your development org ignition-testuser is ready\r\n"))
+		Expect(messages[0]).To(ContainSubstring("Hi Test User,"))
+		Expect(messages[0]).To(ContainSubstring("https://apps.example.com/organizations/test-org-guid"))
+		Expect(messages[0]).To(ContainSubstring("    cf login -a https://api.example.com --sso\r\n"))
+		Expect(messages[0]).To(ContainSubstring("    cf target -o ignition-testuser -s playground\r\n"))
+	})
+
+	it("sends the expiry warning and quota decision emails", func() {
+		expires := time.Date(2026, time.November, 2, 0, 0, 0, 0, time.UTC)
+		Expect(n.ExpiryWarning(notify.ExpiryWarning{Email: "testuser@example.com", Org: "ignition-testuser", ExpiresAt: expires})).To(Succeed())
+		Expect(n.QuotaDecision(notify.QuotaDecision{Email: "testuser@example.com", Org: "ignition-testuser", Quota: "large", Reason: "Large quotas are for production teams."})).To(Succeed())
+		messages := server.received()
+		Expect(messages).To(HaveLen(2))
+		Expect(messages[0]).To(ContainSubstring("Subject: Your development org ignition-testuser will be reclaimed on November 2, 2026\r\n"))
+		Expect(messages[0]).To(ContainSubstring("Hi there,"))
+		Expect(messages[1]).To(ContainSubstring("Subject: Your quota request for ignition-testuser was declined\r\n"))
+		Expect(messages[1]).To(ContainSubstring("Large quotas are for production teams."))
+	})
+
+	it("renders each of the default templates", func() {
+		templates, err := notify.NewTemplates("")
+		Expect(err).NotTo(HaveOccurred())
+		expires := time.Date(2026, time.November, 2, 0, 0, 0, 0, time.UTC)
+		data := map[string]interface{}{
+			notify.KindWelcome:       notify.Welcome{Name: "Test User", Org: "ignition-testuser"},
+			notify.KindExpiryWarning: notify.ExpiryWarning{Name: "Test User", Org: "ignition-testuser", ExpiresAt: expires},
+			notify.KindQuotaDecision: notify.QuotaDecision{Name: "Test User", Org: "ignition-testuser", Quota: "large", Approved: true},
+		}
+		for _, kind := range notify.Kinds {
+			subject, body, err := templates.Render(kind, data[kind])
+			Expect(err).NotTo(HaveOccurred(), kind)
+			Expect(subject).To(ContainSubstring("ignition-testuser"), kind)
+			Expect(body).To(ContainSubstring("Hi Test User,"), kind)
+		}
+	})
+
+	it("does not send an email without a recipient", func() {
+		welcome.Email = ""
+		Expect(n.Welcome(welcome)).To(Succeed())
+		Expect(server.received()).To(BeEmpty())
+		var none *notify.Notifier
+		Expect(none.Welcome(welcome)).To(Succeed())
+	})
+
+	it("fails when the SMTP server cannot be reached", func() {
+		server.l.Close()
+		err := n.Welcome(welcome)
+		Expect(err).To(MatchError(ContainSubstring("could not send the welcome email to [testuser@example.com]")))
+		Expect(err).To(MatchError(ContainSubstring("could not connect to the SMTP server [127.0.0.1:" + strconv.Itoa(server.port()) + "]")))
+	})
+
+	it("keeps line breaks in the data out of the headers", func() {
+		n.Sender = &notify.DirSender{Dir: dir}
+		welcome.Org = "ignition-testuser\r\nBcc: someone@example.com"
+		Expect(n.Welcome(welcome)).To(HaveOccurred())
+		welcome.Org = "ignition-testuser"
+		welcome.Email = "testuser@example.com\r\nBcc: someone@example.com"
+		Expect(n.Welcome(welcome)).To(Succeed())
+		files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+		Expect(files).To(HaveLen(1))
+		b, _ := ioutil.ReadFile(files[0])
+		Expect(string(b)).NotTo(ContainSubstring("\r\nBcc:"))
+	})
+
+	when("in dry-run mode", func() {
+		it("writes the emails to files instead", func() {
+			n.Sender = &notify.DirSender{Dir: filepath.Join(dir, "outbox")}
+			Expect(n.Welcome(welcome)).To(Succeed())
+			Expect(server.received()).To(BeEmpty())
+			files, err := filepath.Glob(filepath.Join(dir, "outbox", "*-testuser@example.com.eml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveLen(1))
+			b, err := ioutil.ReadFile(files[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(ContainSubstring("Subject: Your development org ignition-testuser is ready\r\n"))
+		})
+	})
+
+	when("there are custom templates", func() {
+		it("uses them in place of the defaults", func() {
+			Expect(ioutil.WriteFile(filepath.Join(dir, "welcome.tmpl"), []byte("Subject: Welcome to {{.Org}}\n\nRun {{.LoginCommand}}\n"), 0600)).To(Succeed())
+			templates, err := notify.NewTemplates(dir)
+			Expect(err).NotTo(HaveOccurred())
+			subject, body, err := templates.Render(notify.KindWelcome, welcome)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(subject).To(Equal("Welcome to ignition-testuser"))
+			Expect(body).To(Equal("Run cf login -a https://api.example.com --sso\n"))
+
+			subject, _, err = templates.Render(notify.KindQuotaDecision, notify.QuotaDecision{Org: "ignition-testuser", Approved: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(subject).To(Equal("Your quota request for ignition-testuser was approved"))
+		})
+
+		it("rejects a template that cannot be parsed", func() {
+			Expect(ioutil.WriteFile(filepath.Join(dir, "welcome.tmpl"), []byte("Subject: {{.Org\n\n"), 0600)).To(Succeed())
+			_, err := notify.NewTemplates(dir)
+			Expect(err).To(MatchError(ContainSubstring("could not parse email template")))
+		})
+
+		it("fails to render a template without a subject", func() {
+			Expect(ioutil.WriteFile(filepath.Join(dir, "welcome.tmpl"), []byte("Welcome to {{.Org}}\n"), 0600)).To(Succeed())
+			templates, err := notify.NewTemplates(dir)
+			Expect(err).NotTo(HaveOccurred())
+			_, _, err = templates.Render(notify.KindWelcome, welcome)
+			Expect(err).To(MatchError(ContainSubstring("must start with a Subject: line")))
+		})
+	})
+}
@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// SMTPSender sends email through an SMTP server; the connection is upgraded
+// with STARTTLS when the server supports it, and the credentials are only
+// sent over TLS or to a server on localhost
+type SMTPSender struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// TLSConfig is used for STARTTLS (default: verify the Host's certificate)
+	TLSConfig *tls.Config
+}
+
+// Send sends the message
+func (s *SMTPSender) Send(m Message) error {
+	from, err := address(m.From)
+	if err != nil {
+		return err
+	}
+	to, err := address(m.To)
+	if err != nil {
+		return err
+	}
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	c, err := smtp.Dial(addr)
+	if err != nil {
+		return errors.Wrapf(err, "could not connect to the SMTP server [%s]", addr)
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		config := s.TLSConfig
+		if config == nil {
+			config = &tls.Config{ServerName: s.Host}
+		}
+		if err := c.StartTLS(config); err != nil {
+			return errors.Wrapf(err, "could not start TLS with the SMTP server [%s]", addr)
+		}
+	}
+	if s.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return errors.Wrapf(err, "could not authenticate with the SMTP server [%s]", addr)
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return errors.Wrapf(err, "the SMTP server [%s] rejected the sender [%s]", addr, from)
+	}
+	if err := c.Rcpt(to); err != nil {
+		return errors.Wrapf(err, "the SMTP server [%s] rejected the recipient [%s]", addr, to)
+	}
+	w, err := c.Data()
+	if err != nil {
+		return errors.Wrapf(err, "could not send the message to the SMTP server [%s]", addr)
+	}
+	if _, err := w.Write(m.Bytes()); err != nil {
+		w.Close()
+		return errors.Wrapf(err, "could not send the message to the SMTP server [%s]", addr)
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrapf(err, "the SMTP server [%s] did not accept the message", addr)
+	}
+	return c.Quit()
+}
+
+// address returns the email address of a "Name <address>" or bare address
+func address(s string) (string, error) {
+	a, err := mail.ParseAddress(s)
+	if err != nil {
+		return "", errors.Wrapf(err, "[%s] is not an email address", s)
+	}
+	return a.Address, nil
+}
+
+// unsafeFileChars are replaced in the names of the files that a DirSender
+// writes
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9@._-]+`)
+
+// DirSender writes each message to a file in Dir instead of sending it, so
+// that emails can be checked without an SMTP server
+type DirSender struct {
+	Dir string
+}
+
+// Send writes the message to a .eml file named after its date and recipient
+func (s *DirSender) Send(m Message) error {
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return errors.Wrapf(err, "could not create the email directory [%s]", s.Dir)
+	}
+	name := fmt.Sprintf("%s-%s.eml", m.Date.UTC().Format("20060102T150405.000000000Z"), unsafeFileChars.ReplaceAllString(m.To, "_"))
+	path := filepath.Join(s.Dir, name)
+	return errors.Wrapf(ioutil.WriteFile(path, m.Bytes(), 0600), "could not write email [%s]", path)
+}
@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// The default templates; each starts with a Subject line, which is followed
+// by a blank line and the body
+var defaultTemplates = map[string]string{
+	KindWelcome: `Subject: Your development org {{.Org}} is ready
+
+Hi {{if .Name}}{{.Name}}{{else}}there{{end}},
+
+Your development org {{.Org}} is ready, with the space {{.Space}} to push your apps to.
+
+View your org: {{.OrgURL}}
+
+To use it from the cf CLI, log in with a one-time passcode from {{.PasscodeURL}}:
+
+    {{.LoginCommand}}
+    {{.TargetCommand}}
+`,
+	KindExpiryWarning: `Subject: Your development org {{.Org}} will be reclaimed on {{.ExpiresAt.Format "January 2, 2006"}}
+
+Hi {{if .Name}}{{.Name}}{{else}}there{{end}},
+
+Your development org {{.Org}} will be reclaimed on {{.ExpiresAt.Format "January 2, 2006"}}, and its apps and services will be deleted. Save anything you want to keep before then.
+
+View your org: {{.OrgURL}}
+`,
+	KindQuotaDecision: `Subject: Your quota request for {{.Org}} was {{if .Approved}}approved{{else}}declined{{end}}
+
+Hi {{if .Name}}{{.Name}}{{else}}there{{end}},
+
+Your request for the {{.Quota}} quota for {{.Org}} was {{if .Approved}}approved{{else}}declined{{end}}.
+{{- if .Reason}}
+
+{{.Reason}}
+{{- end}}
+`,
+}
+
+// Templates render the subject and body of each kind of email
+type Templates struct {
+	templates map[string]*template.Template
+}
+
+// NewTemplates returns the default templates, replaced by the <kind>.tmpl
+// files in dir when it is set
+func NewTemplates(dir string) (*Templates, error) {
+	t := &Templates{templates: map[string]*template.Template{}}
+	for _, kind := range Kinds {
+		text := defaultTemplates[kind]
+		name := kind
+		if strings.TrimSpace(dir) != "" {
+			path := filepath.Join(dir, kind+".tmpl")
+			b, err := ioutil.ReadFile(path)
+			if err != nil && !os.IsNotExist(err) {
+				return nil, errors.Wrapf(err, "could not read email template [%s]", path)
+			}
+			if err == nil {
+				text, name = string(b), path
+			}
+		}
+		parsed, err := template.New(name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse email template [%s]", name)
+		}
+		t.templates[kind] = parsed
+	}
+	return t, nil
+}
+
+// Render returns the subject and body of the email of the kind
+func (t *Templates) Render(kind string, data interface{}) (subject string, body string, err error) {
+	tmpl, ok := t.templates[kind]
+	if !ok {
+		return "", "", errors.Errorf("there is no email template for [%s]", kind)
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", "", errors.Wrapf(err, "could not render the %s email", kind)
+	}
+	text := strings.Replace(b.String(), "\r\n", "\n", -1)
+	parts := strings.SplitN(text, "\n\n", 2)
+	if !strings.HasPrefix(parts[0], "Subject:") || strings.Contains(parts[0], "\n") {
+		return "", "", errors.Errorf("the %s email template must start with a Subject: line and a blank line", kind)
+	}
+	subject = strings.TrimSpace(strings.TrimPrefix(parts[0], "Subject:"))
+	if len(parts) == 2 {
+		body = parts[1]
+	}
+	return subject, body, nil
+}
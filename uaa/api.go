@@ -13,6 +13,7 @@ import (
 type API interface {
 	UserIDForAccountName(a string) (string, error)
 	CreateUser(username, origin, externalID, email string) (string, error)
+	Authenticate() error
 }
 
 // Authenticate will authenticate with a UAA server and set the Token and Client
@@ -47,3 +48,21 @@ func (a *Client) Authenticate() error {
 	}
 	return nil
 }
+
+// Check requests a new token from the UAA server, so that the server is
+// contacted however long the client's own token is valid for; the client's
+// Token and Client are not changed
+func (a *Client) Check(ctx context.Context) error {
+	config := oauth2.Config{
+		ClientID:     a.ClientID,
+		ClientSecret: a.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  fmt.Sprintf("%s/oauth/authorize", a.URL),
+			TokenURL: fmt.Sprintf("%s/oauth/token", a.URL),
+		},
+	}
+	if _, err := config.PasswordCredentialsToken(ctx, a.Username, a.Password); err != nil {
+		return errors.Wrap(err, "could not retrieve UAA token")
+	}
+	return nil
+}
@@ -37,6 +37,15 @@ type FakeAPI struct {
 		result1 string
 		result2 error
 	}
+	AuthenticateStub        func() error
+	authenticateMutex       sync.RWMutex
+	authenticateArgsForCall []struct{}
+	authenticateReturns     struct {
+		result1 error
+	}
+	authenticateReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -146,6 +155,46 @@ func (fake *FakeAPI) CreateUserReturnsOnCall(i int, result1 string, result2 erro
 	}{result1, result2}
 }
 
+func (fake *FakeAPI) Authenticate() error {
+	fake.authenticateMutex.Lock()
+	ret, specificReturn := fake.authenticateReturnsOnCall[len(fake.authenticateArgsForCall)]
+	fake.authenticateArgsForCall = append(fake.authenticateArgsForCall, struct{}{})
+	fake.recordInvocation("Authenticate", []interface{}{})
+	fake.authenticateMutex.Unlock()
+	if fake.AuthenticateStub != nil {
+		return fake.AuthenticateStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.authenticateReturns.result1
+}
+
+func (fake *FakeAPI) AuthenticateCallCount() int {
+	fake.authenticateMutex.RLock()
+	defer fake.authenticateMutex.RUnlock()
+	return len(fake.authenticateArgsForCall)
+}
+
+func (fake *FakeAPI) AuthenticateReturns(result1 error) {
+	fake.AuthenticateStub = nil
+	fake.authenticateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPI) AuthenticateReturnsOnCall(i int, result1 error) {
+	fake.AuthenticateStub = nil
+	if fake.authenticateReturnsOnCall == nil {
+		fake.authenticateReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.authenticateReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeAPI) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -153,6 +202,8 @@ func (fake *FakeAPI) Invocations() map[string][][]interface{} {
 	defer fake.userIDForAccountNameMutex.RUnlock()
 	fake.createUserMutex.RLock()
 	defer fake.createUserMutex.RUnlock()
+	fake.authenticateMutex.RLock()
+	defer fake.authenticateMutex.RUnlock()
 	return fake.invocations
 }
 
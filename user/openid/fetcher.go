@@ -34,6 +34,8 @@ type Claims struct {
 	GivenName  string `json:"given_name"`
 	FamilyName string `json:"family_name"`
 	Email      string `json:"email"`
+	// Groups are read from the claim named by OIDCIDVerifier.GroupsClaim
+	Groups []string `json:"-"`
 }
 
 // Profile retrieves the user's profile with the given context, config, and token
@@ -63,12 +65,15 @@ func (g *Fetcher) Profile(ctx context.Context, c *oauth2.Config, t *oauth2.Token
 		Email:       claims.Email,
 		AccountName: username,
 		Name:        strings.TrimSpace(fmt.Sprintf("%s %s", claims.GivenName, claims.FamilyName)),
+		Groups:      claims.Groups,
 	}, nil
 }
 
 // OIDCIDVerifier is an ID token verifier
 type OIDCIDVerifier struct {
 	Verifier OIDCVerifier
+	// GroupsClaim is the name of the claim that lists the user's IdP groups
+	GroupsClaim string
 }
 
 // Verify takes the given raw ID token and returns claims
@@ -81,15 +86,42 @@ func (o *OIDCIDVerifier) Verify(ctx context.Context, rawIDToken string) (*Claims
 	if err = idToken.Claims(&claims); err != nil {
 		return nil, err
 	}
+	if strings.TrimSpace(o.GroupsClaim) != "" {
+		var raw map[string]interface{}
+		if err = idToken.Claims(&raw); err != nil {
+			return nil, err
+		}
+		claims.Groups = groups(raw[o.GroupsClaim])
+	}
 	return &claims, nil
 }
 
-// NewVerifier returns a Verifier that uses a keySet fetched from the jwksURL
-func NewVerifier(issuerURL string, clientID string, jwksURL string) Verifier {
+// groups returns the groups in a claim, which IdPs send as a list of strings
+// or, for a single group, as a string
+func groups(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var result []string
+		for _, g := range v {
+			if s, ok := g.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// NewVerifier returns a Verifier that uses a keySet fetched from the jwksURL,
+// and reads the user's groups from the groupsClaim
+func NewVerifier(issuerURL string, clientID string, jwksURL string, groupsClaim string) Verifier {
 	keySet := oidc.NewRemoteKeySet(context.Background(), jwksURL)
 	return &OIDCIDVerifier{
 		Verifier: oidc.NewVerifier(issuerURL, keySet, &oidc.Config{
 			ClientID: clientID,
 		}),
+		GroupsClaim: groupsClaim,
 	}
 }
@@ -71,6 +71,7 @@ func testFetcher(t *testing.T, when spec.G, it spec.S) {
 			o *openid.OIDCIDVerifier
 			v *oidc.IDTokenVerifier
 		)
+		token := "eyJhbGciOiJSUzI1NiIsImtpZCI6ImtleS0xIiwidHlwIjoiSldUIn0.eyJqdGkiOiIwMDAwMDAwMC0wMDAwLTAwMDAtMDAwMC0wMDAwMDAwMDAwMDQiLCJzdWIiOiIwMDAwMDAwMC0wMDAwLTAwMDAtMDAwMC0wMDAwMDAwMDAwMDEiLCJzY29wZSI6WyJvcGVuaWQiLCJwcm9maWxlIiwidXNlcl9hdHRyaWJ1dGVzIl0sImNsaWVudF9pZCI6IjAwMDAwMDAwLTAwMDAtMDAwMC0wMDAwLTAwMDAwMDAwMDAwMiIsImNpZCI6IjAwMDAwMDAwLTAwMDAtMDAwMC0wMDAwLTAwMDAwMDAwMDAwMiIsImF6cCI6IjAwMDAwMDAwLTAwMDAtMDAwMC0wMDAwLTAwMDAwMDAwMDAwMiIsImdyYW50X3R5cGUiOiJhdXRob3JpemF0aW9uX2NvZGUiLCJ1c2VyX2lkIjoiMDAwMDAwMDAtMDAwMC0wMDAwLTAwMDAtMDAwMDAwMDAwMDAxIiwib3JpZ2luIjoib2t0YS1pZGVudGl0eSIsInVzZXJfbmFtZSI6InRlc3RlckBwaXZvdGFsLmlvIiwiZW1haWwiOiJ0ZXN0ZXJAcGl2b3RhbC5pbyIsImF1dGhfdGltZSI6MTUyMjc4MzUzOSwicmV2X3NpZyI6IjU5MjA4NzY5IiwiaWF0IjoxNTIyNzgzNTQwLCJleHAiOjE1MjI4Njk5NDAsImlzcyI6Imh0dHBzOi8vaWduaXRpb24udWFhLnJ1bi5wY2ZiZXRhLmlvL29hdXRoL3Rva2VuIiwiemlkIjoiMDAwMDAwMDAtMDAwMC0wMDAwLTAwMDAtMDAwMDAwMDAwMDAzIiwiYXVkIjpbIjAwMDAwMDAwLTAwMDAtMDAwMC0wMDAwLTAwMDAwMDAwMDAwMiIsIm9wZW5pZCJdfQ.dKoUGK93QSpOAAoxLUdT1VdfhdTW0FHSEs2jgLv77-1Nx_QLZbwkADLXitvM23GZLnFDMQ-JPjhNLo9UeOJNjtdVdzbpfwAITULvgt9k5jmeCxiIpiGiPE40VQpa7rDkpdVWLLMCbjTzowmSY3cG_FQ9FYwvPUXUzFcDqC3mDBVFhtw6eWygk1wv8lr7s57dNVmOAKY8YRJ6IQRs1pk1r7arq2bLLyXcVGT2dzPh18zsYvbbLc0JU0be6t0XBdsfts-7ZY1Yy30tXMmaaG9jihpYapfWDam1PI5cCV8fwptlx3KHfjKVunXxhK5XnNeHNKH9_UpptzaHsc8ov8Z2pYFNmnH_dbEzYS8wyky-46kLpb1mjokdHKGTAsNzV62ceaVs0quafGpLFmAtnQDxihXTeUNhb7sKQQ66vJI-SnzbiMzpdpuco8DxuGmh5fPcUkYM0rLuz_FlHCKKtjavzcjzgXJ9jEloLKnA_GrnomHk-nkCQdDk60HCih0hgo3odu3Le0vLe4jotZaQF_L1xvW0DseMg61Y44xz91Un-B2GI5l6rU7uC7xjKsLqA72aIu8a6KbdaPl9F0cKpJKZ6sGhEjLNeSI259La7dIvWalzdd7oLECT3q9YfXvpV4hfLR2PwW0YGpT7VTY9RpJwJe6uN5ZqFCxqcJ5RlV4Oc-4"
 
 		it.Before(func() {
 			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -106,18 +107,26 @@ func testFetcher(t *testing.T, when spec.G, it spec.S) {
 		})
 
 		it("returns the claims if there are no errors", func() {
-			c, err := o.Verify(context.Background(), "eyJhbGciOiJSUzI1NiIsImtpZCI6ImtleS0xIiwidHlwIjoiSldUIn0.eyJqdGkiOiIwMDAwMDAwMC0wMDAwLTAwMDAtMDAwMC0wMDAwMDAwMDAwMDQiLCJzdWIiOiIwMDAwMDAwMC0wMDAwLTAwMDAtMDAwMC0wMDAwMDAwMDAwMDEiLCJzY29wZSI6WyJvcGVuaWQiLCJwcm9maWxlIiwidXNlcl9hdHRyaWJ1dGVzIl0sImNsaWVudF9pZCI6IjAwMDAwMDAwLTAwMDAtMDAwMC0wMDAwLTAwMDAwMDAwMDAwMiIsImNpZCI6IjAwMDAwMDAwLTAwMDAtMDAwMC0wMDAwLTAwMDAwMDAwMDAwMiIsImF6cCI6IjAwMDAwMDAwLTAwMDAtMDAwMC0wMDAwLTAwMDAwMDAwMDAwMiIsImdyYW50X3R5cGUiOiJhdXRob3JpemF0aW9uX2NvZGUiLCJ1c2VyX2lkIjoiMDAwMDAwMDAtMDAwMC0wMDAwLTAwMDAtMDAwMDAwMDAwMDAxIiwib3JpZ2luIjoib2t0YS1pZGVudGl0eSIsInVzZXJfbmFtZSI6InRlc3RlckBwaXZvdGFsLmlvIiwiZW1haWwiOiJ0ZXN0ZXJAcGl2b3RhbC5pbyIsImF1dGhfdGltZSI6MTUyMjc4MzUzOSwicmV2X3NpZyI6IjU5MjA4NzY5IiwiaWF0IjoxNTIyNzgzNTQwLCJleHAiOjE1MjI4Njk5NDAsImlzcyI6Imh0dHBzOi8vaWduaXRpb24udWFhLnJ1bi5wY2ZiZXRhLmlvL29hdXRoL3Rva2VuIiwiemlkIjoiMDAwMDAwMDAtMDAwMC0wMDAwLTAwMDAtMDAwMDAwMDAwMDAzIiwiYXVkIjpbIjAwMDAwMDAwLTAwMDAtMDAwMC0wMDAwLTAwMDAwMDAwMDAwMiIsIm9wZW5pZCJdfQ.dKoUGK93QSpOAAoxLUdT1VdfhdTW0FHSEs2jgLv77-1Nx_QLZbwkADLXitvM23GZLnFDMQ-JPjhNLo9UeOJNjtdVdzbpfwAITULvgt9k5jmeCxiIpiGiPE40VQpa7rDkpdVWLLMCbjTzowmSY3cG_FQ9FYwvPUXUzFcDqC3mDBVFhtw6eWygk1wv8lr7s57dNVmOAKY8YRJ6IQRs1pk1r7arq2bLLyXcVGT2dzPh18zsYvbbLc0JU0be6t0XBdsfts-7ZY1Yy30tXMmaaG9jihpYapfWDam1PI5cCV8fwptlx3KHfjKVunXxhK5XnNeHNKH9_UpptzaHsc8ov8Z2pYFNmnH_dbEzYS8wyky-46kLpb1mjokdHKGTAsNzV62ceaVs0quafGpLFmAtnQDxihXTeUNhb7sKQQ66vJI-SnzbiMzpdpuco8DxuGmh5fPcUkYM0rLuz_FlHCKKtjavzcjzgXJ9jEloLKnA_GrnomHk-nkCQdDk60HCih0hgo3odu3Le0vLe4jotZaQF_L1xvW0DseMg61Y44xz91Un-B2GI5l6rU7uC7xjKsLqA72aIu8a6KbdaPl9F0cKpJKZ6sGhEjLNeSI259La7dIvWalzdd7oLECT3q9YfXvpV4hfLR2PwW0YGpT7VTY9RpJwJe6uN5ZqFCxqcJ5RlV4Oc-4")
+			c, err := o.Verify(context.Background(), token)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(c).NotTo(BeNil())
 			Expect(c.Email).To(Equal("tester@pivotal.io"))
 			Expect(c.UserID).To(Equal("00000000-0000-0000-0000-000000000001"))
 			Expect(c.UserName).To(Equal("tester@pivotal.io"))
 			Expect(c.Sub).To(Equal("00000000-0000-0000-0000-000000000001"))
+			Expect(c.Groups).To(BeNil())
+		})
+
+		it("reads the groups from the groups claim", func() {
+			o.GroupsClaim = "scope"
+			c, err := o.Verify(context.Background(), token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Groups).To(Equal([]string{"openid", "profile", "user_attributes"}))
 		})
 	})
 
 	it("creates a valid verifier", func() {
-		v := openid.NewVerifier("", "", "")
+		v := openid.NewVerifier("", "", "", "")
 		Expect(v).NotTo(BeNil())
 	})
 
@@ -132,7 +141,7 @@ func testFetcher(t *testing.T, when spec.G, it spec.S) {
 
 	when("the verifier is valid", func() {
 		it.Before(func() {
-			f.Verifier = openid.NewVerifier("", "", "")
+			f.Verifier = openid.NewVerifier("", "", "", "")
 		})
 
 		when("the token is nil", func() {
@@ -199,6 +208,15 @@ func testFetcher(t *testing.T, when spec.G, it spec.S) {
 					Expect(p.Email).To(Equal("test@example.net"))
 				})
 
+				it("includes the user's groups", func() {
+					v := &openidfakes.FakeVerifier{}
+					v.VerifyReturns(&openid.Claims{Email: "test@example.net", Groups: []string{"platform-team"}}, nil)
+					f.Verifier = v
+					p, err := f.Profile(context.Background(), nil, t)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(p.Groups).To(Equal([]string{"platform-team"}))
+				})
+
 				it("uses the email address as the account name if it is not set", func() {
 					v := &openidfakes.FakeVerifier{}
 					v.VerifyReturns(&openid.Claims{
@@ -17,6 +17,8 @@ type Profile struct {
 	Email       string
 	AccountName string
 	Name        string
+	// Groups are the user's groups in the identity provider
+	Groups []string
 }
 
 // unexported key type prevents collisions
@@ -0,0 +1,3 @@
+// Package web holds the JavaScript single-page app. Building with
+// `go build -tags embed` compiles the built bundle in web/dist into the binary
+package web
@@ -0,0 +1,24 @@
+//go:build embed
+// +build embed
+
+package web
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// dist is the web bundle built by `yarn build`
+//
+//go:embed all:dist
+var dist embed.FS
+
+// FileSystem returns the web bundle that is compiled into the binary
+func FileSystem() http.FileSystem {
+	sub, err := fs.Sub(dist, "dist")
+	if err != nil {
+		panic(err)
+	}
+	return http.FS(sub)
+}
@@ -0,0 +1,13 @@
+//go:build !embed
+// +build !embed
+
+package web
+
+import "net/http"
+
+// FileSystem returns nil, because the web bundle is only compiled into the
+// binary when it is built with the embed tag; the bundle is then served from
+// disk
+func FileSystem() http.FileSystem {
+	return nil
+}
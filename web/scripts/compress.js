@@ -0,0 +1,26 @@
+// Writes gzip and brotli variants of the built bundle, which the Go web app
+// serves to clients that accept them. Brotli needs node 11.7 or later
+const fs = require('fs')
+const path = require('path')
+const zlib = require('zlib')
+
+const dist = path.join(__dirname, '..', 'dist')
+const compressible = /\.(js|css|html|svg|map|json)$/
+
+function files (dir) {
+  return fs.readdirSync(dir).reduce((result, name) => {
+    const file = path.join(dir, name)
+    if (fs.statSync(file).isDirectory()) {
+      return result.concat(files(file))
+    }
+    return compressible.test(name) ? result.concat(file) : result
+  }, [])
+}
+
+files(dist).forEach(file => {
+  const content = fs.readFileSync(file)
+  fs.writeFileSync(`${file}.gz`, zlib.gzipSync(content, { level: 9 }))
+  if (zlib.brotliCompressSync) {
+    fs.writeFileSync(`${file}.br`, zlib.brotliCompressSync(content))
+  }
+})
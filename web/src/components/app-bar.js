@@ -97,7 +97,8 @@ class MenuAppBar extends React.Component {
       .then(response => {
         if (!response.ok) {
           if (response.status === 401) {
-            window.location.replace('/login')
+            const next = window.location.pathname + window.location.search
+            window.location.replace('/login?next=' + encodeURIComponent(next))
             return
           }
           window.location.replace('/' + response.status)
@@ -109,8 +110,9 @@ class MenuAppBar extends React.Component {
   }
 
   render () {
-    const { classes } = this.props
+    const { classes, config } = this.props
     const { anchorEl, profile } = this.state
+    const logo = (config && config.logo_url) || ignitionLogo
     const open = Boolean(anchorEl)
     let name = ''
     if (profile && profile.Name) {
@@ -122,7 +124,11 @@ class MenuAppBar extends React.Component {
         <AppBar color="white">
           <Toolbar disableGutters={true}>
             <div className={classes.logoContainer}>
-              <img className={classes.logo} src={ignitionLogo} />
+              <img
+                className={classes.logo}
+                src={logo}
+                alt={config ? config.title : ''}
+              />
             </div>
             {profile && (
               <div className={classes.userContainer}>
@@ -179,7 +185,8 @@ class MenuAppBar extends React.Component {
 MenuAppBar.propTypes = {
   classes: PropTypes.object.isRequired,
   testing: PropTypes.bool,
-  profile: PropTypes.object
+  profile: PropTypes.object,
+  config: PropTypes.object
 }
 
 MenuAppBar.propTypes = {
@@ -3,7 +3,8 @@ import PropTypes from 'prop-types'
 import { withStyles } from 'material-ui/styles'
 import Button from 'material-ui/Button'
 import Footer from './footer'
-import { getOrgUrl } from '../org'
+import { getOrg, getGettingStarted, watchProgress } from '../org'
+import { supportURL } from '../config'
 
 import milkyWay from './../../images/bkgd_milky-way_full.svg'
 import deepSpace from './../../images/bkgd_lvl2_deep-space.svg'
@@ -52,10 +53,13 @@ const speech1Background = '#083c61'
 const speech2Background = '#9bd2d2'
 const greenButton = '#007D69'
 
-const footerLinks = [
+const stepImages = [step1, step2, step3]
+
+// footerLinks links Contact to the operator's support
+const footerLinks = config => [
   { text: 'Copyright', url: '' },
   { text: 'Terms', url: '' },
-  { text: 'Contact', url: '' }
+  { text: 'Contact', url: supportURL(config) }
 ]
 
 const styles = theme => ({
@@ -142,6 +146,14 @@ const styles = theme => ({
     height: '146px',
     marginTop: '-82px'
   },
+  downloads: {
+    fontSize: '18px',
+    marginTop: '24px',
+    '& a': {
+      color: 'white',
+      padding: '0 8px'
+    }
+  },
 
   // CTA 3: spaces overview
   ctaSpaces: {
@@ -164,6 +176,20 @@ const styles = theme => ({
   temporary: {
     alignItems: 'center'
   },
+  orgError: {
+    color: theme.palette.error.main,
+    fontWeight: 'bold'
+  },
+  commands: {
+    fontSize: '1rem',
+    alignSelf: 'stretch',
+    '& pre': {
+      backgroundColor: 'rgba(0, 0, 0, 0.1)',
+      padding: theme.spacing.unit,
+      whiteSpace: 'pre-wrap',
+      userSelect: 'all'
+    }
+  },
   arrow: {
     position: 'absolute',
     bottom: '10px',
@@ -172,23 +198,65 @@ const styles = theme => ({
   }
 })
 
+const progressMessages = {
+  user_created: 'Your account is ready',
+  org_created: 'Your org has been created',
+  roles_granted: 'You are now an org manager',
+  space_ready: 'Your space is ready',
+  services_ready: 'Your services have been created',
+  sample_app_ready: 'Your sample app is running'
+}
+
 class Body extends React.Component {
   constructor (props) {
     super(props)
     this.state = {
-      orgUrl: ''
+      orgUrl: '',
+      orgError: null,
+      orgJob: null,
+      orgEvent: null,
+      gettingStarted: null
     }
   }
 
+  async componentDidMount () {
+    const gettingStarted = await getGettingStarted()
+    this.setState({ gettingStarted })
+  }
+
   handleOrgButtonClick = async () => {
     // TODO: show spinner
-    const url = await getOrgUrl()
+    this.setState({ orgError: null })
+    const stopWatching = watchProgress(e => this.setState({ orgEvent: e.type }))
+    const { url, error } = await getOrg(job => this.setState({ orgJob: job }))
+    stopWatching()
+    this.setState({ orgJob: null, orgEvent: null })
+    if (error) {
+      this.setState({ orgError: error })
+      return
+    }
     if (url) {
-      this.setState({ orgUrl: url })
+      this.setState({ orgUrl: url, orgError: null })
       window.location = url
     }
   }
 
+  renderOrgError () {
+    const { orgError, orgJob, orgEvent } = this.state
+    if (orgJob) {
+      return <p>{progressMessages[orgEvent] || `Creating ${orgJob.org_name}`}</p>
+    }
+    if (!orgError) {
+      return null
+    }
+    return (
+      <p className={this.props.classes.orgError}>
+        {orgError.message}
+        {orgError.retryable ? '' : ` (${orgError.code})`}
+      </p>
+    )
+  }
+
   renderWelcomeInfo () {
     const { classes } = this.props
 
@@ -198,6 +266,7 @@ class Body extends React.Component {
           <div className={classes.welcomeSpeech}>
             {introMessages.map((msg, i) => <p key={i}>{msg}</p>)}
             {this.renderButton('Give Me an Org!', classes.speechButton)}
+            {this.renderOrgError()}
           </div>
         </div>
         <div className={classes.rocketMan} />
@@ -206,52 +275,53 @@ class Body extends React.Component {
   }
 
   renderGettingStartedSteps () {
-    const { classes } = this.props
+    const { classes, config } = this.props
     return (
       <div className={classes.ctaSteps}>
         <div className={classes.pewPew} />
-        <div className={classes.step}>
-          <div>
-            <img className={classes.stepImage} src={step1} />
+        {config.steps.map((step, i) => (
+          <div className={classes.step} key={i}>
+            <div>
+              <img
+                className={classes.stepImage}
+                src={stepImages[i % stepImages.length]}
+              />
+            </div>
+            <p>
+              {step.text}
+              <br />
+              {step.link_url ? (
+                <a href={step.link_url}>{step.link_text}</a>
+              ) : (
+                step.link_text
+              )}
+              {step.suffix && <br />}
+              {step.suffix}
+            </p>
+            {i === 0 && this.renderCLIDownloads()}
           </div>
-          <p>
-            Get the<br />
-            <a href="https://docs.pivotal.io/pivotalcf/latest/cf-cli/">
-              Cloud Foundry CLI
-            </a>
-            <br />
-            from Pivotal
-          </p>
-        </div>
-        <div className={classes.step}>
-          <div>
-            <img className={classes.stepImage} src={step2} />
-          </div>
-          <p>
-            Download the <br />
-            <a href="https://github.com/cloudfoundry-samples/spring-music">
-              sample app
-            </a>
-            <br />
-            from Github
-          </p>
-        </div>
-        <div className={classes.step}>
-          <div>
-            <img className={classes.stepImage} src={step3} />
-          </div>
-          <p>
-            Learn to<br />
-            <a href="https://docs.pivotal.io/pivotalcf/latest/devguide/deploy-apps/deploy-app.html">
-              deploy an app
-            </a>
-          </p>
-        </div>
+        ))}
         <img className={classes.arrow} src={arrowIcon} />
       </div>
     )
   }
 
+  renderCLIDownloads () {
+    const { classes, config } = this.props
+    if (!config.cli_downloads || config.cli_downloads.length === 0) {
+      return null
+    }
+    return (
+      <div className={classes.downloads}>
+        {config.cli_downloads.map(l => (
+          <a href={l.url} key={l.name}>
+            {l.name}
+          </a>
+        ))}
+      </div>
+    )
+  }
+
   renderSpacesInfo () {
     const { classes } = this.props
     return (
@@ -259,10 +329,12 @@ class Body extends React.Component {
         <div>
           <div className={classes.spacesSpeech}>
             {spaceMessages.map((msg, i) => <p key={i}>{msg}</p>)}
+            {this.renderCommands()}
             {this.renderButton(
               `I'm ready. Go to my org!`,
               classes.speechButton
             )}
+            {this.renderOrgError()}
           </div>
         </div>
         <div className={classes.moonMan} />
@@ -270,6 +342,26 @@ class Body extends React.Component {
     )
   }
 
+  renderCommands () {
+    const { classes } = this.props
+    const { gettingStarted } = this.state
+    if (!gettingStarted) {
+      return null
+    }
+    const { commands, passcode_url: passcodeURL } = gettingStarted
+    return (
+      <div className={classes.commands}>
+        <p>Log in from the cf CLI:</p>
+        <pre>{commands.login}</pre>
+        <p>
+          When you are asked for a passcode, get one from{' '}
+          <a href={passcodeURL}>{passcodeURL}</a>. Then target your space:
+        </p>
+        <pre>{commands.target}</pre>
+      </div>
+    )
+  }
+
   renderButton (text, extraClasses) {
     let classes = this.props.classes.button
     if (extraClasses) classes += ' ' + extraClasses
@@ -292,14 +384,15 @@ class Body extends React.Component {
         {this.renderWelcomeInfo()}
         {this.renderGettingStartedSteps()}
         {this.renderSpacesInfo()}
-        <Footer links={footerLinks} logoURL={pivotalLogo} />
+        <Footer links={footerLinks(this.props.config)} logoURL={pivotalLogo} />
       </div>
     )
   }
 }
 
 Body.propTypes = {
-  classes: PropTypes.object.isRequired
+  classes: PropTypes.object.isRequired,
+  config: PropTypes.object.isRequired
 }
 
 const introMessages = [
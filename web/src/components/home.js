@@ -2,12 +2,29 @@ import React from 'react'
 import AppBar from './app-bar'
 import Body from './body'
 import withRoot from '../withRoot'
+import { getConfig, defaultConfig } from '../config'
 
-const Home = () => (
-  <div>
-    <AppBar />
-    <Body />
-  </div>
-)
+class Home extends React.Component {
+  constructor (props) {
+    super(props)
+    this.state = { config: defaultConfig }
+  }
+
+  async componentDidMount () {
+    const config = await getConfig()
+    document.title = config.title
+    this.setState({ config })
+  }
+
+  render () {
+    const { config } = this.state
+    return (
+      <div>
+        <AppBar config={config} />
+        <Body config={config} />
+      </div>
+    )
+  }
+}
 
 export default withRoot(Home)
@@ -0,0 +1,41 @@
+// defaultConfig is used until /config has been fetched, and when it cannot be
+const defaultConfig = {
+  title: 'Pivotal Ignition',
+  logo_url: '',
+  support: { name: '', email: '', url: '' },
+  steps: [],
+  cli_downloads: [],
+  foundations: []
+}
+
+// getConfig returns the operator's branding and content from /config
+async function getConfig () {
+  try {
+    const response = await window.fetch('/config', {
+      credentials: 'same-origin'
+    })
+    if (!response.ok) {
+      return defaultConfig
+    }
+    return { ...defaultConfig, ...(await response.json()) }
+  } catch (e) {
+    return defaultConfig
+  }
+}
+
+// supportURL is the link to the operator's support, or '' when there is none
+function supportURL (config) {
+  const { support } = config
+  if (!support) {
+    return ''
+  }
+  if (support.url) {
+    return support.url
+  }
+  if (support.email) {
+    return `mailto:${support.email}`
+  }
+  return ''
+}
+
+export { getConfig, supportURL, defaultConfig }
@@ -1,15 +1,132 @@
-async function getOrgUrl () {
-  const response = await window.fetch('/organization', {
-    credentials: 'same-origin'
+const pollInterval = 1000
+
+// maxJobMisses is how many times in a row the job may be unknown to the
+// instance that answers before getOrg gives up; jobs are only known to the
+// instance that runs them
+const maxJobMisses = 30
+
+// getOrg returns { url } for the user's org, or { error } with the code,
+// message and retryable flag from the API when the org is not available. When
+// the org does not exist yet it is provisioned, and onProgress is called with
+// the provisioning job as it runs
+async function getOrg (onProgress = () => {}) {
+  let result = await request('/organization')
+  // POST creates the org, and adds the user to the team orgs of their groups
+  if ((result.error && result.error.code === 'org_not_found') || (result.json && result.json.team_orgs_pending)) {
+    result = await request('/organization', { method: 'POST' })
+  }
+  let misses = 0
+  while (result.json && result.json.status_url) {
+    const job = result.json
+    onProgress(job)
+    if (job.status === 'succeeded') {
+      return { url: job.org.url }
+    }
+    if (job.status === 'failed') {
+      return { error: job.error || unknownError }
+    }
+    await sleep(pollInterval)
+    result = await request(job.status_url)
+    // another instance does not know the job, but knows the org once it has
+    // been created
+    while (result.error && result.error.code === 'job_not_found' && misses < maxJobMisses) {
+      misses++
+      const org = await request('/organization')
+      if (org.json) {
+        return { url: org.json.url }
+      }
+      await sleep(pollInterval)
+      result = await request(job.status_url)
+    }
+    if (result.json) {
+      misses = 0
+    }
+  }
+  if (result.error) {
+    return { error: result.error }
+  }
+  if (!result.json) {
+    return { error: unknownError }
+  }
+  return { url: result.json.url }
+}
+
+// watchProgress calls onEvent with each onboarding event (user_created,
+// org_created, roles_granted, space_ready, services_ready, sample_app_ready and
+// provisioning_failed) streamed from /events; the returned function stops
+// watching
+function watchProgress (onEvent) {
+  if (!window.EventSource) {
+    return () => {}
+  }
+  const source = new window.EventSource('/events', { withCredentials: true })
+  const types = ['user_created', 'org_created', 'roles_granted', 'space_ready', 'services_ready', 'sample_app_ready', 'provisioning_failed']
+  types.forEach(type => {
+    source.addEventListener(type, e => onEvent(JSON.parse(e.data)))
   })
-  if (!response.ok) {
-    return
+  return () => source.close()
+}
+
+// getGettingStarted returns the cf login and target commands for the user's
+// org, or null when the org has not been created yet
+async function getGettingStarted () {
+  const { json } = await request('/getting-started')
+  return json || null
+}
+
+async function getOrgUrl () {
+  const { url } = await getOrg()
+  return url
+}
+
+// csrfToken is the session's CSRF token, which ignition requires in the
+// X-CSRF-Token header of each request that changes state
+let csrfToken = null
+
+async function request (url, options = {}) {
+  const method = (options.method || 'GET').toUpperCase()
+  const headers = { ...options.headers }
+  if (!['GET', 'HEAD', 'OPTIONS'].includes(method)) {
+    headers['X-CSRF-Token'] = csrfToken || csrfCookie()
   }
-  const json = await response.json()
-  if (!json) {
-    return
+  let response
+  try {
+    response = await window.fetch(url, {
+      credentials: 'same-origin',
+      ...options,
+      headers
+    })
+  } catch (e) {
+    return { error: networkError }
   }
-  return json.url
+  csrfToken = response.headers.get('X-CSRF-Token') || csrfToken
+  const json = await response.json().catch(() => null)
+  if (!response.ok) {
+    return { error: (json && json.code) ? json : unknownError }
+  }
+  return { json }
+}
+
+// csrfCookie returns the CSRF token from the ignition-csrf cookie
+function csrfCookie () {
+  const cookie = document.cookie.split('; ').find(c => c.startsWith('ignition-csrf='))
+  return cookie ? decodeURIComponent(cookie.split('=')[1]) : ''
+}
+
+function sleep (ms) {
+  return new Promise(resolve => setTimeout(resolve, ms))
+}
+
+const networkError = {
+  code: 'network_error',
+  message: 'Could not reach ignition. Please try again.',
+  retryable: true
+}
+
+const unknownError = {
+  code: 'unknown_error',
+  message: 'Something went wrong. Please try again.',
+  retryable: true
 }
 
-export { getOrgUrl }
+export { getOrg, getOrgUrl, getGettingStarted, watchProgress }
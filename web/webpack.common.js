@@ -32,13 +32,13 @@ module.exports = {
   output: {
     path: path.join(__dirname, '/dist'),
     publicPath: '/',
-    filename: 'assets/bundle.js'
+    // the content hash lets the go web app serve assets as immutable
+    filename: 'assets/bundle.[contenthash].js'
   },
   plugins: [
     new HtmlWebpackPlugin({
       title: 'Pivotal Ignition',
       template: 'src/index.html',
-      hash: true,
       filename: 'index.html'
     })
   ]
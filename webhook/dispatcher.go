@@ -0,0 +1,265 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The defaults for the settings of a Dispatcher
+const (
+	DefaultMaxAttempts    = 8
+	DefaultInitialBackoff = 10 * time.Second
+	DefaultMaxBackoff     = 30 * time.Minute
+	DefaultTimeout        = 10 * time.Second
+)
+
+// Dispatcher queues a delivery of each event to each endpoint that subscribes
+// to it, and posts the deliveries in the background until they succeed or
+// have been attempted MaxAttempts times
+type Dispatcher struct {
+	Endpoints []Endpoint
+	Store     *Store
+	Client    *http.Client
+	// MaxAttempts is the number of times a delivery is attempted before it
+	// fails (default: DefaultMaxAttempts)
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, which doubles after
+	// each further attempt up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	once    sync.Once
+	mu      sync.Mutex
+	wake    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	running bool
+	now     func() time.Time
+}
+
+func (d *Dispatcher) init() {
+	d.once.Do(func() {
+		d.wake = make(chan struct{}, 1)
+		if d.now == nil {
+			d.now = time.Now
+		}
+		if d.Store == nil {
+			d.Store, _ = NewStore("")
+		}
+	})
+}
+
+// Notify queues a delivery of the event to each endpoint that subscribes to
+// it; the deliveries are posted once the Dispatcher has started
+func (d *Dispatcher) Notify(event string, data map[string]string) {
+	if d == nil {
+		return
+	}
+	d.init()
+	now := d.now().UTC()
+	e := Event{ID: newID(), Type: event, Time: now, Data: data}
+	var deliveries []Delivery
+	for _, endpoint := range d.Endpoints {
+		if !endpoint.Subscribes(event) {
+			continue
+		}
+		deliveries = append(deliveries, Delivery{
+			ID:            newID(),
+			URL:           endpoint.URL,
+			Event:         e,
+			Status:        StatusPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+	if err := d.Store.Save(deliveries...); err != nil {
+		log.Printf("could not queue webhook event [%s %s]: %v\n", event, e.ID, err)
+	}
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// History returns the deliveries selected by the filter, newest first
+func (d *Dispatcher) History(f Filter) []Delivery {
+	if d == nil {
+		return []Delivery{}
+	}
+	d.init()
+	return d.Store.History(f)
+}
+
+// Start posts the queued deliveries in the background, including those that
+// were pending when the process last stopped
+func (d *Dispatcher) Start() {
+	d.init()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.running {
+		return
+	}
+	d.running = true
+	d.stop = make(chan struct{})
+	d.done = make(chan struct{})
+	go d.work(d.stop, d.done)
+}
+
+// Stop waits for the delivery that is being posted to finish; pending
+// deliveries are resumed by the next Start
+func (d *Dispatcher) Stop() {
+	d.init()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.running {
+		return
+	}
+	d.running = false
+	close(d.stop)
+	<-d.done
+}
+
+func (d *Dispatcher) work(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	for {
+		for _, delivery := range d.Store.Due(d.now()) {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			d.attempt(delivery)
+		}
+
+		wait := time.Hour
+		if next, ok := d.Store.NextAttempt(); ok {
+			wait = next.Sub(d.now())
+		}
+		if wait < 0 {
+			wait = 0
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-d.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// attempt posts the delivery, and schedules a retry when it fails and can be
+// retried
+func (d *Dispatcher) attempt(delivery Delivery) {
+	endpoint, ok := d.endpoint(delivery.URL)
+	delivery.Attempts++
+	var retryable bool
+	var err error
+	if !ok {
+		err = errors.Errorf("[%s] is no longer a webhook endpoint", delivery.URL)
+	} else {
+		delivery.StatusCode, retryable, err = d.post(endpoint, delivery)
+	}
+
+	now := d.now().UTC()
+	delivery.UpdatedAt = now
+	switch {
+	case err == nil:
+		delivery.Status = StatusDelivered
+		delivery.Error = ""
+	case retryable && delivery.Attempts < d.maxAttempts():
+		delivery.Error = err.Error()
+		delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+	default:
+		delivery.Status = StatusFailed
+		delivery.Error = err.Error()
+		log.Printf("could not deliver webhook event [%s %s] to [%s] after %d attempts: %v\n", delivery.Event.Type, delivery.Event.ID, delivery.URL, delivery.Attempts, err)
+	}
+	if err := d.Store.Save(delivery); err != nil {
+		log.Printf("could not save webhook delivery [%s]: %v\n", delivery.ID, err)
+	}
+}
+
+// post sends the event to the endpoint; network errors, 408, 429 and 5xx
+// responses can be retried
+func (d *Dispatcher) post(endpoint Endpoint, delivery Delivery) (statusCode int, retryable bool, err error) {
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "could not encode webhook event")
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "could not post to [%s]", endpoint.URL)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ignition-webhook")
+	req.Header.Set(EventHeader, delivery.Event.Type)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(SignatureHeader, Sign(endpoint.Secret, body))
+
+	resp, err := d.client().Do(req)
+	if err != nil {
+		return 0, true, errors.Wrapf(err, "could not post to [%s]", endpoint.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return resp.StatusCode, false, nil
+	}
+	retryable = resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return resp.StatusCode, retryable, fmt.Errorf("[%s] responded with %s", endpoint.URL, resp.Status)
+}
+
+func (d *Dispatcher) endpoint(url string) (Endpoint, bool) {
+	for _, e := range d.Endpoints {
+		if e.URL == url {
+			return e, true
+		}
+	}
+	return Endpoint{}, false
+}
+
+func (d *Dispatcher) client() *http.Client {
+	if d.Client != nil {
+		return d.Client
+	}
+	return &http.Client{Timeout: DefaultTimeout}
+}
+
+func (d *Dispatcher) maxAttempts() int {
+	if d.MaxAttempts <= 0 {
+		return DefaultMaxAttempts
+	}
+	return d.MaxAttempts
+}
+
+// backoff is the wait before the retry that follows the given attempt
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.InitialBackoff
+	if wait <= 0 {
+		wait = DefaultInitialBackoff
+	}
+	max := d.MaxBackoff
+	if max <= 0 {
+		max = DefaultMaxBackoff
+	}
+	for i := 1; i < attempts && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		wait = max
+	}
+	return wait
+}
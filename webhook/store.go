@@ -0,0 +1,194 @@
+package webhook
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotalservices/ignition/internal/jsonfile"
+)
+
+// The statuses of a delivery
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+// DefaultHistoryLimit is the number of deliveries History returns when
+// Filter.Limit is not set
+const DefaultHistoryLimit = 100
+
+// finishedDeliveries is the number of delivered and failed deliveries that
+// are kept for the delivery history
+const finishedDeliveries = 1000
+
+// Delivery is the delivery of an event to one endpoint
+type Delivery struct {
+	ID     string `json:"id"`
+	URL    string `json:"url"`
+	Event  Event  `json:"event"`
+	Status string `json:"status"`
+	// Attempts is the number of times the event has been posted
+	Attempts int `json:"attempts"`
+	// StatusCode is the status of the endpoint's last response
+	StatusCode    int       `json:"status_code,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Finished is true when the delivery will not be attempted again
+func (d Delivery) Finished() bool {
+	return d.Status == StatusDelivered || d.Status == StatusFailed
+}
+
+// Filter selects deliveries; empty fields match every delivery
+type Filter struct {
+	Event  string
+	Status string
+	URL    string
+	// Limit is the maximum number of deliveries to return (default:
+	// DefaultHistoryLimit)
+	Limit int
+}
+
+// Matches is true when the delivery is selected by the filter
+func (f Filter) Matches(d Delivery) bool {
+	if f.Event != "" && f.Event != d.Event.Type {
+		return false
+	}
+	if f.Status != "" && f.Status != d.Status {
+		return false
+	}
+	return f.URL == "" || f.URL == d.URL
+}
+
+func (f Filter) limit() int {
+	if f.Limit <= 0 {
+		return DefaultHistoryLimit
+	}
+	return f.Limit
+}
+
+// Store is the delivery queue and history, and persists it to a file so that
+// pending deliveries survive a restart
+type Store struct {
+	path       string
+	mu         sync.Mutex
+	deliveries map[string]Delivery
+}
+
+// NewStore returns a Store that persists to the file at path, loading any
+// deliveries that are already in it. Deliveries are only kept in memory when
+// path is empty
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path:       path,
+		deliveries: map[string]Delivery{},
+	}
+	if strings.TrimSpace(path) == "" {
+		return s, nil
+	}
+	var deliveries []Delivery
+	if err := jsonfile.Read(path, "webhook store", &deliveries); err != nil {
+		return nil, err
+	}
+	for _, d := range deliveries {
+		s.deliveries[d.ID] = d
+	}
+	return s, nil
+}
+
+// Save stores the deliveries, replacing any deliveries with the same IDs
+func (s *Store) Save(deliveries ...Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range deliveries {
+		s.deliveries[d.ID] = d
+	}
+	return s.persist()
+}
+
+// Due returns the pending deliveries that should be attempted by now, oldest
+// first
+func (s *Store) Due(now time.Time) []Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []Delivery
+	for _, d := range s.sorted() {
+		if d.Status == StatusPending && !d.NextAttemptAt.After(now) {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// NextAttempt returns the time of the next pending delivery, and false when
+// there is none
+func (s *Store) NextAttempt() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var next time.Time
+	found := false
+	for _, d := range s.deliveries {
+		if d.Status != StatusPending {
+			continue
+		}
+		if !found || d.NextAttemptAt.Before(next) {
+			next = d.NextAttemptAt
+			found = true
+		}
+	}
+	return next, found
+}
+
+// History returns the deliveries selected by the filter, newest first
+func (s *Store) History(f Filter) []Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sorted := s.sorted()
+	result := []Delivery{}
+	for i := len(sorted) - 1; i >= 0 && len(result) < f.limit(); i-- {
+		if f.Matches(sorted[i]) {
+			result = append(result, sorted[i])
+		}
+	}
+	return result
+}
+
+func (s *Store) sorted() []Delivery {
+	result := make([]Delivery, 0, len(s.deliveries))
+	for _, d := range s.deliveries {
+		result = append(result, d)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].ID < result[j].ID
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+// persist prunes the oldest finished deliveries and writes the rest to the
+// file
+func (s *Store) persist() error {
+	sorted := s.sorted()
+	finished := 0
+	for i := len(sorted) - 1; i >= 0; i-- {
+		if !sorted[i].Finished() {
+			continue
+		}
+		finished++
+		if finished > finishedDeliveries {
+			delete(s.deliveries, sorted[i].ID)
+		}
+	}
+	if strings.TrimSpace(s.path) == "" {
+		return nil
+	}
+	return jsonfile.Write(s.path, "webhook store", s.sorted())
+}
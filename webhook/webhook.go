@@ -0,0 +1,110 @@
+// Package webhook notifies configured endpoints of onboarding events, with
+// HMAC-signed JSON payloads that are retried with exponential backoff until
+// they are delivered
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// The events that endpoints can subscribe to
+const (
+	EventUserCreated = "user.created"
+	EventOrgCreated  = "org.created"
+	// EventOrgReclaimed fires when an org is reclaimed; ignition does not
+	// reclaim orgs yet, so it is not sent until it does
+	EventOrgReclaimed = "org.reclaimed"
+	// EventQuotaChanged fires when an org's quota is changed; ignition does
+	// not change quotas yet, so it is not sent until it does
+	EventQuotaChanged = "quota.changed"
+)
+
+// Events are all of the events that endpoints can subscribe to
+var Events = []string{EventUserCreated, EventOrgCreated, EventOrgReclaimed, EventQuotaChanged}
+
+// IsEvent is true when name is one of the Events
+func IsEvent(name string) bool {
+	for _, e := range Events {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
+
+// The headers that are sent with each delivery
+const (
+	// SignatureHeader is "sha256=" followed by the hex encoded HMAC-SHA256 of
+	// the body, keyed with the endpoint's secret
+	SignatureHeader = "X-Ignition-Signature"
+	EventHeader     = "X-Ignition-Event"
+	DeliveryHeader  = "X-Ignition-Delivery"
+)
+
+// Event is the JSON payload that is posted to endpoints
+type Event struct {
+	ID   string            `json:"id"`
+	Type string            `json:"type"`
+	Time time.Time         `json:"time"`
+	Data map[string]string `json:"data,omitempty"`
+}
+
+// Endpoint is a URL that is notified of the events it subscribes to
+type Endpoint struct {
+	URL    string
+	Secret string
+	// Events are the events the endpoint is notified of; it is notified of
+	// every event when there are none
+	Events []string
+}
+
+// Subscribes is true when the endpoint is notified of the event
+func (e Endpoint) Subscribes(event string) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, s := range e.Events {
+		if strings.EqualFold(strings.TrimSpace(s), event) {
+			return true
+		}
+	}
+	return false
+}
+
+// Notifier is notified of onboarding events
+type Notifier interface {
+	Notify(event string, data map[string]string)
+}
+
+// Notify notifies n of the event, when there is a Notifier
+func Notify(n Notifier, event string, data map[string]string) {
+	if n == nil {
+		return
+	}
+	n.Notify(event, data)
+}
+
+// Sign returns the value of the SignatureHeader for the body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify is true when signature is the signature of the body
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
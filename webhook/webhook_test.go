@@ -0,0 +1,263 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/ignition/webhook"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+// receiver records the requests posted to it, and responds to each with the
+// next of its statuses, or 200 OK once they run out
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status = r.statuses[0]
+		r.statuses = r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+func TestDispatcher(t *testing.T) {
+	spec.Run(t, "Dispatcher", testDispatcher, spec.Report(report.Terminal{}))
+}
+
+func testDispatcher(t *testing.T, when spec.G, it spec.S) {
+	var (
+		r      *receiver
+		server *httptest.Server
+		d      *webhook.Dispatcher
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		r = &receiver{}
+		server = httptest.NewServer(r)
+		d = &webhook.Dispatcher{
+			Endpoints:      []webhook.Endpoint{{URL: server.URL, Secret: "test-secret"}},
+			InitialBackoff: 10 * time.Millisecond,
+			MaxBackoff:     40 * time.Millisecond,
+		}
+	})
+
+	it.After(func() {
+		d.Stop()
+		server.Close()
+	})
+
+	status := func(f webhook.Filter) func() string {
+		return func() string {
+			h := d.History(f)
+			if len(h) == 0 {
+				return ""
+			}
+			return h[0].Status
+		}
+	}
+
+	it("posts a signed event", func() {
+		d.Start()
+		d.Notify(webhook.EventOrgCreated, map[string]string{"org_name": "ignition-testuser"})
+		Eventually(status(webhook.Filter{}), time.Second).Should(Equal(webhook.StatusDelivered))
+
+		Expect(r.count()).To(Equal(1))
+		req, body := r.requests[0], r.bodies[0]
+		Expect(req.Method).To(Equal(http.MethodPost))
+		Expect(req.Header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(req.Header.Get(webhook.EventHeader)).To(Equal(webhook.EventOrgCreated))
+		Expect(req.Header.Get(webhook.SignatureHeader)).To(HavePrefix("sha256="))
+		Expect(webhook.Verify("test-secret", body, req.Header.Get(webhook.SignatureHeader))).To(BeTrue())
+		Expect(webhook.Verify("other-secret", body, req.Header.Get(webhook.SignatureHeader))).To(BeFalse())
+
+		var e webhook.Event
+		Expect(json.Unmarshal(body, &e)).To(Succeed())
+		Expect(e.Type).To(Equal(webhook.EventOrgCreated))
+		Expect(e.Data).To(HaveKeyWithValue("org_name", "ignition-testuser"))
+		Expect(e.ID).NotTo(BeEmpty())
+
+		h := d.History(webhook.Filter{})
+		Expect(h[0].ID).To(Equal(req.Header.Get(webhook.DeliveryHeader)))
+		Expect(h[0].Attempts).To(Equal(1))
+		Expect(h[0].StatusCode).To(Equal(http.StatusOK))
+	})
+
+	it("only notifies the endpoints that subscribe to the event", func() {
+		d.Endpoints[0].Events = []string{webhook.EventUserCreated}
+		d.Start()
+		d.Notify(webhook.EventOrgCreated, nil)
+		d.Notify(webhook.EventUserCreated, nil)
+		Eventually(status(webhook.Filter{}), time.Second).Should(Equal(webhook.StatusDelivered))
+		Expect(d.History(webhook.Filter{})).To(HaveLen(1))
+		Expect(r.requests[0].Header.Get(webhook.EventHeader)).To(Equal(webhook.EventUserCreated))
+	})
+
+	it("retries a failed delivery with backoff until it succeeds", func() {
+		r.statuses = []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}
+		d.Start()
+		d.Notify(webhook.EventUserCreated, nil)
+		Eventually(status(webhook.Filter{}), time.Second).Should(Equal(webhook.StatusDelivered))
+		Expect(r.count()).To(Equal(3))
+		h := d.History(webhook.Filter{})
+		Expect(h[0].Attempts).To(Equal(3))
+		Expect(h[0].Error).To(BeEmpty())
+		Expect(r.requests[1].Header.Get(webhook.DeliveryHeader)).To(Equal(r.requests[0].Header.Get(webhook.DeliveryHeader)))
+	})
+
+	it("fails the delivery after the maximum number of attempts", func() {
+		d.MaxAttempts = 3
+		r.statuses = []int{500, 500, 500, 500}
+		d.Start()
+		d.Notify(webhook.EventUserCreated, nil)
+		Eventually(status(webhook.Filter{}), time.Second).Should(Equal(webhook.StatusFailed))
+		Consistently(r.count, 100*time.Millisecond).Should(Equal(3))
+		h := d.History(webhook.Filter{Status: webhook.StatusFailed})
+		Expect(h).To(HaveLen(1))
+		Expect(h[0].StatusCode).To(Equal(http.StatusInternalServerError))
+		Expect(h[0].Error).To(ContainSubstring("500"))
+	})
+
+	it("does not retry a delivery that the endpoint rejects", func() {
+		r.statuses = []int{http.StatusBadRequest}
+		d.Start()
+		d.Notify(webhook.EventUserCreated, nil)
+		Eventually(status(webhook.Filter{}), time.Second).Should(Equal(webhook.StatusFailed))
+		Consistently(r.count, 100*time.Millisecond).Should(Equal(1))
+	})
+
+	it("retries a delivery when the endpoint cannot be reached", func() {
+		url := server.URL
+		server.Close()
+		d.MaxAttempts = 2
+		d.Start()
+		d.Notify(webhook.EventUserCreated, nil)
+		Eventually(status(webhook.Filter{}), time.Second).Should(Equal(webhook.StatusFailed))
+		h := d.History(webhook.Filter{URL: url})
+		Expect(h[0].Attempts).To(Equal(2))
+		Expect(h[0].Error).To(ContainSubstring("could not post"))
+	})
+
+	it("does not post until it has started", func() {
+		d.Notify(webhook.EventUserCreated, nil)
+		Consistently(r.count, 50*time.Millisecond).Should(Equal(0))
+		Expect(status(webhook.Filter{})()).To(Equal(webhook.StatusPending))
+		d.Start()
+		Eventually(status(webhook.Filter{}), time.Second).Should(Equal(webhook.StatusDelivered))
+	})
+
+	when("the store is persisted", func() {
+		var dir string
+
+		it.Before(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "webhook")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it.After(func() {
+			os.RemoveAll(dir)
+		})
+
+		it("delivers the events that were pending when it stopped", func() {
+			path := filepath.Join(dir, "webhooks.json")
+			store, err := webhook.NewStore(path)
+			Expect(err).NotTo(HaveOccurred())
+			d.Store = store
+			d.Notify(webhook.EventOrgCreated, map[string]string{"org_name": "ignition-testuser"})
+
+			store, err = webhook.NewStore(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(store.History(webhook.Filter{Status: webhook.StatusPending})).To(HaveLen(1))
+			restarted := &webhook.Dispatcher{Endpoints: d.Endpoints, Store: store}
+			restarted.Start()
+			defer restarted.Stop()
+			Eventually(func() int { return len(restarted.History(webhook.Filter{Status: webhook.StatusDelivered})) }, time.Second).Should(Equal(1))
+			Expect(r.count()).To(Equal(1))
+
+			store, err = webhook.NewStore(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(store.History(webhook.Filter{Status: webhook.StatusDelivered})).To(HaveLen(1))
+		})
+
+		it("fails for a store that cannot be parsed", func() {
+			path := filepath.Join(dir, "webhooks.json")
+			Expect(ioutil.WriteFile(path, []byte("{"), 0600)).To(Succeed())
+			_, err := webhook.NewStore(path)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+}
+
+func TestStore(t *testing.T) {
+	spec.Run(t, "Store", testStore, spec.Report(report.Terminal{}))
+}
+
+func testStore(t *testing.T, when spec.G, it spec.S) {
+	var s *webhook.Store
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		var err error
+		s, err = webhook.NewStore("")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it("returns the history newest first, filtered and limited", func() {
+		now := time.Now()
+		Expect(s.Save(
+			webhook.Delivery{ID: "1", Status: webhook.StatusDelivered, Event: webhook.Event{Type: webhook.EventUserCreated}, CreatedAt: now.Add(-3 * time.Minute)},
+			webhook.Delivery{ID: "2", Status: webhook.StatusFailed, Event: webhook.Event{Type: webhook.EventOrgCreated}, CreatedAt: now.Add(-2 * time.Minute)},
+			webhook.Delivery{ID: "3", Status: webhook.StatusPending, Event: webhook.Event{Type: webhook.EventUserCreated}, CreatedAt: now.Add(-1 * time.Minute), NextAttemptAt: now.Add(time.Minute)},
+		)).To(Succeed())
+
+		h := s.History(webhook.Filter{})
+		Expect(h).To(HaveLen(3))
+		Expect(h[0].ID).To(Equal("3"))
+		Expect(h[2].ID).To(Equal("1"))
+		Expect(s.History(webhook.Filter{Event: webhook.EventUserCreated, Limit: 1})[0].ID).To(Equal("3"))
+		Expect(s.History(webhook.Filter{Status: webhook.StatusFailed})[0].ID).To(Equal("2"))
+
+		Expect(s.Due(now)).To(BeEmpty())
+		Expect(s.Due(now.Add(time.Minute))).To(HaveLen(1))
+		next, ok := s.NextAttempt()
+		Expect(ok).To(BeTrue())
+		Expect(next).To(BeTemporally("~", now.Add(time.Minute)))
+	})
+
+	it("prunes the oldest finished deliveries", func() {
+		now := time.Now()
+		for i := 0; i < 1005; i++ {
+			Expect(s.Save(webhook.Delivery{ID: time.Duration(i).String(), Status: webhook.StatusDelivered, CreatedAt: now.Add(time.Duration(i) * time.Second)})).To(Succeed())
+		}
+		Expect(s.Save(webhook.Delivery{ID: "pending", Status: webhook.StatusPending, CreatedAt: now.Add(-time.Hour)})).To(Succeed())
+		Expect(s.History(webhook.Filter{Limit: 2000})).To(HaveLen(1001))
+		Expect(s.History(webhook.Filter{Status: webhook.StatusPending})).To(HaveLen(1))
+	})
+}